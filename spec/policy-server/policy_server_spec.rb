@@ -15,6 +15,8 @@ module Bosh::Template::Test
       {
         'disable' => false,
         'policy_cleanup_interval' => 1,
+        'fqdn_resolution_interval' => 30,
+        'egress_destination_overlap_mode' => 'reject',
         'max_policies_per_app_source' => 2,
         'enable_space_developer_self_service' => true,
         'listen_ip' => '111.11.11.1',
@@ -47,6 +49,8 @@ module Bosh::Template::Test
         'metron_port' => 6789,
         'log_level' => 'debug',
         'allowed_cors_domains' => ['some-cors-domain'],
+        'rate_limits' => {'create_policies' => {'requests_per_second' => 5, 'burst' => 20}},
+        'rate_limit_exempt_network_admin' => true,
       }
     end
 
@@ -96,9 +100,13 @@ module Bosh::Template::Test
           'metron_address' => '127.0.0.1:6789',
           'log_level' => 'debug',
           'cleanup_interval' => 60,
+          'fqdn_resolution_interval' => 30,
+          'egress_destination_overlap_mode' => 'reject',
           'max_policies' => 2,
           'enable_space_developer_self_service' => true,
           'allowed_cors_domains' => ['some-cors-domain'],
+          'rate_limits' => {'create_policies' => {'requests_per_second' => 5, 'burst' => 20}},
+          'rate_limit_exempt_network_admin' => true,
           'uaa_ca' => '/var/vcap/jobs/policy-server/config/certs/uaa_ca.crt',
           'request_timeout' => 5,
         })
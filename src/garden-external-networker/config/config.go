@@ -20,6 +20,17 @@ type Config struct {
 	ProxyRedirectCIDR string   `json:"proxy_redirect_cidr"`
 	ProxyPort         int      `json:"proxy_port"`
 	ProxyUID          *int     `json:"proxy_uid"`
+
+	IPTablesLockTimeoutSeconds             int `json:"iptables_lock_timeout_seconds"`
+	IPTablesLockContentionThresholdSeconds int `json:"iptables_lock_contention_threshold_seconds"`
+
+	// MetronAddress is where the iptables lock metrics are sent. They are
+	// not sent when it is empty.
+	MetronAddress string `json:"metron_address"`
+
+	PortAllocationStrategy string `json:"port_allocation_strategy"`
+	PortCoolDownSeconds    int    `json:"port_cool_down_seconds"`
+	ReservedPorts          []int  `json:"reserved_ports"`
 }
 
 func New(configFilePath string) (Config, error) {
@@ -35,6 +35,12 @@ var _ = Describe("Config", func() {
 					"total_ports": 56,
 					"log_prefix": "prefix",
 					"iptables_lock_file": "some-lock-file",
+					"iptables_lock_timeout_seconds": 30,
+					"iptables_lock_contention_threshold_seconds": 5,
+					"metron_address": "127.0.0.1:3457",
+					"port_allocation_strategy": "round-robin",
+					"port_cool_down_seconds": 60,
+					"reserved_ports": [1234, 1235],
 					"proxy_redirect_cidr": "some-cidr",
 					"proxy_port": 1111,
 					"proxy_uid": 1,
@@ -55,6 +61,12 @@ var _ = Describe("Config", func() {
 				Expect(c.LogPrefix).To(Equal("prefix"))
 				Expect(c.SearchDomains).Should(ConsistOf("pivotal.io", "foo.bar", "baz.me"))
 				Expect(c.IPTablesLockFile).To(Equal("some-lock-file"))
+				Expect(c.IPTablesLockTimeoutSeconds).To(Equal(30))
+				Expect(c.IPTablesLockContentionThresholdSeconds).To(Equal(5))
+				Expect(c.MetronAddress).To(Equal("127.0.0.1:3457"))
+				Expect(c.PortAllocationStrategy).To(Equal("round-robin"))
+				Expect(c.PortCoolDownSeconds).To(Equal(60))
+				Expect(c.ReservedPorts).To(Equal([]int{1234, 1235}))
 				Expect(c.ProxyRedirectCIDR).To(Equal("some-cidr"))
 				Expect(c.ProxyPort).To(Equal(1111))
 				Expect(*c.ProxyUID).To(Equal(1))
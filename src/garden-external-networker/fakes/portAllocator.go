@@ -31,6 +31,19 @@ type PortAllocator struct {
 	releaseAllPortsReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ReconcileStub        func(liveHandles []string) ([]string, error)
+	reconcileMutex       sync.RWMutex
+	reconcileArgsForCall []struct {
+		liveHandles []string
+	}
+	reconcileReturns struct {
+		result1 []string
+		result2 error
+	}
+	reconcileReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -135,6 +148,57 @@ func (fake *PortAllocator) ReleaseAllPortsReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *PortAllocator) Reconcile(liveHandles []string) ([]string, error) {
+	fake.reconcileMutex.Lock()
+	ret, specificReturn := fake.reconcileReturnsOnCall[len(fake.reconcileArgsForCall)]
+	fake.reconcileArgsForCall = append(fake.reconcileArgsForCall, struct {
+		liveHandles []string
+	}{liveHandles})
+	fake.recordInvocation("Reconcile", []interface{}{liveHandles})
+	fake.reconcileMutex.Unlock()
+	if fake.ReconcileStub != nil {
+		return fake.ReconcileStub(liveHandles)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.reconcileReturns.result1, fake.reconcileReturns.result2
+}
+
+func (fake *PortAllocator) ReconcileCallCount() int {
+	fake.reconcileMutex.RLock()
+	defer fake.reconcileMutex.RUnlock()
+	return len(fake.reconcileArgsForCall)
+}
+
+func (fake *PortAllocator) ReconcileArgsForCall(i int) []string {
+	fake.reconcileMutex.RLock()
+	defer fake.reconcileMutex.RUnlock()
+	return fake.reconcileArgsForCall[i].liveHandles
+}
+
+func (fake *PortAllocator) ReconcileReturns(result1 []string, result2 error) {
+	fake.ReconcileStub = nil
+	fake.reconcileReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *PortAllocator) ReconcileReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.ReconcileStub = nil
+	if fake.reconcileReturnsOnCall == nil {
+		fake.reconcileReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.reconcileReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *PortAllocator) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -142,6 +206,8 @@ func (fake *PortAllocator) Invocations() map[string][][]interface{} {
 	defer fake.allocatePortMutex.RUnlock()
 	fake.releaseAllPortsMutex.RLock()
 	defer fake.releaseAllPortsMutex.RUnlock()
+	fake.reconcileMutex.RLock()
+	defer fake.reconcileMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
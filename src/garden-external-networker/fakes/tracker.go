@@ -33,16 +33,18 @@ type Tracker struct {
 	releaseAllReturnsOnCall map[int]struct {
 		result1 error
 	}
-	InRangeStub        func(port int) bool
-	inRangeMutex       sync.RWMutex
-	inRangeArgsForCall []struct {
-		port int
+	AcquireStub        func(pool *port_allocator.Pool, port int, handle string) error
+	acquireMutex       sync.RWMutex
+	acquireArgsForCall []struct {
+		pool   *port_allocator.Pool
+		port   int
+		handle string
 	}
-	inRangeReturns struct {
-		result1 bool
+	acquireReturns struct {
+		result1 error
 	}
-	inRangeReturnsOnCall map[int]struct {
-		result1 bool
+	acquireReturnsOnCall map[int]struct {
+		result1 error
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
@@ -149,51 +151,53 @@ func (fake *Tracker) ReleaseAllReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *Tracker) InRange(port int) bool {
-	fake.inRangeMutex.Lock()
-	ret, specificReturn := fake.inRangeReturnsOnCall[len(fake.inRangeArgsForCall)]
-	fake.inRangeArgsForCall = append(fake.inRangeArgsForCall, struct {
-		port int
-	}{port})
-	fake.recordInvocation("InRange", []interface{}{port})
-	fake.inRangeMutex.Unlock()
-	if fake.InRangeStub != nil {
-		return fake.InRangeStub(port)
+func (fake *Tracker) Acquire(pool *port_allocator.Pool, port int, handle string) error {
+	fake.acquireMutex.Lock()
+	ret, specificReturn := fake.acquireReturnsOnCall[len(fake.acquireArgsForCall)]
+	fake.acquireArgsForCall = append(fake.acquireArgsForCall, struct {
+		pool   *port_allocator.Pool
+		port   int
+		handle string
+	}{pool, port, handle})
+	fake.recordInvocation("Acquire", []interface{}{pool, port, handle})
+	fake.acquireMutex.Unlock()
+	if fake.AcquireStub != nil {
+		return fake.AcquireStub(pool, port, handle)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	return fake.inRangeReturns.result1
+	return fake.acquireReturns.result1
 }
 
-func (fake *Tracker) InRangeCallCount() int {
-	fake.inRangeMutex.RLock()
-	defer fake.inRangeMutex.RUnlock()
-	return len(fake.inRangeArgsForCall)
+func (fake *Tracker) AcquireCallCount() int {
+	fake.acquireMutex.RLock()
+	defer fake.acquireMutex.RUnlock()
+	return len(fake.acquireArgsForCall)
 }
 
-func (fake *Tracker) InRangeArgsForCall(i int) int {
-	fake.inRangeMutex.RLock()
-	defer fake.inRangeMutex.RUnlock()
-	return fake.inRangeArgsForCall[i].port
+func (fake *Tracker) AcquireArgsForCall(i int) (*port_allocator.Pool, int, string) {
+	fake.acquireMutex.RLock()
+	defer fake.acquireMutex.RUnlock()
+	return fake.acquireArgsForCall[i].pool, fake.acquireArgsForCall[i].port, fake.acquireArgsForCall[i].handle
 }
 
-func (fake *Tracker) InRangeReturns(result1 bool) {
-	fake.InRangeStub = nil
-	fake.inRangeReturns = struct {
-		result1 bool
+func (fake *Tracker) AcquireReturns(result1 error) {
+	fake.AcquireStub = nil
+	fake.acquireReturns = struct {
+		result1 error
 	}{result1}
 }
 
-func (fake *Tracker) InRangeReturnsOnCall(i int, result1 bool) {
-	fake.InRangeStub = nil
-	if fake.inRangeReturnsOnCall == nil {
-		fake.inRangeReturnsOnCall = make(map[int]struct {
-			result1 bool
+func (fake *Tracker) AcquireReturnsOnCall(i int, result1 error) {
+	fake.AcquireStub = nil
+	if fake.acquireReturnsOnCall == nil {
+		fake.acquireReturnsOnCall = make(map[int]struct {
+			result1 error
 		})
 	}
-	fake.inRangeReturnsOnCall[i] = struct {
-		result1 bool
+	fake.acquireReturnsOnCall[i] = struct {
+		result1 error
 	}{result1}
 }
 
@@ -204,8 +208,8 @@ func (fake *Tracker) Invocations() map[string][][]interface{} {
 	defer fake.acquireOneMutex.RUnlock()
 	fake.releaseAllMutex.RLock()
 	defer fake.releaseAllMutex.RUnlock()
-	fake.inRangeMutex.RLock()
-	defer fake.inRangeMutex.RUnlock()
+	fake.acquireMutex.RLock()
+	defer fake.acquireMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
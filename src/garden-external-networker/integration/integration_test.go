@@ -357,6 +357,25 @@ var _ = Describe("Garden External Networker", func() {
 		runAndWait(downCommand2)
 	})
 
+	It("releases the ports of containers that were never brought down when reconciling", func() {
+		runAndWait(upCommand)
+
+		reconcileCommand := exec.Command(paths.PathToAdapter)
+		reconcileCommand.Env = append(os.Environ(), "FAKE_LOG_DIR="+fakeLogDir)
+		reconcileCommand.Stdin = strings.NewReader(`{"live_handles": []}`)
+		reconcileCommand.Args = []string{
+			paths.PathToAdapter,
+			"--action", "reconcile",
+			"--configFile", fakeConfigFilePath,
+		}
+		reconcileSession := runAndWait(reconcileCommand)
+		Expect(reconcileSession.Out.Contents()).To(MatchJSON(fmt.Sprintf(`{"released_handles": [%q]}`, containerHandle)))
+
+		stateFileContents, err := ioutil.ReadFile(stateFilePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(stateFileContents)).NotTo(ContainSubstring(containerHandle))
+	})
+
 	Context("when the CNI plugin result DNS servers list is empty", func() {
 		BeforeEach(func() {
 			upCommand.Env = append(upCommand.Env, "FAKE_CNI_DEBUG=no_dns_result")
@@ -8,12 +8,13 @@ import (
 )
 
 type Mux struct {
-	Up   func(handle string, inputs manager.UpInputs) (*manager.UpOutputs, error)
-	Down func(handle string) error
+	Up        func(handle string, inputs manager.UpInputs) (*manager.UpOutputs, error)
+	Down      func(handle string) error
+	Reconcile func(inputs manager.ReconcileInputs) (*manager.ReconcileOutputs, error)
 }
 
 func (m *Mux) Handle(action string, handle string, stdin io.Reader, stdout io.Writer) error {
-	if handle == "" {
+	if handle == "" && action != "reconcile" {
 		return fmt.Errorf("missing handle")
 	}
 
@@ -35,6 +36,18 @@ func (m *Mux) Handle(action string, handle string, stdin io.Reader, stdout io.Wr
 		if err != nil {
 			return err
 		}
+	case "reconcile":
+		var inputs manager.ReconcileInputs
+		if err := json.NewDecoder(stdin).Decode(&inputs); err != nil && err != io.EOF {
+			return err
+		}
+		outputs, err := m.Reconcile(inputs)
+		if err != nil {
+			return err
+		}
+		if err := json.NewEncoder(stdout).Encode(outputs); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("unrecognized action: %s", action)
 	}
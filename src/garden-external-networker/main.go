@@ -16,10 +16,15 @@ import (
 	"lib/serial"
 	"os"
 	"sync"
+	"time"
 
+	"github.com/cloudfoundry/dropsonde"
+	dropsondemetrics "github.com/cloudfoundry/dropsonde/metrics"
 	"github.com/coreos/go-iptables/iptables"
 
+	"code.cloudfoundry.org/cf-networking-helpers/metrics"
 	"code.cloudfoundry.org/filelock"
+	"code.cloudfoundry.org/lager"
 )
 
 var (
@@ -56,7 +61,7 @@ func parseArgs(allArgs []string) error {
 		return fmt.Errorf("unexpected extra args: %+v", flagSet.Args())
 	}
 
-	if handle == "" {
+	if handle == "" && action != "reconcile" {
 		return fmt.Errorf("missing required flag 'handle'")
 	}
 
@@ -106,11 +111,19 @@ func mainWithError(logger io.Writer) error {
 	mounter := &bindmount.Mounter{}
 
 	locker := filelock.NewLocker(cfg.StateFilePath)
+	strategy, err := port_allocator.NewStrategy(cfg.PortAllocationStrategy, time.Duration(cfg.PortCoolDownSeconds)*time.Second)
+	if err != nil {
+		return fmt.Errorf("port allocation strategy: %s", err)
+	}
 	tracker := &port_allocator.Tracker{
-		StartPort: cfg.StartPort,
-		Capacity:  cfg.TotalPorts,
+		StartPort:     cfg.StartPort,
+		Capacity:      cfg.TotalPorts,
+		ReservedPorts: cfg.ReservedPorts,
+		Strategy:      strategy,
+	}
+	serializer := &serial.ChecksumSerial{
+		BackupPath: cfg.StateFilePath + ".backup",
 	}
-	serializer := &serial.Serial{}
 	portAllocator := &port_allocator.PortAllocator{
 		Tracker:    tracker,
 		Serializer: serializer,
@@ -128,9 +141,28 @@ func mainWithError(logger io.Writer) error {
 	}
 	restorer := &rules.Restorer{}
 	lockedIPTables := &rules.LockedIPTables{
-		IPTables: ipt,
-		Locker:   iptLocker,
-		Restorer: restorer,
+		IPTables:            ipt,
+		Locker:              iptLocker,
+		Restorer:            restorer,
+		Saver:               &rules.Saver{},
+		TableRestorer:       &rules.TableRestorer{},
+		LockTimeout:         time.Duration(cfg.IPTablesLockTimeoutSeconds) * time.Second,
+		ContentionThreshold: time.Duration(cfg.IPTablesLockContentionThresholdSeconds) * time.Second,
+		LockFile:            cfg.IPTablesLockFile,
+		Logger:              logger,
+	}
+	if cfg.MetronAddress != "" {
+		if err := dropsonde.Initialize(cfg.MetronAddress, "garden-external-networker"); err != nil {
+			return fmt.Errorf("initializing dropsonde: %s", err)
+		}
+		// counters are batched, so flush them before exiting
+		defer dropsondemetrics.Close()
+
+		metricsLogger := lager.NewLogger(cfg.LogPrefix + ".garden-external-networker")
+		metricsLogger.RegisterSink(lager.NewWriterSink(logger, lager.ERROR))
+		lockedIPTables.MetricsSender = &metrics.MetricsSender{
+			Logger: metricsLogger.Session("time-metric-emitter"),
+		}
 	}
 
 	namespaceAdapter := &adapter.NamespaceAdapter{}
@@ -154,8 +186,9 @@ func mainWithError(logger io.Writer) error {
 	}
 
 	mux := ipc.Mux{
-		Up:   manager.Up,
-		Down: manager.Down,
+		Up:        manager.Up,
+		Down:      manager.Down,
+		Reconcile: manager.Reconcile,
 	}
 
 	return mux.Handle(action, handle, os.Stdin, os.Stdout)
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"path/filepath"
 
 	"code.cloudfoundry.org/garden"
@@ -33,6 +34,7 @@ type mounter interface {
 type portAllocator interface {
 	AllocatePort(handle string, port int) (int, error)
 	ReleaseAllPorts(handle string) error
+	Reconcile(liveHandles []string) ([]string, error)
 }
 
 type Manager struct {
@@ -80,13 +82,15 @@ func (m *Manager) Up(containerHandle string, inputs UpInputs) (*UpOutputs, error
 
 	mappedPorts := []garden.PortMapping{}
 	for i := range inputs.NetIn {
-		if inputs.NetIn[i].HostPort == 0 {
-			hostPort, err := m.PortAllocator.AllocatePort(containerHandle, int(inputs.NetIn[i].HostPort))
-			if err != nil {
-				return nil, fmt.Errorf("allocating port: %s", err)
+		hostPort, err := m.PortAllocator.AllocatePort(containerHandle, int(inputs.NetIn[i].HostPort))
+		if err != nil {
+			// do not leak the ports allocated for the earlier entries
+			if releaseErr := m.PortAllocator.ReleaseAllPorts(containerHandle); releaseErr != nil {
+				return nil, fmt.Errorf("allocating port: %s and releasing ports: %s", err, releaseErr)
 			}
-			inputs.NetIn[i].HostPort = uint32(hostPort)
+			return nil, fmt.Errorf("allocating port: %s", err)
 		}
+		inputs.NetIn[i].HostPort = uint32(hostPort)
 
 		mappedPorts = append(mappedPorts, garden.PortMapping{
 			HostPort:      inputs.NetIn[i].HostPort,
@@ -155,6 +159,38 @@ func (m *Manager) Down(containerHandle string) error {
 	return nil
 }
 
+type ReconcileInputs struct {
+	LiveHandles []string `json:"live_handles"`
+}
+
+type ReconcileOutputs struct {
+	ReleasedHandles []string `json:"released_handles"`
+}
+
+// Reconcile releases the ports of containers that are gone without having
+// been brought down. Without a list of live handles, the containers that
+// still have a bind mount are live.
+func (m *Manager) Reconcile(inputs ReconcileInputs) (*ReconcileOutputs, error) {
+	liveHandles := inputs.LiveHandles
+	if liveHandles == nil {
+		bindMounts, err := ioutil.ReadDir(m.BindMountRoot)
+		if err != nil {
+			return nil, fmt.Errorf("listing bind mounts: %s", err)
+		}
+		liveHandles = []string{}
+		for _, bindMount := range bindMounts {
+			liveHandles = append(liveHandles, bindMount.Name())
+		}
+	}
+
+	released, err := m.PortAllocator.Reconcile(liveHandles)
+	if err != nil {
+		return nil, fmt.Errorf("reconciling ports: %s", err)
+	}
+
+	return &ReconcileOutputs{ReleasedHandles: released}, nil
+}
+
 func toJson(mappedPorts []garden.PortMapping) string {
 	bytes, err := json.Marshal(mappedPorts)
 	if err != nil {
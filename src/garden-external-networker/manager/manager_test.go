@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"os"
 	"path/filepath"
 
 	"code.cloudfoundry.org/garden"
@@ -42,6 +44,9 @@ var _ = Describe("Manager", func() {
 		cniController = &fakes.CNIController{}
 		portAllocator = &fakes.PortAllocator{}
 		proxyRedirect = &fakes.ProxyRedirect{}
+		portAllocator.AllocatePortStub = func(handle string, port int) (int, error) {
+			return port, nil
+		}
 
 		cniController.UpReturns(&types020.Result{
 			IP4: &types020.IPConfig{
@@ -184,6 +189,18 @@ var _ = Describe("Manager", func() {
 			]`))
 		})
 
+		It("allocates the host ports that are given", func() {
+			_, err := mgr.Up(containerHandle, upInputs)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(portAllocator.AllocatePortCallCount()).To(Equal(2))
+			handle, port := portAllocator.AllocatePortArgsForCall(0)
+			Expect(handle).To(Equal(containerHandle))
+			Expect(port).To(Equal(12345))
+			_, port = portAllocator.AllocatePortArgsForCall(1)
+			Expect(port).To(Equal(23456))
+		})
+
 		Context("when the host port is 0", func() {
 			BeforeEach(func() {
 				netInRules = []garden.NetIn{
@@ -193,6 +210,7 @@ var _ = Describe("Manager", func() {
 					},
 				}
 				upInputs.NetIn = netInRules
+				portAllocator.AllocatePortStub = nil
 				portAllocator.AllocatePortReturns(1234, nil)
 			})
 			It("allocates a port", func() {
@@ -228,6 +246,7 @@ var _ = Describe("Manager", func() {
 					},
 				}
 				upInputs.NetIn = netInRules
+				portAllocator.AllocatePortStub = nil
 				portAllocator.AllocatePortReturns(0, errors.New("banana"))
 			})
 			It("returns an error", func() {
@@ -237,6 +256,38 @@ var _ = Describe("Manager", func() {
 			})
 		})
 
+		Context("when a later host port conflicts", func() {
+			BeforeEach(func() {
+				portAllocator.AllocatePortStub = func(handle string, port int) (int, error) {
+					if port == 23456 {
+						return 0, errors.New("port 23456 is allocated to another-handle")
+					}
+					return port, nil
+				}
+			})
+
+			It("releases the ports it allocated for the earlier entries", func() {
+				_, err := mgr.Up(containerHandle, upInputs)
+				Expect(err).To(MatchError("allocating port: port 23456 is allocated to another-handle"))
+
+				Expect(portAllocator.AllocatePortCallCount()).To(Equal(2))
+				Expect(portAllocator.ReleaseAllPortsCallCount()).To(Equal(1))
+				Expect(portAllocator.ReleaseAllPortsArgsForCall(0)).To(Equal(containerHandle))
+				Expect(cniController.UpCallCount()).To(Equal(0))
+			})
+
+			Context("when releasing the ports fails too", func() {
+				BeforeEach(func() {
+					portAllocator.ReleaseAllPortsReturns(errors.New("potato"))
+				})
+
+				It("returns both errors", func() {
+					_, err := mgr.Up(containerHandle, upInputs)
+					Expect(err).To(MatchError("allocating port: port 23456 is allocated to another-handle and releasing ports: potato"))
+				})
+			})
+		})
+
 		Context("when CNI up returns a nil result", func() {
 			BeforeEach(func() {
 				cniController.UpReturns(nil, nil)
@@ -371,4 +422,61 @@ var _ = Describe("Manager", func() {
 		})
 
 	})
+
+	Describe("Reconcile", func() {
+		var bindMountRoot string
+
+		BeforeEach(func() {
+			var err error
+			bindMountRoot, err = ioutil.TempDir("", "bind-mounts")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ioutil.WriteFile(filepath.Join(bindMountRoot, "handle-a"), []byte{}, 0600)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(bindMountRoot, "handle-b"), []byte{}, 0600)).To(Succeed())
+			mgr.BindMountRoot = bindMountRoot
+
+			portAllocator.ReconcileReturns([]string{"leaked-handle"}, nil)
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(bindMountRoot)).To(Succeed())
+		})
+
+		It("releases the ports of the containers without a bind mount", func() {
+			outputs, err := mgr.Reconcile(manager.ReconcileInputs{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(outputs.ReleasedHandles).To(Equal([]string{"leaked-handle"}))
+
+			Expect(portAllocator.ReconcileCallCount()).To(Equal(1))
+			Expect(portAllocator.ReconcileArgsForCall(0)).To(Equal([]string{"handle-a", "handle-b"}))
+		})
+
+		Context("when live handles are given", func() {
+			It("uses them instead", func() {
+				_, err := mgr.Reconcile(manager.ReconcileInputs{LiveHandles: []string{}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(portAllocator.ReconcileArgsForCall(0)).To(Equal([]string{}))
+			})
+		})
+
+		Context("when the bind mounts cannot be listed", func() {
+			BeforeEach(func() {
+				mgr.BindMountRoot = filepath.Join(bindMountRoot, "missing")
+			})
+			It("returns the error", func() {
+				_, err := mgr.Reconcile(manager.ReconcileInputs{})
+				Expect(err).To(MatchError(ContainSubstring("listing bind mounts: ")))
+				Expect(portAllocator.ReconcileCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when reconciling the ports fails", func() {
+			BeforeEach(func() {
+				portAllocator.ReconcileReturns(nil, errors.New("potato"))
+			})
+			It("returns the error", func() {
+				_, err := mgr.Reconcile(manager.ReconcileInputs{})
+				Expect(err).To(MatchError("reconciling ports: potato"))
+			})
+		})
+	})
 })
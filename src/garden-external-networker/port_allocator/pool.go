@@ -3,35 +3,47 @@ package port_allocator
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"time"
 )
 
 var ErrorPortPoolExhausted = errors.New("port pool exhausted")
 
+// Pool is the state file of the PortAllocator. Besides the acquired ports,
+// it keeps what the strategies need to know about past allocations.
 type Pool struct {
-	AcquiredPorts map[int]string
+	AcquiredPorts    map[int]string
+	LastAcquiredPort int
+	ReleasedPorts    map[int]time.Time
+}
+
+type poolJSON struct {
+	AcquiredPorts    map[string][]int  `json:"acquired_ports"`
+	LastAcquiredPort int               `json:"last_acquired_port,omitempty"`
+	ReleasedPorts    map[int]time.Time `json:"released_ports,omitempty"`
 }
 
 func (p *Pool) MarshalJSON() ([]byte, error) {
-	var jsonData struct {
-		AcquiredPorts map[string][]int `json:"acquired_ports"`
-	}
+	var jsonData poolJSON
 	jsonData.AcquiredPorts = make(map[string][]int)
 
 	for port, handle := range p.AcquiredPorts {
 		jsonData.AcquiredPorts[handle] = append(jsonData.AcquiredPorts[handle], port)
 	}
+	jsonData.LastAcquiredPort = p.LastAcquiredPort
+	jsonData.ReleasedPorts = p.ReleasedPorts
 	return json.Marshal(jsonData)
 }
 
 func (p *Pool) UnmarshalJSON(bytes []byte) error {
-	var jsonData struct {
-		AcquiredPorts map[string][]int `json:"acquired_ports"`
-	}
+	var jsonData poolJSON
 	err := json.Unmarshal(bytes, &jsonData)
 	if err != nil {
 		return err
 	}
 
+	p.LastAcquiredPort = jsonData.LastAcquiredPort
+	p.ReleasedPorts = jsonData.ReleasedPorts
 	p.AcquiredPorts = make(map[int]string)
 	for handle, ports := range jsonData.AcquiredPorts {
 		for _, port := range ports {
@@ -41,9 +53,14 @@ func (p *Pool) UnmarshalJSON(bytes []byte) error {
 	return nil
 }
 
+// Tracker acquires ports from StartPort up to StartPort+Capacity, leaving
+// out the ReservedPorts. Which free port it acquires is up to the Strategy,
+// which defaults to the lowest free port.
 type Tracker struct {
-	StartPort int
-	Capacity  int
+	StartPort     int
+	Capacity      int
+	ReservedPorts []int
+	Strategy      Strategy
 }
 
 func (t *Tracker) InRange(port int) bool {
@@ -55,25 +72,78 @@ func (t *Tracker) AcquireOne(pool *Pool, handler string) (int, error) {
 		pool.AcquiredPorts = make(map[int]string)
 	}
 
+	reserved := make(map[int]bool, len(t.ReservedPorts))
+	for _, port := range t.ReservedPorts {
+		reserved[port] = true
+	}
+
+	free := []int{}
 	for i := 0; i < t.Capacity; i++ {
 		candidatePort := t.StartPort + i
-		if !contains(pool.AcquiredPorts, candidatePort) {
-			pool.AcquiredPorts[candidatePort] = handler
-			return candidatePort, nil
+		if !contains(pool.AcquiredPorts, candidatePort) && !reserved[candidatePort] {
+			free = append(free, candidatePort)
+		}
+	}
+	if len(free) == 0 {
+		return -1, ErrorPortPoolExhausted
+	}
+
+	port, err := t.strategy().Choose(pool, free)
+	if err != nil {
+		return -1, err
+	}
+	pool.AcquiredPorts[port] = handler
+	pool.LastAcquiredPort = port
+	delete(pool.ReleasedPorts, port)
+	return port, nil
+}
+
+// Acquire acquires the port for the handle, whether it is in the range or
+// not, unless it is reserved or already acquired by another handle.
+func (t *Tracker) Acquire(pool *Pool, port int, handle string) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("invalid port %d", port)
+	}
+	if pool.AcquiredPorts == nil {
+		pool.AcquiredPorts = make(map[int]string)
+	}
+
+	if owner, ok := pool.AcquiredPorts[port]; ok {
+		if owner == handle {
+			return nil
 		}
+		return fmt.Errorf("port %d is already acquired by %s", port, owner)
 	}
-	return -1, ErrorPortPoolExhausted
+	for _, reserved := range t.ReservedPorts {
+		if port == reserved {
+			return fmt.Errorf("port %d is reserved", port)
+		}
+	}
+
+	pool.AcquiredPorts[port] = handle
+	delete(pool.ReleasedPorts, port)
+	return nil
 }
 
 func (t *Tracker) ReleaseAll(pool *Pool, handle string) error {
 	for port, h := range pool.AcquiredPorts {
 		if h == handle {
 			delete(pool.AcquiredPorts, port)
+			if t.InRange(port) {
+				t.strategy().Released(pool, port)
+			}
 		}
 	}
 	return nil
 }
 
+func (t *Tracker) strategy() Strategy {
+	if t.Strategy == nil {
+		return LowestFree{}
+	}
+	return t.Strategy
+}
+
 func contains(list map[int]string, candidate int) bool {
 	_, ok := list[candidate]
 	return ok
@@ -3,6 +3,7 @@ package port_allocator_test
 import (
 	"encoding/json"
 	"garden-external-networker/port_allocator"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -76,6 +77,22 @@ var _ = Describe("Tracker", func() {
 			})
 		})
 
+		Context("when ports are reserved", func() {
+			BeforeEach(func() {
+				tracker.Capacity = 3
+				tracker.ReservedPorts = []int{100, 101}
+			})
+
+			It("never acquires them", func() {
+				port, err := tracker.AcquireOne(pool, "some-handle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(port).To(Equal(102))
+
+				_, err = tracker.AcquireOne(pool, "some-handle")
+				Expect(err).To(Equal(port_allocator.ErrorPortPoolExhausted))
+			})
+		})
+
 		Describe("performance", func() {
 			Measure("should acquire all of the ports quickly", func(b Benchmarker) {
 				tracker.Capacity = 4000
@@ -91,6 +108,59 @@ var _ = Describe("Tracker", func() {
 		})
 	})
 
+	Describe("Acquire", func() {
+		It("acquires the port for the handle", func() {
+			Expect(tracker.Acquire(pool, 105, "some-handle")).To(Succeed())
+			Expect(pool.AcquiredPorts).To(Equal(map[int]string{105: "some-handle"}))
+		})
+
+		It("acquires ports outside the range too", func() {
+			Expect(tracker.Acquire(pool, 8080, "some-handle")).To(Succeed())
+			Expect(pool.AcquiredPorts).To(HaveKeyWithValue(8080, "some-handle"))
+
+			Expect(tracker.ReleaseAll(pool, "some-handle")).To(Succeed())
+			Expect(pool.AcquiredPorts).To(BeEmpty())
+		})
+
+		It("keeps AcquireOne from acquiring the port", func() {
+			tracker.Capacity = 2
+			Expect(tracker.Acquire(pool, 100, "some-handle")).To(Succeed())
+
+			Expect(tracker.AcquireOne(pool, "other-handle")).To(Equal(101))
+		})
+
+		Context("when the handle already acquired the port", func() {
+			It("succeeds", func() {
+				Expect(tracker.Acquire(pool, 8080, "some-handle")).To(Succeed())
+				Expect(tracker.Acquire(pool, 8080, "some-handle")).To(Succeed())
+			})
+		})
+
+		Context("when another handle acquired the port", func() {
+			It("returns a useful error", func() {
+				Expect(tracker.Acquire(pool, 8080, "some-handle")).To(Succeed())
+
+				err := tracker.Acquire(pool, 8080, "other-handle")
+				Expect(err).To(MatchError("port 8080 is already acquired by some-handle"))
+				Expect(pool.AcquiredPorts).To(HaveKeyWithValue(8080, "some-handle"))
+			})
+		})
+
+		Context("when the port is reserved", func() {
+			It("returns a useful error", func() {
+				tracker.ReservedPorts = []int{105}
+				Expect(tracker.Acquire(pool, 105, "some-handle")).To(MatchError("port 105 is reserved"))
+			})
+		})
+
+		Context("when the port is invalid", func() {
+			It("returns a useful error", func() {
+				Expect(tracker.Acquire(pool, 65536, "some-handle")).To(MatchError("invalid port 65536"))
+				Expect(tracker.Acquire(pool, -1, "some-handle")).To(MatchError("invalid port -1"))
+			})
+		})
+	})
+
 	Describe("acquire and release lifecycle", func() {
 		It("can re-acquire ports which have been acquired and then released", func() {
 			var err error
@@ -136,6 +206,27 @@ var _ = Describe("Tracker", func() {
 			Expect(newPool.AcquiredPorts).To(Equal(pool.AcquiredPorts))
 		})
 
+		It("keeps what the strategies need about past allocations", func() {
+			pool.LastAcquiredPort = 105
+			pool.ReleasedPorts = map[int]time.Time{
+				103: time.Unix(1000, 0).UTC(),
+			}
+
+			bytes, err := json.Marshal(pool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bytes).To(MatchJSON(`{
+				"acquired_ports": {},
+				"last_acquired_port": 105,
+				"released_ports": { "103": "1970-01-01T00:16:40Z" }
+			}`))
+
+			var newPool port_allocator.Pool
+			Expect(json.Unmarshal(bytes, &newPool)).To(Succeed())
+
+			Expect(newPool.LastAcquiredPort).To(Equal(105))
+			Expect(newPool.ReleasedPorts).To(Equal(pool.ReleasedPorts))
+		})
+
 		It("marshals as a map from container handle to list of allocated ports", func() {
 			pool.AcquiredPorts = map[int]string{
 				42:  "some-handle",
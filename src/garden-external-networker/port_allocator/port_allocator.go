@@ -1,9 +1,9 @@
 package port_allocator
 
 import (
-	"errors"
 	"fmt"
 	"lib/serial"
+	"sort"
 
 	"code.cloudfoundry.org/filelock"
 )
@@ -21,8 +21,8 @@ type LockedFile interface {
 //go:generate counterfeiter -o ../fakes/tracker.go --fake-name Tracker . tracker
 type tracker interface {
 	AcquireOne(pool *Pool, handle string) (int, error)
+	Acquire(pool *Pool, port int, handle string) error
 	ReleaseAll(pool *Pool, handle string) error
-	InRange(port int) bool
 }
 
 type PortAllocator struct {
@@ -31,28 +31,39 @@ type PortAllocator struct {
 	Locker     filelock.FileLocker
 }
 
-func (p *PortAllocator) AllocatePort(handle string, port int) (int, error) {
-	if port != 0 {
-		if p.Tracker.InRange(port) {
-			return -1, errors.New("cannot specify port from allocation range")
-		} else {
-			return port, nil
-		}
+// decodePool fails on a corrupt state file rather than starting over from an
+// empty pool, since every port held by a live container would then look free
+// and could be handed out again. The state file is left untouched until
+// Reconcile rebuilds it.
+func (p *PortAllocator) decodePool(file filelock.LockedFile) (*Pool, error) {
+	pool := &Pool{}
+	err := p.Serializer.DecodeAll(file, pool)
+	if err != nil {
+		return nil, err
 	}
+	return pool, nil
+}
 
+// AllocatePort acquires a free port for the handle, or the given port when it
+// is not 0. Either way the port is kept in the pool until it is released.
+func (p *PortAllocator) AllocatePort(handle string, port int) (int, error) {
 	file, err := p.Locker.Open()
 	if err != nil {
 		return -1, fmt.Errorf("open lock: %s", err)
 	}
 	defer file.Close() // defer not tested
 
-	pool := &Pool{}
-	err = p.Serializer.DecodeAll(file, pool)
+	pool, err := p.decodePool(file)
 	if err != nil {
 		return -1, fmt.Errorf("decoding state file: %s", err)
 	}
 
-	newPort, err := p.Tracker.AcquireOne(pool, handle)
+	newPort := port
+	if port == 0 {
+		newPort, err = p.Tracker.AcquireOne(pool, handle)
+	} else {
+		err = p.Tracker.Acquire(pool, port, handle)
+	}
 	if err != nil {
 		return -1, fmt.Errorf("acquire port: %s", err)
 	}
@@ -72,8 +83,7 @@ func (p *PortAllocator) ReleaseAllPorts(handle string) error {
 	}
 	defer file.Close() // defer not tested
 
-	pool := &Pool{}
-	err = p.Serializer.DecodeAll(file, pool)
+	pool, err := p.decodePool(file)
 	if err != nil {
 		return fmt.Errorf("decoding state file: %s", err)
 	}
@@ -89,3 +99,52 @@ func (p *PortAllocator) ReleaseAllPorts(handle string) error {
 
 	return nil
 }
+
+// Reconcile releases the ports of every handle that is not live, such as
+// the handles of containers that were never brought down, and returns those
+// handles. A state file that is corrupt, backup and all, is rebuilt from the
+// live handles: none of them hold a port in the new pool, since which ports
+// they held is lost, but the allocator works again.
+func (p *PortAllocator) Reconcile(liveHandles []string) ([]string, error) {
+	file, err := p.Locker.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open lock: %s", err)
+	}
+	defer file.Close() // defer not tested
+
+	pool, err := p.decodePool(file)
+	if _, corrupt := err.(*serial.CorruptError); corrupt {
+		pool = &Pool{AcquiredPorts: map[int]string{}}
+	} else if err != nil {
+		return nil, fmt.Errorf("decoding state file: %s", err)
+	}
+
+	live := map[string]bool{}
+	for _, handle := range liveHandles {
+		live[handle] = true
+	}
+	isLeaked := map[string]bool{}
+	for _, handle := range pool.AcquiredPorts {
+		if !live[handle] {
+			isLeaked[handle] = true
+		}
+	}
+	leaked := []string{}
+	for handle := range isLeaked {
+		leaked = append(leaked, handle)
+	}
+	sort.Strings(leaked)
+
+	for _, handle := range leaked {
+		if err := p.Tracker.ReleaseAll(pool, handle); err != nil {
+			return nil, fmt.Errorf("release all ports: %s", err)
+		}
+	}
+
+	err = p.Serializer.EncodeAndOverwrite(file, pool)
+	if err != nil {
+		return nil, fmt.Errorf("encode and overwrite: %s", err)
+	}
+
+	return leaked, nil
+}
@@ -4,9 +4,14 @@ import (
 	"errors"
 	"garden-external-networker/fakes"
 	"garden-external-networker/port_allocator"
+	"io"
 	"io/ioutil"
 	libfakes "lib/fakes"
+	"lib/serial"
 	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/filelock"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -63,26 +68,32 @@ var _ = Describe("PortAllocator", func() {
 			})
 		})
 
-		Context("when the passed in port is non-zero and not in the range", func() {
-			BeforeEach(func() {
-				tracker.InRangeReturns(false)
-			})
-			It("noops and returns the port", func() {
+		Context("when the passed in port is non-zero", func() {
+			It("acquires that port in the pool", func() {
 				port, err := portAllocator.AllocatePort("some-handle", 42)
 				Expect(err).NotTo(HaveOccurred())
+				Expect(port).To(Equal(42))
 
 				Expect(tracker.AcquireOneCallCount()).To(Equal(0))
-				Expect(port).To(Equal(42))
-			})
-		})
+				Expect(tracker.AcquireCallCount()).To(Equal(1))
+				_, pool := serializer.DecodeAllArgsForCall(0)
+				receivedPool, receivedPort, receivedHandle := tracker.AcquireArgsForCall(0)
+				Expect(receivedPool).To(Equal(pool))
+				Expect(receivedPort).To(Equal(42))
+				Expect(receivedHandle).To(Equal("some-handle"))
 
-		Context("when the passed in port is non-zero in the range", func() {
-			BeforeEach(func() {
-				tracker.InRangeReturns(true)
+				Expect(serializer.EncodeAndOverwriteCallCount()).To(Equal(1))
 			})
-			It("returns an error", func() {
-				_, err := portAllocator.AllocatePort("some-handle", 42)
-				Expect(err).To(MatchError(errors.New("cannot specify port from allocation range")))
+
+			Context("when the tracker cannot acquire it", func() {
+				BeforeEach(func() {
+					tracker.AcquireReturns(errors.New("port 42 is already acquired by other-handle"))
+				})
+				It("wraps and returns the error", func() {
+					_, err := portAllocator.AllocatePort("some-handle", 42)
+					Expect(err).To(MatchError("acquire port: port 42 is already acquired by other-handle"))
+					Expect(serializer.EncodeAndOverwriteCallCount()).To(Equal(0))
+				})
 			})
 		})
 
@@ -237,4 +248,149 @@ var _ = Describe("PortAllocator", func() {
 		})
 
 	})
+
+	Describe("Reconcile", func() {
+		BeforeEach(func() {
+			serializer.DecodeAllStub = func(_ io.ReadSeeker, outData interface{}) error {
+				outData.(*port_allocator.Pool).AcquiredPorts = map[int]string{
+					100: "live-handle",
+					101: "leaked-handle-b",
+					102: "leaked-handle-a",
+					103: "leaked-handle-a",
+				}
+				return nil
+			}
+		})
+
+		It("releases the ports of the handles that are not live", func() {
+			leaked, err := portAllocator.Reconcile([]string{"live-handle", "other-live-handle"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(leaked).To(Equal([]string{"leaked-handle-a", "leaked-handle-b"}))
+
+			Expect(tracker.ReleaseAllCallCount()).To(Equal(2))
+			_, poolForDecode := serializer.DecodeAllArgsForCall(0)
+			pool, handle := tracker.ReleaseAllArgsForCall(0)
+			Expect(pool).To(Equal(poolForDecode))
+			Expect(handle).To(Equal("leaked-handle-a"))
+			_, handle = tracker.ReleaseAllArgsForCall(1)
+			Expect(handle).To(Equal("leaked-handle-b"))
+
+			Expect(serializer.EncodeAndOverwriteCallCount()).To(Equal(1))
+			file, poolForEncode := serializer.EncodeAndOverwriteArgsForCall(0)
+			Expect(file).To(Equal(lockedFile))
+			Expect(poolForEncode).To(Equal(poolForDecode))
+		})
+
+		Context("when the locker fails to open the file", func() {
+			BeforeEach(func() {
+				locker.OpenReturns(nil, errors.New("potato"))
+			})
+			It("wraps and returns the error", func() {
+				_, err := portAllocator.Reconcile([]string{})
+				Expect(err).To(MatchError("open lock: potato"))
+			})
+		})
+
+		Context("when the serializer fails to decode", func() {
+			BeforeEach(func() {
+				serializer.DecodeAllStub = nil
+				serializer.DecodeAllReturns(errors.New("potato"))
+			})
+			It("wraps and returns the error", func() {
+				_, err := portAllocator.Reconcile([]string{})
+				Expect(err).To(MatchError("decoding state file: potato"))
+			})
+		})
+
+		Context("when the tracker releases ports fail", func() {
+			BeforeEach(func() {
+				tracker.ReleaseAllReturns(errors.New("turnip"))
+			})
+			It("wraps and returns the error", func() {
+				_, err := portAllocator.Reconcile([]string{})
+				Expect(err).To(MatchError("release all ports: turnip"))
+			})
+		})
+
+		Context("when serializing the pool fails", func() {
+			BeforeEach(func() {
+				serializer.EncodeAndOverwriteReturns(errors.New("turnip"))
+			})
+			It("wraps and returns the error", func() {
+				_, err := portAllocator.Reconcile([]string{})
+				Expect(err).To(MatchError("encode and overwrite: turnip"))
+			})
+		})
+	})
+
+	Context("when the state file is corrupt", func() {
+		BeforeEach(func() {
+			serializer.DecodeAllReturns(&serial.CorruptError{Err: errors.New("checksum mismatch")})
+		})
+
+		It("fails to allocate without overwriting the state file", func() {
+			_, err := portAllocator.AllocatePort("some-handle", 0)
+			Expect(err).To(MatchError("decoding state file: corrupt: checksum mismatch"))
+			Expect(tracker.AcquireOneCallCount()).To(Equal(0))
+			Expect(serializer.EncodeAndOverwriteCallCount()).To(Equal(0))
+		})
+
+		It("fails to release without overwriting the state file", func() {
+			err := portAllocator.ReleaseAllPorts("some-handle")
+			Expect(err).To(MatchError("decoding state file: corrupt: checksum mismatch"))
+			Expect(tracker.ReleaseAllCallCount()).To(Equal(0))
+			Expect(serializer.EncodeAndOverwriteCallCount()).To(Equal(0))
+		})
+
+		It("rebuilds the state file when reconciling", func() {
+			leaked, err := portAllocator.Reconcile([]string{"live-handle"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(leaked).To(BeEmpty())
+			Expect(tracker.ReleaseAllCallCount()).To(Equal(0))
+
+			Expect(serializer.EncodeAndOverwriteCallCount()).To(Equal(1))
+			file, pool := serializer.EncodeAndOverwriteArgsForCall(0)
+			Expect(file).To(Equal(lockedFile))
+			Expect(pool).To(Equal(&port_allocator.Pool{AcquiredPorts: map[int]string{}}))
+		})
+
+		Context("with a real state file and backup", func() {
+			var dir string
+
+			BeforeEach(func() {
+				var err error
+				dir, err = ioutil.TempDir("", "port-allocator")
+				Expect(err).NotTo(HaveOccurred())
+				statePath := filepath.Join(dir, "state.json")
+				Expect(ioutil.WriteFile(statePath, []byte(`{"checksum": "sha256:00`), 0600)).To(Succeed())
+				Expect(ioutil.WriteFile(statePath+".backup", []byte(`{"chec`), 0600)).To(Succeed())
+
+				locker.OpenStub = func() (filelock.LockedFile, error) {
+					return os.OpenFile(statePath, os.O_RDWR, 0600)
+				}
+				portAllocator.Serializer = &serial.ChecksumSerial{BackupPath: statePath + ".backup"}
+				portAllocator.Tracker = &port_allocator.Tracker{
+					StartPort: 100,
+					Capacity:  10,
+					Strategy:  port_allocator.LowestFree{},
+				}
+			})
+
+			AfterEach(func() {
+				Expect(os.RemoveAll(dir)).To(Succeed())
+			})
+
+			It("allocates again once reconcile has rebuilt the state file", func() {
+				_, err := portAllocator.AllocatePort("some-handle", 0)
+				Expect(err).To(MatchError(ContainSubstring("decoding state file: corrupt: ")))
+
+				_, err = portAllocator.Reconcile([]string{"live-handle"})
+				Expect(err).NotTo(HaveOccurred())
+
+				port, err := portAllocator.AllocatePort("some-handle", 0)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(port).To(Equal(100))
+			})
+		})
+	})
 })
@@ -0,0 +1,92 @@
+package port_allocator
+
+import (
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+)
+
+// Strategy chooses which of the free ports of a Tracker to acquire. The free
+// ports are never empty and are in ascending order. Released is called for
+// every port that is released, so a strategy can keep track of them in the
+// pool.
+type Strategy interface {
+	Choose(pool *Pool, free []int) (int, error)
+	Released(pool *Pool, port int)
+}
+
+// NewStrategy returns the strategy called name: lowest-free (the default),
+// round-robin, or least-recently-released, which keeps released ports free
+// for at least coolDown.
+func NewStrategy(name string, coolDown time.Duration) (Strategy, error) {
+	switch name {
+	case "", "lowest-free":
+		return LowestFree{}, nil
+	case "round-robin":
+		return RoundRobin{}, nil
+	case "least-recently-released":
+		return LeastRecentlyReleased{CoolDown: coolDown, Clock: clock.NewClock()}, nil
+	}
+	return nil, fmt.Errorf("unknown port allocation strategy %s", name)
+}
+
+// LowestFree acquires the lowest free port.
+type LowestFree struct{}
+
+func (LowestFree) Choose(pool *Pool, free []int) (int, error) {
+	return free[0], nil
+}
+
+func (LowestFree) Released(pool *Pool, port int) {}
+
+// RoundRobin acquires the lowest free port above the last acquired port, and
+// wraps around to the lowest free port.
+type RoundRobin struct{}
+
+func (RoundRobin) Choose(pool *Pool, free []int) (int, error) {
+	for _, port := range free {
+		if port > pool.LastAcquiredPort {
+			return port, nil
+		}
+	}
+	return free[0], nil
+}
+
+func (RoundRobin) Released(pool *Pool, port int) {}
+
+// LeastRecentlyReleased acquires the port that was released the longest time
+// ago, preferring ports that were never released. Ports released less than
+// CoolDown ago are not acquired at all.
+type LeastRecentlyReleased struct {
+	CoolDown time.Duration
+	Clock    clock.Clock
+}
+
+func (s LeastRecentlyReleased) Choose(pool *Pool, free []int) (int, error) {
+	now := s.Clock.Now()
+	chosen := -1
+	for _, port := range free {
+		releasedAt, ok := pool.ReleasedPorts[port]
+		if !ok {
+			return port, nil
+		}
+		if now.Sub(releasedAt) < s.CoolDown {
+			continue
+		}
+		if chosen == -1 || releasedAt.Before(pool.ReleasedPorts[chosen]) {
+			chosen = port
+		}
+	}
+	if chosen == -1 {
+		return -1, ErrorPortPoolExhausted
+	}
+	return chosen, nil
+}
+
+func (s LeastRecentlyReleased) Released(pool *Pool, port int) {
+	if pool.ReleasedPorts == nil {
+		pool.ReleasedPorts = make(map[int]time.Time)
+	}
+	pool.ReleasedPorts[port] = s.Clock.Now()
+}
@@ -0,0 +1,121 @@
+package port_allocator_test
+
+import (
+	"garden-external-networker/port_allocator"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Strategies", func() {
+	var (
+		pool    *port_allocator.Pool
+		tracker *port_allocator.Tracker
+	)
+
+	BeforeEach(func() {
+		pool = &port_allocator.Pool{}
+		tracker = &port_allocator.Tracker{
+			StartPort: 100,
+			Capacity:  5,
+		}
+	})
+
+	Describe("NewStrategy", func() {
+		It("returns the strategy with the given name", func() {
+			Expect(port_allocator.NewStrategy("", 0)).To(Equal(port_allocator.LowestFree{}))
+			Expect(port_allocator.NewStrategy("lowest-free", 0)).To(Equal(port_allocator.LowestFree{}))
+			Expect(port_allocator.NewStrategy("round-robin", 0)).To(Equal(port_allocator.RoundRobin{}))
+
+			strategy, err := port_allocator.NewStrategy("least-recently-released", time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strategy.(port_allocator.LeastRecentlyReleased).CoolDown).To(Equal(time.Minute))
+		})
+
+		Context("when the name is unknown", func() {
+			It("returns an error", func() {
+				_, err := port_allocator.NewStrategy("random", 0)
+				Expect(err).To(MatchError("unknown port allocation strategy random"))
+			})
+		})
+	})
+
+	Describe("RoundRobin", func() {
+		BeforeEach(func() {
+			tracker.Strategy = port_allocator.RoundRobin{}
+		})
+
+		It("acquires the next free port after the last acquired one, wrapping around", func() {
+			for _, expected := range []int{100, 101, 102} {
+				Expect(tracker.AcquireOne(pool, "some-handle")).To(Equal(expected))
+			}
+			Expect(tracker.ReleaseAll(pool, "some-handle")).To(Succeed())
+
+			for _, expected := range []int{103, 104, 100} {
+				Expect(tracker.AcquireOne(pool, "some-handle")).To(Equal(expected))
+			}
+			Expect(pool.LastAcquiredPort).To(Equal(100))
+		})
+	})
+
+	Describe("LeastRecentlyReleased", func() {
+		var clock *fakeclock.FakeClock
+
+		BeforeEach(func() {
+			clock = fakeclock.NewFakeClock(time.Unix(1000, 0))
+			tracker.Capacity = 3
+			tracker.Strategy = port_allocator.LeastRecentlyReleased{
+				CoolDown: time.Minute,
+				Clock:    clock,
+			}
+			pool.AcquiredPorts = map[int]string{
+				100: "handle-a",
+				101: "handle-b",
+			}
+		})
+
+		It("prefers ports that were never released", func() {
+			Expect(tracker.ReleaseAll(pool, "handle-a")).To(Succeed())
+			clock.Increment(2 * time.Minute)
+
+			Expect(tracker.AcquireOne(pool, "some-handle")).To(Equal(102))
+		})
+
+		It("only keeps track of released ports in the range", func() {
+			Expect(tracker.Acquire(pool, 8080, "handle-a")).To(Succeed())
+			Expect(tracker.ReleaseAll(pool, "handle-a")).To(Succeed())
+
+			Expect(pool.ReleasedPorts).To(HaveKey(100))
+			Expect(pool.ReleasedPorts).NotTo(HaveKey(8080))
+		})
+
+		It("acquires the port released longest ago once it has cooled down", func() {
+			pool.AcquiredPorts[102] = "handle-c"
+			Expect(tracker.ReleaseAll(pool, "handle-b")).To(Succeed())
+			clock.Increment(time.Second)
+			Expect(tracker.ReleaseAll(pool, "handle-a")).To(Succeed())
+			Expect(pool.ReleasedPorts).To(Equal(map[int]time.Time{
+				100: time.Unix(1001, 0),
+				101: time.Unix(1000, 0),
+			}))
+
+			clock.Increment(2 * time.Minute)
+			Expect(tracker.AcquireOne(pool, "some-handle")).To(Equal(101))
+			Expect(pool.ReleasedPorts).NotTo(HaveKey(101))
+		})
+
+		Context("when every free port is cooling down", func() {
+			It("returns a useful error", func() {
+				pool.AcquiredPorts[102] = "handle-c"
+				Expect(tracker.ReleaseAll(pool, "handle-a")).To(Succeed())
+				clock.Increment(30 * time.Second)
+
+				_, err := tracker.AcquireOne(pool, "some-handle")
+				Expect(err).To(Equal(port_allocator.ErrorPortPoolExhausted))
+			})
+		})
+	})
+})
@@ -44,6 +44,17 @@ func (c *InternalClient) GetPoliciesByID(ids ...string) ([]api.Policy, error) {
 	return policies.Policies, nil
 }
 
+func (c *InternalClient) GetTags() ([]api.Tag, error) {
+	var tags struct {
+		Tags []api.Tag `json:"tags"`
+	}
+	err := c.JsonClient.Do("GET", "/networking/v1/internal/tags", nil, &tags, "")
+	if err != nil {
+		return nil, err
+	}
+	return tags.Tags, nil
+}
+
 func (c *InternalClient) HealthCheck() (bool, error) {
 	var healthcheck struct {
 		Healthcheck bool `json:"healthcheck"`
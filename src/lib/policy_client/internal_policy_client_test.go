@@ -133,6 +133,42 @@ var _ = Describe("InternalClient", func() {
 		})
 	})
 
+	Describe("GetTags", func() {
+		BeforeEach(func() {
+			jsonClient.DoStub = func(method, route string, reqData, respData interface{}, token string) error {
+				respBytes := []byte(`{ "tags": [ { "id": "some-app-guid", "tag": "0001", "type": "app" } ] }`)
+				json.Unmarshal(respBytes, respData)
+				return nil
+			}
+		})
+
+		It("does the right json http client request", func() {
+			tags, err := client.GetTags()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(jsonClient.DoCallCount()).To(Equal(1))
+			method, route, reqData, _, token := jsonClient.DoArgsForCall(0)
+			Expect(method).To(Equal("GET"))
+			Expect(route).To(Equal("/networking/v1/internal/tags"))
+			Expect(reqData).To(BeNil())
+			Expect(token).To(BeEmpty())
+
+			Expect(tags).To(Equal([]api.Tag{
+				{ID: "some-app-guid", Tag: "0001", Type: "app"},
+			}))
+		})
+
+		Context("when the json client fails", func() {
+			BeforeEach(func() {
+				jsonClient.DoReturns(errors.New("banana"))
+			})
+			It("returns the error", func() {
+				_, err := client.GetTags()
+				Expect(err).To(MatchError("banana"))
+			})
+		})
+	})
+
 	Describe("HealthCheck", func() {
 		BeforeEach(func() {
 			jsonClient.DoStub = func(method, route string, reqData, respData interface{}, token string) error {
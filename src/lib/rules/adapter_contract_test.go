@@ -0,0 +1,129 @@
+package rules_test
+
+import (
+	"lib/rules"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func positionOf(adapter rules.IPTablesAdapter, table, chain, text string) int {
+	listed, err := adapter.List(table, chain)
+	Expect(err).NotTo(HaveOccurred())
+	position := 0
+	for _, rule := range listed {
+		if strings.HasPrefix(rule, "-N ") || strings.HasPrefix(rule, "-P ") {
+			continue
+		}
+		position++
+		if strings.Contains(rule, text) {
+			return position
+		}
+	}
+	return -1
+}
+
+// itBehavesLikeAnIPTablesAdapter checks the behavior every IPTablesAdapter
+// must share, against a backend that starts out with no rules.
+func itBehavesLikeAnIPTablesAdapter(newAdapter func() rules.IPTablesAdapter) {
+	Describe("the IPTablesAdapter contract", func() {
+		var (
+			adapter             rules.IPTablesAdapter
+			ruleA, ruleB, ruleC rules.IPTablesRule
+		)
+
+		BeforeEach(func() {
+			adapter = newAdapter()
+			ruleA = rules.NewMarkSetRule("10.0.0.1", "A", "a-guid")
+			ruleB = rules.NewNetOutWithPortsRule("10.0.0.2", "10.0.0.2", 80, 80, "tcp")
+			ruleC = rules.NewOverlayDefaultRejectRule("10.0.0.3")
+
+			Expect(adapter.NewChain("filter", "some-chain")).To(Succeed())
+		})
+
+		It("appends rules in order", func() {
+			Expect(adapter.BulkAppend("filter", "some-chain", ruleA, ruleB)).To(Succeed())
+
+			Expect(positionOf(adapter, "filter", "some-chain", "10.0.0.1")).To(Equal(1))
+			Expect(positionOf(adapter, "filter", "some-chain", "10.0.0.2")).To(Equal(2))
+		})
+
+		It("inserts rules at the given position", func() {
+			Expect(adapter.BulkAppend("filter", "some-chain", ruleA, ruleB)).To(Succeed())
+			Expect(adapter.BulkInsert("filter", "some-chain", 2, ruleC)).To(Succeed())
+
+			Expect(positionOf(adapter, "filter", "some-chain", "10.0.0.1")).To(Equal(1))
+			Expect(positionOf(adapter, "filter", "some-chain", "10.0.0.3")).To(Equal(2))
+			Expect(positionOf(adapter, "filter", "some-chain", "10.0.0.2")).To(Equal(3))
+		})
+
+		It("inserts several rules at once the way iptables-restore does", func() {
+			Expect(adapter.BulkAppend("filter", "some-chain", ruleA)).To(Succeed())
+			Expect(adapter.BulkInsert("filter", "some-chain", 1, ruleB, ruleC)).To(Succeed())
+
+			Expect(positionOf(adapter, "filter", "some-chain", "10.0.0.3")).To(Equal(1))
+			Expect(positionOf(adapter, "filter", "some-chain", "10.0.0.2")).To(Equal(2))
+			Expect(positionOf(adapter, "filter", "some-chain", "10.0.0.1")).To(Equal(3))
+		})
+
+		It("rejects an insert past the end of the chain", func() {
+			Expect(adapter.BulkAppend("filter", "some-chain", ruleA)).To(Succeed())
+			Expect(adapter.BulkInsert("filter", "some-chain", 3, ruleB)).NotTo(Succeed())
+			Expect(adapter.Exists("filter", "some-chain", ruleB)).To(BeFalse())
+		})
+
+		It("reports whether a rule exists", func() {
+			Expect(adapter.BulkAppend("filter", "some-chain", ruleA)).To(Succeed())
+
+			Expect(adapter.Exists("filter", "some-chain", ruleA)).To(BeTrue())
+			Expect(adapter.Exists("filter", "some-chain", ruleB)).To(BeFalse())
+		})
+
+		It("deletes a rule", func() {
+			Expect(adapter.BulkAppend("filter", "some-chain", ruleA, ruleB)).To(Succeed())
+			Expect(adapter.Delete("filter", "some-chain", ruleA)).To(Succeed())
+
+			Expect(adapter.Exists("filter", "some-chain", ruleA)).To(BeFalse())
+			Expect(adapter.Exists("filter", "some-chain", ruleB)).To(BeTrue())
+		})
+
+		It("finds and deletes rules that match on connection state", func() {
+			established := rules.NewAcceptExistingLocalRule()
+			Expect(adapter.BulkAppend("filter", "some-chain", ruleA, established)).To(Succeed())
+
+			Expect(adapter.Exists("filter", "some-chain", established)).To(BeTrue())
+			Expect(adapter.Delete("filter", "some-chain", established)).To(Succeed())
+			Expect(adapter.Exists("filter", "some-chain", established)).To(BeFalse())
+		})
+
+		It("fails to delete a rule that does not exist", func() {
+			Expect(adapter.Delete("filter", "some-chain", ruleA)).NotTo(Succeed())
+		})
+
+		It("fails to create a chain that already exists", func() {
+			Expect(adapter.NewChain("filter", "some-chain")).NotTo(Succeed())
+		})
+
+		It("clears a chain", func() {
+			Expect(adapter.BulkAppend("filter", "some-chain", ruleA, ruleB)).To(Succeed())
+			Expect(adapter.ClearChain("filter", "some-chain")).To(Succeed())
+
+			Expect(adapter.Exists("filter", "some-chain", ruleA)).To(BeFalse())
+			Expect(adapter.Exists("filter", "some-chain", ruleB)).To(BeFalse())
+		})
+
+		It("deletes an empty chain", func() {
+			Expect(adapter.DeleteChain("filter", "some-chain")).To(Succeed())
+
+			_, err := adapter.List("filter", "some-chain")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("programs the built-in chains", func() {
+			Expect(adapter.BulkAppend("filter", "FORWARD", ruleA)).To(Succeed())
+
+			Expect(adapter.Exists("filter", "FORWARD", ruleA)).To(BeTrue())
+		})
+	})
+}
@@ -0,0 +1,206 @@
+package rules
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"lib/serial"
+	"sort"
+
+	"code.cloudfoundry.org/filelock"
+)
+
+const (
+	maxChainNameLength = 28
+	minChainHashLength = 8
+
+	maxChainNameAttempts = 16
+)
+
+// ChainName returns the name of the chain that the prefix gives to the
+// handle: the prefix followed by as much of the hash of the handle as fits
+// into the 28 characters iptables allows.
+func ChainName(prefix, handle string) (string, error) {
+	return chainName(prefix, handle, 0)
+}
+
+// chainName hashes the attempt along with the handle after the first
+// attempt, so that a handle whose name collides gets a different one.
+func chainName(prefix, handle string, attempt int) (string, error) {
+	hashLength := maxChainNameLength - len(prefix)
+	if hashLength < minChainHashLength {
+		return "", fmt.Errorf("chain prefix %s leaves less than %d characters for the hash", prefix, minChainHashLength)
+	}
+	hashed := handle
+	if attempt > 0 {
+		hashed = fmt.Sprintf("%s\x00%d", handle, attempt)
+	}
+	sum := sha256.Sum256([]byte(hashed))
+	return prefix + hex.EncodeToString(sum[:])[:hashLength], nil
+}
+
+type ChainOwner struct {
+	Table  string `json:"table"`
+	Prefix string `json:"prefix"`
+	Handle string `json:"handle"`
+}
+
+// ChainOwners is the state file of a ChainNamer, keyed by chain name.
+type ChainOwners struct {
+	Chains map[string]ChainOwner `json:"chains"`
+}
+
+func (o *ChainOwners) chainOf(owner ChainOwner) (string, bool) {
+	for name, other := range o.Chains {
+		if other == owner {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+type chainAdapter interface {
+	NewChain(table, chain string) error
+	ClearChain(table, chain string) error
+	DeleteChain(table, chain string) error
+}
+
+// ChainNamer names the chains of container handles, keeping track of which
+// handle owns which chain in a state file. That lets it give a handle whose
+// name collides with the chain of another handle a different name, and find
+// the chains of handles that are gone. LockedIPTables creates and deletes
+// chains through it.
+type ChainNamer struct {
+	Serializer serial.Serializer
+	Locker     filelock.FileLocker
+}
+
+func (c *ChainNamer) withState(update func(owners *ChainOwners) error) error {
+	file, err := c.Locker.Open()
+	if err != nil {
+		return fmt.Errorf("open lock: %s", err)
+	}
+	defer file.Close() // defer not tested
+
+	owners := &ChainOwners{}
+	err = c.Serializer.DecodeAll(file, owners)
+	if err != nil {
+		return fmt.Errorf("decoding state file: %s", err)
+	}
+	if owners.Chains == nil {
+		owners.Chains = map[string]ChainOwner{}
+	}
+
+	updateErr := update(owners)
+
+	err = c.Serializer.EncodeAndOverwrite(file, owners)
+	if err != nil {
+		return fmt.Errorf("encode and overwrite: %s", err)
+	}
+	return updateErr
+}
+
+// Create creates the chain of the handle, unless the handle already owns it,
+// and returns its name.
+func (c *ChainNamer) Create(ipt chainAdapter, table, prefix, handle string) (string, error) {
+	if _, err := ChainName(prefix, handle); err != nil {
+		return "", err
+	}
+
+	owner := ChainOwner{Table: table, Prefix: prefix, Handle: handle}
+	var name string
+	err := c.withState(func(owners *ChainOwners) error {
+		if existing, ok := owners.chainOf(owner); ok {
+			name = existing
+			return nil
+		}
+
+		for attempt := 0; attempt < maxChainNameAttempts; attempt++ {
+			candidate, _ := chainName(prefix, handle, attempt)
+			if _, taken := owners.Chains[candidate]; taken {
+				continue
+			}
+			if err := ipt.NewChain(table, candidate); err != nil {
+				return fmt.Errorf("creating chain: %s", err)
+			}
+			owners.Chains[candidate] = owner
+			name = candidate
+			return nil
+		}
+		return fmt.Errorf("no free chain name for handle %s after %d attempts", handle, maxChainNameAttempts)
+	})
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// Delete clears and deletes the chain of the handle. Rules that jump to the
+// chain have to be deleted first.
+func (c *ChainNamer) Delete(ipt chainAdapter, table, prefix, handle string) error {
+	return c.withState(func(owners *ChainOwners) error {
+		name, ok := owners.chainOf(ChainOwner{Table: table, Prefix: prefix, Handle: handle})
+		if !ok {
+			return nil
+		}
+		return c.deleteChain(ipt, owners, name)
+	})
+}
+
+func (c *ChainNamer) deleteChain(ipt chainAdapter, owners *ChainOwners, name string) error {
+	table := owners.Chains[name].Table
+	if err := ipt.ClearChain(table, name); err != nil {
+		return fmt.Errorf("clearing chain %s: %s", name, err)
+	}
+	if err := ipt.DeleteChain(table, name); err != nil {
+		return fmt.Errorf("deleting chain %s: %s", name, err)
+	}
+	delete(owners.Chains, name)
+	return nil
+}
+
+func (c *ChainNamer) List() (map[string]ChainOwner, error) {
+	var chains map[string]ChainOwner
+	err := c.withState(func(owners *ChainOwners) error {
+		chains = owners.Chains
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chains, nil
+}
+
+// GarbageCollect deletes the chains of every handle that is not live, and
+// returns the names of the chains it deleted. It carries on past chains it
+// fails to delete, and returns the first of those errors.
+func (c *ChainNamer) GarbageCollect(ipt chainAdapter, liveHandles []string) ([]string, error) {
+	live := map[string]bool{}
+	for _, handle := range liveHandles {
+		live[handle] = true
+	}
+
+	deleted := []string{}
+	err := c.withState(func(owners *ChainOwners) error {
+		names := []string{}
+		for name, owner := range owners.Chains {
+			if !live[owner.Handle] {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		var firstErr error
+		for _, name := range names {
+			if err := c.deleteChain(ipt, owners, name); err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			deleted = append(deleted, name)
+		}
+		return firstErr
+	})
+	return deleted, err
+}
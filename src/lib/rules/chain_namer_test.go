@@ -0,0 +1,217 @@
+package rules_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"lib/fakes"
+	"lib/rules"
+	"lib/serial"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/filelock"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ChainName", func() {
+	It("is the prefix followed by as much of the hash of the handle as fits", func() {
+		name, err := rules.ChainName("netout--", "some-handle")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(HaveLen(28))
+		Expect(name).To(HavePrefix("netout--"))
+
+		again, err := rules.ChainName("netout--", "some-handle")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(name))
+
+		other, err := rules.ChainName("netout--", "some-other-handle")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(other).NotTo(Equal(name))
+	})
+
+	It("refuses prefixes that leave too little room for the hash", func() {
+		_, err := rules.ChainName("a-prefix-that-is-way-too-lo", "some-handle")
+		Expect(err).To(MatchError("chain prefix a-prefix-that-is-way-too-lo leaves less than 8 characters for the hash"))
+	})
+})
+
+var _ = Describe("ChainNamer", func() {
+	var (
+		namer     *rules.ChainNamer
+		iptables  *fakes.IPTablesAdapter
+		stateDir  string
+		stateFile string
+	)
+
+	BeforeEach(func() {
+		var err error
+		stateDir, err = ioutil.TempDir("", "chain-namer")
+		Expect(err).NotTo(HaveOccurred())
+		stateFile = filepath.Join(stateDir, "chains.json")
+
+		iptables = &fakes.IPTablesAdapter{}
+		namer = &rules.ChainNamer{
+			Serializer: &serial.Serial{},
+			Locker:     filelock.NewLocker(stateFile),
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(stateDir)).To(Succeed())
+	})
+
+	Describe("Create", func() {
+		It("creates the chain once and records its owner", func() {
+			name, err := namer.Create(iptables, "filter", "netout--", "some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			expectedName, _ := rules.ChainName("netout--", "some-handle")
+			Expect(name).To(Equal(expectedName))
+
+			Expect(iptables.NewChainCallCount()).To(Equal(1))
+			table, chain := iptables.NewChainArgsForCall(0)
+			Expect(table).To(Equal("filter"))
+			Expect(chain).To(Equal(name))
+
+			again, err := namer.Create(iptables, "filter", "netout--", "some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(again).To(Equal(name))
+			Expect(iptables.NewChainCallCount()).To(Equal(1))
+
+			Expect(namer.List()).To(Equal(map[string]rules.ChainOwner{
+				name: {Table: "filter", Prefix: "netout--", Handle: "some-handle"},
+			}))
+		})
+
+		It("gives a handle whose name collides with another handle a different name", func() {
+			name, _ := rules.ChainName("netout--", "some-handle")
+			Expect(ioutil.WriteFile(stateFile, []byte(`{"chains": {"`+name+`": {"table": "filter", "prefix": "netout--", "handle": "other-handle"}}}`), 0600)).To(Succeed())
+
+			other, err := namer.Create(iptables, "filter", "netout--", "some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(other).NotTo(Equal(name))
+			Expect(other).To(HavePrefix("netout--"))
+			Expect(other).To(HaveLen(28))
+
+			Expect(iptables.NewChainCallCount()).To(Equal(1))
+			_, chain := iptables.NewChainArgsForCall(0)
+			Expect(chain).To(Equal(other))
+
+			again, err := namer.Create(iptables, "filter", "netout--", "some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(again).To(Equal(other))
+
+			Expect(namer.Delete(iptables, "filter", "netout--", "some-handle")).To(Succeed())
+			_, chain = iptables.DeleteChainArgsForCall(0)
+			Expect(chain).To(Equal(other))
+			Expect(namer.List()).To(HaveKey(name))
+		})
+
+		It("refuses prefixes that leave too little room for the hash", func() {
+			_, err := namer.Create(iptables, "filter", "a-prefix-that-is-way-too-lo", "some-handle")
+			Expect(err).To(MatchError(HaveSuffix("leaves less than 8 characters for the hash")))
+			Expect(iptables.NewChainCallCount()).To(Equal(0))
+		})
+
+		Context("when creating the chain fails", func() {
+			BeforeEach(func() {
+				iptables.NewChainReturns(errors.New("banana"))
+			})
+			It("does not record the owner", func() {
+				_, err := namer.Create(iptables, "filter", "netout--", "some-handle")
+				Expect(err).To(MatchError("creating chain: banana"))
+				Expect(namer.List()).To(BeEmpty())
+			})
+		})
+
+		Context("when the state file cannot be decoded", func() {
+			BeforeEach(func() {
+				Expect(ioutil.WriteFile(stateFile, []byte("banana"), 0600)).To(Succeed())
+			})
+			It("returns an error", func() {
+				_, err := namer.Create(iptables, "filter", "netout--", "some-handle")
+				Expect(err).To(MatchError(HavePrefix("decoding state file: ")))
+			})
+		})
+
+		Context("when the state file cannot be written", func() {
+			BeforeEach(func() {
+				serializer := &fakes.Serializer{}
+				serializer.EncodeAndOverwriteReturns(errors.New("banana"))
+				namer.Serializer = serializer
+			})
+			It("returns an error", func() {
+				_, err := namer.Create(iptables, "filter", "netout--", "some-handle")
+				Expect(err).To(MatchError("encode and overwrite: banana"))
+			})
+		})
+	})
+
+	Describe("Delete", func() {
+		It("clears and deletes the chain of the handle", func() {
+			name, err := namer.Create(iptables, "nat", "netin--", "some-handle")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(namer.Delete(iptables, "nat", "netin--", "some-handle")).To(Succeed())
+
+			Expect(iptables.ClearChainCallCount()).To(Equal(1))
+			Expect(iptables.DeleteChainCallCount()).To(Equal(1))
+			table, chain := iptables.DeleteChainArgsForCall(0)
+			Expect(table).To(Equal("nat"))
+			Expect(chain).To(Equal(name))
+			Expect(namer.List()).To(BeEmpty())
+		})
+
+		It("does nothing for handles without a chain", func() {
+			Expect(namer.Delete(iptables, "nat", "netin--", "some-handle")).To(Succeed())
+			Expect(iptables.DeleteChainCallCount()).To(Equal(0))
+		})
+
+		Context("when deleting the chain fails", func() {
+			BeforeEach(func() {
+				iptables.DeleteChainReturns(errors.New("banana"))
+			})
+			It("keeps the owner", func() {
+				name, err := namer.Create(iptables, "nat", "netin--", "some-handle")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = namer.Delete(iptables, "nat", "netin--", "some-handle")
+				Expect(err).To(MatchError("deleting chain " + name + ": banana"))
+				Expect(namer.List()).To(HaveKey(name))
+			})
+		})
+	})
+
+	Describe("GarbageCollect", func() {
+		It("deletes the chains of handles that are gone", func() {
+			liveChain, err := namer.Create(iptables, "filter", "netout--", "live-handle")
+			Expect(err).NotTo(HaveOccurred())
+			goneChain, err := namer.Create(iptables, "filter", "netout--", "gone-handle")
+			Expect(err).NotTo(HaveOccurred())
+			goneNatChain, err := namer.Create(iptables, "nat", "netin--", "gone-handle")
+			Expect(err).NotTo(HaveOccurred())
+
+			deleted, err := namer.GarbageCollect(iptables, []string{"live-handle"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(ConsistOf(goneChain, goneNatChain))
+
+			Expect(namer.List()).To(Equal(map[string]rules.ChainOwner{
+				liveChain: {Table: "filter", Prefix: "netout--", Handle: "live-handle"},
+			}))
+		})
+
+		It("carries on past chains it fails to delete", func() {
+			_, err := namer.Create(iptables, "filter", "a--", "gone-handle")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = namer.Create(iptables, "filter", "b--", "gone-handle")
+			Expect(err).NotTo(HaveOccurred())
+			iptables.DeleteChainReturnsOnCall(0, errors.New("banana"))
+
+			deleted, err := namer.GarbageCollect(iptables, []string{})
+			Expect(err).To(MatchError(HaveSuffix(": banana")))
+			Expect(deleted).To(HaveLen(1))
+			Expect(namer.List()).To(HaveLen(1))
+		})
+	})
+})
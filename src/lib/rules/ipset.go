@@ -0,0 +1,232 @@
+package rules
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"os/exec"
+	"strings"
+)
+
+const maxIPSetNameLength = 31
+
+// IPSet is the desired content of a set. Type is hash:net for entries like
+// 10.0.0.0/24, or hash:net,port or hash:ip,port for entries like
+// 10.0.0.0/24,tcp:80-90. Family is inet unless it is set to inet6.
+type IPSet struct {
+	Name    string
+	Type    string
+	Family  string
+	Entries []string
+}
+
+// IPSetRestorer feeds scripts to `ipset restore`.
+type IPSetRestorer struct{}
+
+func (r *IPSetRestorer) Restore(input string) error {
+	cmd := exec.Command("ipset", "restore")
+	cmd.Stdin = strings.NewReader(input)
+
+	bytes, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ipset restore error: %s combined output: %s", err, string(bytes))
+	}
+	return nil
+}
+
+// IPSets manages the sets referenced by the set rules. A set is filled under
+// a temporary name and then swapped in, so rules matching the set never see
+// it half-filled. Sets have to exist before rules can reference them, and
+// can only be destroyed once no rule references them.
+type IPSets struct {
+	Restorer restorer
+	Locker   locker
+}
+
+func handleIPSetError(err1, err2 error) error {
+	return fmt.Errorf("ipset call: %+v and unlock: %+v", err1, err2)
+}
+
+func (s *IPSets) Replace(set IPSet) error {
+	if len(set.Name) > maxIPSetNameLength {
+		return fmt.Errorf("ipset name %s is longer than %d characters", set.Name, maxIPSetNameLength)
+	}
+	family := set.Family
+	if family == "" {
+		family = "inet"
+	}
+	temporary := fmt.Sprintf("tmp-%s", set.Name)
+	if len(temporary) > maxIPSetNameLength {
+		temporary = temporary[:maxIPSetNameLength]
+	}
+
+	input := []string{
+		fmt.Sprintf("create %s %s family %s -exist\n", temporary, set.Type, family),
+		fmt.Sprintf("flush %s\n", temporary),
+	}
+	for _, entry := range set.Entries {
+		input = append(input, fmt.Sprintf("add %s %s -exist\n", temporary, entry))
+	}
+	input = append(input,
+		fmt.Sprintf("create %s %s family %s -exist\n", set.Name, set.Type, family),
+		fmt.Sprintf("swap %s %s\n", temporary, set.Name),
+		fmt.Sprintf("destroy %s\n", temporary),
+	)
+
+	return s.restore(strings.Join(input, ""))
+}
+
+func (s *IPSets) Destroy(name string) error {
+	return s.restore(fmt.Sprintf("destroy %s\n", name))
+}
+
+func (s *IPSets) restore(input string) error {
+	if err := s.Locker.Lock(); err != nil {
+		return fmt.Errorf("lock: %s", err)
+	}
+
+	err := s.Restorer.Restore(input)
+	if err != nil {
+		return handleIPSetError(err, s.Locker.Unlock())
+	}
+
+	return s.Locker.Unlock()
+}
+
+// NetOutRange is a destination that a container may reach. Protocol is tcp,
+// udp, or empty for all protocols; ports are only used for tcp and udp.
+type NetOutRange struct {
+	StartIP   string
+	EndIP     string
+	Protocol  string
+	StartPort int
+	EndPort   int
+}
+
+// NetOutCollapser builds the netout rules for a list of ranges. Up to
+// Threshold ranges get one rule each; above it, the ranges are put into sets
+// named after SetPrefix, matched by one rule per set. A Threshold of zero
+// never collapses. With a LogChain, the rules go to that chain instead of
+// accepting.
+type NetOutCollapser struct {
+	Threshold int
+	SetPrefix string
+	LogChain  string
+}
+
+// Rules returns the rules, and the sets that have to be replaced before the
+// rules are written.
+func (c NetOutCollapser) Rules(ranges []NetOutRange) ([]IPTablesRule, []IPSet, error) {
+	for _, r := range ranges {
+		if r.Protocol != "" && r.Protocol != "all" && !hasPorts(r) {
+			return nil, nil, fmt.Errorf("unsupported protocol %s for %s-%s", r.Protocol, r.StartIP, r.EndIP)
+		}
+	}
+
+	if c.Threshold == 0 || len(ranges) <= c.Threshold {
+		rules := []IPTablesRule{}
+		for _, r := range ranges {
+			switch {
+			case hasPorts(r) && c.LogChain != "":
+				rules = append(rules, NewNetOutWithPortsLogRule(r.StartIP, r.EndIP, r.StartPort, r.EndPort, r.Protocol, c.LogChain))
+			case hasPorts(r):
+				rules = append(rules, NewNetOutWithPortsRule(r.StartIP, r.EndIP, r.StartPort, r.EndPort, r.Protocol))
+			case c.LogChain != "":
+				rules = append(rules, NewNetOutLogRule(r.StartIP, r.EndIP, c.LogChain))
+			default:
+				rules = append(rules, NewNetOutRule(r.StartIP, r.EndIP))
+			}
+		}
+		return rules, []IPSet{}, nil
+	}
+
+	sets := map[string]*IPSet{}
+	order := []string{}
+	for _, r := range ranges {
+		start, end := net.ParseIP(r.StartIP), net.ParseIP(r.EndIP)
+		if start == nil || end == nil || (start.To4() == nil) != (end.To4() == nil) {
+			return nil, nil, fmt.Errorf("bad ip range %s-%s", r.StartIP, r.EndIP)
+		}
+
+		name, setType, family := c.SetPrefix+"-net", "hash:net", "inet"
+		if hasPorts(r) {
+			name, setType = c.SetPrefix+"-port", "hash:net,port"
+		}
+		if start.To4() == nil {
+			name, family = name+"6", "inet6"
+		}
+		if len(name) > maxIPSetNameLength {
+			return nil, nil, fmt.Errorf("ipset name %s is longer than %d characters", name, maxIPSetNameLength)
+		}
+		if _, ok := sets[name]; !ok {
+			sets[name] = &IPSet{Name: name, Type: setType, Family: family, Entries: []string{}}
+			order = append(order, name)
+		}
+
+		for _, cidr := range rangeToCIDRs(start, end) {
+			entry := cidr
+			if hasPorts(r) {
+				entry = fmt.Sprintf("%s,%s:%d-%d", cidr, r.Protocol, r.StartPort, r.EndPort)
+			}
+			sets[name].Entries = append(sets[name].Entries, entry)
+		}
+	}
+
+	rules := []IPTablesRule{}
+	result := []IPSet{}
+	for _, name := range order {
+		ports := sets[name].Type != "hash:net"
+		switch {
+		case ports && c.LogChain != "":
+			rules = append(rules, NewNetOutWithPortsSetLogRule(name, c.LogChain))
+		case ports:
+			rules = append(rules, NewNetOutWithPortsSetRule(name))
+		case c.LogChain != "":
+			rules = append(rules, NewNetOutSetLogRule(name, c.LogChain))
+		default:
+			rules = append(rules, NewNetOutSetRule(name))
+		}
+		result = append(result, *sets[name])
+	}
+	return rules, result, nil
+}
+
+func hasPorts(r NetOutRange) bool {
+	return r.Protocol == "tcp" || r.Protocol == "udp"
+}
+
+// rangeToCIDRs returns the fewest CIDRs that cover exactly start to end. The
+// largest CIDR is a /1, since hash:net sets do not take a /0 entry.
+func rangeToCIDRs(start, end net.IP) []string {
+	bits := 128
+	if start.To4() != nil {
+		start, end, bits = start.To4(), end.To4(), 32
+	} else {
+		start, end = start.To16(), end.To16()
+	}
+
+	current := new(big.Int).SetBytes(start)
+	last := new(big.Int).SetBytes(end)
+	one := big.NewInt(1)
+
+	cidrs := []string{}
+	for current.Cmp(last) <= 0 {
+		size := 0
+		for size < bits-1 && current.Bit(size) == 0 {
+			next := new(big.Int).Lsh(one, uint(size+1))
+			next.Add(current, next).Sub(next, one)
+			if next.Cmp(last) > 0 {
+				break
+			}
+			size++
+		}
+
+		ip := make(net.IP, bits/8)
+		b := current.Bytes()
+		copy(ip[len(ip)-len(b):], b)
+		cidrs = append(cidrs, fmt.Sprintf("%s/%d", ip, bits-size))
+
+		current.Add(current, new(big.Int).Lsh(one, uint(size)))
+	}
+	return cidrs
+}
@@ -0,0 +1,237 @@
+package rules_test
+
+import (
+	"errors"
+	"lib/fakes"
+	"lib/rules"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IPSets", func() {
+	var (
+		ipsets   *rules.IPSets
+		restorer *fakes.Restorer
+		lock     *fakes.Locker
+	)
+
+	BeforeEach(func() {
+		restorer = &fakes.Restorer{}
+		lock = &fakes.Locker{}
+		ipsets = &rules.IPSets{
+			Restorer: restorer,
+			Locker:   lock,
+		}
+	})
+
+	Describe("Replace", func() {
+		It("fills a temporary set and swaps it in", func() {
+			err := ipsets.Replace(rules.IPSet{
+				Name:    "some-set",
+				Type:    "hash:net",
+				Entries: []string{"10.0.0.0/24", "10.0.1.1/32"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(lock.LockCallCount()).To(Equal(1))
+			Expect(lock.UnlockCallCount()).To(Equal(1))
+			Expect(restorer.RestoreCallCount()).To(Equal(1))
+			Expect(restorer.RestoreArgsForCall(0)).To(Equal(
+				"create tmp-some-set hash:net family inet -exist\n" +
+					"flush tmp-some-set\n" +
+					"add tmp-some-set 10.0.0.0/24 -exist\n" +
+					"add tmp-some-set 10.0.1.1/32 -exist\n" +
+					"create some-set hash:net family inet -exist\n" +
+					"swap tmp-some-set some-set\n" +
+					"destroy tmp-some-set\n",
+			))
+		})
+
+		It("creates ipv6 sets", func() {
+			err := ipsets.Replace(rules.IPSet{Name: "some-set", Type: "hash:net,port", Family: "inet6"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(restorer.RestoreArgsForCall(0)).To(ContainSubstring("create some-set hash:net,port family inet6 -exist\n"))
+		})
+
+		It("keeps the temporary name within the limit", func() {
+			err := ipsets.Replace(rules.IPSet{Name: "a-set-with-a-30-character-name", Type: "hash:net"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(restorer.RestoreArgsForCall(0)).To(ContainSubstring("swap tmp-a-set-with-a-30-character-n a-set-with-a-30-character-name\n"))
+		})
+
+		It("rejects names that are too long", func() {
+			err := ipsets.Replace(rules.IPSet{Name: "a-set-with-a-much-too-long-name!", Type: "hash:net"})
+			Expect(err).To(MatchError("ipset name a-set-with-a-much-too-long-name! is longer than 31 characters"))
+			Expect(lock.LockCallCount()).To(Equal(0))
+		})
+
+		Context("when the lock fails", func() {
+			BeforeEach(func() {
+				lock.LockReturns(errors.New("banana"))
+			})
+			It("returns an error", func() {
+				err := ipsets.Replace(rules.IPSet{Name: "some-set", Type: "hash:net"})
+				Expect(err).To(MatchError("lock: banana"))
+				Expect(restorer.RestoreCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the restore fails", func() {
+			BeforeEach(func() {
+				restorer.RestoreReturns(errors.New("banana"))
+				lock.UnlockReturns(errors.New("potato"))
+			})
+			It("unlocks and returns both errors", func() {
+				err := ipsets.Replace(rules.IPSet{Name: "some-set", Type: "hash:net"})
+				Expect(err).To(MatchError("ipset call: banana and unlock: potato"))
+				Expect(lock.UnlockCallCount()).To(Equal(1))
+			})
+		})
+	})
+
+	Describe("Destroy", func() {
+		It("destroys the set", func() {
+			Expect(ipsets.Destroy("some-set")).To(Succeed())
+			Expect(restorer.RestoreArgsForCall(0)).To(Equal("destroy some-set\n"))
+			Expect(lock.UnlockCallCount()).To(Equal(1))
+		})
+	})
+})
+
+var _ = Describe("NetOutCollapser", func() {
+	var ranges []rules.NetOutRange
+
+	BeforeEach(func() {
+		ranges = []rules.NetOutRange{
+			{StartIP: "10.0.0.1", EndIP: "10.0.0.10"},
+			{StartIP: "10.1.0.0", EndIP: "10.1.255.255", Protocol: "tcp", StartPort: 443, EndPort: 443},
+			{StartIP: "2001:db8::", EndIP: "2001:db8::3", Protocol: "all"},
+		}
+	})
+
+	It("writes a rule per range up to the threshold", func() {
+		ruleset, sets, err := rules.NetOutCollapser{Threshold: 3, SetPrefix: "netout"}.Rules(ranges)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sets).To(BeEmpty())
+		Expect(ruleset).To(Equal([]rules.IPTablesRule{
+			rules.NewNetOutRule("10.0.0.1", "10.0.0.10"),
+			rules.NewNetOutWithPortsRule("10.1.0.0", "10.1.255.255", 443, 443, "tcp"),
+			rules.NewNetOutRule("2001:db8::", "2001:db8::3"),
+		}))
+	})
+
+	It("never collapses with a zero threshold", func() {
+		ruleset, sets, err := rules.NetOutCollapser{SetPrefix: "netout"}.Rules(ranges)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sets).To(BeEmpty())
+		Expect(ruleset).To(HaveLen(3))
+	})
+
+	It("collapses the ranges into sets above the threshold", func() {
+		ruleset, sets, err := rules.NetOutCollapser{Threshold: 2, SetPrefix: "netout"}.Rules(ranges)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sets).To(Equal([]rules.IPSet{
+			{
+				Name: "netout-net", Type: "hash:net", Family: "inet",
+				Entries: []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/30", "10.0.0.8/31", "10.0.0.10/32"},
+			},
+			{
+				Name: "netout-port", Type: "hash:net,port", Family: "inet",
+				Entries: []string{"10.1.0.0/16,tcp:443-443"},
+			},
+			{
+				Name: "netout-net6", Type: "hash:net", Family: "inet6",
+				Entries: []string{"2001:db8::/126"},
+			},
+		}))
+		Expect(ruleset).To(Equal([]rules.IPTablesRule{
+			rules.NewNetOutSetRule("netout-net"),
+			rules.NewNetOutWithPortsSetRule("netout-port"),
+			rules.NewNetOutSetRule("netout-net6"),
+		}))
+	})
+
+	It("splits the range of all addresses into two /1 entries", func() {
+		ranges = []rules.NetOutRange{
+			{StartIP: "0.0.0.0", EndIP: "255.255.255.255"},
+			{StartIP: "0.0.0.0", EndIP: "255.255.255.255", Protocol: "udp", StartPort: 53, EndPort: 53},
+			{StartIP: "::", EndIP: "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+		}
+		_, sets, err := rules.NetOutCollapser{Threshold: 2, SetPrefix: "netout"}.Rules(ranges)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sets[0].Entries).To(Equal([]string{"0.0.0.0/1", "128.0.0.0/1"}))
+		Expect(sets[1].Entries).To(Equal([]string{"0.0.0.0/1,udp:53-53", "128.0.0.0/1,udp:53-53"}))
+		Expect(sets[2].Entries).To(Equal([]string{"::/1", "8000::/1"}))
+	})
+
+	It("goes to the log chain when there is one", func() {
+		collapser := rules.NetOutCollapser{Threshold: 2, SetPrefix: "netout", LogChain: "some-log-chain"}
+		ruleset, _, err := collapser.Rules(ranges)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ruleset[0]).To(Equal(rules.NewNetOutSetLogRule("netout-net", "some-log-chain")))
+		Expect(ruleset[1]).To(Equal(rules.NewNetOutWithPortsSetLogRule("netout-port", "some-log-chain")))
+
+		collapser.Threshold = 0
+		ruleset, _, err = collapser.Rules(ranges)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ruleset[0]).To(Equal(rules.NewNetOutLogRule("10.0.0.1", "10.0.0.10", "some-log-chain")))
+		Expect(ruleset[1]).To(Equal(rules.NewNetOutWithPortsLogRule("10.1.0.0", "10.1.255.255", 443, 443, "tcp", "some-log-chain")))
+	})
+
+	It("matches the same packets collapsed or not", func() {
+		packets := []rules.Packet{
+			{DestinationIP: "10.0.0.0", Protocol: "tcp"},
+			{DestinationIP: "10.0.0.1", Protocol: "tcp"},
+			{DestinationIP: "10.0.0.10", Protocol: "udp"},
+			{DestinationIP: "10.0.0.11", Protocol: "udp"},
+			{DestinationIP: "10.1.2.3", Protocol: "tcp", DestinationPort: 443},
+			{DestinationIP: "10.1.2.3", Protocol: "tcp", DestinationPort: 80},
+			{DestinationIP: "10.1.2.3", Protocol: "udp", DestinationPort: 443},
+			{DestinationIP: "2001:db8::3", Protocol: "tcp"},
+			{DestinationIP: "2001:db8::4", Protocol: "tcp"},
+		}
+
+		verdicts := func(threshold int) []string {
+			ruleset, sets, err := rules.NetOutCollapser{Threshold: threshold, SetPrefix: "netout"}.Rules(ranges)
+			Expect(err).NotTo(HaveOccurred())
+
+			simulator := rules.NewSimulator()
+			for _, set := range sets {
+				simulator.LoadSet(set)
+			}
+			Expect(simulator.Load("filter", "OUTPUT", ruleset...)).To(Succeed())
+			Expect(simulator.SetPolicy("filter", "OUTPUT", "DROP")).To(Succeed())
+
+			targets := []string{}
+			for _, packet := range packets {
+				verdict, err := simulator.Evaluate("filter", "OUTPUT", packet)
+				Expect(err).NotTo(HaveOccurred())
+				targets = append(targets, verdict.Target)
+			}
+			return targets
+		}
+
+		Expect(verdicts(1)).To(Equal(verdicts(0)))
+		Expect(verdicts(1)).To(Equal([]string{"DROP", "ACCEPT", "ACCEPT", "DROP", "ACCEPT", "DROP", "DROP", "ACCEPT", "DROP"}))
+	})
+
+	It("rejects protocols that sets cannot express", func() {
+		ranges = append(ranges, rules.NetOutRange{StartIP: "8.8.8.8", EndIP: "8.8.8.8", Protocol: "icmp"})
+		_, _, err := rules.NetOutCollapser{Threshold: 1, SetPrefix: "netout"}.Rules(ranges)
+		Expect(err).To(MatchError("unsupported protocol icmp for 8.8.8.8-8.8.8.8"))
+	})
+
+	It("rejects bad ranges", func() {
+		ranges = []rules.NetOutRange{{StartIP: "10.0.0.1", EndIP: "2001:db8::1"}}
+		_, _, err := rules.NetOutCollapser{Threshold: 0, SetPrefix: "netout"}.Rules(append(ranges, ranges...))
+		Expect(err).NotTo(HaveOccurred())
+		_, _, err = rules.NetOutCollapser{Threshold: 1, SetPrefix: "netout"}.Rules(append(ranges, ranges...))
+		Expect(err).To(MatchError("bad ip range 10.0.0.1-2001:db8::1"))
+	})
+
+	It("rejects set prefixes that make names too long", func() {
+		_, _, err := rules.NetOutCollapser{Threshold: 1, SetPrefix: "a-very-long-prefix-for-a-set"}.Rules(ranges)
+		Expect(err).To(MatchError("ipset name a-very-long-prefix-for-a-set-net is longer than 31 characters"))
+	})
+})
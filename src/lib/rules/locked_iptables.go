@@ -2,8 +2,14 @@ package rules
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 )
 
 //go:generate counterfeiter -o ../fakes/iptables.go --fake-name IPTables . iptables
@@ -41,6 +47,11 @@ type restorer interface {
 	Restore(ruleState string) error
 }
 
+//go:generate counterfeiter -o ../fakes/saver.go --fake-name Saver . saver
+type saver interface {
+	Save(table string) (string, error)
+}
+
 type Restorer struct{}
 
 func (r *Restorer) Restore(input string) error {
@@ -54,31 +65,209 @@ func (r *Restorer) Restore(input string) error {
 	return nil
 }
 
+// TableRestorer replaces every table in its input entirely, where Restorer
+// only changes the chains its input names.
+type TableRestorer struct{}
+
+func (r *TableRestorer) Restore(input string) error {
+	cmd := exec.Command("iptables-restore")
+	cmd.Stdin = strings.NewReader(input)
+
+	bytes, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("iptables-restore error: %s combined output: %s", err, string(bytes))
+	}
+	return nil
+}
+
+type Saver struct{}
+
+func (s *Saver) Save(table string) (string, error) {
+	bytes, err := exec.Command("iptables-save", "-t", table).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("iptables-save error: %s combined output: %s", err, string(bytes))
+	}
+	return string(bytes), nil
+}
+
+type metricsSender interface {
+	IncrementCounter(string)
+	SendDuration(string, time.Duration)
+}
+
+// LockedIPTables takes the Locker around every call. The other fields are
+// optional: with a LockTimeout, a call gives up waiting for the lock after
+// that long, and with a ContentionThreshold, a call that waits longer than
+// that logs who holds the lock. The holder is the process that /proc/locks
+// lists as holding a lock on the LockFile, which may be another process
+// entirely. Transactions need the Saver and TableRestorer.
 type LockedIPTables struct {
-	IPTables iptables
-	Locker   locker
-	Restorer restorer
+	IPTables      iptables
+	Locker        locker
+	Restorer      restorer
+	Saver         saver
+	TableRestorer restorer
+
+	LockTimeout         time.Duration
+	ContentionThreshold time.Duration
+	LockFile            string
+	ProcDir             string
+	MetricsSender       metricsSender
+	Logger              io.Writer
+
+	ChainNamer *ChainNamer
 }
 
 func handleIPTablesError(err1, err2 error) error {
 	return fmt.Errorf("iptables call: %+v and unlock: %+v", err1, err2)
 }
 
+// lock returns when the lock was acquired, which unlock needs to report how
+// long it was held.
+func (l *LockedIPTables) lock(operation string) (time.Time, error) {
+	start := time.Now()
+	if err := l.waitForLock(operation); err != nil {
+		return time.Time{}, err
+	}
+	acquiredAt := time.Now()
+	l.sendDuration(fmt.Sprintf("IPTables%sLockWaitTime", operation), acquiredAt.Sub(start))
+	return acquiredAt, nil
+}
+
+func (l *LockedIPTables) waitForLock(operation string) error {
+	if l.LockTimeout == 0 && l.ContentionThreshold == 0 {
+		return l.Locker.Lock()
+	}
+
+	locked := make(chan error, 1)
+	go func() {
+		locked <- l.Locker.Lock()
+	}()
+
+	var timeout, contention <-chan time.Time
+	if l.LockTimeout > 0 {
+		timer := time.NewTimer(l.LockTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	if l.ContentionThreshold > 0 {
+		timer := time.NewTimer(l.ContentionThreshold)
+		defer timer.Stop()
+		contention = timer.C
+	}
+
+	for {
+		select {
+		case err := <-locked:
+			return err
+		case <-contention:
+			contention = nil
+			l.incrementCounter("IPTablesLockContention")
+			l.logf("%s has waited more than %s for the iptables lock held by %s", operation, l.ContentionThreshold, l.holder())
+		case <-timeout:
+			l.incrementCounter("IPTablesLockTimeout")
+			// the lock cannot be abandoned, so give it back once it is acquired
+			go func() {
+				if err := <-locked; err == nil {
+					l.Locker.Unlock()
+				}
+			}()
+			return fmt.Errorf("timed out after %s waiting for the iptables lock held by %s", l.LockTimeout, l.holder())
+		}
+	}
+}
+
+func (l *LockedIPTables) unlock(operation string, acquiredAt time.Time) error {
+	l.sendDuration(fmt.Sprintf("IPTables%sLockHoldTime", operation), time.Since(acquiredAt))
+	return l.Locker.Unlock()
+}
+
+// holder finds the process holding the lock on the LockFile in the
+// /proc/locks of the ProcDir, which defaults to /proc.
+func (l *LockedIPTables) holder() string {
+	if l.LockFile == "" {
+		return "an unknown holder"
+	}
+	info, err := os.Stat(l.LockFile)
+	if err != nil {
+		return "an unknown holder"
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "an unknown holder"
+	}
+	major, minor := deviceNumbers(uint64(stat.Dev))
+	fileID := fmt.Sprintf("%02x:%02x:%d", major, minor, uint64(stat.Ino))
+
+	procDir := l.ProcDir
+	if procDir == "" {
+		procDir = "/proc"
+	}
+	locks, err := ioutil.ReadFile(filepath.Join(procDir, "locks"))
+	if err != nil {
+		return "an unknown holder"
+	}
+
+	for _, line := range strings.Split(string(locks), "\n") {
+		// 1: FLOCK  ADVISORY  WRITE 1234 08:01:5678 0 EOF
+		// waiters are listed after the holder as "1: -> FLOCK ..."
+		fields := strings.Fields(line)
+		if len(fields) < 6 || fields[1] == "->" || fields[5] != fileID {
+			continue
+		}
+		pid := fields[4]
+		cmdline, err := ioutil.ReadFile(filepath.Join(procDir, pid, "cmdline"))
+		if err != nil || len(cmdline) == 0 {
+			return fmt.Sprintf("pid %s", pid)
+		}
+		command := strings.TrimSpace(strings.Replace(string(cmdline), "\x00", " ", -1))
+		return fmt.Sprintf("pid %s running %s", pid, command)
+	}
+	return "an unknown holder"
+}
+
+// deviceNumbers splits a device number from stat the way glibc does.
+func deviceNumbers(dev uint64) (uint64, uint64) {
+	major := (dev>>8)&0xfff | (dev>>32)&^uint64(0xfff)
+	minor := dev&0xff | uint64(uint32(dev>>12))&^uint64(0xff)
+	return major, minor
+}
+
+func (l *LockedIPTables) incrementCounter(name string) {
+	if l.MetricsSender != nil {
+		l.MetricsSender.IncrementCounter(name)
+	}
+}
+
+func (l *LockedIPTables) sendDuration(name string, duration time.Duration) {
+	if l.MetricsSender != nil {
+		l.MetricsSender.SendDuration(name, duration)
+	}
+}
+
+func (l *LockedIPTables) logf(format string, args ...interface{}) {
+	if l.Logger != nil {
+		fmt.Fprintf(l.Logger, format+"\n", args...)
+	}
+}
+
 func (l *LockedIPTables) Exists(table, chain string, rulespec IPTablesRule) (bool, error) {
-	if err := l.Locker.Lock(); err != nil {
+	acquiredAt, err := l.lock("Exists")
+	if err != nil {
 		return false, fmt.Errorf("lock: %s", err)
 	}
 
 	b, err := l.IPTables.Exists(table, chain, rulespec...)
 	if err != nil {
-		return false, handleIPTablesError(err, l.Locker.Unlock())
+		return false, handleIPTablesError(err, l.unlock("Exists", acquiredAt))
 	}
 
-	return b, l.Locker.Unlock()
+	return b, l.unlock("Exists", acquiredAt)
 }
 
-func (l *LockedIPTables) bulkAction(table, prefix string, rulespec ...IPTablesRule) error {
-	if err := l.Locker.Lock(); err != nil {
+func (l *LockedIPTables) bulkAction(operation, table, prefix string, rulespec ...IPTablesRule) error {
+	acquiredAt, err := l.lock(operation)
+	if err != nil {
 		return fmt.Errorf("lock: %s", err)
 	}
 
@@ -89,65 +278,96 @@ func (l *LockedIPTables) bulkAction(table, prefix string, rulespec ...IPTablesRu
 	}
 	input = append(input, "COMMIT\n")
 
-	err := l.Restorer.Restore(strings.Join(input, ""))
+	err = l.Restorer.Restore(strings.Join(input, ""))
 	if err != nil {
-		return handleIPTablesError(err, l.Locker.Unlock())
+		return handleIPTablesError(err, l.unlock(operation, acquiredAt))
 	}
 
-	return l.Locker.Unlock()
+	return l.unlock(operation, acquiredAt)
 }
 
 func (l *LockedIPTables) BulkInsert(table, chain string, pos int, rulespec ...IPTablesRule) error {
-	return l.bulkAction(table, fmt.Sprintf("-I %s %d", chain, pos), rulespec...)
+	return l.bulkAction("BulkInsert", table, fmt.Sprintf("-I %s %d", chain, pos), rulespec...)
 }
 
 func (l *LockedIPTables) BulkAppend(table, chain string, rulespec ...IPTablesRule) error {
-	return l.bulkAction(table, fmt.Sprintf("-A %s", chain), rulespec...)
+	return l.bulkAction("BulkAppend", table, fmt.Sprintf("-A %s", chain), rulespec...)
 }
 
 func (l *LockedIPTables) Delete(table, chain string, rulespec IPTablesRule) error {
-	if err := l.Locker.Lock(); err != nil {
+	acquiredAt, err := l.lock("Delete")
+	if err != nil {
 		return fmt.Errorf("lock: %s", err)
 	}
 
-	err := l.IPTables.Delete(table, chain, rulespec...)
+	err = l.IPTables.Delete(table, chain, rulespec...)
 	if err != nil {
-		return handleIPTablesError(err, l.Locker.Unlock())
+		return handleIPTablesError(err, l.unlock("Delete", acquiredAt))
 	}
 
-	return l.Locker.Unlock()
+	return l.unlock("Delete", acquiredAt)
 }
 
 func (l *LockedIPTables) List(table, chain string) ([]string, error) {
-	if err := l.Locker.Lock(); err != nil {
+	acquiredAt, err := l.lock("List")
+	if err != nil {
 		return nil, fmt.Errorf("lock: %s", err)
 	}
 
 	ret, err := l.IPTables.List(table, chain)
 	if err != nil {
-		return nil, handleIPTablesError(err, l.Locker.Unlock())
+		return nil, handleIPTablesError(err, l.unlock("List", acquiredAt))
 	}
 
-	return ret, l.Locker.Unlock()
+	return ret, l.unlock("List", acquiredAt)
 }
 
 func (l *LockedIPTables) NewChain(table, chain string) error {
-	return l.chainExec(table, chain, l.IPTables.NewChain)
+	return l.chainExec("NewChain", table, chain, l.IPTables.NewChain)
 }
 func (l *LockedIPTables) ClearChain(table, chain string) error {
-	return l.chainExec(table, chain, l.IPTables.ClearChain)
+	return l.chainExec("ClearChain", table, chain, l.IPTables.ClearChain)
 }
 func (l *LockedIPTables) DeleteChain(table, chain string) error {
-	return l.chainExec(table, chain, l.IPTables.DeleteChain)
+	return l.chainExec("DeleteChain", table, chain, l.IPTables.DeleteChain)
 }
 
-func (l *LockedIPTables) chainExec(table, chain string, action func(string, string) error) error {
-	if err := l.Locker.Lock(); err != nil {
+// NewHandleChain creates the chain that the prefix gives to the handle and
+// returns its name. The ChainNamer records the handle as its owner, and picks
+// another name when the first one is owned by another handle.
+func (l *LockedIPTables) NewHandleChain(table, prefix, handle string) (string, error) {
+	if l.ChainNamer == nil {
+		return "", fmt.Errorf("handle chains need a ChainNamer")
+	}
+	return l.ChainNamer.Create(l, table, prefix, handle)
+}
+
+// DeleteHandleChain clears and deletes the chain of the handle, if it has
+// one. Rules that jump to the chain have to be deleted first.
+func (l *LockedIPTables) DeleteHandleChain(table, prefix, handle string) error {
+	if l.ChainNamer == nil {
+		return fmt.Errorf("handle chains need a ChainNamer")
+	}
+	return l.ChainNamer.Delete(l, table, prefix, handle)
+}
+
+// GarbageCollectHandleChains deletes the chains of the handles that are not
+// live, and returns their names.
+func (l *LockedIPTables) GarbageCollectHandleChains(liveHandles []string) ([]string, error) {
+	if l.ChainNamer == nil {
+		return nil, fmt.Errorf("handle chains need a ChainNamer")
+	}
+	return l.ChainNamer.GarbageCollect(l, liveHandles)
+}
+
+func (l *LockedIPTables) chainExec(operation, table, chain string, action func(string, string) error) error {
+	acquiredAt, err := l.lock(operation)
+	if err != nil {
 		return fmt.Errorf("lock: %s", err)
 	}
 	if err := action(table, chain); err != nil {
-		return handleIPTablesError(err, l.Locker.Unlock())
+		return handleIPTablesError(err, l.unlock(operation, acquiredAt))
 	}
 
-	return l.Locker.Unlock()
+	return l.unlock(operation, acquiredAt)
 }
@@ -3,11 +3,21 @@ package rules_test
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"lib/fakes"
 	"lib/rules"
+	"lib/serial"
+	"lib/testsupport"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/filelock"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 )
 
 var _ = Describe("LockedIptables", func() {
@@ -347,6 +357,61 @@ var _ = Describe("LockedIptables", func() {
 		})
 	})
 
+	Describe("handle chains", func() {
+		var stateDir string
+
+		BeforeEach(func() {
+			var err error
+			stateDir, err = ioutil.TempDir("", "chain-namer")
+			Expect(err).NotTo(HaveOccurred())
+			lockedIPT.ChainNamer = &rules.ChainNamer{
+				Serializer: &serial.Serial{},
+				Locker:     filelock.NewLocker(filepath.Join(stateDir, "chains.json")),
+			}
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(stateDir)).To(Succeed())
+		})
+
+		It("creates, deletes and garbage collects them under the lock", func() {
+			name, err := lockedIPT.NewHandleChain("filter", "netout--", "some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			expectedName, _ := rules.ChainName("netout--", "some-handle")
+			Expect(name).To(Equal(expectedName))
+			Expect(ipt.NewChainCallCount()).To(Equal(1))
+			table, chain := ipt.NewChainArgsForCall(0)
+			Expect(table).To(Equal("filter"))
+			Expect(chain).To(Equal(name))
+			Expect(lock.LockCallCount()).To(Equal(1))
+			Expect(lock.UnlockCallCount()).To(Equal(1))
+
+			Expect(lockedIPT.DeleteHandleChain("filter", "netout--", "some-handle")).To(Succeed())
+			_, chain = ipt.DeleteChainArgsForCall(0)
+			Expect(chain).To(Equal(name))
+
+			goneChain, err := lockedIPT.NewHandleChain("filter", "netout--", "gone-handle")
+			Expect(err).NotTo(HaveOccurred())
+			deleted, err := lockedIPT.GarbageCollectHandleChains([]string{"some-handle"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(Equal([]string{goneChain}))
+		})
+
+		Context("without a ChainNamer", func() {
+			BeforeEach(func() {
+				lockedIPT.ChainNamer = nil
+			})
+			It("returns an error", func() {
+				_, err := lockedIPT.NewHandleChain("filter", "netout--", "some-handle")
+				Expect(err).To(MatchError("handle chains need a ChainNamer"))
+				Expect(lockedIPT.DeleteHandleChain("filter", "netout--", "some-handle")).To(MatchError("handle chains need a ChainNamer"))
+				_, err = lockedIPT.GarbageCollectHandleChains(nil)
+				Expect(err).To(MatchError("handle chains need a ChainNamer"))
+				Expect(ipt.NewChainCallCount()).To(Equal(0))
+			})
+		})
+	})
+
 	Describe("DeleteChain", func() {
 		It("locks and passes the correct parameters to the iptables library", func() {
 			err := lockedIPT.DeleteChain("some-table", "some-chain")
@@ -436,4 +501,164 @@ var _ = Describe("LockedIptables", func() {
 			})
 		})
 	})
+
+	Describe("lock metrics", func() {
+		var metricsSender *fakes.MetricsSender
+
+		BeforeEach(func() {
+			metricsSender = &fakes.MetricsSender{}
+			lockedIPT.MetricsSender = metricsSender
+		})
+
+		It("sends how long each operation waited for and held the lock", func() {
+			Expect(lockedIPT.NewChain("some-table", "some-chain")).To(Succeed())
+
+			Expect(metricsSender.SendDurationCallCount()).To(Equal(2))
+			name, _ := metricsSender.SendDurationArgsForCall(0)
+			Expect(name).To(Equal("IPTablesNewChainLockWaitTime"))
+			name, _ = metricsSender.SendDurationArgsForCall(1)
+			Expect(name).To(Equal("IPTablesNewChainLockHoldTime"))
+		})
+
+		Context("when the lock fails", func() {
+			BeforeEach(func() {
+				lock.LockReturns(errors.New("banana"))
+			})
+			It("sends no durations", func() {
+				Expect(lockedIPT.NewChain("some-table", "some-chain")).To(MatchError("lock: banana"))
+				Expect(metricsSender.SendDurationCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("lock contention", func() {
+		var (
+			procDir       string
+			lockFile      string
+			release       chan struct{}
+			logger        *gbytes.Buffer
+			metricsSender *fakes.MetricsSender
+		)
+
+		BeforeEach(func() {
+			var err error
+			procDir, err = ioutil.TempDir("", "locked-iptables")
+			Expect(err).NotTo(HaveOccurred())
+			lockFile = filepath.Join(procDir, "iptables.lock")
+			Expect(ioutil.WriteFile(lockFile, nil, 0600)).To(Succeed())
+
+			var stat syscall.Stat_t
+			Expect(syscall.Stat(lockFile, &stat)).To(Succeed())
+			dev := uint64(stat.Dev)
+			fileID := fmt.Sprintf("%02x:%02x:%d", (dev>>8)&0xfff|(dev>>32)&^uint64(0xfff), dev&0xff|uint64(uint32(dev>>12))&^uint64(0xff), stat.Ino)
+
+			locks := fmt.Sprintf("1: FLOCK  ADVISORY  WRITE 42 00:15:999 0 EOF\n"+
+				"2: FLOCK  ADVISORY  WRITE 1234 %s 0 EOF\n"+
+				"2: -> FLOCK  ADVISORY  WRITE 5678 %s 0 EOF\n", fileID, fileID)
+			Expect(ioutil.WriteFile(filepath.Join(procDir, "locks"), []byte(locks), 0600)).To(Succeed())
+			Expect(os.Mkdir(filepath.Join(procDir, "1234"), 0700)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(procDir, "1234", "cmdline"), []byte("/var/vcap/packages/vxlan-policy-agent/bin/vxlan-policy-agent\x00-config-file\x00agent.json\x00"), 0600)).To(Succeed())
+
+			release = make(chan struct{})
+			lock.LockStub = func() error {
+				<-release
+				return nil
+			}
+			logger = gbytes.NewBuffer()
+			metricsSender = &fakes.MetricsSender{}
+
+			lockedIPT.LockFile = lockFile
+			lockedIPT.ProcDir = procDir
+			lockedIPT.Logger = logger
+			lockedIPT.MetricsSender = metricsSender
+		})
+
+		AfterEach(func() {
+			close(release)
+			os.RemoveAll(procDir)
+		})
+
+		Context("when the lock is not acquired within the timeout", func() {
+			BeforeEach(func() {
+				lockedIPT.LockTimeout = 50 * time.Millisecond
+			})
+
+			It("returns an error naming the holder from /proc/locks", func() {
+				err := lockedIPT.NewChain("some-table", "some-chain")
+				Expect(err).To(MatchError("lock: timed out after 50ms waiting for the iptables lock held by pid 1234 running /var/vcap/packages/vxlan-policy-agent/bin/vxlan-policy-agent -config-file agent.json"))
+				Expect(ipt.NewChainCallCount()).To(Equal(0))
+				Expect(metricsSender.IncrementCounterCallCount()).To(Equal(1))
+				Expect(metricsSender.IncrementCounterArgsForCall(0)).To(Equal("IPTablesLockTimeout"))
+			})
+
+			It("gives the lock back once it is acquired", func() {
+				Expect(lockedIPT.NewChain("some-table", "some-chain")).NotTo(Succeed())
+				Expect(lock.UnlockCallCount()).To(Equal(0))
+
+				release <- struct{}{}
+				Eventually(lock.UnlockCallCount).Should(Equal(1))
+			})
+
+			Context("when the command line of the holder cannot be read", func() {
+				BeforeEach(func() {
+					Expect(os.RemoveAll(filepath.Join(procDir, "1234"))).To(Succeed())
+				})
+				It("names the holder by pid", func() {
+					err := lockedIPT.NewChain("some-table", "some-chain")
+					Expect(err).To(MatchError(HaveSuffix("held by pid 1234")))
+				})
+			})
+
+			Context("when /proc/locks has no lock on the lock file", func() {
+				BeforeEach(func() {
+					Expect(ioutil.WriteFile(filepath.Join(procDir, "locks"), []byte("1: FLOCK  ADVISORY  WRITE 42 00:15:999 0 EOF\n"), 0600)).To(Succeed())
+				})
+				It("says the holder is unknown", func() {
+					err := lockedIPT.NewChain("some-table", "some-chain")
+					Expect(err).To(MatchError(ContainSubstring("held by an unknown holder")))
+				})
+			})
+
+			Context("when the lock file does not exist", func() {
+				BeforeEach(func() {
+					Expect(os.Remove(lockFile)).To(Succeed())
+				})
+				It("says the holder is unknown", func() {
+					err := lockedIPT.NewChain("some-table", "some-chain")
+					Expect(err).To(MatchError(ContainSubstring("held by an unknown holder")))
+				})
+			})
+		})
+
+		Context("when the lock is contended for longer than the threshold", func() {
+			BeforeEach(func() {
+				lockedIPT.ContentionThreshold = 10 * time.Millisecond
+			})
+
+			It("logs the holder and carries on waiting", func() {
+				done := make(chan error)
+				go func() {
+					done <- lockedIPT.DeleteChain("some-table", "some-chain")
+				}()
+
+				Eventually(logger).Should(gbytes.Say("DeleteChain has waited more than 10ms for the iptables lock held by pid 1234 running /var/vcap/packages/vxlan-policy-agent"))
+				Expect(metricsSender.IncrementCounterArgsForCall(0)).To(Equal("IPTablesLockContention"))
+
+				release <- struct{}{}
+				Eventually(done).Should(Receive(BeNil()))
+				Expect(ipt.DeleteChainCallCount()).To(Equal(1))
+			})
+		})
+	})
+
+	Context("backed by an in-memory iptables", func() {
+		itBehavesLikeAnIPTablesAdapter(func() rules.IPTablesAdapter {
+			memory := testsupport.NewInMemoryIPTables()
+			return &rules.LockedIPTables{
+				IPTables: memory,
+				Locker:   &fakes.Locker{},
+				Restorer: memory,
+			}
+		})
+	})
 })
@@ -0,0 +1,151 @@
+package rules
+
+import (
+	"strconv"
+	"strings"
+)
+
+const maxLogPrefixLength = 28
+
+// DefaultTagLength is the default tag_length of the policy server, in bytes.
+// Its tags are written as two hex digits per byte.
+const DefaultTagLength = 2
+
+// LogEvent is a kernel log line written by one of the LOG rules in this
+// package. The app GUID and container handle come from the log prefix, which
+// trimAndPad cuts to 28 characters, so they may only be the start of the real
+// value; Truncated says when that may have happened.
+type LogEvent struct {
+	Action          string `json:"action"`
+	Kind            string `json:"kind"`
+	Protocol        string `json:"protocol,omitempty"`
+	SourceIP        string `json:"source_ip,omitempty"`
+	SourcePort      int    `json:"source_port,omitempty"`
+	DestinationIP   string `json:"destination_ip,omitempty"`
+	DestinationPort int    `json:"destination_port,omitempty"`
+	Tag             string `json:"tag,omitempty"`
+	SourceID        string `json:"source_id,omitempty"`
+	AppGUID         string `json:"app_guid,omitempty"`
+	ContainerHandle string `json:"container_handle,omitempty"`
+	Truncated       bool   `json:"truncated,omitempty"`
+}
+
+// DecodeKernelLog decodes a kernel log line with the DefaultTagLength.
+func DecodeKernelLog(line string) (LogEvent, bool) {
+	return LogDecoder{TagLength: DefaultTagLength}.Decode(line)
+}
+
+// LogDecoder needs the tag length of the policy server to tell accepted c2c
+// packets, logged as OK_<tag>_<app guid>, from accepted egress packets,
+// logged as OK_<container handle>, since handles may contain underscores.
+type LogDecoder struct {
+	TagLength int
+}
+
+// Decode decodes a kernel log line, whether it came from dmesg, kern.log or
+// the journal. It returns false for lines that were not written by a rule
+// from this package.
+func (d LogDecoder) Decode(line string) (LogEvent, bool) {
+	fieldsStart := strings.Index(line, "IN=")
+	if fieldsStart == -1 {
+		return LogEvent{}, false
+	}
+
+	before := strings.Fields(line[:fieldsStart])
+	if len(before) == 0 {
+		return LogEvent{}, false
+	}
+	prefix := before[len(before)-1]
+
+	event, ok := d.decodeLogPrefix(prefix)
+	if !ok {
+		return LogEvent{}, false
+	}
+
+	for _, field := range strings.Fields(line[fieldsStart:]) {
+		keyValue := strings.SplitN(field, "=", 2)
+		if len(keyValue) != 2 {
+			continue
+		}
+		switch keyValue[0] {
+		case "SRC":
+			event.SourceIP = keyValue[1]
+		case "DST":
+			event.DestinationIP = keyValue[1]
+		case "PROTO":
+			event.Protocol = strings.ToLower(keyValue[1])
+		case "SPT":
+			event.SourcePort, _ = strconv.Atoi(keyValue[1])
+		case "DPT":
+			event.DestinationPort, _ = strconv.Atoi(keyValue[1])
+		}
+	}
+	return event, true
+}
+
+func (d LogDecoder) decodeLogPrefix(prefix string) (LogEvent, bool) {
+	truncated := len(prefix) >= maxLogPrefixLength
+
+	switch {
+	case prefix == "REJECT_LOCAL:":
+		return LogEvent{Action: "rejected", Kind: "local"}, true
+	case strings.HasPrefix(prefix, "DENY_C2C_"):
+		return LogEvent{
+			Action:          "denied",
+			Kind:            "c2c",
+			ContainerHandle: strings.TrimPrefix(prefix, "DENY_C2C_"),
+			Truncated:       truncated,
+		}, true
+	case strings.HasPrefix(prefix, "DENY_"):
+		return LogEvent{
+			Action:          "denied",
+			Kind:            "egress",
+			ContainerHandle: strings.TrimPrefix(prefix, "DENY_"),
+			Truncated:       truncated,
+		}, true
+	case strings.HasPrefix(prefix, "OK_"):
+		rest := strings.TrimPrefix(prefix, "OK_")
+		if rest == "" {
+			return LogEvent{}, false
+		}
+		tagAndGUID := strings.SplitN(rest, "_", 2)
+		if len(tagAndGUID) == 2 && d.isTag(tagAndGUID[0]) {
+			return LogEvent{
+				Action:    "accepted",
+				Kind:      "c2c",
+				Tag:       tagAndGUID[0],
+				AppGUID:   tagAndGUID[1],
+				Truncated: truncated,
+			}, true
+		}
+		return LogEvent{
+			Action:          "accepted",
+			Kind:            "egress",
+			ContainerHandle: rest,
+			Truncated:       truncated,
+		}, true
+	}
+	return LogEvent{}, false
+}
+
+func (d LogDecoder) isTag(s string) bool {
+	if len(s) != d.TagLength*2 {
+		return false
+	}
+	_, err := strconv.ParseUint(s, 16, 64)
+	return err == nil
+}
+
+// ResolveTag sets SourceID to the policy group that the event's tag was
+// created for, given the IDs of the policy server's tags keyed by tag.
+func (e *LogEvent) ResolveTag(idsByTag map[string]string) {
+	if e.Tag == "" {
+		return
+	}
+	for tag, id := range idsByTag {
+		if strings.EqualFold(tag, e.Tag) {
+			e.SourceID = id
+			return
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package rules_test
+
+import (
+	"lib/rules"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DecodeKernelLog", func() {
+	DescribeTable("decoding the log prefixes of the rule builders",
+		func(line string, expected rules.LogEvent) {
+			event, ok := rules.DecodeKernelLog(line)
+			Expect(ok).To(BeTrue())
+			Expect(event).To(Equal(expected))
+		},
+		Entry("an accepted c2c packet, from kern.log",
+			"Oct 18 12:00:00 cell-1 kernel: [1234.5678] OK_0004_a1b2c3d4-e5f6-4a5b-8 IN=silk-vtep OUT=s-010255013003 MAC=ee:ee:0a:ff:0d:03 SRC=10.255.13.2 DST=10.255.13.3 LEN=60 TOS=0x00 PREC=0x00 TTL=63 ID=2937 DF PROTO=TCP SPT=45620 DPT=8080 WINDOW=27400 RES=0x00 SYN URGP=0 MARK=0x4",
+			rules.LogEvent{
+				Action: "accepted", Kind: "c2c", Protocol: "tcp",
+				SourceIP: "10.255.13.2", SourcePort: 45620,
+				DestinationIP: "10.255.13.3", DestinationPort: 8080,
+				Tag: "0004", AppGUID: "a1b2c3d4-e5f6-4a5b-8", Truncated: true,
+			}),
+		Entry("a denied c2c packet, from dmesg",
+			"[1234.5678] DENY_C2C_5e0f5c4a-1d62-4c IN=silk-vtep OUT=s-010255013003 SRC=10.255.13.2 DST=10.255.13.3 PROTO=UDP SPT=5353 DPT=53",
+			rules.LogEvent{
+				Action: "denied", Kind: "c2c", Protocol: "udp",
+				SourceIP: "10.255.13.2", SourcePort: 5353,
+				DestinationIP: "10.255.13.3", DestinationPort: 53,
+				ContainerHandle: "5e0f5c4a-1d62-4c", Truncated: false,
+			}),
+		Entry("an accepted egress packet",
+			"kernel: OK_some-handle IN=s-010255013003 OUT=eth0 SRC=10.255.13.3 DST=8.8.8.8 PROTO=ICMP TYPE=8 CODE=0 ID=1 SEQ=1",
+			rules.LogEvent{
+				Action: "accepted", Kind: "egress", Protocol: "icmp",
+				SourceIP: "10.255.13.3", DestinationIP: "8.8.8.8",
+				ContainerHandle: "some-handle",
+			}),
+		Entry("an accepted egress packet of a handle with underscores",
+			"kernel: OK_some_handle_1 IN=s-010255013003 OUT=eth0 SRC=10.255.13.3 DST=8.8.8.8 PROTO=UDP SPT=1 DPT=53",
+			rules.LogEvent{
+				Action: "accepted", Kind: "egress", Protocol: "udp",
+				SourceIP: "10.255.13.3", SourcePort: 1,
+				DestinationIP: "8.8.8.8", DestinationPort: 53,
+				ContainerHandle: "some_handle_1",
+			}),
+		Entry("an accepted egress packet of a handle that starts like a tag",
+			"kernel: OK_abcd1_x IN=s-010255013003 OUT=eth0 SRC=10.255.13.3 DST=8.8.8.8 PROTO=ICMP TYPE=8 CODE=0",
+			rules.LogEvent{
+				Action: "accepted", Kind: "egress", Protocol: "icmp",
+				SourceIP: "10.255.13.3", DestinationIP: "8.8.8.8",
+				ContainerHandle: "abcd1_x",
+			}),
+		Entry("a denied egress packet",
+			"kernel: DENY_some-handle IN=s-010255013003 OUT=eth0 SRC=10.255.13.3 DST=1.2.3.4 PROTO=TCP SPT=1 DPT=443",
+			rules.LogEvent{
+				Action: "denied", Kind: "egress", Protocol: "tcp",
+				SourceIP: "10.255.13.3", SourcePort: 1,
+				DestinationIP: "1.2.3.4", DestinationPort: 443,
+				ContainerHandle: "some-handle",
+			}),
+		Entry("a rejected local packet",
+			"kernel: REJECT_LOCAL: IN=s-010255013003 OUT=s-010255013002 SRC=10.255.13.3 DST=10.255.13.2 PROTO=TCP SPT=1 DPT=2",
+			rules.LogEvent{
+				Action: "rejected", Kind: "local", Protocol: "tcp",
+				SourceIP: "10.255.13.3", SourcePort: 1,
+				DestinationIP: "10.255.13.2", DestinationPort: 2,
+			}),
+	)
+
+	It("decodes the prefixes the rule builders write", func() {
+		rule := rules.NewMarkAllowLogRule("10.255.0.2", "tcp", 8080, 8080, "0004", "a1b2c3d4-e5f6-4a5b-8c9d-0e1f2a3b4c5d", 100)
+		prefix := rule[len(rule)-1]
+		prefix = prefix[1 : len(prefix)-1]
+
+		event, ok := rules.DecodeKernelLog("kernel: " + prefix + "IN=silk-vtep SRC=10.255.0.1 DST=10.255.0.2")
+		Expect(ok).To(BeTrue())
+		Expect(event.Tag).To(Equal("0004"))
+		Expect(event.AppGUID).To(Equal("a1b2c3d4-e5f6-4a5b-8"))
+		Expect(event.Truncated).To(BeTrue())
+	})
+
+	DescribeTable("ignoring other kernel log lines",
+		func(line string) {
+			_, ok := rules.DecodeKernelLog(line)
+			Expect(ok).To(BeFalse())
+		},
+		Entry("not a packet log", "kernel: [1.0] eth0: link up"),
+		Entry("some other log prefix", "kernel: SOMEONE_ELSE IN=eth0 SRC=1.2.3.4 DST=5.6.7.8"),
+		Entry("no prefix at all", "IN=eth0 SRC=1.2.3.4"),
+		Entry("an empty OK prefix", "kernel: OK_ IN=eth0 SRC=1.2.3.4"),
+	)
+
+	Describe("LogDecoder", func() {
+		It("only takes tags of its tag length", func() {
+			decoder := rules.LogDecoder{TagLength: 1}
+			event, ok := decoder.Decode("kernel: OK_0A_some-app-guid IN=silk-vtep SRC=10.255.0.1 DST=10.255.0.2")
+			Expect(ok).To(BeTrue())
+			Expect(event.Kind).To(Equal("c2c"))
+			Expect(event.Tag).To(Equal("0A"))
+
+			event, ok = decoder.Decode("kernel: OK_0004_some-app-guid IN=silk-vtep SRC=10.255.0.1 DST=10.255.0.2")
+			Expect(ok).To(BeTrue())
+			Expect(event.Kind).To(Equal("egress"))
+			Expect(event.ContainerHandle).To(Equal("0004_some-app-guid"))
+		})
+	})
+
+	Describe("ResolveTag", func() {
+		It("sets the source ID of the tag, ignoring case", func() {
+			event := rules.LogEvent{Tag: "00aa"}
+			event.ResolveTag(map[string]string{"0004": "some-app-guid", "00AA": "other-app-guid"})
+			Expect(event.SourceID).To(Equal("other-app-guid"))
+		})
+
+		It("leaves events without a known tag alone", func() {
+			event := rules.LogEvent{ContainerHandle: "some-handle"}
+			event.ResolveTag(map[string]string{"": "some-app-guid"})
+			Expect(event.SourceID).To(BeEmpty())
+
+			event = rules.LogEvent{Tag: "0009"}
+			event.ResolveTag(map[string]string{"0004": "some-app-guid"})
+			Expect(event.SourceID).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,235 @@
+package rules
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/google/nftables"
+)
+
+// NFTSets manages the sets referenced by the set rules when they are written
+// by NFTables, as IPSets does for iptables. A set of family inet goes into
+// the ip table named Table, a set of family inet6 into the ip6 table. A set
+// is flushed and filled in the same netlink batch, so rules matching the set
+// never see it half-filled.
+//
+// nftables sets hold intervals, which may not overlap, so overlapping and
+// adjacent entries are merged. Entries with ports are merged only with
+// entries of the same protocol and ports.
+type NFTSets struct {
+	Conn   nftConn
+	Locker locker
+	Table  string
+}
+
+func (s *NFTSets) table(family string) (*nftables.Table, error) {
+	switch family {
+	case "", "inet":
+		return &nftables.Table{Name: s.Table, Family: nftables.TableFamilyIPv4}, nil
+	case "inet6":
+		return &nftables.Table{Name: s.Table, Family: nftables.TableFamilyIPv6}, nil
+	}
+	return nil, fmt.Errorf("unsupported set family %s", family)
+}
+
+func (s *NFTSets) locked(f func() error) error {
+	if err := s.Locker.Lock(); err != nil {
+		return fmt.Errorf("lock: %s", err)
+	}
+
+	if err := f(); err != nil {
+		return handleNFTError(err, s.Locker.Unlock())
+	}
+
+	return s.Locker.Unlock()
+}
+
+func (s *NFTSets) Replace(set IPSet) error {
+	table, err := s.table(set.Family)
+	if err != nil {
+		return err
+	}
+
+	addressType := nftables.TypeIPAddr
+	if table.Family == nftables.TableFamilyIPv6 {
+		addressType = nftables.TypeIP6Addr
+	}
+	nftSet := &nftables.Set{Table: table, Name: set.Name, Interval: true}
+	var elements []nftables.SetElement
+	switch set.Type {
+	case "hash:net":
+		nftSet.KeyType = addressType
+		elements, err = nftNetElements(set.Entries, table.Family)
+	case "hash:net,port", "hash:ip,port":
+		nftSet.Concatenation = true
+		nftSet.KeyType, err = nftables.ConcatSetType(addressType, nftables.TypeInetProto, nftables.TypeInetService)
+		if err == nil {
+			elements, err = nftNetPortElements(set.Entries, table.Family)
+		}
+	default:
+		err = fmt.Errorf("unsupported set type %s", set.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("set %s: %s", set.Name, err)
+	}
+
+	return s.locked(func() error {
+		s.Conn.AddTable(table)
+		if err := s.Conn.AddSet(nftSet, nil); err != nil {
+			return err
+		}
+		s.Conn.FlushSet(nftSet)
+		if len(elements) > 0 {
+			if err := s.Conn.SetAddElements(nftSet, elements); err != nil {
+				return err
+			}
+		}
+		return s.Conn.Flush()
+	})
+}
+
+// Destroy deletes the set. Only the name and family of the set are used.
+func (s *NFTSets) Destroy(set IPSet) error {
+	table, err := s.table(set.Family)
+	if err != nil {
+		return err
+	}
+
+	return s.locked(func() error {
+		s.Conn.DelSet(&nftables.Set{Table: table, Name: set.Name})
+		return s.Conn.Flush()
+	})
+}
+
+type addressInterval struct {
+	start, end *big.Int
+}
+
+func parseAddressInterval(entry string, family nftables.TableFamily) (addressInterval, error) {
+	ipNet, err := parseNFTAddress(entry)
+	if err != nil {
+		return addressInterval{}, err
+	}
+	if (len(ipNet.IP) == net.IPv4len) != (family == nftables.TableFamilyIPv4) {
+		return addressInterval{}, fmt.Errorf("address %s does not belong in an %s table", entry, nftFamilyName(family))
+	}
+
+	end := make(net.IP, len(ipNet.IP))
+	for i := range ipNet.IP {
+		end[i] = ipNet.IP[i] | ^ipNet.Mask[i]
+	}
+	return addressInterval{
+		start: new(big.Int).SetBytes(ipNet.IP),
+		end:   new(big.Int).SetBytes(end),
+	}, nil
+}
+
+// mergeIntervals sorts the intervals and joins those that overlap or touch.
+func mergeIntervals(intervals []addressInterval) []addressInterval {
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start.Cmp(intervals[j].start) < 0
+	})
+
+	merged := []addressInterval{}
+	for _, interval := range intervals {
+		last := len(merged) - 1
+		if last >= 0 && interval.start.Cmp(new(big.Int).Add(merged[last].end, big.NewInt(1))) <= 0 {
+			if interval.end.Cmp(merged[last].end) > 0 {
+				merged[last].end = interval.end
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
+
+func addressBytes(address *big.Int, family nftables.TableFamily) []byte {
+	length := net.IPv6len
+	if family == nftables.TableFamilyIPv4 {
+		length = net.IPv4len
+	}
+	return address.FillBytes(make([]byte, length))
+}
+
+// nftNetElements returns the elements of an interval set: the start of each
+// interval, followed by the address after it marked as the end of the
+// interval. An interval running to the last address has no end element.
+func nftNetElements(entries []string, family nftables.TableFamily) ([]nftables.SetElement, error) {
+	intervals := []addressInterval{}
+	for _, entry := range entries {
+		interval, err := parseAddressInterval(entry, family)
+		if err != nil {
+			return nil, err
+		}
+		intervals = append(intervals, interval)
+	}
+
+	elements := []nftables.SetElement{}
+	for _, interval := range mergeIntervals(intervals) {
+		elements = append(elements, nftables.SetElement{Key: addressBytes(interval.start, family)})
+		after := new(big.Int).Add(interval.end, big.NewInt(1))
+		if after.BitLen() <= len(addressBytes(interval.end, family))*8 {
+			elements = append(elements, nftables.SetElement{Key: addressBytes(after, family), IntervalEnd: true})
+		}
+	}
+	return elements, nil
+}
+
+// nftNetPortElements returns the elements of a set keyed by the address,
+// protocol and port, for entries like 10.0.0.0/24,tcp:80-90. Each element
+// holds the first and last key of its range, with every field of the keys
+// padded to 4 bytes.
+func nftNetPortElements(entries []string, family nftables.TableFamily) ([]nftables.SetElement, error) {
+	type ports struct {
+		protocol   byte
+		start, end uint16
+	}
+	groups := []ports{}
+	intervals := map[ports][]addressInterval{}
+	for _, entry := range entries {
+		fields := strings.SplitN(entry, ",", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("entry %s has no port", entry)
+		}
+		protocolAndPorts := strings.SplitN(fields[1], ":", 2)
+		protocol, ok := nftProtocols[protocolAndPorts[0]]
+		if !ok || len(protocolAndPorts) != 2 || (protocol != nftProtocols["tcp"] && protocol != nftProtocols["udp"]) {
+			return nil, fmt.Errorf("entry %s does not have a tcp or udp port", entry)
+		}
+		start, end, err := parsePortRange(strings.Replace(protocolAndPorts[1], "-", ":", 1))
+		if err != nil {
+			return nil, err
+		}
+		interval, err := parseAddressInterval(fields[0], family)
+		if err != nil {
+			return nil, err
+		}
+
+		group := ports{protocol: protocol, start: start, end: end}
+		if _, ok := intervals[group]; !ok {
+			groups = append(groups, group)
+		}
+		intervals[group] = append(intervals[group], interval)
+	}
+
+	elements := []nftables.SetElement{}
+	for _, group := range groups {
+		for _, interval := range mergeIntervals(intervals[group]) {
+			elements = append(elements, nftables.SetElement{
+				Key:    nftConcatKey(addressBytes(interval.start, family), group.protocol, group.start),
+				KeyEnd: nftConcatKey(addressBytes(interval.end, family), group.protocol, group.end),
+			})
+		}
+	}
+	return elements, nil
+}
+
+func nftConcatKey(address []byte, protocol byte, port uint16) []byte {
+	key := append([]byte{}, address...)
+	key = append(key, protocol, 0, 0, 0)
+	return append(key, byte(port>>8), byte(port), 0, 0)
+}
@@ -0,0 +1,192 @@
+package rules_test
+
+import (
+	"errors"
+	"lib/fakes"
+	"lib/rules"
+	"lib/testsupport"
+
+	"github.com/google/nftables"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NFTSets", func() {
+	var (
+		sets *rules.NFTSets
+		conn *fakes.NFTConn
+		lock *fakes.Locker
+	)
+
+	BeforeEach(func() {
+		conn = &fakes.NFTConn{}
+		lock = &fakes.Locker{}
+		sets = &rules.NFTSets{
+			Conn:   conn,
+			Locker: lock,
+			Table:  "filter",
+		}
+	})
+
+	Describe("Replace", func() {
+		It("flushes and fills the set in one batch", func() {
+			err := sets.Replace(rules.IPSet{
+				Name:    "some-set",
+				Type:    "hash:net",
+				Entries: []string{"10.0.0.0/24", "10.0.1.1/32"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(lock.LockCallCount()).To(Equal(1))
+			Expect(lock.UnlockCallCount()).To(Equal(1))
+			Expect(conn.AddTableArgsForCall(0)).To(Equal(&nftables.Table{Name: "filter", Family: nftables.TableFamilyIPv4}))
+
+			set, elements := conn.AddSetArgsForCall(0)
+			Expect(set.Name).To(Equal("some-set"))
+			Expect(set.Interval).To(BeTrue())
+			Expect(set.KeyType).To(Equal(nftables.TypeIPAddr))
+			Expect(elements).To(BeEmpty())
+			Expect(conn.FlushSetArgsForCall(0)).To(Equal(set))
+
+			_, elements = conn.SetAddElementsArgsForCall(0)
+			Expect(elements).To(Equal([]nftables.SetElement{
+				{Key: []byte{10, 0, 0, 0}},
+				{Key: []byte{10, 0, 1, 0}, IntervalEnd: true},
+				{Key: []byte{10, 0, 1, 1}},
+				{Key: []byte{10, 0, 1, 2}, IntervalEnd: true},
+			}))
+			Expect(conn.FlushCallCount()).To(Equal(1))
+		})
+
+		It("merges entries that overlap or touch", func() {
+			err := sets.Replace(rules.IPSet{
+				Name:    "some-set",
+				Type:    "hash:net",
+				Entries: []string{"10.0.1.0/24", "10.0.0.0/24", "10.0.0.128/25", "255.255.255.255/32"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, elements := conn.SetAddElementsArgsForCall(0)
+			Expect(elements).To(Equal([]nftables.SetElement{
+				{Key: []byte{10, 0, 0, 0}},
+				{Key: []byte{10, 0, 2, 0}, IntervalEnd: true},
+				{Key: []byte{255, 255, 255, 255}},
+			}))
+		})
+
+		It("keys sets with ports by the address, protocol and port", func() {
+			err := sets.Replace(rules.IPSet{
+				Name:    "some-set",
+				Type:    "hash:net,port",
+				Entries: []string{"10.0.0.0/24,tcp:80-90", "10.0.1.0/24,tcp:80-90", "10.0.0.1/32,udp:53"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			set, _ := conn.AddSetArgsForCall(0)
+			Expect(set.Concatenation).To(BeTrue())
+			Expect(set.KeyType.Bytes).To(Equal(uint32(12)))
+
+			_, elements := conn.SetAddElementsArgsForCall(0)
+			Expect(elements).To(Equal([]nftables.SetElement{
+				{
+					Key:    []byte{10, 0, 0, 0, 6, 0, 0, 0, 0, 80, 0, 0},
+					KeyEnd: []byte{10, 0, 1, 255, 6, 0, 0, 0, 0, 90, 0, 0},
+				},
+				{
+					Key:    []byte{10, 0, 0, 1, 17, 0, 0, 0, 0, 53, 0, 0},
+					KeyEnd: []byte{10, 0, 0, 1, 17, 0, 0, 0, 0, 53, 0, 0},
+				},
+			}))
+		})
+
+		It("puts ipv6 sets into the ip6 table", func() {
+			err := sets.Replace(rules.IPSet{Name: "some-set6", Type: "hash:net", Family: "inet6", Entries: []string{"2001:db8::/64"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(conn.AddTableArgsForCall(0).Family).To(Equal(nftables.TableFamilyIPv6))
+			set, _ := conn.AddSetArgsForCall(0)
+			Expect(set.KeyType).To(Equal(nftables.TypeIP6Addr))
+		})
+
+		It("does not add elements to an empty set", func() {
+			Expect(sets.Replace(rules.IPSet{Name: "some-set", Type: "hash:net"})).To(Succeed())
+			Expect(conn.FlushSetCallCount()).To(Equal(1))
+			Expect(conn.SetAddElementsCallCount()).To(Equal(0))
+		})
+
+		DescribeTable("rejecting sets it cannot create",
+			func(set rules.IPSet, expectedErr string) {
+				Expect(sets.Replace(set)).To(MatchError(expectedErr))
+				Expect(lock.LockCallCount()).To(Equal(0))
+			},
+			Entry("unknown type", rules.IPSet{Name: "some-set", Type: "hash:ip"}, "set some-set: unsupported set type hash:ip"),
+			Entry("unknown family", rules.IPSet{Name: "some-set", Type: "hash:net", Family: "banana"}, "unsupported set family banana"),
+			Entry("address of the other family", rules.IPSet{Name: "some-set", Type: "hash:net", Entries: []string{"2001:db8::/64"}},
+				"set some-set: address 2001:db8::/64 does not belong in an ip table"),
+			Entry("entry without a port", rules.IPSet{Name: "some-set", Type: "hash:net,port", Entries: []string{"10.0.0.0/24"}},
+				"set some-set: entry 10.0.0.0/24 has no port"),
+			Entry("entry with another protocol", rules.IPSet{Name: "some-set", Type: "hash:net,port", Entries: []string{"10.0.0.0/24,icmp:8"}},
+				"set some-set: entry 10.0.0.0/24,icmp:8 does not have a tcp or udp port"),
+		)
+
+		Context("when the lock fails", func() {
+			It("returns an error", func() {
+				lock.LockReturns(errors.New("banana"))
+				Expect(sets.Replace(rules.IPSet{Name: "some-set", Type: "hash:net"})).To(MatchError("lock: banana"))
+				Expect(conn.FlushCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when sending the batch fails", func() {
+			It("returns an error", func() {
+				conn.FlushReturns(errors.New("banana"))
+				lock.UnlockReturns(errors.New("potato"))
+				Expect(sets.Replace(rules.IPSet{Name: "some-set", Type: "hash:net"})).To(MatchError("nft call: banana and unlock: potato"))
+			})
+		})
+	})
+
+	Describe("Destroy", func() {
+		It("deletes the set from the table of its family", func() {
+			Expect(sets.Destroy(rules.IPSet{Name: "some-set6", Family: "inet6"})).To(Succeed())
+
+			set := conn.DelSetArgsForCall(0)
+			Expect(set.Name).To(Equal("some-set6"))
+			Expect(set.Table).To(Equal(&nftables.Table{Name: "filter", Family: nftables.TableFamilyIPv6}))
+			Expect(conn.FlushCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("backed by an in-memory nftables", func() {
+		var memory *testsupport.InMemoryNFT
+
+		BeforeEach(func() {
+			memory = testsupport.NewInMemoryNFT()
+			sets.Conn = memory
+		})
+
+		It("replaces the content of the set", func() {
+			Expect(sets.Replace(rules.IPSet{Name: "some-set", Type: "hash:net", Entries: []string{"10.0.0.0/24"}})).To(Succeed())
+			Expect(sets.Replace(rules.IPSet{Name: "some-set", Type: "hash:net", Entries: []string{"10.0.9.9"}})).To(Succeed())
+
+			Expect(memory.SetElements(nftables.TableFamilyIPv4, "filter", "some-set")).To(Equal([]nftables.SetElement{
+				{Key: []byte{10, 0, 9, 9}},
+				{Key: []byte{10, 0, 9, 10}, IntervalEnd: true},
+			}))
+		})
+
+		It("cannot destroy a set a rule still matches", func() {
+			nft := &rules.NFTables{Conn: memory, Locker: &fakes.Locker{}}
+			Expect(sets.Replace(rules.IPSet{Name: "some-set", Type: "hash:net"})).To(Succeed())
+			Expect(nft.BulkAppend("filter", "some-chain", rules.NewNetOutSetRule("some-set"))).To(Succeed())
+
+			Expect(sets.Destroy(rules.IPSet{Name: "some-set"})).To(MatchError("nft call: set some-set is in use and unlock: <nil>"))
+
+			Expect(nft.ClearChain("filter", "some-chain")).To(Succeed())
+			Expect(sets.Destroy(rules.IPSet{Name: "some-set"})).To(Succeed())
+			_, err := memory.SetElements(nftables.TableFamilyIPv4, "filter", "some-set")
+			Expect(err).To(MatchError("set some-set does not exist"))
+		})
+	})
+})
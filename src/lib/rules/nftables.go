@@ -0,0 +1,1361 @@
+package rules
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/binaryutil"
+	"github.com/google/nftables/expr"
+	"github.com/google/nftables/userdata"
+	"golang.org/x/sys/unix"
+)
+
+//go:generate counterfeiter -o ../fakes/nft_conn.go --fake-name NFTConn . nftConn
+type nftConn interface {
+	AddTable(t *nftables.Table) *nftables.Table
+	AddChain(c *nftables.Chain) *nftables.Chain
+	FlushChain(c *nftables.Chain)
+	DelChain(c *nftables.Chain)
+	ListChainsOfTableFamily(family nftables.TableFamily) ([]*nftables.Chain, error)
+	GetRules(t *nftables.Table, c *nftables.Chain) ([]*nftables.Rule, error)
+	AddRule(r *nftables.Rule) *nftables.Rule
+	InsertRule(r *nftables.Rule) *nftables.Rule
+	DelRule(r *nftables.Rule) error
+	AddSet(s *nftables.Set, vals []nftables.SetElement) error
+	FlushSet(s *nftables.Set)
+	SetAddElements(s *nftables.Set, vals []nftables.SetElement) error
+	DelSet(s *nftables.Set)
+	Flush() error
+}
+
+var nftFamilies = map[string]nftables.TableFamily{
+	"ip":   nftables.TableFamilyIPv4,
+	"ip6":  nftables.TableFamilyIPv6,
+	"inet": nftables.TableFamilyINet,
+}
+
+var nftHooks = map[string]*nftables.ChainHook{
+	"PREROUTING":  nftables.ChainHookPrerouting,
+	"INPUT":       nftables.ChainHookInput,
+	"FORWARD":     nftables.ChainHookForward,
+	"OUTPUT":      nftables.ChainHookOutput,
+	"POSTROUTING": nftables.ChainHookPostrouting,
+}
+
+// NFTables is an IPTablesAdapter that programs the equivalent nftables
+// tables and chains over netlink; its Conn is a *nftables.Conn. The built-in
+// iptables chains become base chains hooked into netfilter at the same
+// points. Each call is sent to the kernel as a single netlink batch, so it is
+// applied atomically. Family is ip, ip6 or inet, and defaults to ip.
+//
+// Rules are listed in the form `iptables -S` prints them, decoded from the
+// expressions in the kernel. Exists and Delete compare rules in that form, so
+// a rule is found however it was spelled when it was written.
+type NFTables struct {
+	Conn   nftConn
+	Locker locker
+	Family string
+}
+
+func handleNFTError(err1, err2 error) error {
+	return fmt.Errorf("nft call: %+v and unlock: %+v", err1, err2)
+}
+
+func nftFamily(family string) (nftables.TableFamily, error) {
+	if family == "" {
+		family = "ip"
+	}
+	tableFamily, ok := nftFamilies[family]
+	if !ok {
+		return 0, fmt.Errorf("unsupported nftables family %s", family)
+	}
+	return tableFamily, nil
+}
+
+func nftFamilyName(family nftables.TableFamily) string {
+	for name, f := range nftFamilies {
+		if f == family {
+			return name
+		}
+	}
+	return strconv.Itoa(int(family))
+}
+
+func (n *NFTables) table(name string) (*nftables.Table, error) {
+	family, err := nftFamily(n.Family)
+	if err != nil {
+		return nil, err
+	}
+	return &nftables.Table{Name: name, Family: family}, nil
+}
+
+func nftChain(table *nftables.Table, name string) *nftables.Chain {
+	chain := &nftables.Chain{Name: name, Table: table}
+	hook, ok := nftHooks[name]
+	if !ok {
+		return chain
+	}
+
+	chain.Hooknum, chain.Type, chain.Priority = hook, nftables.ChainTypeFilter, nftables.ChainPriorityFilter
+	switch table.Name {
+	case "nat":
+		chain.Type, chain.Priority = nftables.ChainTypeNAT, nftables.ChainPriorityNATSource
+		if name == "PREROUTING" || name == "OUTPUT" {
+			chain.Priority = nftables.ChainPriorityNATDest
+		}
+	case "mangle":
+		chain.Priority = nftables.ChainPriorityMangle
+	}
+	return chain
+}
+
+// ensureChain queues the creation of the table and chain, which leaves them
+// alone if they already exist.
+func (n *NFTables) ensureChain(table *nftables.Table, chain string) *nftables.Chain {
+	n.Conn.AddTable(table)
+	return n.Conn.AddChain(nftChain(table, chain))
+}
+
+func (n *NFTables) locked(f func() error) error {
+	if err := n.Locker.Lock(); err != nil {
+		return fmt.Errorf("lock: %s", err)
+	}
+
+	if err := f(); err != nil {
+		return handleNFTError(err, n.Locker.Unlock())
+	}
+
+	return n.Locker.Unlock()
+}
+
+func (n *NFTables) chainExists(table *nftables.Table, chain string) (bool, error) {
+	chains, err := n.Conn.ListChainsOfTableFamily(table.Family)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range chains {
+		if c.Table != nil && c.Table.Name == table.Name && c.Name == chain {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// findRule returns the rule of the chain that lists the same as rulespec.
+func (n *NFTables) findRule(table *nftables.Table, chain string, rulespec IPTablesRule) (*nftables.Rule, error) {
+	wanted, err := newNFTRule(table, nftChain(table, chain), rulespec)
+	if err != nil {
+		return nil, err
+	}
+	wantedSpec, err := iptablesRuleOf(table.Family, wanted)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := n.Conn.GetRules(table, nftChain(table, chain))
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range existing {
+		spec, err := iptablesRuleOf(table.Family, r)
+		if err != nil {
+			return nil, fmt.Errorf("rule with handle %d in chain %s: %s", r.Handle, chain, err)
+		}
+		if strings.Join(spec, " ") == strings.Join(wantedSpec, " ") {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (n *NFTables) Exists(table, chain string, rulespec IPTablesRule) (bool, error) {
+	t, err := n.table(table)
+	if err != nil {
+		return false, err
+	}
+
+	var rule *nftables.Rule
+	err = n.locked(func() error {
+		var err error
+		rule, err = n.findRule(t, chain, rulespec)
+		return err
+	})
+	return rule != nil, err
+}
+
+func (n *NFTables) Delete(table, chain string, rulespec IPTablesRule) error {
+	t, err := n.table(table)
+	if err != nil {
+		return err
+	}
+
+	return n.locked(func() error {
+		rule, err := n.findRule(t, chain, rulespec)
+		if err != nil {
+			return err
+		}
+		if rule == nil {
+			return fmt.Errorf("rule does not exist in chain %s", chain)
+		}
+		rule.Table, rule.Chain = t, nftChain(t, chain)
+		if err := n.Conn.DelRule(rule); err != nil {
+			return err
+		}
+		return n.Conn.Flush()
+	})
+}
+
+// List returns the chain in the format of `iptables -S`.
+func (n *NFTables) List(table, chain string) ([]string, error) {
+	t, err := n.table(table)
+	if err != nil {
+		return nil, err
+	}
+
+	listed := []string{}
+	err = n.locked(func() error {
+		exists, err := n.chainExists(t, chain)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("chain %s does not exist in table %s", chain, table)
+		}
+
+		existing, err := n.Conn.GetRules(t, nftChain(t, chain))
+		if err != nil {
+			return err
+		}
+		if _, ok := nftHooks[chain]; ok {
+			listed = append(listed, fmt.Sprintf("-P %s ACCEPT", chain))
+		} else {
+			listed = append(listed, fmt.Sprintf("-N %s", chain))
+		}
+		for _, r := range existing {
+			spec, err := iptablesRuleOf(t.Family, r)
+			if err != nil {
+				return fmt.Errorf("rule with handle %d in chain %s: %s", r.Handle, chain, err)
+			}
+			listed = append(listed, strings.Join(append([]string{"-A", chain}, spec...), " "))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return listed, nil
+}
+
+func (n *NFTables) NewChain(table, chain string) error {
+	t, err := n.table(table)
+	if err != nil {
+		return err
+	}
+
+	return n.locked(func() error {
+		exists, err := n.chainExists(t, chain)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("chain %s already exists in table %s", chain, table)
+		}
+		n.ensureChain(t, chain)
+		return n.Conn.Flush()
+	})
+}
+
+func (n *NFTables) ClearChain(table, chain string) error {
+	t, err := n.table(table)
+	if err != nil {
+		return err
+	}
+
+	return n.locked(func() error {
+		n.Conn.FlushChain(n.ensureChain(t, chain))
+		return n.Conn.Flush()
+	})
+}
+
+func (n *NFTables) DeleteChain(table, chain string) error {
+	t, err := n.table(table)
+	if err != nil {
+		return err
+	}
+
+	return n.locked(func() error {
+		n.Conn.DelChain(nftChain(t, chain))
+		return n.Conn.Flush()
+	})
+}
+
+// BulkInsert inserts the rules at pos the way iptables-restore would, so the
+// last rule given ends up first.
+func (n *NFTables) BulkInsert(table, chain string, pos int, rulespec ...IPTablesRule) error {
+	t, err := n.table(table)
+	if err != nil {
+		return err
+	}
+
+	return n.locked(func() error {
+		nftRules, err := newNFTRules(t, nftChain(t, chain), rulespec)
+		if err != nil {
+			return err
+		}
+
+		if pos == 1 {
+			n.ensureChain(t, chain)
+			for _, r := range nftRules {
+				n.Conn.InsertRule(r)
+			}
+			return n.Conn.Flush()
+		}
+
+		existing, err := n.Conn.GetRules(t, nftChain(t, chain))
+		if err != nil {
+			return err
+		}
+		if pos < 1 || pos > len(existing)+1 {
+			return fmt.Errorf("rule position %d out of range for chain %s", pos, chain)
+		}
+
+		n.ensureChain(t, chain)
+		if pos == len(existing)+1 {
+			for _, r := range nftRules {
+				r.Position = existing[pos-2].Handle
+				n.Conn.AddRule(r)
+			}
+			return n.Conn.Flush()
+		}
+		for i := len(nftRules) - 1; i >= 0; i-- {
+			nftRules[i].Position = existing[pos-1].Handle
+			n.Conn.InsertRule(nftRules[i])
+		}
+		return n.Conn.Flush()
+	})
+}
+
+func (n *NFTables) BulkAppend(table, chain string, rulespec ...IPTablesRule) error {
+	t, err := n.table(table)
+	if err != nil {
+		return err
+	}
+	nftRules, err := newNFTRules(t, nftChain(t, chain), rulespec)
+	if err != nil {
+		return err
+	}
+
+	return n.locked(func() error {
+		n.ensureChain(t, chain)
+		for _, r := range nftRules {
+			n.Conn.AddRule(r)
+		}
+		return n.Conn.Flush()
+	})
+}
+
+// maxNFTCommentLength leaves room for the type, length and terminating null
+// of the comment in the 256 bytes of user data the kernel keeps with a rule.
+const maxNFTCommentLength = 252
+
+func newNFTRule(table *nftables.Table, chain *nftables.Chain, rulespec IPTablesRule) (*nftables.Rule, error) {
+	t := &nftTranslation{family: table.Family}
+	if err := t.translate(rulespec); err != nil {
+		return nil, err
+	}
+
+	rule := &nftables.Rule{Table: table, Chain: chain, Exprs: t.exprs}
+	if t.comment != "" {
+		if len(t.comment) > maxNFTCommentLength {
+			return nil, fmt.Errorf("comment %s is longer than %d characters", t.comment, maxNFTCommentLength)
+		}
+		rule.UserData = userdata.AppendString(nil, userdata.TypeComment, t.comment)
+	}
+	return rule, nil
+}
+
+func newNFTRules(table *nftables.Table, chain *nftables.Chain, rulespec []IPTablesRule) ([]*nftables.Rule, error) {
+	nftRules := []*nftables.Rule{}
+	for _, r := range rulespec {
+		rule, err := newNFTRule(table, chain, r)
+		if err != nil {
+			return nil, err
+		}
+		nftRules = append(nftRules, rule)
+	}
+	return nftRules, nil
+}
+
+// TranslateToNFT converts a rule built by this package into the nftables
+// expressions of a rule in a table of the family. It supports the matches
+// and targets used by the rule builders. Comments are not expressions; they
+// are kept in the user data of the rule.
+func TranslateToNFT(family string, rule IPTablesRule) ([]expr.Any, error) {
+	tableFamily, err := nftFamily(family)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &nftTranslation{family: tableFamily}
+	if err := t.translate(rule); err != nil {
+		return nil, err
+	}
+	return t.exprs, nil
+}
+
+// nftArgValues is the number of values each supported argument takes.
+var nftArgValues = map[string]int{
+	"-s": 1, "--source": 1, "-d": 1, "--destination": 1, "-i": 1, "-o": 1,
+	"-p": 1, "--protocol": 1, "-m": 1, "--dport": 1, "--destination-port": 1,
+	"--dst-range": 1, "--mark": 1, "--state": 1, "--ctstate": 1, "--limit": 1,
+	"--limit-burst": 1, "--icmp-type": 1, "--icmpv6-type": 1, "--comment": 1,
+	"--uid-owner": 1, "--gid-owner": 1, "--match-set": 2,
+	"-j": 1, "--jump": 1, "-g": 1, "--goto": 1, "--reject-with": 1,
+	"--log-prefix": 1, "--set-mark": 1, "--set-xmark": 1, "--to-destination": 1,
+}
+
+var nftProtocols = map[string]byte{
+	"tcp":       unix.IPPROTO_TCP,
+	"udp":       unix.IPPROTO_UDP,
+	"icmp":      unix.IPPROTO_ICMP,
+	"ipv6-icmp": unix.IPPROTO_ICMPV6,
+}
+
+// nftCTStates are the conntrack states in the order iptables prints them.
+var nftCTStates = []struct {
+	name string
+	bit  uint32
+}{
+	{"INVALID", expr.CtStateBitINVALID},
+	{"NEW", expr.CtStateBitNEW},
+	{"RELATED", expr.CtStateBitRELATED},
+	{"ESTABLISHED", expr.CtStateBitESTABLISHED},
+	{"UNTRACKED", expr.CtStateBitUNTRACKED},
+}
+
+var nftLimitUnits = map[string]expr.LimitTime{
+	"s": expr.LimitTimeSecond, "sec": expr.LimitTimeSecond, "second": expr.LimitTimeSecond,
+	"m": expr.LimitTimeMinute, "min": expr.LimitTimeMinute, "minute": expr.LimitTimeMinute,
+	"h": expr.LimitTimeHour, "hour": expr.LimitTimeHour,
+	"d": expr.LimitTimeDay, "day": expr.LimitTimeDay,
+}
+
+// iptablesDefaultLimitBurst is the burst of an iptables limit match without
+// --limit-burst.
+const iptablesDefaultLimitBurst = 5
+
+const (
+	nftIPv4SourceOffset      = 12
+	nftIPv4DestinationOffset = 16
+	nftIPv6SourceOffset      = 8
+	nftIPv6DestinationOffset = 24
+	nftPortOffset            = 2
+	nftICMPCodeOffset        = 1
+)
+
+// The codes of port unreachable in ICMP and ICMPv6 destination unreachable
+// messages.
+const (
+	icmpPortUnreachable   = 3
+	icmpv6PortUnreachable = 4
+)
+
+type nftTranslation struct {
+	family   nftables.TableFamily
+	exprs    []expr.Any
+	comment  string
+	guarded  byte
+	protocol string
+	limit    *expr.Limit
+}
+
+func (t *nftTranslation) add(exprs ...expr.Any) {
+	t.exprs = append(t.exprs, exprs...)
+}
+
+func cmpOp(negated bool) expr.CmpOp {
+	if negated {
+		return expr.CmpOpNeq
+	}
+	return expr.CmpOpEq
+}
+
+func (t *nftTranslation) translate(rule IPTablesRule) error {
+	var (
+		negate     bool
+		target     string
+		goTo       bool
+		targetOpts = map[string]string{}
+	)
+
+	for i := 0; i < len(rule); i++ {
+		arg := rule[i]
+		if arg == "!" {
+			negate = true
+			continue
+		}
+		count, ok := nftArgValues[arg]
+		if !ok {
+			return fmt.Errorf("unsupported iptables argument %q", arg)
+		}
+		if i+count >= len(rule) {
+			return fmt.Errorf("missing value for %s", arg)
+		}
+		values := rule[i+1 : i+1+count]
+		i += count
+		v := values[0]
+
+		negated := negate
+		negate = false
+		var err error
+		switch arg {
+		case "-s", "--source":
+			err = t.address("-s", v, negated)
+		case "-d", "--destination":
+			err = t.address("-d", v, negated)
+		case "-i":
+			t.add(&expr.Meta{Key: expr.MetaKeyIIFNAME, Register: 1}, &expr.Cmp{Op: cmpOp(negated), Register: 1, Data: nftIfname(v)})
+		case "-o":
+			t.add(&expr.Meta{Key: expr.MetaKeyOIFNAME, Register: 1}, &expr.Cmp{Op: cmpOp(negated), Register: 1, Data: nftIfname(v)})
+		case "-p", "--protocol":
+			err = t.protocolMatch(v, negated)
+		case "-m":
+		case "--dport", "--destination-port":
+			err = t.port(v, negated)
+		case "--dst-range":
+			err = t.dstRange(v, negated)
+		case "--mark":
+			err = t.mark(v, negated)
+		case "--state", "--ctstate":
+			err = t.ctState(v, negated)
+		case "--limit":
+			err = t.rateLimit(v)
+		case "--limit-burst":
+			err = t.limitBurst(v)
+		case "--icmp-type", "--icmpv6-type":
+			err = t.icmpType(arg, v)
+		case "--uid-owner":
+			err = t.owner(expr.MetaKeySKUID, v, negated)
+		case "--gid-owner":
+			err = t.owner(expr.MetaKeySKGID, v, negated)
+		case "--match-set":
+			err = t.matchSet(values[0], values[1], negated)
+		case "--comment":
+			t.comment = v
+		case "-j", "--jump":
+			target = v
+		case "-g", "--goto":
+			target, goTo = v, true
+		default:
+			targetOpts[arg] = v
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return t.target(target, goTo, targetOpts)
+}
+
+func parseNFTAddress(value string) (*net.IPNet, error) {
+	if strings.Contains(value, "/") {
+		_, ipNet, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %s", value)
+		}
+		return ipNet, nil
+	}
+
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid address %s", value)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// guard makes sure an address of the given version can be matched in the
+// table. Rules in an inet table see both versions, so they first check which
+// one the packet has.
+func (t *nftTranslation) guard(v4 bool, address string) error {
+	nfproto := byte(unix.NFPROTO_IPV6)
+	if v4 {
+		nfproto = unix.NFPROTO_IPV4
+	}
+
+	switch t.family {
+	case nftables.TableFamilyIPv4, nftables.TableFamilyIPv6:
+		if (t.family == nftables.TableFamilyIPv4) != v4 {
+			return fmt.Errorf("address %s does not belong in an %s table", address, nftFamilyName(t.family))
+		}
+	case nftables.TableFamilyINet:
+		if t.guarded == nfproto {
+			return nil
+		}
+		if t.guarded != 0 {
+			return fmt.Errorf("address %s mixes ip versions in one rule", address)
+		}
+		t.guarded = nfproto
+		t.add(&expr.Meta{Key: expr.MetaKeyNFPROTO, Register: 1}, &expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{nfproto}})
+	}
+	return nil
+}
+
+func nftAddressField(flag string, v4 bool) *expr.Payload {
+	offset, length := uint32(nftIPv6DestinationOffset), uint32(net.IPv6len)
+	switch {
+	case v4 && flag == "-s":
+		offset, length = nftIPv4SourceOffset, net.IPv4len
+	case v4:
+		offset, length = nftIPv4DestinationOffset, net.IPv4len
+	case flag == "-s":
+		offset = nftIPv6SourceOffset
+	}
+	return &expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader, Offset: offset, Len: length}
+}
+
+func (t *nftTranslation) address(flag, value string, negated bool) error {
+	ipNet, err := parseNFTAddress(value)
+	if err != nil {
+		return err
+	}
+	v4 := len(ipNet.IP) == net.IPv4len
+	if err := t.guard(v4, value); err != nil {
+		return err
+	}
+
+	field := nftAddressField(flag, v4)
+	t.add(field)
+	if ones, bits := ipNet.Mask.Size(); ones != bits {
+		t.add(&expr.Bitwise{SourceRegister: 1, DestRegister: 1, Len: field.Len, Mask: []byte(ipNet.Mask), Xor: make([]byte, field.Len)})
+	}
+	t.add(&expr.Cmp{Op: cmpOp(negated), Register: 1, Data: []byte(ipNet.IP)})
+	return nil
+}
+
+func (t *nftTranslation) dstRange(value string, negated bool) error {
+	bounds := strings.SplitN(value, "-", 2)
+	start, end := net.ParseIP(bounds[0]), net.ParseIP(bounds[len(bounds)-1])
+	if start == nil || end == nil || (start.To4() == nil) != (end.To4() == nil) {
+		return fmt.Errorf("invalid ip range %s", value)
+	}
+	v4 := start.To4() != nil
+	if v4 {
+		start, end = start.To4(), end.To4()
+	}
+	if err := t.guard(v4, value); err != nil {
+		return err
+	}
+
+	t.add(nftAddressField("-d", v4), &expr.Range{Op: cmpOp(negated), Register: 1, FromData: []byte(start), ToData: []byte(end)})
+	return nil
+}
+
+func (t *nftTranslation) protocolMatch(value string, negated bool) error {
+	if value == "icmpv6" {
+		value = "ipv6-icmp"
+	}
+	if value == "all" && !negated {
+		return nil
+	}
+	protocol, ok := nftProtocols[value]
+	if !ok {
+		return fmt.Errorf("unsupported protocol %q", value)
+	}
+
+	if !negated {
+		t.protocol = value
+	}
+	t.add(&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 1}, &expr.Cmp{Op: cmpOp(negated), Register: 1, Data: []byte{protocol}})
+	return nil
+}
+
+func parsePortRange(value string) (uint16, uint16, error) {
+	bounds := strings.SplitN(value, ":", 2)
+	start, err := strconv.ParseUint(bounds[0], 10, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port %s", value)
+	}
+	end, err := strconv.ParseUint(bounds[len(bounds)-1], 10, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port %s", value)
+	}
+	return uint16(start), uint16(end), nil
+}
+
+func (t *nftTranslation) port(value string, negated bool) error {
+	if t.protocol != "tcp" && t.protocol != "udp" {
+		return fmt.Errorf("port match requires tcp or udp, got %q", t.protocol)
+	}
+	start, end, err := parsePortRange(value)
+	if err != nil {
+		return err
+	}
+
+	t.add(&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseTransportHeader, Offset: nftPortOffset, Len: 2})
+	if start == end {
+		t.add(&expr.Cmp{Op: cmpOp(negated), Register: 1, Data: binaryutil.BigEndian.PutUint16(start)})
+		return nil
+	}
+	t.add(&expr.Range{Op: cmpOp(negated), Register: 1, FromData: binaryutil.BigEndian.PutUint16(start), ToData: binaryutil.BigEndian.PutUint16(end)})
+	return nil
+}
+
+// icmpType matches the type and code of a value like 8/0, where -1 matches
+// any type or code.
+func (t *nftTranslation) icmpType(arg, value string) error {
+	protocol := "icmp"
+	if arg == "--icmpv6-type" {
+		protocol = "ipv6-icmp"
+	}
+	if t.protocol != protocol {
+		return fmt.Errorf("%s requires protocol %s, got %q", arg, protocol, t.protocol)
+	}
+
+	typeAndCode := strings.SplitN(value, "/", 2)
+	fields := []uint32{0, nftICMPCodeOffset}
+	for i, field := range typeAndCode {
+		if field == "-1" {
+			if i == 0 {
+				return nil
+			}
+			continue
+		}
+		number, err := strconv.ParseUint(field, 10, 8)
+		if err != nil {
+			return fmt.Errorf("invalid icmp type %s", value)
+		}
+		t.add(
+			&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseTransportHeader, Offset: fields[i], Len: 1},
+			&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{byte(number)}},
+		)
+	}
+	return nil
+}
+
+// parseNFTMark reads a value like 0xa or 0xa/0xff. Without a mask, every bit is
+// compared.
+func parseNFTMark(value string) (uint32, uint32, error) {
+	valueAndMask := strings.SplitN(value, "/", 2)
+	mark, err := strconv.ParseUint(valueAndMask[0], 0, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid mark %s", value)
+	}
+	mask := uint64(0xffffffff)
+	if len(valueAndMask) == 2 {
+		mask, err = strconv.ParseUint(valueAndMask[1], 0, 32)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid mark %s", value)
+		}
+	}
+	return uint32(mark), uint32(mask), nil
+}
+
+func (t *nftTranslation) mark(value string, negated bool) error {
+	mark, mask, err := parseNFTMark(value)
+	if err != nil {
+		return err
+	}
+
+	t.add(&expr.Meta{Key: expr.MetaKeyMARK, Register: 1})
+	if mask != 0xffffffff {
+		t.add(&expr.Bitwise{SourceRegister: 1, DestRegister: 1, Len: 4, Mask: binaryutil.NativeEndian.PutUint32(mask), Xor: make([]byte, 4)})
+	}
+	t.add(&expr.Cmp{Op: cmpOp(negated), Register: 1, Data: binaryutil.NativeEndian.PutUint32(mark)})
+	return nil
+}
+
+func (t *nftTranslation) ctState(value string, negated bool) error {
+	bits := uint32(0)
+	for _, name := range strings.Split(strings.ToUpper(value), ",") {
+		found := false
+		for _, state := range nftCTStates {
+			if state.name == name {
+				bits, found = bits|state.bit, true
+			}
+		}
+		if !found {
+			return fmt.Errorf("unsupported conntrack state %q", name)
+		}
+	}
+
+	// Any of the state bits has to be set, or none of them when negated.
+	op := expr.CmpOpNeq
+	if negated {
+		op = expr.CmpOpEq
+	}
+	t.add(
+		&expr.Ct{Register: 1, Key: expr.CtKeySTATE},
+		&expr.Bitwise{SourceRegister: 1, DestRegister: 1, Len: 4, Mask: binaryutil.NativeEndian.PutUint32(bits), Xor: make([]byte, 4)},
+		&expr.Cmp{Op: op, Register: 1, Data: make([]byte, 4)},
+	)
+	return nil
+}
+
+func (t *nftTranslation) rateLimit(value string) error {
+	rateAndUnit := strings.SplitN(value, "/", 2)
+	rate, err := strconv.ParseUint(rateAndUnit[0], 10, 64)
+	if err != nil || len(rateAndUnit) != 2 {
+		return fmt.Errorf("invalid limit %s", value)
+	}
+	unit, ok := nftLimitUnits[rateAndUnit[1]]
+	if !ok {
+		return fmt.Errorf("invalid limit %s", value)
+	}
+
+	t.limit = &expr.Limit{Type: expr.LimitTypePkts, Rate: rate, Unit: unit, Burst: iptablesDefaultLimitBurst}
+	t.add(t.limit)
+	return nil
+}
+
+func (t *nftTranslation) limitBurst(value string) error {
+	if t.limit == nil {
+		return fmt.Errorf("--limit-burst without --limit")
+	}
+	burst, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid limit burst %s", value)
+	}
+	t.limit.Burst = uint32(burst)
+	return nil
+}
+
+func (t *nftTranslation) owner(key expr.MetaKey, value string, negated bool) error {
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return fmt.Errorf("owner %s is not numeric", value)
+	}
+
+	t.add(&expr.Meta{Key: key, Register: 1}, &expr.Cmp{Op: cmpOp(negated), Register: 1, Data: binaryutil.NativeEndian.PutUint32(uint32(id))})
+	return nil
+}
+
+// matchSet looks the destination up in a set made by NFTSets. A dst,dst set
+// is keyed by the address, protocol and port, which are loaded into
+// consecutive 32 bit registers the way nft loads a concatenation.
+func (t *nftTranslation) matchSet(name, directions string, negated bool) error {
+	if t.family == nftables.TableFamilyINet {
+		return fmt.Errorf("set %s can only be matched in an ip or ip6 table", name)
+	}
+	address := nftAddressField("-d", t.family == nftables.TableFamilyIPv4)
+
+	switch directions {
+	case "dst":
+		t.add(address, &expr.Lookup{SourceRegister: 1, SetName: name, Invert: negated})
+	case "dst,dst":
+		address.DestRegister = unix.NFT_REG32_00
+		protocolRegister := unix.NFT_REG32_00 + address.Len/4
+		t.add(
+			address,
+			&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: protocolRegister},
+			&expr.Payload{DestRegister: protocolRegister + 1, Base: expr.PayloadBaseTransportHeader, Offset: nftPortOffset, Len: 2},
+			&expr.Lookup{SourceRegister: unix.NFT_REG32_00, SetName: name, Invert: negated},
+		)
+	default:
+		return fmt.Errorf("unsupported set match %s %s", name, directions)
+	}
+	return nil
+}
+
+func nftIfname(name string) []byte {
+	if strings.HasSuffix(name, "+") {
+		return []byte(strings.TrimSuffix(name, "+"))
+	}
+	ifname := make([]byte, unix.IFNAMSIZ)
+	copy(ifname, name)
+	return ifname
+}
+
+func (t *nftTranslation) target(target string, goTo bool, opts map[string]string) error {
+	switch target {
+	case "":
+		return nil
+	case "ACCEPT":
+		t.add(&expr.Verdict{Kind: expr.VerdictAccept})
+	case "DROP":
+		t.add(&expr.Verdict{Kind: expr.VerdictDrop})
+	case "RETURN":
+		t.add(&expr.Verdict{Kind: expr.VerdictReturn})
+	case "MASQUERADE":
+		t.add(&expr.Masq{})
+	case "REJECT":
+		return t.reject(opts["--reject-with"])
+	case "LOG":
+		log := &expr.Log{}
+		if prefix := strings.Trim(opts["--log-prefix"], `"`); prefix != "" {
+			log.Key, log.Data = 1<<unix.NFTA_LOG_PREFIX, []byte(prefix)
+		}
+		t.add(log)
+	case "MARK":
+		return t.setMark(opts)
+	case "DNAT":
+		return t.dnat(opts["--to-destination"])
+	default:
+		kind := expr.VerdictJump
+		if goTo {
+			kind = expr.VerdictGoto
+		}
+		t.add(&expr.Verdict{Kind: kind, Chain: target})
+	}
+	return nil
+}
+
+// reject answers with port unreachable. An inet table sees both ip versions,
+// so it rejects with whichever version of ICMP the packet came in with.
+func (t *nftTranslation) reject(with string) error {
+	switch {
+	case with == "" && t.family == nftables.TableFamilyINet:
+		t.add(&expr.Reject{Type: unix.NFT_REJECT_ICMPX_UNREACH, Code: unix.NFT_REJECT_ICMPX_PORT_UNREACH})
+	case (with == "" || with == "icmp6-port-unreachable") && t.family == nftables.TableFamilyIPv6:
+		t.add(&expr.Reject{Type: unix.NFT_REJECT_ICMP_UNREACH, Code: icmpv6PortUnreachable})
+	case (with == "" || with == "icmp-port-unreachable") && t.family == nftables.TableFamilyIPv4:
+		t.add(&expr.Reject{Type: unix.NFT_REJECT_ICMP_UNREACH, Code: icmpPortUnreachable})
+	default:
+		return fmt.Errorf("unsupported reject type %q in an %s table", with, nftFamilyName(t.family))
+	}
+	return nil
+}
+
+func (t *nftTranslation) setMark(opts map[string]string) error {
+	value, xmark := opts["--set-xmark"]
+	if !xmark {
+		value = opts["--set-mark"]
+	}
+	mark, mask, err := parseNFTMark(value)
+	if err != nil {
+		return err
+	}
+
+	if mask == 0xffffffff {
+		t.add(
+			&expr.Immediate{Register: 1, Data: binaryutil.NativeEndian.PutUint32(mark)},
+			&expr.Meta{Key: expr.MetaKeyMARK, SourceRegister: true, Register: 1},
+		)
+		return nil
+	}
+	if !xmark {
+		return fmt.Errorf("unsupported --set-mark %s, use --set-xmark", value)
+	}
+	t.add(
+		&expr.Meta{Key: expr.MetaKeyMARK, Register: 1},
+		&expr.Bitwise{SourceRegister: 1, DestRegister: 1, Len: 4, Mask: binaryutil.NativeEndian.PutUint32(^mask), Xor: binaryutil.NativeEndian.PutUint32(mark)},
+		&expr.Meta{Key: expr.MetaKeyMARK, SourceRegister: true, Register: 1},
+	)
+	return nil
+}
+
+func (t *nftTranslation) dnat(destination string) error {
+	address, port := destination, ""
+	if i := strings.LastIndex(destination, ":"); i != -1 {
+		address, port = destination[:i], destination[i+1:]
+	}
+	ip := net.ParseIP(address).To4()
+	if ip == nil {
+		return fmt.Errorf("unsupported dnat destination %q", destination)
+	}
+	if err := t.guard(true, address); err != nil {
+		return err
+	}
+
+	nat := &expr.NAT{Type: expr.NATTypeDestNAT, Family: unix.NFPROTO_IPV4, RegAddrMin: 1}
+	t.add(&expr.Immediate{Register: 1, Data: []byte(ip)})
+	if port != "" {
+		number, err := strconv.ParseUint(port, 10, 16)
+		if err != nil {
+			return fmt.Errorf("unsupported dnat destination %q", destination)
+		}
+		t.add(&expr.Immediate{Register: 2, Data: binaryutil.BigEndian.PutUint16(uint16(number))})
+		nat.RegProtoMin, nat.Specified = 2, true
+	}
+	t.add(nat)
+	return nil
+}
+
+// iptablesRuleOf decodes the expressions of a rule into the form `iptables
+// -S` prints it in. It understands the expressions TranslateToNFT writes.
+func iptablesRuleOf(family nftables.TableFamily, rule *nftables.Rule) (IPTablesRule, error) {
+	d := &nftDecoding{family: family, exprs: rule.Exprs, rule: IPTablesRule{}, target: IPTablesRule{}}
+	for d.i < len(d.exprs) {
+		if err := d.decodeNext(); err != nil {
+			return nil, err
+		}
+	}
+
+	decoded := d.rule
+	if comment, ok := userdata.GetString(rule.UserData, userdata.TypeComment); ok {
+		decoded = append(decoded, "-m", "comment", "--comment", comment)
+	}
+	return append(decoded, d.target...), nil
+}
+
+type nftDecoding struct {
+	family   nftables.TableFamily
+	exprs    []expr.Any
+	i        int
+	rule     IPTablesRule
+	target   IPTablesRule
+	protocol string
+}
+
+func (d *nftDecoding) next() expr.Any {
+	if d.i >= len(d.exprs) {
+		return nil
+	}
+	e := d.exprs[d.i]
+	d.i++
+	return e
+}
+
+func (d *nftDecoding) cmp() (*expr.Cmp, error) {
+	cmp, ok := d.next().(*expr.Cmp)
+	if !ok || (cmp.Op != expr.CmpOpEq && cmp.Op != expr.CmpOpNeq) {
+		return nil, fmt.Errorf("expected a comparison at expression %d", d.i)
+	}
+	return cmp, nil
+}
+
+// match adds a match, negated the way iptables prints it: before the flag,
+// after the module.
+func (d *nftDecoding) match(module string, negated bool, flag string, values ...string) {
+	if module != "" {
+		d.rule = append(d.rule, "-m", module)
+	}
+	if negated {
+		d.rule = append(d.rule, "!")
+	}
+	d.rule = append(append(d.rule, flag), values...)
+}
+
+func (d *nftDecoding) decodeNext() error {
+	switch e := d.next().(type) {
+	case *expr.Meta:
+		if e.SourceRegister {
+			return fmt.Errorf("unexpected meta %d set at expression %d", e.Key, d.i)
+		}
+		return d.meta(e)
+	case *expr.Payload:
+		return d.payload(e)
+	case *expr.Ct:
+		return d.ctState(e)
+	case *expr.Limit:
+		d.match("limit", false, "--limit", fmt.Sprintf("%d/%s", e.Rate, iptablesLimitUnit(e.Unit)), "--limit-burst", strconv.FormatUint(uint64(e.Burst), 10))
+	case *expr.Counter:
+	case *expr.Verdict:
+		return d.verdict(e)
+	case *expr.Log:
+		d.target = IPTablesRule{"-j", "LOG"}
+		if len(e.Data) > 0 {
+			d.target = append(d.target, "--log-prefix", fmt.Sprintf(`"%s"`, e.Data))
+		}
+	case *expr.Immediate:
+		return d.immediate(e)
+	case *expr.Masq:
+		d.target = IPTablesRule{"-j", "MASQUERADE"}
+	case *expr.Reject:
+		return d.reject(e)
+	default:
+		return fmt.Errorf("unsupported expression %T", e)
+	}
+	return nil
+}
+
+func (d *nftDecoding) meta(e *expr.Meta) error {
+	if e.Key == expr.MetaKeyMARK && d.i < len(d.exprs) {
+		if bitwise, ok := d.exprs[d.i].(*expr.Bitwise); ok && d.i+1 < len(d.exprs) {
+			if set, ok := d.exprs[d.i+1].(*expr.Meta); ok && set.SourceRegister {
+				d.i += 2
+				mask := ^binaryutil.NativeEndian.Uint32(bitwise.Mask)
+				d.target = IPTablesRule{"-j", "MARK", "--set-xmark", fmt.Sprintf("0x%x/0x%x", binaryutil.NativeEndian.Uint32(bitwise.Xor), mask)}
+				return nil
+			}
+		}
+	}
+
+	var mask *expr.Bitwise
+	if e.Key == expr.MetaKeyMARK && d.i < len(d.exprs) {
+		if bitwise, ok := d.exprs[d.i].(*expr.Bitwise); ok {
+			mask = bitwise
+			d.i++
+		}
+	}
+	cmp, err := d.cmp()
+	if err != nil {
+		return err
+	}
+	negated := cmp.Op == expr.CmpOpNeq
+
+	switch e.Key {
+	case expr.MetaKeyNFPROTO:
+	case expr.MetaKeyL4PROTO:
+		d.protocol = strconv.Itoa(int(cmp.Data[0]))
+		for name, number := range nftProtocols {
+			if number == cmp.Data[0] {
+				d.protocol = name
+			}
+		}
+		if negated {
+			d.rule = append(d.rule, "!")
+		}
+		d.rule = append(d.rule, "-p", d.protocol)
+	case expr.MetaKeyIIFNAME, expr.MetaKeyOIFNAME:
+		flag := "-i"
+		if e.Key == expr.MetaKeyOIFNAME {
+			flag = "-o"
+		}
+		if negated {
+			d.rule = append(d.rule, "!")
+		}
+		d.rule = append(d.rule, flag, iptablesIfname(cmp.Data))
+	case expr.MetaKeyMARK:
+		value := fmt.Sprintf("0x%x", binaryutil.NativeEndian.Uint32(cmp.Data))
+		if mask != nil {
+			value += fmt.Sprintf("/0x%x", binaryutil.NativeEndian.Uint32(mask.Mask))
+		}
+		d.match("mark", negated, "--mark", value)
+	case expr.MetaKeySKUID:
+		d.match("owner", negated, "--uid-owner", strconv.FormatUint(uint64(binaryutil.NativeEndian.Uint32(cmp.Data)), 10))
+	case expr.MetaKeySKGID:
+		d.match("owner", negated, "--gid-owner", strconv.FormatUint(uint64(binaryutil.NativeEndian.Uint32(cmp.Data)), 10))
+	default:
+		return fmt.Errorf("unsupported meta key %d", e.Key)
+	}
+	return nil
+}
+
+func iptablesIfname(data []byte) string {
+	if i := strings.IndexByte(string(data), 0); i != -1 {
+		return string(data[:i])
+	}
+	return string(data) + "+"
+}
+
+func (d *nftDecoding) payload(e *expr.Payload) error {
+	switch e.Base {
+	case expr.PayloadBaseNetworkHeader:
+		return d.address(e)
+	case expr.PayloadBaseTransportHeader:
+		if e.Offset == nftPortOffset && e.Len == 2 {
+			return d.port()
+		}
+		if e.Offset == 0 && e.Len == 1 {
+			return d.icmpType()
+		}
+	}
+	return fmt.Errorf("unsupported payload at offset %d", e.Offset)
+}
+
+func (d *nftDecoding) address(e *expr.Payload) error {
+	var flag string
+	switch {
+	case e.Len == net.IPv4len && e.Offset == nftIPv4SourceOffset, e.Len == net.IPv6len && e.Offset == nftIPv6SourceOffset:
+		flag = "-s"
+	case e.Len == net.IPv4len && e.Offset == nftIPv4DestinationOffset, e.Len == net.IPv6len && e.Offset == nftIPv6DestinationOffset:
+		flag = "-d"
+	default:
+		return fmt.Errorf("unsupported network header payload at offset %d", e.Offset)
+	}
+
+	if e.DestRegister == unix.NFT_REG32_00 {
+		return d.concatenatedSet(flag)
+	}
+
+	switch next := d.next().(type) {
+	case *expr.Lookup:
+		d.match("set", next.Invert, "--match-set", next.SetName, strings.TrimPrefix(flag, "-")+"st")
+	case *expr.Range:
+		d.match("iprange", next.Op == expr.CmpOpNeq, "--"+strings.TrimPrefix(flag, "-")+"st-range",
+			fmt.Sprintf("%s-%s", net.IP(next.FromData), net.IP(next.ToData)))
+	case *expr.Bitwise:
+		cmp, err := d.cmp()
+		if err != nil {
+			return err
+		}
+		ones, _ := net.IPMask(next.Mask).Size()
+		d.addressMatch(flag, cmp, ones)
+	case *expr.Cmp:
+		d.i--
+		cmp, err := d.cmp()
+		if err != nil {
+			return err
+		}
+		d.addressMatch(flag, cmp, len(cmp.Data)*8)
+	default:
+		return fmt.Errorf("unsupported address match at expression %d", d.i)
+	}
+	return nil
+}
+
+func (d *nftDecoding) addressMatch(flag string, cmp *expr.Cmp, ones int) {
+	if cmp.Op == expr.CmpOpNeq {
+		d.rule = append(d.rule, "!")
+	}
+	d.rule = append(d.rule, flag, fmt.Sprintf("%s/%d", net.IP(cmp.Data), ones))
+}
+
+func (d *nftDecoding) concatenatedSet(flag string) error {
+	protocol, ok := d.next().(*expr.Meta)
+	if !ok || protocol.Key != expr.MetaKeyL4PROTO {
+		return fmt.Errorf("unsupported concatenation at expression %d", d.i)
+	}
+	port, ok := d.next().(*expr.Payload)
+	if !ok || port.Base != expr.PayloadBaseTransportHeader || port.Offset != nftPortOffset {
+		return fmt.Errorf("unsupported concatenation at expression %d", d.i)
+	}
+	lookup, ok := d.next().(*expr.Lookup)
+	if !ok {
+		return fmt.Errorf("expected a set lookup at expression %d", d.i)
+	}
+
+	direction := strings.TrimPrefix(flag, "-") + "st"
+	d.match("set", lookup.Invert, "--match-set", lookup.SetName, direction+",dst")
+	return nil
+}
+
+func (d *nftDecoding) port() error {
+	if d.protocol != "tcp" && d.protocol != "udp" {
+		return fmt.Errorf("port match without tcp or udp at expression %d", d.i)
+	}
+
+	switch next := d.next().(type) {
+	case *expr.Cmp:
+		d.match(d.protocol, next.Op == expr.CmpOpNeq, "--dport", strconv.Itoa(int(binaryutil.BigEndian.Uint16(next.Data))))
+	case *expr.Range:
+		d.match(d.protocol, next.Op == expr.CmpOpNeq, "--dport", fmt.Sprintf("%d:%d",
+			binaryutil.BigEndian.Uint16(next.FromData), binaryutil.BigEndian.Uint16(next.ToData)))
+	default:
+		return fmt.Errorf("unsupported port match at expression %d", d.i)
+	}
+	return nil
+}
+
+func (d *nftDecoding) icmpType() error {
+	cmp, err := d.cmp()
+	if err != nil {
+		return err
+	}
+	value := strconv.Itoa(int(cmp.Data[0]))
+
+	if d.nextIsICMPCode() {
+		d.i++
+		code, err := d.cmp()
+		if err != nil {
+			return err
+		}
+		value = fmt.Sprintf("%s/%d", value, code.Data[0])
+	}
+
+	switch d.protocol {
+	case "icmp":
+		d.match("icmp", false, "--icmp-type", value)
+	case "ipv6-icmp":
+		d.match("icmp6", false, "--icmpv6-type", value)
+	default:
+		return fmt.Errorf("icmp type match without icmp at expression %d", d.i)
+	}
+	return nil
+}
+
+func (d *nftDecoding) nextIsICMPCode() bool {
+	if d.i >= len(d.exprs) {
+		return false
+	}
+	payload, ok := d.exprs[d.i].(*expr.Payload)
+	return ok && payload.Base == expr.PayloadBaseTransportHeader && payload.Offset == nftICMPCodeOffset
+}
+
+func (d *nftDecoding) ctState(e *expr.Ct) error {
+	if e.Key != expr.CtKeySTATE {
+		return fmt.Errorf("unsupported conntrack key %d", e.Key)
+	}
+	bitwise, ok := d.next().(*expr.Bitwise)
+	if !ok {
+		return fmt.Errorf("expected a conntrack state mask at expression %d", d.i)
+	}
+	cmp, err := d.cmp()
+	if err != nil {
+		return err
+	}
+
+	bits := binaryutil.NativeEndian.Uint32(bitwise.Mask)
+	states := []string{}
+	for _, state := range nftCTStates {
+		if bits&state.bit != 0 {
+			states = append(states, state.name)
+		}
+	}
+	d.match("conntrack", cmp.Op == expr.CmpOpEq, "--ctstate", strings.Join(states, ","))
+	return nil
+}
+
+func iptablesLimitUnit(unit expr.LimitTime) string {
+	switch unit {
+	case expr.LimitTimeSecond:
+		return "sec"
+	case expr.LimitTimeMinute:
+		return "min"
+	case expr.LimitTimeHour:
+		return "hour"
+	}
+	return "day"
+}
+
+func (d *nftDecoding) verdict(e *expr.Verdict) error {
+	switch e.Kind {
+	case expr.VerdictAccept:
+		d.target = IPTablesRule{"-j", "ACCEPT"}
+	case expr.VerdictDrop:
+		d.target = IPTablesRule{"-j", "DROP"}
+	case expr.VerdictReturn:
+		d.target = IPTablesRule{"-j", "RETURN"}
+	case expr.VerdictJump:
+		d.target = IPTablesRule{"-j", e.Chain}
+	case expr.VerdictGoto:
+		d.target = IPTablesRule{"-g", e.Chain}
+	default:
+		return fmt.Errorf("unsupported verdict %d", e.Kind)
+	}
+	return nil
+}
+
+func (d *nftDecoding) immediate(e *expr.Immediate) error {
+	switch next := d.next().(type) {
+	case *expr.Meta:
+		if next.Key != expr.MetaKeyMARK || !next.SourceRegister {
+			return fmt.Errorf("unsupported meta %d after an immediate", next.Key)
+		}
+		d.target = IPTablesRule{"-j", "MARK", "--set-xmark", fmt.Sprintf("0x%x/0xffffffff", binaryutil.NativeEndian.Uint32(e.Data))}
+	case *expr.Immediate:
+		nat, ok := d.next().(*expr.NAT)
+		if !ok || nat.Type != expr.NATTypeDestNAT {
+			return fmt.Errorf("expected dnat at expression %d", d.i)
+		}
+		d.target = IPTablesRule{"-j", "DNAT", "--to-destination", fmt.Sprintf("%s:%d", net.IP(e.Data), binaryutil.BigEndian.Uint16(next.Data))}
+	case *expr.NAT:
+		if next.Type != expr.NATTypeDestNAT {
+			return fmt.Errorf("expected dnat at expression %d", d.i)
+		}
+		d.target = IPTablesRule{"-j", "DNAT", "--to-destination", net.IP(e.Data).String()}
+	default:
+		return fmt.Errorf("unsupported immediate at expression %d", d.i)
+	}
+	return nil
+}
+
+func (d *nftDecoding) reject(e *expr.Reject) error {
+	switch {
+	case e.Type == unix.NFT_REJECT_ICMPX_UNREACH && e.Code == unix.NFT_REJECT_ICMPX_PORT_UNREACH:
+		d.target = IPTablesRule{"-j", "REJECT"}
+	case e.Type == unix.NFT_REJECT_ICMP_UNREACH && e.Code == icmpv6PortUnreachable && d.family == nftables.TableFamilyIPv6:
+		d.target = IPTablesRule{"-j", "REJECT", "--reject-with", "icmp6-port-unreachable"}
+	case e.Type == unix.NFT_REJECT_ICMP_UNREACH && e.Code == icmpPortUnreachable && d.family == nftables.TableFamilyIPv4:
+		d.target = IPTablesRule{"-j", "REJECT", "--reject-with", "icmp-port-unreachable"}
+	default:
+		return fmt.Errorf("unsupported reject type %d code %d", e.Type, e.Code)
+	}
+	return nil
+}
@@ -0,0 +1,370 @@
+package rules_test
+
+import (
+	"errors"
+	"lib/fakes"
+	"lib/rules"
+	"lib/testsupport"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/binaryutil"
+	"github.com/google/nftables/expr"
+	"github.com/google/nftables/userdata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NFTables", func() {
+	var (
+		nft  *rules.NFTables
+		conn *fakes.NFTConn
+		lock *fakes.Locker
+		rule rules.IPTablesRule
+	)
+
+	BeforeEach(func() {
+		conn = &fakes.NFTConn{}
+		lock = &fakes.Locker{}
+		nft = &rules.NFTables{
+			Conn:   conn,
+			Locker: lock,
+		}
+		rule = rules.NewMarkSetRule("1.2.3.4", "A", "a-guid")
+	})
+
+	Describe("BulkAppend", func() {
+		It("creates the table and chain and appends the translated rules in one batch", func() {
+			err := nft.BulkAppend("filter", "some-chain", rule, rules.NewAcceptRule())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(lock.LockCallCount()).To(Equal(1))
+			Expect(lock.UnlockCallCount()).To(Equal(1))
+			Expect(conn.AddTableArgsForCall(0)).To(Equal(&nftables.Table{Name: "filter", Family: nftables.TableFamilyIPv4}))
+			Expect(conn.AddChainArgsForCall(0).Name).To(Equal("some-chain"))
+			Expect(conn.AddChainArgsForCall(0).Hooknum).To(BeNil())
+			Expect(conn.AddRuleCallCount()).To(Equal(2))
+			Expect(conn.AddRuleArgsForCall(1).Exprs).To(Equal([]expr.Any{&expr.Verdict{Kind: expr.VerdictAccept}}))
+			Expect(conn.FlushCallCount()).To(Equal(1))
+		})
+
+		It("keeps the comment in the user data of the rule", func() {
+			Expect(nft.BulkAppend("filter", "some-chain", rule)).To(Succeed())
+
+			comment, ok := userdata.GetString(conn.AddRuleArgsForCall(0).UserData, userdata.TypeComment)
+			Expect(ok).To(BeTrue())
+			Expect(comment).To(Equal("src:a-guid"))
+		})
+
+		It("hooks the built-in chains into netfilter", func() {
+			Expect(nft.BulkAppend("nat", "POSTROUTING", rules.NewDefaultEgressRule("10.255.0.0/16", "eth0"))).To(Succeed())
+
+			chain := conn.AddChainArgsForCall(0)
+			Expect(chain.Hooknum).To(Equal(nftables.ChainHookPostrouting))
+			Expect(chain.Type).To(Equal(nftables.ChainTypeNAT))
+			Expect(chain.Priority).To(Equal(nftables.ChainPriorityNATSource))
+		})
+
+		It("uses the configured family", func() {
+			nft.Family = "inet"
+			Expect(nft.BulkAppend("filter", "some-chain", rule)).To(Succeed())
+
+			Expect(conn.AddTableArgsForCall(0).Family).To(Equal(nftables.TableFamilyINet))
+		})
+
+		Context("when the family is unknown", func() {
+			It("returns an error", func() {
+				nft.Family = "banana"
+				Expect(nft.BulkAppend("filter", "some-chain", rule)).To(MatchError("unsupported nftables family banana"))
+				Expect(lock.LockCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when a rule cannot be translated", func() {
+			It("returns an error without touching nftables", func() {
+				err := nft.BulkAppend("filter", "some-chain", rules.IPTablesRule{"--banana"})
+				Expect(err).To(MatchError(`unsupported iptables argument "--banana"`))
+				Expect(lock.LockCallCount()).To(Equal(0))
+				Expect(conn.FlushCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the lock fails", func() {
+			It("returns an error", func() {
+				lock.LockReturns(errors.New("banana"))
+				Expect(nft.BulkAppend("filter", "some-chain", rule)).To(MatchError("lock: banana"))
+			})
+		})
+
+		Context("when sending the batch fails", func() {
+			It("returns an error", func() {
+				conn.FlushReturns(errors.New("banana"))
+				lock.UnlockReturns(errors.New("potato"))
+				Expect(nft.BulkAppend("filter", "some-chain", rule)).To(MatchError("nft call: banana and unlock: potato"))
+			})
+		})
+	})
+
+	Describe("BulkInsert", func() {
+		BeforeEach(func() {
+			conn.GetRulesReturns([]*nftables.Rule{
+				{Handle: 4, Exprs: []expr.Any{&expr.Verdict{Kind: expr.VerdictAccept}}},
+				{Handle: 7, Exprs: []expr.Any{&expr.Verdict{Kind: expr.VerdictDrop}}},
+			}, nil)
+		})
+
+		It("inserts at the start of the chain without listing it", func() {
+			Expect(nft.BulkInsert("filter", "some-chain", 1, rule)).To(Succeed())
+
+			Expect(conn.GetRulesCallCount()).To(Equal(0))
+			Expect(conn.InsertRuleCallCount()).To(Equal(1))
+			Expect(conn.InsertRuleArgsForCall(0).Position).To(BeZero())
+		})
+
+		It("inserts before the rule currently at the position", func() {
+			Expect(nft.BulkInsert("filter", "some-chain", 2, rule)).To(Succeed())
+
+			table, chain := conn.GetRulesArgsForCall(0)
+			Expect(table.Name).To(Equal("filter"))
+			Expect(chain.Name).To(Equal("some-chain"))
+			Expect(conn.InsertRuleArgsForCall(0).Position).To(Equal(uint64(7)))
+		})
+
+		It("inserts the rules in reverse, so the last rule given ends up first", func() {
+			Expect(nft.BulkInsert("filter", "some-chain", 2, rule, rules.NewAcceptRule())).To(Succeed())
+
+			Expect(conn.InsertRuleArgsForCall(0).Exprs).To(Equal([]expr.Any{&expr.Verdict{Kind: expr.VerdictAccept}}))
+			Expect(conn.InsertRuleArgsForCall(1).UserData).NotTo(BeEmpty())
+		})
+
+		It("adds after the last rule when the position is just past the end", func() {
+			Expect(nft.BulkInsert("filter", "some-chain", 3, rule)).To(Succeed())
+
+			Expect(conn.InsertRuleCallCount()).To(Equal(0))
+			Expect(conn.AddRuleArgsForCall(0).Position).To(Equal(uint64(7)))
+		})
+
+		Context("when the position is out of range", func() {
+			It("returns an error", func() {
+				err := nft.BulkInsert("filter", "some-chain", 4, rule)
+				Expect(err).To(MatchError("nft call: rule position 4 out of range for chain some-chain and unlock: <nil>"))
+				Expect(conn.FlushCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when listing the chain fails", func() {
+			It("returns an error", func() {
+				conn.GetRulesReturns(nil, errors.New("banana"))
+				Expect(nft.BulkInsert("filter", "some-chain", 2, rule)).To(MatchError("nft call: banana and unlock: <nil>"))
+			})
+		})
+	})
+
+	Describe("Delete", func() {
+		It("deletes the matching rule by its handle", func() {
+			conn.GetRulesReturns([]*nftables.Rule{
+				{Handle: 3, Exprs: []expr.Any{&expr.Verdict{Kind: expr.VerdictAccept}}},
+				{Handle: 9, Exprs: []expr.Any{
+					&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader, Offset: 12, Len: 4},
+					&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{1, 2, 3, 4}},
+					&expr.Counter{},
+					&expr.Immediate{Register: 1, Data: binaryutil.NativeEndian.PutUint32(0xa)},
+					&expr.Meta{Key: expr.MetaKeyMARK, SourceRegister: true, Register: 1},
+				}, UserData: userdata.AppendString(nil, userdata.TypeComment, "src:a-guid")},
+			}, nil)
+
+			Expect(nft.Delete("filter", "some-chain", rule)).To(Succeed())
+			Expect(conn.DelRuleCallCount()).To(Equal(1))
+			deleted := conn.DelRuleArgsForCall(0)
+			Expect(deleted.Handle).To(Equal(uint64(9)))
+			Expect(deleted.Table.Name).To(Equal("filter"))
+			Expect(deleted.Chain.Name).To(Equal("some-chain"))
+			Expect(conn.FlushCallCount()).To(Equal(1))
+		})
+
+		It("returns an error when no rule matches", func() {
+			Expect(nft.Delete("filter", "some-chain", rule)).To(MatchError("nft call: rule does not exist in chain some-chain and unlock: <nil>"))
+			Expect(conn.DelRuleCallCount()).To(Equal(0))
+		})
+
+		Context("when a listed rule cannot be decoded", func() {
+			It("returns an error", func() {
+				conn.GetRulesReturns([]*nftables.Rule{{Handle: 5, Exprs: []expr.Any{&expr.Quota{}}}}, nil)
+				Expect(nft.Delete("filter", "some-chain", rule)).To(MatchError(
+					"nft call: rule with handle 5 in chain some-chain: unsupported expression *expr.Quota and unlock: <nil>"))
+			})
+		})
+	})
+
+	Describe("Exists", func() {
+		Context("when listing the chain fails", func() {
+			It("returns an error", func() {
+				conn.GetRulesReturns(nil, errors.New("banana"))
+				_, err := nft.Exists("filter", "some-chain", rule)
+				Expect(err).To(MatchError("nft call: banana and unlock: <nil>"))
+			})
+		})
+	})
+
+	Describe("List", func() {
+		var memory *testsupport.InMemoryNFT
+
+		BeforeEach(func() {
+			memory = testsupport.NewInMemoryNFT()
+			nft.Conn = memory
+		})
+
+		DescribeTable("listing the rule builders the way iptables does",
+			func(family string, rule rules.IPTablesRule, expected string) {
+				nft.Family = family
+				Expect(nft.BulkAppend("filter", "some-chain", rule)).To(Succeed())
+
+				listed, err := nft.List("filter", "some-chain")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(listed).To(Equal([]string{"-N some-chain", "-A some-chain " + expected}))
+			},
+			Entry("port forwarding", "ip", rules.NewPortForwardingRule(8080, 80, "10.0.0.1", "10.255.0.2"),
+				"-d 10.0.0.1/32 -p tcp -m tcp --dport 8080 -j DNAT --to-destination 10.255.0.2:80"),
+			Entry("ingress mark", "ip", rules.NewIngressMarkRule("eth0", 8080, "10.0.0.1", "A"),
+				"-i eth0 -d 10.0.0.1/32 -p tcp -m tcp --dport 8080 -j MARK --set-xmark 0xa/0xffffffff"),
+			Entry("mark allow", "ip", rules.NewMarkAllowRule("10.255.0.2", "tcp", 8080, 9000, "A", "src-guid", "dst-guid"),
+				"-d 10.255.0.2/32 -p tcp -m tcp --dport 8080:9000 -m mark --mark 0xa -m comment --comment src:src-guid_dst:dst-guid -j ACCEPT"),
+			Entry("mark allow log", "ip", rules.NewMarkAllowLogRule("10.255.0.2", "udp", 8080, 8080, "A", "dst-guid", 100),
+				`-d 10.255.0.2/32 -p udp -m udp --dport 8080 -m mark --mark 0xa -m limit --limit 100/sec --limit-burst 100 -j LOG --log-prefix "OK_A_dst-guid "`),
+			Entry("mark set", "ip", rules.NewMarkSetRule("10.255.0.2", "A", "a-guid"),
+				"-s 10.255.0.2/32 -m comment --comment src:a-guid -j MARK --set-xmark 0xa/0xffffffff"),
+			Entry("default egress", "ip", rules.NewDefaultEgressRule("10.255.0.0/16", "eth0"),
+				"-s 10.255.0.0/16 ! -o eth0 -j MASQUERADE"),
+			Entry("log", "ip", rules.NewLogLocalRejectRule("10.255.0.0/16"),
+				`-s 10.255.0.0/16 -d 10.255.0.0/16 -m limit --limit 2/min --limit-burst 5 -j LOG --log-prefix "REJECT_LOCAL:  "`),
+			Entry("netout with ports", "ip", rules.NewNetOutWithPortsRule("10.0.0.1", "10.0.0.9", 80, 90, "udp"),
+				"-p udp -m iprange --dst-range 10.0.0.1-10.0.0.9 -m udp --dport 80:90 -j ACCEPT"),
+			Entry("netout icmp", "ip", rules.NewNetOutICMPRule("10.0.0.1", "10.0.0.9", 8, 0),
+				"-p icmp -m iprange --dst-range 10.0.0.1-10.0.0.9 -m icmp --icmp-type 8/0 -j ACCEPT"),
+			Entry("netout icmp of any type", "ip", rules.NewNetOutICMPRule("10.0.0.1", "10.0.0.9", -1, -1),
+				"-p icmp -m iprange --dst-range 10.0.0.1-10.0.0.9 -j ACCEPT"),
+			Entry("netout icmpv6 log", "ip6", rules.NewNetOutICMPv6LogRule("2001:db8::1", "2001:db8::9", 128, 0, "some-chain"),
+				"-p ipv6-icmp -m iprange --dst-range 2001:db8::1-2001:db8::9 -m icmp6 --icmpv6-type 128/0 -g some-chain"),
+			Entry("netout default non udp log", "ip", rules.NewNetOutDefaultNonUDPLogRule("some-handle"),
+				`! -p udp -m conntrack --ctstate INVALID,NEW,UNTRACKED -j LOG --log-prefix "OK_some-handle "`),
+			Entry("input allow", "ip", rules.NewInputAllowRule("tcp", "10.0.0.1", 53),
+				"-p tcp -d 10.0.0.1/32 -m tcp --dport 53 -j ACCEPT"),
+			Entry("overlay allow egress", "ip", rules.NewOverlayAllowEgress("silk-vtep", "10.255.0.2"),
+				"-s 10.255.0.2/32 -o silk-vtep -m mark ! --mark 0x0 -j ACCEPT"),
+			Entry("related established", "ip", rules.NewNetOutRelatedEstablishedRule(),
+				"-m conntrack --ctstate RELATED,ESTABLISHED -j ACCEPT"),
+			Entry("reject", "ip", rules.NewNetOutDefaultRejectRule(),
+				"-j REJECT --reject-with icmp-port-unreachable"),
+			Entry("reject ipv6", "ip6", rules.NewNetOutDefaultRejectIPv6Rule(),
+				"-j REJECT --reject-with icmp6-port-unreachable"),
+			Entry("owner", "ip", rules.IPTablesRule{"-m", "owner", "--uid-owner", "1000", "-j", "RETURN"},
+				"-m owner --uid-owner 1000 -j RETURN"),
+			Entry("interface prefix", "inet", rules.IPTablesRule{"-s", "10.0.0.0/8", "-o", "eth+", "-j", "DROP"},
+				"-s 10.0.0.0/8 -o eth+ -j DROP"),
+		)
+
+		It("lists the sets a rule matches", func() {
+			sets := &rules.NFTSets{Conn: memory, Locker: &fakes.Locker{}, Table: "filter"}
+			Expect(sets.Replace(rules.IPSet{Name: "some-set", Type: "hash:net", Entries: []string{"10.0.0.0/24"}})).To(Succeed())
+			Expect(sets.Replace(rules.IPSet{Name: "some-port-set", Type: "hash:net,port", Entries: []string{"10.0.0.0/24,tcp:80-90"}})).To(Succeed())
+
+			Expect(nft.BulkAppend("filter", "some-log-chain", rules.NewAcceptRule())).To(Succeed())
+			Expect(nft.BulkAppend("filter", "some-chain",
+				rules.NewNetOutSetRule("some-set"),
+				rules.NewNetOutWithPortsSetLogRule("some-port-set", "some-log-chain"),
+			)).To(Succeed())
+
+			Expect(nft.List("filter", "some-chain")).To(Equal([]string{
+				"-N some-chain",
+				"-A some-chain -m set --match-set some-set dst -j ACCEPT",
+				"-A some-chain -m set --match-set some-port-set dst,dst -g some-log-chain",
+			}))
+		})
+
+		It("lists the policy of the built-in chains", func() {
+			Expect(nft.ClearChain("filter", "FORWARD")).To(Succeed())
+			Expect(nft.List("filter", "FORWARD")).To(Equal([]string{"-P FORWARD ACCEPT"}))
+		})
+
+		It("finds a rule however it was spelled", func() {
+			Expect(nft.BulkAppend("filter", "some-chain", rules.NewInputRelatedEstablishedRule())).To(Succeed())
+
+			Expect(nft.Exists("filter", "some-chain", rules.IPTablesRule{"-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "-j", "ACCEPT"})).To(BeTrue())
+		})
+
+		Context("when a set rule references a missing set", func() {
+			It("fails without adding any rule", func() {
+				err := nft.BulkAppend("filter", "some-chain", rules.NewAcceptRule(), rules.NewNetOutSetRule("some-set"))
+				Expect(err).To(MatchError("nft call: set some-set does not exist and unlock: <nil>"))
+
+				_, err = nft.List("filter", "some-chain")
+				Expect(err).To(MatchError("nft call: chain some-chain does not exist in table filter and unlock: <nil>"))
+			})
+		})
+	})
+
+	Context("backed by an in-memory nftables", func() {
+		itBehavesLikeAnIPTablesAdapter(func() rules.IPTablesAdapter {
+			return &rules.NFTables{
+				Conn:   testsupport.NewInMemoryNFT(),
+				Locker: &fakes.Locker{},
+			}
+		})
+	})
+})
+
+var _ = Describe("TranslateToNFT", func() {
+	It("loads the destination, protocol and port into consecutive registers for a dst,dst set", func() {
+		exprs, err := rules.TranslateToNFT("ip", rules.NewNetOutWithPortsSetRule("some-set"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exprs).To(Equal([]expr.Any{
+			&expr.Payload{DestRegister: 8, Base: expr.PayloadBaseNetworkHeader, Offset: 16, Len: 4},
+			&expr.Meta{Key: expr.MetaKeyL4PROTO, Register: 9},
+			&expr.Payload{DestRegister: 10, Base: expr.PayloadBaseTransportHeader, Offset: 2, Len: 2},
+			&expr.Lookup{SourceRegister: 8, SetName: "some-set"},
+			&expr.Verdict{Kind: expr.VerdictAccept},
+		}))
+	})
+
+	It("matches the owner of the socket", func() {
+		exprs, err := rules.TranslateToNFT("ip6", rules.IPTablesRule{"-m", "owner", "!", "--gid-owner", "20", "-j", "DROP"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exprs).To(Equal([]expr.Any{
+			&expr.Meta{Key: expr.MetaKeySKGID, Register: 1},
+			&expr.Cmp{Op: expr.CmpOpNeq, Register: 1, Data: binaryutil.NativeEndian.PutUint32(20)},
+			&expr.Verdict{Kind: expr.VerdictDrop},
+		}))
+	})
+
+	It("checks the ip version of addresses in an inet table", func() {
+		exprs, err := rules.TranslateToNFT("inet", rules.IPTablesRule{"-s", "10.0.0.0/8", "-d", "10.1.2.3"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exprs).To(Equal([]expr.Any{
+			&expr.Meta{Key: expr.MetaKeyNFPROTO, Register: 1},
+			&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{2}},
+			&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader, Offset: 12, Len: 4},
+			&expr.Bitwise{SourceRegister: 1, DestRegister: 1, Len: 4, Mask: []byte{255, 0, 0, 0}, Xor: []byte{0, 0, 0, 0}},
+			&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{10, 0, 0, 0}},
+			&expr.Payload{DestRegister: 1, Base: expr.PayloadBaseNetworkHeader, Offset: 16, Len: 4},
+			&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: []byte{10, 1, 2, 3}},
+		}))
+	})
+
+	DescribeTable("rejecting rules it cannot translate",
+		func(family string, rule rules.IPTablesRule, expectedErr string) {
+			_, err := rules.TranslateToNFT(family, rule)
+			Expect(err).To(MatchError(expectedErr))
+		},
+		Entry("unknown family", "bridge", rules.NewAcceptRule(), "unsupported nftables family bridge"),
+		Entry("unknown argument", "ip", rules.IPTablesRule{"--banana", "x"}, `unsupported iptables argument "--banana"`),
+		Entry("missing value", "ip", rules.IPTablesRule{"-d"}, "missing value for -d"),
+		Entry("port without protocol", "ip", rules.IPTablesRule{"--dport", "80"}, `port match requires tcp or udp, got ""`),
+		Entry("burst without limit", "ip", rules.IPTablesRule{"--limit-burst", "5"}, "--limit-burst without --limit"),
+		Entry("address of the other family", "ip6", rules.NewMarkSetRule("10.0.0.1", "A", "a-guid"),
+			"address 10.0.0.1 does not belong in an ip6 table"),
+		Entry("owner by name", "ip", rules.IPTablesRule{"--uid-owner", "vcap"}, "owner vcap is not numeric"),
+		Entry("set in an inet table", "inet", rules.NewNetOutSetRule("some-set"), "set some-set can only be matched in an ip or ip6 table"),
+		Entry("unknown reject type", "ip", rules.IPTablesRule{"--jump", "REJECT", "--reject-with", "tcp-reset"}, `unsupported reject type "tcp-reset" in an ip table`),
+		Entry("reject of the other family", "ip", rules.NewNetOutDefaultRejectIPv6Rule(), `unsupported reject type "icmp6-port-unreachable" in an ip table`),
+	)
+})
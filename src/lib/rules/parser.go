@@ -0,0 +1,438 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Option is a single flag of a rule with its values, e.g. `! -s 10.0.0.1/32`.
+type Option struct {
+	Negated bool
+	Flag    string
+	Values  []string
+}
+
+// Match is a run of options. Module is the name given to -m (or --match, as
+// recorded in Flag), or empty for the options iptables understands without
+// loading a module.
+type Match struct {
+	Flag    string
+	Module  string
+	Options []Option
+}
+
+// Target is what -j or -g (as recorded in Flag) hands the packet to.
+type Target struct {
+	Flag    string
+	Name    string
+	Options []Option
+}
+
+func (t Target) Goto() bool {
+	return t.Flag == "-g" || t.Flag == "--goto"
+}
+
+// ParsedRule is the structured form of a rule as listed by `iptables -S` or
+// `iptables-save`. It keeps the original spelling and order of the rule, so
+// it converts back to the IPTablesRule it was parsed from.
+type ParsedRule struct {
+	Chain   string
+	Matches []Match
+	// Target is nil for rules without -j or -g.
+	Target *Target
+	// TargetIndex is the number of matches that come before the target.
+	TargetIndex int
+
+	HasCounters bool
+	Packets     uint64
+	Bytes       uint64
+}
+
+type ParsedChain struct {
+	Name    string
+	Policy  string
+	Packets uint64
+	Bytes   uint64
+}
+
+type ParsedTable struct {
+	Name   string
+	Chains []ParsedChain
+	Rules  []ParsedRule
+}
+
+var baseFlags = map[string]bool{
+	"-s": true, "--source": true, "-d": true, "--destination": true,
+	"-p": true, "--protocol": true, "-i": true, "--in-interface": true,
+	"-o": true, "--out-interface": true, "-f": true, "--fragment": true,
+}
+
+// ParseRule parses a single `-A chain ...` line, optionally prefixed with
+// `[packets:bytes]` counters as written by `iptables-save -c`.
+func ParseRule(line string) (ParsedRule, error) {
+	tokens, err := tokenize(line)
+	if err != nil {
+		return ParsedRule{}, err
+	}
+
+	rule := ParsedRule{}
+	if len(tokens) > 0 && strings.HasPrefix(tokens[0], "[") {
+		rule.Packets, rule.Bytes, err = parseCounters(tokens[0])
+		if err != nil {
+			return ParsedRule{}, err
+		}
+		rule.HasCounters = true
+		tokens = tokens[1:]
+	}
+
+	if len(tokens) < 2 || (tokens[0] != "-A" && tokens[0] != "--append") {
+		return ParsedRule{}, fmt.Errorf("not an append rule: %q", line)
+	}
+	rule.Chain = tokens[1]
+
+	spec, err := parseSpec(tokens[2:])
+	if err != nil {
+		return ParsedRule{}, err
+	}
+	spec.Chain = rule.Chain
+	if rule.HasCounters {
+		spec.HasCounters, spec.Packets, spec.Bytes = true, rule.Packets, rule.Bytes
+	}
+	return spec, nil
+}
+
+// ParseIPTablesRule parses a rule built by this package. The result has no
+// chain.
+func ParseIPTablesRule(rule IPTablesRule) (ParsedRule, error) {
+	return parseSpec(rule)
+}
+
+// ParseRules parses the rules in the output of `iptables -S`, ignoring the
+// chain and policy lines.
+func ParseRules(lines []string) ([]ParsedRule, error) {
+	parsed := []ParsedRule{}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-N ") || strings.HasPrefix(line, "-P ") {
+			continue
+		}
+		rule, err := ParseRule(line)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, rule)
+	}
+	return parsed, nil
+}
+
+// ParseSave parses the output of `iptables-save`, with or without counters.
+func ParseSave(output string) ([]ParsedTable, error) {
+	tables := []ParsedTable{}
+	var table *ParsedTable
+	for n, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "*"):
+			tables = append(tables, ParsedTable{Name: strings.TrimPrefix(line, "*")})
+			table = &tables[len(tables)-1]
+		case table == nil:
+			return nil, fmt.Errorf("line %d: %q is outside of a table", n+1, line)
+		case line == "COMMIT":
+			table = nil
+		case strings.HasPrefix(line, ":"):
+			fields := strings.Fields(strings.TrimPrefix(line, ":"))
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("line %d: bad chain %q", n+1, line)
+			}
+			chain := ParsedChain{Name: fields[0], Policy: fields[1]}
+			if len(fields) > 2 {
+				var err error
+				chain.Packets, chain.Bytes, err = parseCounters(fields[2])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %s", n+1, err)
+				}
+			}
+			table.Chains = append(table.Chains, chain)
+		default:
+			rule, err := ParseRule(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", n+1, err)
+			}
+			table.Rules = append(table.Rules, rule)
+		}
+	}
+	if table != nil {
+		return nil, fmt.Errorf("table %s is missing COMMIT", table.Name)
+	}
+	return tables, nil
+}
+
+func parseCounters(token string) (uint64, uint64, error) {
+	counters := strings.SplitN(strings.Trim(token, "[]"), ":", 2)
+	if len(counters) != 2 {
+		return 0, 0, fmt.Errorf("bad counters %q", token)
+	}
+	packets, err := strconv.ParseUint(counters[0], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("bad counters %q", token)
+	}
+	bytes, err := strconv.ParseUint(counters[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("bad counters %q", token)
+	}
+	return packets, bytes, nil
+}
+
+// tokenize splits a line on whitespace, keeping double quoted strings
+// (quotes included) together as one token.
+func tokenize(line string) ([]string, error) {
+	tokens := []string{}
+	current := ""
+	inQuotes, escaped, started := false, false, false
+	for _, c := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && inQuotes:
+			escaped = true
+		case c == '"':
+			inQuotes = !inQuotes
+		case (c == ' ' || c == '\t') && !inQuotes:
+			if started {
+				tokens = append(tokens, current)
+			}
+			current, started = "", false
+			continue
+		}
+		current += string(c)
+		started = true
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in %q", line)
+	}
+	if started {
+		tokens = append(tokens, current)
+	}
+	return tokens, nil
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isFlag tells flags like -s and --dport apart from values like -1/-1.
+func isFlag(token string) bool {
+	if len(token) == 2 {
+		return token[0] == '-' && isLetter(token[1])
+	}
+	return len(token) > 2 && strings.HasPrefix(token, "--") && isLetter(token[2])
+}
+
+func parseSpec(tokens []string) (ParsedRule, error) {
+	rule := ParsedRule{}
+	var current *Match
+	negated := false
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if token == "!" {
+			negated = true
+			continue
+		}
+		if !isFlag(token) {
+			return ParsedRule{}, fmt.Errorf("unexpected %q", token)
+		}
+
+		option := Option{Negated: negated, Flag: token, Values: []string{}}
+		negated = false
+		for i+1 < len(tokens) && tokens[i+1] != "!" && !isFlag(tokens[i+1]) {
+			i++
+			option.Values = append(option.Values, tokens[i])
+		}
+
+		switch {
+		case token == "-c" && len(option.Values) == 2:
+			// `iptables -v -S` writes the counters as an option.
+			packets, err1 := strconv.ParseUint(option.Values[0], 10, 64)
+			bytes, err2 := strconv.ParseUint(option.Values[1], 10, 64)
+			if err1 != nil || err2 != nil {
+				return ParsedRule{}, fmt.Errorf("bad counters %v", option.Values)
+			}
+			rule.HasCounters, rule.Packets, rule.Bytes = true, packets, bytes
+		case token == "-m" || token == "--match":
+			if len(option.Values) != 1 {
+				return ParsedRule{}, fmt.Errorf("%s takes a module name", token)
+			}
+			rule.Matches = append(rule.Matches, Match{Flag: token, Module: option.Values[0], Options: []Option{}})
+			current = &rule.Matches[len(rule.Matches)-1]
+		case token == "-j" || token == "--jump" || token == "-g" || token == "--goto":
+			if rule.Target != nil {
+				return ParsedRule{}, fmt.Errorf("more than one target")
+			}
+			if len(option.Values) != 1 {
+				return ParsedRule{}, fmt.Errorf("%s takes a target name", token)
+			}
+			rule.Target = &Target{Flag: token, Name: option.Values[0], Options: []Option{}}
+			rule.TargetIndex = len(rule.Matches)
+			current = nil
+		case baseFlags[token]:
+			if current == nil || current.Module != "" {
+				rule.Matches = append(rule.Matches, Match{Options: []Option{}})
+				current = &rule.Matches[len(rule.Matches)-1]
+			}
+			current.Options = append(current.Options, option)
+		case current == nil && rule.Target != nil && len(rule.Matches) == rule.TargetIndex:
+			rule.Target.Options = append(rule.Target.Options, option)
+		default:
+			if current == nil {
+				rule.Matches = append(rule.Matches, Match{Options: []Option{}})
+				current = &rule.Matches[len(rule.Matches)-1]
+			}
+			current.Options = append(current.Options, option)
+		}
+	}
+	if negated {
+		return ParsedRule{}, fmt.Errorf("dangling !")
+	}
+	return rule, nil
+}
+
+func (o Option) tokens() []string {
+	tokens := []string{}
+	if o.Negated {
+		tokens = append(tokens, "!")
+	}
+	return append(append(tokens, o.Flag), o.Values...)
+}
+
+// IPTablesRule returns the rule without its chain and counters, exactly as it
+// was spelled.
+func (r ParsedRule) IPTablesRule() IPTablesRule {
+	rule := IPTablesRule{}
+	for i, match := range r.Matches {
+		if r.Target != nil && i == r.TargetIndex {
+			rule = append(rule, r.Target.tokens()...)
+		}
+		rule = append(rule, match.tokens()...)
+	}
+	if r.Target != nil && r.TargetIndex >= len(r.Matches) {
+		rule = append(rule, r.Target.tokens()...)
+	}
+	return rule
+}
+
+func (m Match) tokens() []string {
+	tokens := []string{}
+	if m.Module != "" {
+		tokens = append(tokens, m.Flag, m.Module)
+	}
+	for _, o := range m.Options {
+		tokens = append(tokens, o.tokens()...)
+	}
+	return tokens
+}
+
+func (t Target) tokens() []string {
+	tokens := []string{t.Flag, t.Name}
+	for _, o := range t.Options {
+		tokens = append(tokens, o.tokens()...)
+	}
+	return tokens
+}
+
+// String returns the rule as an `iptables -S` line.
+func (r ParsedRule) String() string {
+	return strings.Join(append([]string{"-A", r.Chain}, r.IPTablesRule()...), " ")
+}
+
+func (r ParsedRule) Comment() string {
+	for _, m := range r.Matches {
+		for _, o := range m.Options {
+			if o.Flag == "--comment" && len(o.Values) == 1 {
+				return strings.Trim(o.Values[0], `"`)
+			}
+		}
+	}
+	return ""
+}
+
+// Equal reports whether the rules match the same packets and do the same
+// thing with them. It ignores counters, the order of matches, long and short
+// spellings of flags, and the way iptables rewrites addresses, marks and
+// connection states.
+func (r ParsedRule) Equal(other ParsedRule) bool {
+	return r.Chain == other.Chain && r.semanticKey() == other.semanticKey()
+}
+
+var canonicalFlags = map[string]string{
+	"--source":           "-s",
+	"--destination":      "-d",
+	"--protocol":         "-p",
+	"--in-interface":     "-i",
+	"--out-interface":    "-o",
+	"--fragment":         "-f",
+	"--destination-port": "--dport",
+	"--source-port":      "--sport",
+}
+
+func canonicalOption(o Option) string {
+	flag := o.Flag
+	if canonical, ok := canonicalFlags[flag]; ok {
+		flag = canonical
+	}
+	values := make([]string, len(o.Values))
+	for i, v := range o.Values {
+		switch {
+		case (flag == "-s" || flag == "-d") && !strings.Contains(v, "/"):
+			if strings.Contains(v, ":") {
+				v += "/128"
+			} else {
+				v += "/32"
+			}
+		case strings.HasPrefix(v, "0x") || strings.HasPrefix(v, "0X"):
+			v = strings.TrimSuffix(strings.ToLower(v), "/0xffffffff")
+		case flag == "--dport" || flag == "--sport":
+			bounds := strings.SplitN(v, ":", 2)
+			if len(bounds) == 2 && bounds[0] == bounds[1] {
+				v = bounds[0]
+			}
+		case flag == "--comment":
+			v = strings.Trim(v, `"`)
+		case flag == "--state" || flag == "--ctstate":
+			states := strings.Split(strings.ToUpper(v), ",")
+			sort.Strings(states)
+			v = strings.Join(states, ",")
+		}
+		values[i] = v
+	}
+	key := flag + " " + strings.Join(values, " ")
+	if o.Negated {
+		key = "! " + key
+	}
+	return key
+}
+
+func (r ParsedRule) semanticKey() string {
+	options := []string{}
+	for _, m := range r.Matches {
+		for _, o := range m.Options {
+			options = append(options, canonicalOption(o))
+		}
+	}
+	sort.Strings(options)
+
+	target := ""
+	if r.Target != nil {
+		target = "-j " + r.Target.Name
+		if r.Target.Goto() {
+			target = "-g " + r.Target.Name
+		}
+		for _, o := range r.Target.Options {
+			target += " " + canonicalOption(o)
+		}
+	}
+	return strings.Join(options, " ") + " => " + target
+}
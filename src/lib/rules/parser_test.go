@@ -0,0 +1,195 @@
+package rules_test
+
+import (
+	"lib/rules"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parser", func() {
+	Describe("ParseRule", func() {
+		It("parses the matches, target and comment of an iptables -S line", func() {
+			rule, err := rules.ParseRule(`-A netout--some-handle -d 10.0.0.1/32 ! -o eth0 -p tcp -m tcp --dport 80:90 -m comment --comment "my app" -j LOG --log-prefix "OK_some-handle "`)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(rule.Chain).To(Equal("netout--some-handle"))
+			Expect(rule.Matches).To(Equal([]rules.Match{
+				{Options: []rules.Option{
+					{Flag: "-d", Values: []string{"10.0.0.1/32"}},
+					{Negated: true, Flag: "-o", Values: []string{"eth0"}},
+					{Flag: "-p", Values: []string{"tcp"}},
+				}},
+				{Flag: "-m", Module: "tcp", Options: []rules.Option{{Flag: "--dport", Values: []string{"80:90"}}}},
+				{Flag: "-m", Module: "comment", Options: []rules.Option{{Flag: "--comment", Values: []string{`"my app"`}}}},
+			}))
+			Expect(rule.Target).To(Equal(&rules.Target{
+				Flag:    "-j",
+				Name:    "LOG",
+				Options: []rules.Option{{Flag: "--log-prefix", Values: []string{`"OK_some-handle "`}}},
+			}))
+			Expect(rule.TargetIndex).To(Equal(3))
+			Expect(rule.Comment()).To(Equal("my app"))
+			Expect(rule.HasCounters).To(BeFalse())
+		})
+
+		It("parses iptables-save counters", func() {
+			rule, err := rules.ParseRule("[12:3456] -A INPUT -j ACCEPT")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rule.HasCounters).To(BeTrue())
+			Expect(rule.Packets).To(Equal(uint64(12)))
+			Expect(rule.Bytes).To(Equal(uint64(3456)))
+			Expect(rule.String()).To(Equal("-A INPUT -j ACCEPT"))
+		})
+
+		It("parses iptables -v -S counters", func() {
+			rule, err := rules.ParseRule("-A INPUT -s 10.0.0.1/32 -c 7 420 -j ACCEPT")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rule.HasCounters).To(BeTrue())
+			Expect(rule.Packets).To(Equal(uint64(7)))
+			Expect(rule.Bytes).To(Equal(uint64(420)))
+			Expect(rule.String()).To(Equal("-A INPUT -s 10.0.0.1/32 -j ACCEPT"))
+		})
+
+		It("keeps values that look like negative numbers", func() {
+			rule, err := rules.ParseRule("-A netout -p icmp -m icmp --icmp-type -1/-1 -j ACCEPT")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rule.Matches[1].Options).To(Equal([]rules.Option{{Flag: "--icmp-type", Values: []string{"-1/-1"}}}))
+		})
+
+		DescribeTable("rejecting malformed lines",
+			func(line, expectedErr string) {
+				_, err := rules.ParseRule(line)
+				Expect(err).To(MatchError(expectedErr))
+			},
+			Entry("not an append", "-N some-chain", `not an append rule: "-N some-chain"`),
+			Entry("unterminated quote", `-A c -m comment --comment "oops`, `unterminated quote in "-A c -m comment --comment \"oops"`),
+			Entry("stray value", "-A c banana", `unexpected "banana"`),
+			Entry("two targets", "-A c -j ACCEPT -j DROP", "more than one target"),
+			Entry("module without name", "-A c -m", "-m takes a module name"),
+			Entry("dangling negation", "-A c -s 1.2.3.4 !", "dangling !"),
+			Entry("bad counters", "[x:1] -A c -j ACCEPT", `bad counters "[x:1]"`),
+		)
+	})
+
+	DescribeTable("round tripping the rule builders losslessly",
+		func(rule rules.IPTablesRule) {
+			parsed, err := rules.ParseIPTablesRule(rule)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed.IPTablesRule()).To(Equal(rule))
+		},
+		Entry("port forwarding", rules.NewPortForwardingRule(8080, 80, "10.0.0.1", "10.255.0.2")),
+		Entry("mark allow, with the comment after the target", rules.NewMarkAllowRule("10.255.0.2", "tcp", 8080, 9000, "A", "src-guid", "dst-guid")),
+		Entry("mark allow log", rules.NewMarkAllowLogRule("10.255.0.2", "udp", 8080, 8080, "A", "dst-guid", 100)),
+		Entry("default egress", rules.NewDefaultEgressRule("10.255.0.0/16", "eth0")),
+		Entry("netout icmp log", rules.NewNetOutICMPLogRule("10.0.0.1", "10.0.0.9", -1, -1, "some-log-chain")),
+		Entry("overlay allow egress", rules.NewOverlayAllowEgress("silk-vtep", "10.255.0.2")),
+		Entry("netout default non udp log", rules.NewNetOutDefaultNonUDPLogRule("some-handle")),
+		Entry("reject", rules.NewNetOutDefaultRejectRule()),
+	)
+
+	Describe("Equal", func() {
+		It("matches a built rule with the way iptables lists it", func() {
+			built, err := rules.ParseIPTablesRule(rules.NewMarkAllowRule("10.255.0.2", "tcp", 8080, 9000, "A", "src-guid", "dst-guid"))
+			Expect(err).NotTo(HaveOccurred())
+			built.Chain = "vpa--1234"
+
+			listed, err := rules.ParseRule("[3:180] -A vpa--1234 -d 10.255.0.2/32 -p tcp -m tcp --dport 8080:9000 -m mark --mark 0xa -m comment --comment src:src-guid_dst:dst-guid -j ACCEPT")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(built.Equal(listed)).To(BeTrue())
+		})
+
+		It("matches marks with and without the full mask", func() {
+			a, _ := rules.ParseRule("-A c -j MARK --set-xmark 0xA")
+			b, _ := rules.ParseRule("-A c -j MARK --set-xmark 0xa/0xffffffff")
+			Expect(a.Equal(b)).To(BeTrue())
+		})
+
+		It("matches connection states in the order iptables lists them", func() {
+			built, err := rules.ParseIPTablesRule(rules.NewAcceptExistingLocalRule())
+			Expect(err).NotTo(HaveOccurred())
+			built.Chain = "INPUT"
+
+			listed, err := rules.ParseRule("-A INPUT -m state --state RELATED,ESTABLISHED -j ACCEPT")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(built.Equal(listed)).To(BeTrue())
+
+			listed, err = rules.ParseRule("-A INPUT -m state --state ESTABLISHED -j ACCEPT")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(built.Equal(listed)).To(BeFalse())
+		})
+
+		DescribeTable("telling different rules apart",
+			func(a, b string) {
+				ruleA, err := rules.ParseRule(a)
+				Expect(err).NotTo(HaveOccurred())
+				ruleB, err := rules.ParseRule(b)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ruleA.Equal(ruleB)).To(BeFalse())
+			},
+			Entry("chain", "-A a -j ACCEPT", "-A b -j ACCEPT"),
+			Entry("negation", "-A c -s 10.0.0.1 -j ACCEPT", "-A c ! -s 10.0.0.1 -j ACCEPT"),
+			Entry("target", "-A c -j ACCEPT", "-A c -j DROP"),
+			Entry("goto and jump", "-A c -j other", "-A c -g other"),
+			Entry("match value", "-A c --dport 80 -j ACCEPT", "-A c --dport 81 -j ACCEPT"),
+		)
+	})
+
+	Describe("ParseRules", func() {
+		It("skips the chain and policy lines of iptables -S", func() {
+			parsed, err := rules.ParseRules([]string{"-P FORWARD ACCEPT", "-N some-chain", "-A FORWARD -j some-chain", ""})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed).To(HaveLen(1))
+			Expect(parsed[0].String()).To(Equal("-A FORWARD -j some-chain"))
+		})
+	})
+
+	Describe("ParseSave", func() {
+		It("parses tables, chains and rules with their counters", func() {
+			tables, err := rules.ParseSave(`# Generated by iptables-save
+*nat
+:PREROUTING ACCEPT [10:600]
+:POSTROUTING ACCEPT [0:0]
+[2:120] -A POSTROUTING -s 10.255.0.0/16 ! -o silk-vtep -j MASQUERADE
+COMMIT
+*filter
+:FORWARD DROP [0:0]
+:netout--x - [0:0]
+-A FORWARD -j netout--x
+COMMIT
+`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tables).To(HaveLen(2))
+
+			Expect(tables[0].Name).To(Equal("nat"))
+			Expect(tables[0].Chains).To(Equal([]rules.ParsedChain{
+				{Name: "PREROUTING", Policy: "ACCEPT", Packets: 10, Bytes: 600},
+				{Name: "POSTROUTING", Policy: "ACCEPT"},
+			}))
+			Expect(tables[0].Rules).To(HaveLen(1))
+			Expect(tables[0].Rules[0].Packets).To(Equal(uint64(2)))
+			Expect(tables[0].Rules[0].String()).To(Equal("-A POSTROUTING -s 10.255.0.0/16 ! -o silk-vtep -j MASQUERADE"))
+
+			Expect(tables[1].Name).To(Equal("filter"))
+			Expect(tables[1].Chains[1]).To(Equal(rules.ParsedChain{Name: "netout--x", Policy: "-"}))
+			Expect(tables[1].Rules[0].Target.Name).To(Equal("netout--x"))
+		})
+
+		It("reports the line of a bad rule", func() {
+			_, err := rules.ParseSave("*filter\n-A c banana\nCOMMIT\n")
+			Expect(err).To(MatchError(`line 2: unexpected "banana"`))
+		})
+
+		It("requires every table to be committed", func() {
+			_, err := rules.ParseSave("*filter\n-A c -j ACCEPT\n")
+			Expect(err).To(MatchError("table filter is missing COMMIT"))
+		})
+
+		It("rejects rules outside of a table", func() {
+			_, err := rules.ParseSave("-A c -j ACCEPT\n")
+			Expect(err).To(MatchError(`line 1: "-A c -j ACCEPT" is outside of a table`))
+		})
+	})
+})
@@ -0,0 +1,285 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+)
+
+const (
+	defaultPlannerChainPrefix           = "vpa--"
+	defaultPlannerAcceptedUDPLogsPerSec = 100
+)
+
+// PolicyContainer is what the planner knows about a container on the cell.
+// The space and org are only needed for egress policies with those sources.
+type PolicyContainer struct {
+	AppGUID   string `json:"app_guid"`
+	SpaceGUID string `json:"space_guid"`
+	OrgGUID   string `json:"org_guid"`
+}
+
+// UnmarshalJSON also accepts a bare app guid, for containers whose space and
+// org are not known.
+func (c *PolicyContainer) UnmarshalJSON(data []byte) error {
+	var appGUID string
+	if err := json.Unmarshal(data, &appGUID); err == nil {
+		*c = PolicyContainer{AppGUID: appGUID}
+		return nil
+	}
+
+	type container PolicyContainer
+	return json.Unmarshal(data, (*container)(c))
+}
+
+type C2CPolicy struct {
+	SourceAppGUID      string
+	SourceTag          string
+	DestinationAppGUID string
+	Protocol           string
+	StartPort          int
+	EndPort            int
+	Log                bool
+}
+
+type PortRange struct {
+	Start int
+	End   int
+}
+
+// EgressPolicy allows the containers of a source to reach the IP ranges.
+// SourceType is app (or empty), space, org or default, which applies to every
+// container. Ports are only used for tcp and udp, the ICMP type and code only
+// for icmp.
+type EgressPolicy struct {
+	ID         string
+	SourceType string
+	SourceID   string
+	Protocol   string
+	IPRanges   []IPRange
+	Ports      []PortRange
+	ICMPType   int
+	ICMPCode   int
+	Log        bool
+}
+
+type IPRange struct {
+	Start string
+	End   string
+}
+
+type SkippedEgressPolicy struct {
+	ID     string
+	Reason string
+}
+
+// PolicyPlan holds the chains for iptables and for ip6tables. Each list
+// starts with the tag, c2c and egress chains, followed by the log chains the
+// egress chain jumps to.
+type PolicyPlan struct {
+	IPv4Chains []CompiledChain
+	IPv6Chains []CompiledChain
+	Skipped    []SkippedEgressPolicy
+}
+
+// PolicyPlanner turns policies into the filter chains a cell should have for
+// the containers running on it.
+type PolicyPlanner struct {
+	ChainPrefix           string
+	AcceptedUDPLogsPerSec int
+}
+
+type plannedChains struct {
+	tag, c2c, egress CompiledChain
+	logs             []CompiledChain
+}
+
+func (p *plannedChains) list() []CompiledChain {
+	return append([]CompiledChain{p.tag, p.c2c, p.egress}, p.logs...)
+}
+
+// Plan returns the chains for the policies. A rule goes into the IPv4 or the
+// IPv6 chains by the family of its container or destination addresses. Egress
+// policies that cannot be planned are returned in the plan, as are IP ranges
+// that none of the source containers have an address of the same family for.
+func (p PolicyPlanner) Plan(c2cPolicies []C2CPolicy, egressPolicies []EgressPolicy, containersByIP map[string]PolicyContainer) (PolicyPlan, error) {
+	prefix := p.ChainPrefix
+	if prefix == "" {
+		prefix = defaultPlannerChainPrefix
+	}
+	logsPerSec := p.AcceptedUDPLogsPerSec
+	if logsPerSec == 0 {
+		logsPerSec = defaultPlannerAcceptedUDPLogsPerSec
+	}
+
+	containerIPs := []string{}
+	for ip := range containersByIP {
+		containerIPs = append(containerIPs, ip)
+	}
+	sort.Strings(containerIPs)
+	ipsByApp := map[string][]string{}
+	ipsBySpace := map[string][]string{}
+	ipsByOrg := map[string][]string{}
+	for _, ip := range containerIPs {
+		container := containersByIP[ip]
+		ipsByApp[container.AppGUID] = append(ipsByApp[container.AppGUID], ip)
+		if container.SpaceGUID != "" {
+			ipsBySpace[container.SpaceGUID] = append(ipsBySpace[container.SpaceGUID], ip)
+		}
+		if container.OrgGUID != "" {
+			ipsByOrg[container.OrgGUID] = append(ipsByOrg[container.OrgGUID], ip)
+		}
+	}
+
+	families := map[bool]*plannedChains{}
+	for _, ipv6 := range []bool{false, true} {
+		families[ipv6] = &plannedChains{
+			tag:    CompiledChain{Name: prefix + "tag", Rules: []IPTablesRule{}},
+			c2c:    CompiledChain{Name: prefix + "c2c", Rules: []IPTablesRule{}},
+			egress: CompiledChain{Name: prefix + "egress", Rules: []IPTablesRule{}},
+			logs:   []CompiledChain{},
+		}
+	}
+	skipped := []SkippedEgressPolicy{}
+
+	tagged := map[string]bool{}
+	for _, policy := range c2cPolicies {
+		for _, ip := range ipsByApp[policy.SourceAppGUID] {
+			if tagged[ip] {
+				continue
+			}
+			tagged[ip] = true
+			chains := families[isIPv6(ip)]
+			chains.tag.Rules = append(chains.tag.Rules, NewMarkSetRule(ip, policy.SourceTag, policy.SourceAppGUID))
+		}
+
+		for _, ip := range ipsByApp[policy.DestinationAppGUID] {
+			chains := families[isIPv6(ip)]
+			if policy.Log {
+				chains.c2c.Rules = append(chains.c2c.Rules, NewMarkAllowLogRule(ip, policy.Protocol,
+					policy.StartPort, policy.EndPort, policy.SourceTag, policy.DestinationAppGUID, logsPerSec))
+			}
+			chains.c2c.Rules = append(chains.c2c.Rules, NewMarkAllowRule(ip, policy.Protocol,
+				policy.StartPort, policy.EndPort, policy.SourceTag, policy.SourceAppGUID, policy.DestinationAppGUID))
+		}
+	}
+
+	logChains := map[bool]map[string]bool{false: {}, true: {}}
+	for _, policy := range egressPolicies {
+		var sourceIPs []string
+		switch policy.SourceType {
+		case "", "app":
+			sourceIPs = ipsByApp[policy.SourceID]
+		case "space":
+			sourceIPs = ipsBySpace[policy.SourceID]
+		case "org":
+			sourceIPs = ipsByOrg[policy.SourceID]
+		case "default":
+			sourceIPs = containerIPs
+		default:
+			skipped = append(skipped, SkippedEgressPolicy{
+				ID:     policy.ID,
+				Reason: fmt.Sprintf("unsupported source type '%s'", policy.SourceType),
+			})
+			continue
+		}
+
+		for _, ipRange := range policy.IPRanges {
+			start, end := net.ParseIP(ipRange.Start), net.ParseIP(ipRange.End)
+			if start == nil || end == nil || (start.To4() == nil) != (end.To4() == nil) {
+				return PolicyPlan{}, fmt.Errorf("egress policy %s: bad ip range %s-%s", policy.ID, ipRange.Start, ipRange.End)
+			}
+			ipv6 := start.To4() == nil
+			chains := families[ipv6]
+
+			sameFamilyIPs := []string{}
+			for _, ip := range sourceIPs {
+				if isIPv6(ip) == ipv6 {
+					sameFamilyIPs = append(sameFamilyIPs, ip)
+				}
+			}
+			if len(sourceIPs) > 0 && len(sameFamilyIPs) == 0 {
+				skipped = append(skipped, SkippedEgressPolicy{
+					ID:     policy.ID,
+					Reason: fmt.Sprintf("no source container has an address of the family of %s-%s", ipRange.Start, ipRange.End),
+				})
+				continue
+			}
+
+			for _, sourceIP := range sameFamilyIPs {
+				logChain := ""
+				if policy.Log {
+					appGUID := containersByIP[sourceIP].AppGUID
+					name, err := ChainName(prefix+"log--", appGUID)
+					if err != nil {
+						return PolicyPlan{}, fmt.Errorf("egress policy %s: %s", policy.ID, err)
+					}
+					if !logChains[ipv6][name] {
+						logChains[ipv6][name] = true
+						chains.logs = append(chains.logs, CompiledChain{
+							Name: name,
+							Rules: []IPTablesRule{
+								NewNetOutDefaultNonUDPLogRule(appGUID),
+								NewNetOutDefaultUDPLogRule(appGUID, logsPerSec),
+								NewAcceptRule(),
+							},
+						})
+					}
+					logChain = name
+				}
+
+				for _, rule := range netOutRules(ipRange, policy, ipv6, logChain) {
+					chains.egress.Rules = append(chains.egress.Rules, append(IPTablesRule{"-s", sourceIP}, rule...))
+				}
+			}
+		}
+	}
+
+	return PolicyPlan{
+		IPv4Chains: families[false].list(),
+		IPv6Chains: families[true].list(),
+		Skipped:    skipped,
+	}, nil
+}
+
+// netOutRules returns rules that accept the traffic or, with a log chain, go
+// to it.
+func netOutRules(ipRange IPRange, policy EgressPolicy, ipv6 bool, logChain string) []IPTablesRule {
+	start, end := ipRange.Start, ipRange.End
+	switch policy.Protocol {
+	case "icmp":
+		switch {
+		case ipv6 && logChain != "":
+			return []IPTablesRule{NewNetOutICMPv6LogRule(start, end, policy.ICMPType, policy.ICMPCode, logChain)}
+		case ipv6:
+			return []IPTablesRule{NewNetOutICMPv6Rule(start, end, policy.ICMPType, policy.ICMPCode)}
+		case logChain != "":
+			return []IPTablesRule{NewNetOutICMPLogRule(start, end, policy.ICMPType, policy.ICMPCode, logChain)}
+		}
+		return []IPTablesRule{NewNetOutICMPRule(start, end, policy.ICMPType, policy.ICMPCode)}
+	case "tcp", "udp":
+		ports := policy.Ports
+		if len(ports) == 0 {
+			ports = []PortRange{{Start: 1, End: 65535}}
+		}
+		netOut := []IPTablesRule{}
+		for _, p := range ports {
+			if logChain != "" {
+				netOut = append(netOut, NewNetOutWithPortsLogRule(start, end, p.Start, p.End, policy.Protocol, logChain))
+				continue
+			}
+			netOut = append(netOut, NewNetOutWithPortsRule(start, end, p.Start, p.End, policy.Protocol))
+		}
+		return netOut
+	}
+	if logChain != "" {
+		return []IPTablesRule{NewNetOutLogRule(start, end, logChain)}
+	}
+	return []IPTablesRule{NewNetOutRule(start, end)}
+}
+
+func isIPv6(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && parsed.To4() == nil
+}
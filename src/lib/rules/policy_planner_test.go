@@ -0,0 +1,242 @@
+package rules_test
+
+import (
+	"encoding/json"
+	"lib/rules"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PolicyPlanner", func() {
+	var (
+		planner        rules.PolicyPlanner
+		c2cPolicies    []rules.C2CPolicy
+		egressPolicies []rules.EgressPolicy
+		containersByIP map[string]rules.PolicyContainer
+		logChain       string
+	)
+
+	BeforeEach(func() {
+		planner = rules.PolicyPlanner{}
+		c2cPolicies = []rules.C2CPolicy{
+			{SourceAppGUID: "app-a", SourceTag: "000A", DestinationAppGUID: "app-b", Protocol: "tcp", StartPort: 8080, EndPort: 8080},
+			{SourceAppGUID: "app-a", SourceTag: "000A", DestinationAppGUID: "app-remote", Protocol: "udp", StartPort: 53, EndPort: 53},
+			{SourceAppGUID: "app-remote", SourceTag: "000C", DestinationAppGUID: "app-b", Protocol: "udp", StartPort: 9000, EndPort: 9001, Log: true},
+		}
+		egressPolicies = []rules.EgressPolicy{
+			{
+				ID: "egress-1", SourceType: "app", SourceID: "app-a", Protocol: "tcp",
+				IPRanges: []rules.IPRange{{Start: "10.1.0.0", End: "10.1.0.255"}},
+				Ports:    []rules.PortRange{{Start: 443, End: 443}},
+				Log:      true,
+			},
+			{
+				ID: "egress-2", SourceType: "default", SourceID: "default-guid", Protocol: "icmp",
+				IPRanges: []rules.IPRange{{Start: "8.8.8.8", End: "8.8.8.8"}},
+				ICMPType: 8, ICMPCode: -1,
+			},
+			{
+				ID: "egress-3", SourceType: "space", SourceID: "some-space", Protocol: "all",
+				IPRanges: []rules.IPRange{{Start: "1.1.1.1", End: "1.1.1.1"}},
+			},
+			{
+				ID: "egress-4", SourceType: "org", SourceID: "some-org", Protocol: "udp",
+				IPRanges: []rules.IPRange{{Start: "9.9.9.9", End: "9.9.9.9"}},
+				Ports:    []rules.PortRange{{Start: 53, End: 53}},
+			},
+			{
+				ID: "egress-5", SourceType: "isolation-segment", SourceID: "some-iso-seg", Protocol: "all",
+				IPRanges: []rules.IPRange{{Start: "2.2.2.2", End: "2.2.2.2"}},
+			},
+		}
+		containersByIP = map[string]rules.PolicyContainer{
+			"10.255.0.3": {AppGUID: "app-b", SpaceGUID: "space-b", OrgGUID: "some-org"},
+			"10.255.0.2": {AppGUID: "app-a", SpaceGUID: "some-space", OrgGUID: "some-org"},
+		}
+
+		var err error
+		logChain, err = rules.ChainName("vpa--log--", "app-a")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("plans the tag, c2c, egress and log chains for the local containers", func() {
+		plan, err := planner.Plan(c2cPolicies, egressPolicies, containersByIP)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.Skipped).To(Equal([]rules.SkippedEgressPolicy{
+			{ID: "egress-5", Reason: "unsupported source type 'isolation-segment'"},
+		}))
+
+		Expect(plan.IPv4Chains).To(Equal([]rules.CompiledChain{
+			{
+				Name: "vpa--tag",
+				Rules: []rules.IPTablesRule{
+					rules.NewMarkSetRule("10.255.0.2", "000A", "app-a"),
+				},
+			},
+			{
+				Name: "vpa--c2c",
+				Rules: []rules.IPTablesRule{
+					rules.NewMarkAllowRule("10.255.0.3", "tcp", 8080, 8080, "000A", "app-a", "app-b"),
+					rules.NewMarkAllowLogRule("10.255.0.3", "udp", 9000, 9001, "000C", "app-b", 100),
+					rules.NewMarkAllowRule("10.255.0.3", "udp", 9000, 9001, "000C", "app-remote", "app-b"),
+				},
+			},
+			{
+				Name: "vpa--egress",
+				Rules: []rules.IPTablesRule{
+					append(rules.IPTablesRule{"-s", "10.255.0.2"}, rules.NewNetOutWithPortsLogRule("10.1.0.0", "10.1.0.255", 443, 443, "tcp", logChain)...),
+					append(rules.IPTablesRule{"-s", "10.255.0.2"}, rules.NewNetOutICMPRule("8.8.8.8", "8.8.8.8", 8, -1)...),
+					append(rules.IPTablesRule{"-s", "10.255.0.3"}, rules.NewNetOutICMPRule("8.8.8.8", "8.8.8.8", 8, -1)...),
+					append(rules.IPTablesRule{"-s", "10.255.0.2"}, rules.NewNetOutRule("1.1.1.1", "1.1.1.1")...),
+					append(rules.IPTablesRule{"-s", "10.255.0.2"}, rules.NewNetOutWithPortsRule("9.9.9.9", "9.9.9.9", 53, 53, "udp")...),
+					append(rules.IPTablesRule{"-s", "10.255.0.3"}, rules.NewNetOutWithPortsRule("9.9.9.9", "9.9.9.9", 53, 53, "udp")...),
+				},
+			},
+			{
+				Name: logChain,
+				Rules: []rules.IPTablesRule{
+					rules.NewNetOutDefaultNonUDPLogRule("app-a"),
+					rules.NewNetOutDefaultUDPLogRule("app-a", 100),
+					rules.NewAcceptRule(),
+				},
+			},
+		}))
+		Expect(plan.IPv6Chains).To(Equal([]rules.CompiledChain{
+			{Name: "vpa--tag", Rules: []rules.IPTablesRule{}},
+			{Name: "vpa--c2c", Rules: []rules.IPTablesRule{}},
+			{Name: "vpa--egress", Rules: []rules.IPTablesRule{}},
+		}))
+	})
+
+	It("uses the configured chain prefix and udp log rate", func() {
+		planner = rules.PolicyPlanner{ChainPrefix: "cell--", AcceptedUDPLogsPerSec: 7}
+		plan, err := planner.Plan(c2cPolicies, egressPolicies, containersByIP)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(plan.IPv4Chains[0].Name).To(Equal("cell--tag"))
+		Expect(plan.IPv4Chains[1].Rules[1]).To(Equal(rules.NewMarkAllowLogRule("10.255.0.3", "udp", 9000, 9001, "000C", "app-b", 7)))
+		Expect(plan.IPv4Chains[3].Name).To(HavePrefix("cell--log--"))
+		Expect(plan.IPv4Chains[3].Rules[1]).To(Equal(rules.NewNetOutDefaultUDPLogRule("app-a", 7)))
+	})
+
+	It("plans rules that enforce the policies", func() {
+		plan, err := planner.Plan(c2cPolicies, egressPolicies, containersByIP)
+		Expect(err).NotTo(HaveOccurred())
+
+		simulator := rules.NewSimulator()
+		for _, chain := range plan.IPv4Chains {
+			Expect(simulator.Load("filter", chain.Name, chain.Rules...)).To(Succeed())
+		}
+		Expect(simulator.Load("filter", "FORWARD",
+			rules.IPTablesRule{"-j", "vpa--tag"},
+			rules.IPTablesRule{"-j", "vpa--c2c"},
+			rules.IPTablesRule{"-j", "vpa--egress"},
+			rules.IPTablesRule{"-j", "REJECT"},
+		)).To(Succeed())
+
+		verdict, err := simulator.Evaluate("filter", "FORWARD", rules.Packet{
+			SourceIP: "10.255.0.2", DestinationIP: "10.255.0.3", Protocol: "tcp", DestinationPort: 8080,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(verdict.Target).To(Equal("ACCEPT"))
+
+		verdict, err = simulator.Evaluate("filter", "FORWARD", rules.Packet{
+			SourceIP: "10.255.0.2", DestinationIP: "10.1.0.7", Protocol: "tcp", DestinationPort: 443,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(verdict.Target).To(Equal("ACCEPT"))
+		Expect(verdict.LogPrefixes).To(Equal([]string{"OK_app-a "}))
+
+		verdict, err = simulator.Evaluate("filter", "FORWARD", rules.Packet{
+			SourceIP: "10.255.0.3", DestinationIP: "10.1.0.7", Protocol: "tcp", DestinationPort: 443,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(verdict.Target).To(Equal("REJECT"))
+	})
+
+	Context("when there are IPv6 containers and destinations", func() {
+		BeforeEach(func() {
+			containersByIP["fd00::2"] = rules.PolicyContainer{AppGUID: "app-c", SpaceGUID: "some-space"}
+			c2cPolicies = append(c2cPolicies, rules.C2CPolicy{
+				SourceAppGUID: "app-a", SourceTag: "000A", DestinationAppGUID: "app-c", Protocol: "tcp", StartPort: 80, EndPort: 80,
+			})
+			egressPolicies = []rules.EgressPolicy{
+				{
+					ID: "egress-6", SourceType: "space", SourceID: "some-space", Protocol: "icmp",
+					IPRanges: []rules.IPRange{{Start: "2001:db8::", End: "2001:db8::ff"}, {Start: "1.1.1.1", End: "1.1.1.1"}},
+					ICMPType: 128, ICMPCode: -1,
+					Log: true,
+				},
+				{
+					ID: "egress-7", SourceType: "app", SourceID: "app-a", Protocol: "all",
+					IPRanges: []rules.IPRange{{Start: "2001:db8::1", End: "2001:db8::1"}},
+				},
+			}
+		})
+
+		It("plans them into the IPv6 chains", func() {
+			plan, err := planner.Plan(c2cPolicies, egressPolicies, containersByIP)
+			Expect(err).NotTo(HaveOccurred())
+
+			appCLogChain, err := rules.ChainName("vpa--log--", "app-c")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(plan.IPv6Chains).To(Equal([]rules.CompiledChain{
+				{Name: "vpa--tag", Rules: []rules.IPTablesRule{}},
+				{
+					Name: "vpa--c2c",
+					Rules: []rules.IPTablesRule{
+						rules.NewMarkAllowRule("fd00::2", "tcp", 80, 80, "000A", "app-a", "app-c"),
+					},
+				},
+				{
+					Name: "vpa--egress",
+					Rules: []rules.IPTablesRule{
+						append(rules.IPTablesRule{"-s", "fd00::2"}, rules.NewNetOutICMPv6LogRule("2001:db8::", "2001:db8::ff", 128, -1, appCLogChain)...),
+					},
+				},
+				{
+					Name: appCLogChain,
+					Rules: []rules.IPTablesRule{
+						rules.NewNetOutDefaultNonUDPLogRule("app-c"),
+						rules.NewNetOutDefaultUDPLogRule("app-c", 100),
+						rules.NewAcceptRule(),
+					},
+				},
+			}))
+
+			Expect(plan.IPv4Chains[2].Rules).To(Equal([]rules.IPTablesRule{
+				append(rules.IPTablesRule{"-s", "10.255.0.2"}, rules.NewNetOutICMPLogRule("1.1.1.1", "1.1.1.1", 128, -1, logChain)...),
+			}))
+		})
+
+		It("skips the ranges that no source container can reach", func() {
+			plan, err := planner.Plan(c2cPolicies, egressPolicies, containersByIP)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(plan.Skipped).To(Equal([]rules.SkippedEgressPolicy{
+				{ID: "egress-7", Reason: "no source container has an address of the family of 2001:db8::1-2001:db8::1"},
+			}))
+		})
+	})
+
+	It("fails on bad ip ranges", func() {
+		egressPolicies[0].IPRanges[0].End = "2001:db8::1"
+		_, err := planner.Plan(c2cPolicies, egressPolicies, containersByIP)
+		Expect(err).To(MatchError("egress policy egress-1: bad ip range 10.1.0.0-2001:db8::1"))
+	})
+
+	Describe("PolicyContainer", func() {
+		It("decodes a bare app guid or an object", func() {
+			containers := map[string]rules.PolicyContainer{}
+			err := json.Unmarshal([]byte(`{
+				"10.255.0.2": "app-a",
+				"10.255.0.3": {"app_guid": "app-b", "space_guid": "space-b", "org_guid": "org-b"}
+			}`), &containers)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(containers).To(Equal(map[string]rules.PolicyContainer{
+				"10.255.0.2": {AppGUID: "app-a"},
+				"10.255.0.3": {AppGUID: "app-b", SpaceGUID: "space-b", OrgGUID: "org-b"},
+			}))
+		})
+	})
+})
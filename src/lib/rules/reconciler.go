@@ -0,0 +1,136 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ReconcileReport struct {
+	Added   []string
+	Removed []string
+}
+
+func (r ReconcileReport) Changed() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0
+}
+
+// Reconciler makes an iptables chain match a desired list of rules. It keeps
+// the rules that are already in the right order and only deletes and inserts
+// around them, all in a single iptables-restore payload. The lock is held
+// from listing the chain to restoring it, since rules are deleted by their
+// position in the chain. IPTables must not take the lock itself.
+type Reconciler struct {
+	IPTables iptables
+	Locker   locker
+	Restorer restorer
+}
+
+func (r *Reconciler) Reconcile(table, chain string, desired []IPTablesRule) (ReconcileReport, error) {
+	if err := r.Locker.Lock(); err != nil {
+		return ReconcileReport{}, fmt.Errorf("lock: %s", err)
+	}
+
+	listed, err := r.IPTables.List(table, chain)
+	if err != nil {
+		return ReconcileReport{}, handleIPTablesError(fmt.Errorf("listing chain: %s", err), r.Locker.Unlock())
+	}
+
+	actual := []string{}
+	prefix := fmt.Sprintf("-A %s ", chain)
+	for _, line := range listed {
+		if strings.HasPrefix(line, prefix) {
+			actual = append(actual, strings.TrimPrefix(line, prefix))
+		}
+	}
+
+	wanted := []string{}
+	for _, rule := range desired {
+		wanted = append(wanted, strings.Join(rule, " "))
+	}
+
+	keepActual, keepWanted := longestCommonSubsequence(actual, wanted)
+
+	report := ReconcileReport{}
+	input := []string{fmt.Sprintf("*%s\n", table)}
+	for i := len(actual) - 1; i >= 0; i-- {
+		if !keepActual[i] {
+			input = append(input, fmt.Sprintf("-D %s %d\n", chain, i+1))
+			report.Removed = append([]string{actual[i]}, report.Removed...)
+		}
+	}
+	for i, rule := range wanted {
+		if !keepWanted[i] {
+			input = append(input, fmt.Sprintf("-I %s %d %s\n", chain, i+1, rule))
+			report.Added = append(report.Added, rule)
+		}
+	}
+	input = append(input, "COMMIT\n")
+
+	if !report.Changed() {
+		return report, r.Locker.Unlock()
+	}
+
+	err = r.Restorer.Restore(strings.Join(input, ""))
+	if err != nil {
+		return ReconcileReport{}, handleIPTablesError(err, r.Locker.Unlock())
+	}
+
+	return report, r.Locker.Unlock()
+}
+
+// longestCommonSubsequence marks the rules of a and b that belong to their
+// longest common subsequence, comparing rules by meaning rather than spelling.
+func longestCommonSubsequence(a, b []string) ([]bool, []bool) {
+	canonicalA, canonicalB := make([]string, len(a)), make([]string, len(b))
+	for i := range a {
+		canonicalA[i] = semanticKey(a[i])
+	}
+	for j := range b {
+		canonicalB[j] = semanticKey(b[j])
+	}
+
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if canonicalA[i] == canonicalB[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	keepA, keepB := make([]bool, len(a)), make([]bool, len(b))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case canonicalA[i] == canonicalB[j]:
+			keepA[i], keepB[j] = true, true
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return keepA, keepB
+}
+
+// semanticKey lets rules that iptables spells differently from the way this
+// package built them compare equal.
+func semanticKey(rule string) string {
+	tokens, err := tokenize(rule)
+	if err != nil {
+		return rule
+	}
+	parsed, err := parseSpec(tokens)
+	if err != nil {
+		return rule
+	}
+	return parsed.semanticKey()
+}
@@ -0,0 +1,180 @@
+package rules_test
+
+import (
+	"errors"
+	"lib/fakes"
+	"lib/rules"
+	"lib/testsupport"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reconciler", func() {
+	var (
+		reconciler *rules.Reconciler
+		ipt        *fakes.IPTables
+		lock       *fakes.Locker
+		restorer   *fakes.Restorer
+	)
+
+	BeforeEach(func() {
+		ipt = &fakes.IPTables{}
+		lock = &fakes.Locker{}
+		restorer = &fakes.Restorer{}
+		reconciler = &rules.Reconciler{
+			IPTables: ipt,
+			Locker:   lock,
+			Restorer: restorer,
+		}
+		ipt.ListReturns([]string{
+			"-N some-chain",
+			"-A some-chain -s 10.0.0.1/32 -j ACCEPT",
+			"-A some-chain -s 10.0.0.2/32 -j ACCEPT",
+			"-A some-chain -s 10.0.0.3/32 -j ACCEPT",
+		}, nil)
+	})
+
+	It("deletes and inserts only the rules that differ, in one restore", func() {
+		report, err := reconciler.Reconcile("filter", "some-chain", []rules.IPTablesRule{
+			{"--source", "10.0.0.1", "--jump", "ACCEPT"},
+			{"--source", "10.0.0.9", "--jump", "ACCEPT"},
+			{"--source", "10.0.0.3", "--jump", "ACCEPT"},
+			{"--jump", "REJECT"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		table, chain := ipt.ListArgsForCall(0)
+		Expect(table).To(Equal("filter"))
+		Expect(chain).To(Equal("some-chain"))
+
+		Expect(lock.LockCallCount()).To(Equal(1))
+		Expect(lock.UnlockCallCount()).To(Equal(1))
+		Expect(restorer.RestoreCallCount()).To(Equal(1))
+		Expect(restorer.RestoreArgsForCall(0)).To(Equal("*filter\n" +
+			"-D some-chain 2\n" +
+			"-I some-chain 2 --source 10.0.0.9 --jump ACCEPT\n" +
+			"-I some-chain 4 --jump REJECT\n" +
+			"COMMIT\n"))
+
+		Expect(report).To(Equal(rules.ReconcileReport{
+			Added:   []string{"--source 10.0.0.9 --jump ACCEPT", "--jump REJECT"},
+			Removed: []string{"-s 10.0.0.2/32 -j ACCEPT"},
+		}))
+		Expect(report.Changed()).To(BeTrue())
+	})
+
+	It("lists the chain while holding the lock", func() {
+		ipt.ListStub = func(string, string) ([]string, error) {
+			Expect(lock.LockCallCount()).To(Equal(1))
+			Expect(lock.UnlockCallCount()).To(Equal(0))
+			return []string{"-N some-chain"}, nil
+		}
+
+		_, err := reconciler.Reconcile("filter", "some-chain", []rules.IPTablesRule{{"--jump", "REJECT"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lock.LockCallCount()).To(Equal(1))
+		Expect(lock.UnlockCallCount()).To(Equal(1))
+	})
+
+	It("deletes from the bottom of the chain up so the rule numbers stay valid", func() {
+		_, err := reconciler.Reconcile("filter", "some-chain", []rules.IPTablesRule{
+			{"--source", "10.0.0.2", "--jump", "ACCEPT"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(restorer.RestoreArgsForCall(0)).To(Equal("*filter\n" +
+			"-D some-chain 3\n" +
+			"-D some-chain 1\n" +
+			"COMMIT\n"))
+	})
+
+	Context("when the chain already matches", func() {
+		It("does not restore anything", func() {
+			report, err := reconciler.Reconcile("filter", "some-chain", []rules.IPTablesRule{
+				{"-s", "10.0.0.1/32", "-j", "ACCEPT"},
+				{"--source", "10.0.0.2", "-j", "ACCEPT"},
+				{"-s", "10.0.0.3", "--jump", "ACCEPT"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Changed()).To(BeFalse())
+			Expect(lock.LockCallCount()).To(Equal(1))
+			Expect(lock.UnlockCallCount()).To(Equal(1))
+			Expect(restorer.RestoreCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when iptables lists the connection states in another order", func() {
+		It("does not restore anything", func() {
+			ipt.ListReturns([]string{
+				"-N some-chain",
+				"-A some-chain -m state --state RELATED,ESTABLISHED -j ACCEPT",
+			}, nil)
+
+			report, err := reconciler.Reconcile("filter", "some-chain", []rules.IPTablesRule{
+				rules.NewAcceptExistingLocalRule(),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Changed()).To(BeFalse())
+			Expect(restorer.RestoreCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when listing the chain fails", func() {
+		It("returns an error", func() {
+			ipt.ListReturns(nil, errors.New("banana"))
+			_, err := reconciler.Reconcile("filter", "some-chain", nil)
+			Expect(err).To(MatchError("iptables call: listing chain: banana and unlock: <nil>"))
+		})
+	})
+
+	Context("when the lock fails", func() {
+		It("returns an error", func() {
+			lock.LockReturns(errors.New("banana"))
+			_, err := reconciler.Reconcile("filter", "some-chain", nil)
+			Expect(err).To(MatchError("lock: banana"))
+			Expect(ipt.ListCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the restore fails", func() {
+		It("returns an error", func() {
+			restorer.RestoreReturns(errors.New("banana"))
+			_, err := reconciler.Reconcile("filter", "some-chain", nil)
+			Expect(err).To(MatchError("iptables call: banana and unlock: <nil>"))
+		})
+	})
+
+	Context("against an in-memory iptables", func() {
+		It("converges the chain on the desired rules", func() {
+			memory := testsupport.NewInMemoryIPTables()
+			Expect(memory.NewChain("filter", "some-chain")).To(Succeed())
+			Expect(memory.Restore("*filter\n" +
+				"-A some-chain c\n" +
+				"-A some-chain a\n" +
+				"-A some-chain x\n" +
+				"-A some-chain b\n" +
+				"COMMIT\n")).To(Succeed())
+
+			reconciler = &rules.Reconciler{
+				IPTables: memory,
+				Locker:   lock,
+				Restorer: memory,
+			}
+			desired := []rules.IPTablesRule{{"a"}, {"b"}, {"c"}, {"d"}}
+
+			report, err := reconciler.Reconcile("filter", "some-chain", desired)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Removed).To(Equal([]string{"c", "x"}))
+			Expect(report.Added).To(Equal([]string{"c", "d"}))
+
+			listed, err := memory.List("filter", "some-chain")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listed).To(Equal([]string{"-N some-chain", "-A some-chain a", "-A some-chain b", "-A some-chain c", "-A some-chain d"}))
+
+			report, err = reconciler.Reconcile("filter", "some-chain", desired)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Changed()).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,27 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+type CompiledChain struct {
+	Name  string
+	Rules []IPTablesRule
+}
+
+// RestoreDocument renders the chains as input for `iptables-restore
+// --noflush`, which replaces the rules of exactly those chains.
+func RestoreDocument(table string, chains []CompiledChain) string {
+	lines := []string{fmt.Sprintf("*%s", table)}
+	for _, chain := range chains {
+		lines = append(lines, fmt.Sprintf(":%s - [0:0]", chain.Name))
+	}
+	for _, chain := range chains {
+		for _, rule := range chain.Rules {
+			lines = append(lines, fmt.Sprintf("-A %s %s", chain.Name, strings.Join(rule, " ")))
+		}
+	}
+	lines = append(lines, "COMMIT", "")
+	return strings.Join(lines, "\n")
+}
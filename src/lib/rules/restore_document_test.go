@@ -0,0 +1,22 @@
+package rules_test
+
+import (
+	"lib/rules"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RestoreDocument", func() {
+	It("declares the chains and appends their rules", func() {
+		document := rules.RestoreDocument("filter", []rules.CompiledChain{
+			{Name: "a", Rules: []rules.IPTablesRule{{"-s", "10.0.0.1", "-j", "ACCEPT"}}},
+			{Name: "b", Rules: []rules.IPTablesRule{}},
+		})
+		Expect(document).To(Equal("*filter\n:a - [0:0]\n:b - [0:0]\n-A a -s 10.0.0.1 -j ACCEPT\nCOMMIT\n"))
+
+		tables, err := rules.ParseSave(document)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tables[0].Rules).To(HaveLen(1))
+	})
+})
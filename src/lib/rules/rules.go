@@ -156,6 +156,28 @@ func NewNetOutICMPLogRule(startIP, endIP string, icmpType, icmpCode int, chain s
 	}
 }
 
+func NewNetOutICMPv6Rule(startIP, endIP string, icmpType, icmpCode int) IPTablesRule {
+	return IPTablesRule{
+		"-m", "iprange",
+		"-p", "ipv6-icmp",
+		"--dst-range", fmt.Sprintf("%s-%s", startIP, endIP),
+		"-m", "icmp6",
+		"--icmpv6-type", fmt.Sprintf("%d/%d", icmpType, icmpCode),
+		"--jump", "ACCEPT",
+	}
+}
+
+func NewNetOutICMPv6LogRule(startIP, endIP string, icmpType, icmpCode int, chain string) IPTablesRule {
+	return IPTablesRule{
+		"-m", "iprange",
+		"-p", "ipv6-icmp",
+		"--dst-range", fmt.Sprintf("%s-%s", startIP, endIP),
+		"-m", "icmp6",
+		"--icmpv6-type", fmt.Sprintf("%d/%d", icmpType, icmpCode),
+		"-g", chain,
+	}
+}
+
 func NewNetOutLogRule(startIP, endIP, chain string) IPTablesRule {
 	return IPTablesRule{
 		"-m", "iprange",
@@ -175,6 +197,38 @@ func NewNetOutWithPortsLogRule(startIP, endIP string, startPort, endPort int, pr
 	}
 }
 
+func NewNetOutSetRule(setName string) IPTablesRule {
+	return IPTablesRule{
+		"-m", "set",
+		"--match-set", setName, "dst",
+		"--jump", "ACCEPT",
+	}
+}
+
+func NewNetOutSetLogRule(setName, chain string) IPTablesRule {
+	return IPTablesRule{
+		"-m", "set",
+		"--match-set", setName, "dst",
+		"-g", chain,
+	}
+}
+
+func NewNetOutWithPortsSetRule(setName string) IPTablesRule {
+	return IPTablesRule{
+		"-m", "set",
+		"--match-set", setName, "dst,dst",
+		"--jump", "ACCEPT",
+	}
+}
+
+func NewNetOutWithPortsSetLogRule(setName, chain string) IPTablesRule {
+	return IPTablesRule{
+		"-m", "set",
+		"--match-set", setName, "dst,dst",
+		"-g", chain,
+	}
+}
+
 func NewNetOutDefaultNonUDPLogRule(prefix string) IPTablesRule {
 	return IPTablesRule{
 		"!", "-p", "udp",
@@ -286,6 +340,13 @@ func NewNetOutDefaultRejectRule() IPTablesRule {
 	}
 }
 
+func NewNetOutDefaultRejectIPv6Rule() IPTablesRule {
+	return IPTablesRule{
+		"--jump", "REJECT",
+		"--reject-with", "icmp6-port-unreachable",
+	}
+}
+
 func trimAndPad(name string) string {
 	if len(name) > 28 {
 		name = name[:28]
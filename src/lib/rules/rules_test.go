@@ -67,6 +67,73 @@ var _ = Describe("Rules", func() {
 		})
 	})
 
+	Describe("NewNetOutICMPv6Rule", func() {
+		It("matches icmpv6 traffic to the ipv6 range", func() {
+			rule := rules.NewNetOutICMPv6Rule("2001:db8::1", "2001:db8::ff", 128, 0)
+			Expect(rule).To(Equal(rules.IPTablesRule{
+				"-m", "iprange",
+				"-p", "ipv6-icmp",
+				"--dst-range", "2001:db8::1-2001:db8::ff",
+				"-m", "icmp6",
+				"--icmpv6-type", "128/0",
+				"--jump", "ACCEPT",
+			}))
+		})
+	})
+
+	Describe("NewNetOutICMPv6LogRule", func() {
+		It("jumps to the log chain for icmpv6 traffic to the ipv6 range", func() {
+			rule := rules.NewNetOutICMPv6LogRule("2001:db8::1", "2001:db8::ff", 128, 0, "some-chain")
+			Expect(rule).To(Equal(rules.IPTablesRule{
+				"-m", "iprange",
+				"-p", "ipv6-icmp",
+				"--dst-range", "2001:db8::1-2001:db8::ff",
+				"-m", "icmp6",
+				"--icmpv6-type", "128/0",
+				"-g", "some-chain",
+			}))
+		})
+	})
+
+	Describe("NewNetOutSetRule", func() {
+		It("accepts traffic to addresses in the set", func() {
+			Expect(rules.NewNetOutSetRule("some-set")).To(Equal(rules.IPTablesRule{
+				"-m", "set",
+				"--match-set", "some-set", "dst",
+				"--jump", "ACCEPT",
+			}))
+			Expect(rules.NewNetOutSetLogRule("some-set", "some-chain")).To(Equal(rules.IPTablesRule{
+				"-m", "set",
+				"--match-set", "some-set", "dst",
+				"-g", "some-chain",
+			}))
+		})
+	})
+
+	Describe("NewNetOutWithPortsSetRule", func() {
+		It("accepts traffic to addresses and ports in the set", func() {
+			Expect(rules.NewNetOutWithPortsSetRule("some-set")).To(Equal(rules.IPTablesRule{
+				"-m", "set",
+				"--match-set", "some-set", "dst,dst",
+				"--jump", "ACCEPT",
+			}))
+			Expect(rules.NewNetOutWithPortsSetLogRule("some-set", "some-chain")).To(Equal(rules.IPTablesRule{
+				"-m", "set",
+				"--match-set", "some-set", "dst,dst",
+				"-g", "some-chain",
+			}))
+		})
+	})
+
+	Describe("NewNetOutDefaultRejectIPv6Rule", func() {
+		It("rejects with an icmpv6 port unreachable", func() {
+			Expect(rules.NewNetOutDefaultRejectIPv6Rule()).To(Equal(rules.IPTablesRule{
+				"--jump", "REJECT",
+				"--reject-with", "icmp6-port-unreachable",
+			}))
+		})
+	})
+
 	Describe("NewNetOutDefaultNonUDPLogRule", func() {
 		Context("when the log prefix is greater than 28 characters", func() {
 			It("shortens the log-prefix to 28 characters and adds a space", func() {
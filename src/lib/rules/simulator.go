@@ -0,0 +1,453 @@
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const maxSimulatedJumps = 64
+
+var builtinChainsByTable = map[string][]string{
+	"filter": {"INPUT", "FORWARD", "OUTPUT"},
+	"nat":    {"PREROUTING", "INPUT", "OUTPUT", "POSTROUTING"},
+	"mangle": {"PREROUTING", "INPUT", "FORWARD", "OUTPUT", "POSTROUTING"},
+}
+
+// Packet is a synthetic packet for the Simulator. State is the conntrack
+// state, NEW when empty. UID and GID are only checked by owner matches.
+type Packet struct {
+	SourceIP        string
+	DestinationIP   string
+	Protocol        string
+	SourcePort      int
+	DestinationPort int
+	ICMPType        int
+	ICMPCode        int
+	InInterface     string
+	OutInterface    string
+	Mark            uint32
+	State           string
+	UID             int
+	GID             int
+}
+
+type SimulatedRule struct {
+	Table    string
+	Chain    string
+	Position int
+	Rule     IPTablesRule
+}
+
+// Verdict is what became of a packet. Target is the terminating target, or
+// the policy of the built-in chain it fell off of, or RETURN when it fell off
+// a user-defined chain. Matched lists every rule that matched the packet, in
+// the order they were evaluated.
+type Verdict struct {
+	Target      string
+	Mark        uint32
+	LogPrefixes []string
+	Matched     []SimulatedRule
+}
+
+// Simulator walks packets through in-memory chains of IPTablesRule, so that
+// generated rule sets can be checked without root or a kernel. It understands
+// the matches the builders in this package use; limit matches always match.
+type Simulator struct {
+	chains   map[string][]ParsedRule
+	policies map[string]string
+	sets     map[string]IPSet
+}
+
+func NewSimulator() *Simulator {
+	s := &Simulator{
+		chains:   map[string][]ParsedRule{},
+		policies: map[string]string{},
+		sets:     map[string]IPSet{},
+	}
+	for table, chains := range builtinChainsByTable {
+		for _, chain := range chains {
+			s.chains[table+" "+chain] = []ParsedRule{}
+			s.policies[table+" "+chain] = "ACCEPT"
+		}
+	}
+	return s
+}
+
+// Load appends the rules to the chain, creating it if needed.
+func (s *Simulator) Load(table, chain string, rules ...IPTablesRule) error {
+	parsed := []ParsedRule{}
+	for _, rule := range rules {
+		p, err := ParseIPTablesRule(rule)
+		if err != nil {
+			return fmt.Errorf("parsing %q: %s", strings.Join(rule, " "), err)
+		}
+		p.Chain = chain
+		parsed = append(parsed, p)
+	}
+	s.chains[table+" "+chain] = append(s.chains[table+" "+chain], parsed...)
+	return nil
+}
+
+// LoadSave loads the output of iptables-save.
+func (s *Simulator) LoadSave(output string) error {
+	tables, err := ParseSave(output)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		for _, chain := range table.Chains {
+			if _, ok := s.chains[table.Name+" "+chain.Name]; !ok {
+				s.chains[table.Name+" "+chain.Name] = []ParsedRule{}
+			}
+			if chain.Policy != "-" {
+				s.policies[table.Name+" "+chain.Name] = chain.Policy
+			}
+		}
+		for _, rule := range table.Rules {
+			s.chains[table.Name+" "+rule.Chain] = append(s.chains[table.Name+" "+rule.Chain], rule)
+		}
+	}
+	return nil
+}
+
+// LoadSet makes the set available to set matches, replacing any set of the
+// same name.
+func (s *Simulator) LoadSet(set IPSet) {
+	s.sets[set.Name] = set
+}
+
+func (s *Simulator) SetPolicy(table, chain, policy string) error {
+	if _, ok := s.policies[table+" "+chain]; !ok {
+		return fmt.Errorf("%s is not a built-in chain of table %s", chain, table)
+	}
+	s.policies[table+" "+chain] = policy
+	return nil
+}
+
+// Evaluate sends the packet through the chain and reports the verdict.
+func (s *Simulator) Evaluate(table, chain string, packet Packet) (Verdict, error) {
+	if _, ok := s.chains[table+" "+chain]; !ok {
+		return Verdict{}, fmt.Errorf("chain %s does not exist in table %s", chain, table)
+	}
+
+	verdict := Verdict{Mark: packet.Mark, LogPrefixes: []string{}, Matched: []SimulatedRule{}}
+	target, err := s.walk(table, chain, &packet, &verdict, 0)
+	if err != nil {
+		return Verdict{}, err
+	}
+	if target == "" {
+		target = "RETURN"
+		if policy, ok := s.policies[table+" "+chain]; ok {
+			target = policy
+		}
+	}
+	verdict.Target = target
+	verdict.Mark = packet.Mark
+	return verdict, nil
+}
+
+// walk returns the terminating target, or "" when the packet returns from
+// the chain.
+func (s *Simulator) walk(table, chain string, packet *Packet, verdict *Verdict, depth int) (string, error) {
+	if depth > maxSimulatedJumps {
+		return "", fmt.Errorf("too many jumps at chain %s", chain)
+	}
+
+	for i, rule := range s.chains[table+" "+chain] {
+		matched, err := s.matchesRule(rule, packet)
+		if err != nil {
+			return "", fmt.Errorf("%s rule %d: %s", chain, i+1, err)
+		}
+		if !matched {
+			continue
+		}
+		verdict.Matched = append(verdict.Matched, SimulatedRule{
+			Table:    table,
+			Chain:    chain,
+			Position: i + 1,
+			Rule:     rule.IPTablesRule(),
+		})
+		if rule.Target == nil {
+			continue
+		}
+
+		switch rule.Target.Name {
+		case "ACCEPT", "DROP", "REJECT", "MASQUERADE", "SNAT", "DNAT":
+			return rule.Target.Name, nil
+		case "RETURN":
+			return "", nil
+		case "LOG":
+			verdict.LogPrefixes = append(verdict.LogPrefixes, strings.Trim(optionValue(rule.Target.Options, "--log-prefix"), `"`))
+			continue
+		case "MARK":
+			if err := setMark(rule.Target.Options, packet); err != nil {
+				return "", fmt.Errorf("%s rule %d: %s", chain, i+1, err)
+			}
+			continue
+		}
+
+		if _, ok := s.chains[table+" "+rule.Target.Name]; !ok {
+			return "", fmt.Errorf("%s rule %d: unsupported target %s", chain, i+1, rule.Target.Name)
+		}
+		target, err := s.walk(table, rule.Target.Name, packet, verdict, depth+1)
+		if err != nil || target != "" || rule.Target.Goto() {
+			return target, err
+		}
+	}
+	return "", nil
+}
+
+func (s *Simulator) matchesRule(rule ParsedRule, packet *Packet) (bool, error) {
+	for _, match := range rule.Matches {
+		for _, option := range match.Options {
+			matched, err := s.matchesOption(option, packet)
+			if err != nil {
+				return false, err
+			}
+			if matched == option.Negated {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+func (s *Simulator) matchesOption(option Option, packet *Packet) (bool, error) {
+	flag := option.Flag
+	if canonical, ok := canonicalFlags[flag]; ok {
+		flag = canonical
+	}
+	value := strings.Join(option.Values, " ")
+
+	switch flag {
+	case "-s":
+		return inCIDR(packet.SourceIP, value)
+	case "-d":
+		return inCIDR(packet.DestinationIP, value)
+	case "-p":
+		return value == "all" || strings.EqualFold(value, packet.Protocol) ||
+			(value == "ipv6-icmp" && packet.Protocol == "icmpv6"), nil
+	case "-i":
+		return matchesInterface(packet.InInterface, value), nil
+	case "-o":
+		return matchesInterface(packet.OutInterface, value), nil
+	case "--sport":
+		return inPortRange(packet.SourcePort, value)
+	case "--dport":
+		return inPortRange(packet.DestinationPort, value)
+	case "--src-range":
+		return inIPRange(packet.SourceIP, value)
+	case "--dst-range":
+		return inIPRange(packet.DestinationIP, value)
+	case "--icmp-type", "--icmpv6-type":
+		return matchesICMP(packet, value)
+	case "--mark":
+		mark, mask, err := parseMark(value)
+		if err != nil {
+			return false, err
+		}
+		return packet.Mark&mask == mark, nil
+	case "--ctstate", "--state":
+		state := packet.State
+		if state == "" {
+			state = "NEW"
+		}
+		for _, v := range strings.Split(value, ",") {
+			if v == state {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "--uid-owner":
+		return strconv.Itoa(packet.UID) == value, nil
+	case "--gid-owner":
+		return strconv.Itoa(packet.GID) == value, nil
+	case "--match-set":
+		return s.matchesSet(option.Values, packet)
+	case "--limit", "--limit-burst", "--comment":
+		return true, nil
+	}
+	return false, fmt.Errorf("unsupported match %s", option.Flag)
+}
+
+// matchesSet supports hash:net sets matched by address, and hash:net,port and
+// hash:ip,port sets matched by address and port.
+func (s *Simulator) matchesSet(values []string, packet *Packet) (bool, error) {
+	if len(values) != 2 {
+		return false, fmt.Errorf("bad --match-set %v", values)
+	}
+	set, ok := s.sets[values[0]]
+	if !ok {
+		return false, fmt.Errorf("set %s does not exist", values[0])
+	}
+
+	directions := strings.Split(values[1], ",")
+	address, port := packet.DestinationIP, packet.DestinationPort
+	if directions[0] == "src" {
+		address = packet.SourceIP
+	}
+	if len(directions) > 1 && directions[1] == "src" {
+		port = packet.SourcePort
+	}
+
+	for _, entry := range set.Entries {
+		parts := strings.SplitN(entry, ",", 2)
+		matched, err := inSetEntry(address, parts[0])
+		if err != nil || !matched {
+			if err != nil {
+				return false, fmt.Errorf("set %s: %s", set.Name, err)
+			}
+			continue
+		}
+		if len(directions) == 1 || len(parts) == 1 {
+			return true, nil
+		}
+
+		protocolAndPorts := strings.SplitN(parts[1], ":", 2)
+		if len(protocolAndPorts) != 2 {
+			return false, fmt.Errorf("set %s: bad entry %q", set.Name, entry)
+		}
+		if !strings.EqualFold(protocolAndPorts[0], packet.Protocol) {
+			continue
+		}
+		matched, err = inPortRange(port, strings.Replace(protocolAndPorts[1], "-", ":", 1))
+		if err != nil {
+			return false, fmt.Errorf("set %s: %s", set.Name, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func inSetEntry(address, value string) (bool, error) {
+	if strings.Contains(value, "-") {
+		return inIPRange(address, value)
+	}
+	return inCIDR(address, value)
+}
+
+func inCIDR(address, value string) (bool, error) {
+	ip := net.ParseIP(address)
+	if !strings.Contains(value, "/") {
+		return ip != nil && ip.Equal(net.ParseIP(value)), nil
+	}
+	_, network, err := net.ParseCIDR(value)
+	if err != nil {
+		return false, err
+	}
+	return ip != nil && network.Contains(ip), nil
+}
+
+func inIPRange(address, value string) (bool, error) {
+	bounds := strings.SplitN(value, "-", 2)
+	if len(bounds) != 2 {
+		return false, fmt.Errorf("bad ip range %q", value)
+	}
+	ip, start, end := net.ParseIP(address), net.ParseIP(bounds[0]), net.ParseIP(bounds[1])
+	if start == nil || end == nil {
+		return false, fmt.Errorf("bad ip range %q", value)
+	}
+	if ip == nil || (ip.To4() == nil) != (start.To4() == nil) {
+		return false, nil
+	}
+	return bytes.Compare(ip.To16(), start.To16()) >= 0 && bytes.Compare(ip.To16(), end.To16()) <= 0, nil
+}
+
+func inPortRange(port int, value string) (bool, error) {
+	bounds := strings.SplitN(value, ":", 2)
+	start, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return false, fmt.Errorf("bad port %q", value)
+	}
+	end := start
+	if len(bounds) == 2 {
+		end, err = strconv.Atoi(bounds[1])
+		if err != nil {
+			return false, fmt.Errorf("bad port %q", value)
+		}
+	}
+	return port >= start && port <= end, nil
+}
+
+func matchesInterface(name, value string) bool {
+	if strings.HasSuffix(value, "+") {
+		return strings.HasPrefix(name, strings.TrimSuffix(value, "+"))
+	}
+	return name == value
+}
+
+// matchesICMP treats a type or code of -1 as any, the way the netout builders
+// write it.
+func matchesICMP(packet *Packet, value string) (bool, error) {
+	if value == "any" {
+		return true, nil
+	}
+	typeAndCode := strings.SplitN(value, "/", 2)
+	icmpType, err := strconv.Atoi(typeAndCode[0])
+	if err != nil {
+		return false, fmt.Errorf("bad icmp type %q", value)
+	}
+	if icmpType != -1 && icmpType != packet.ICMPType {
+		return false, nil
+	}
+	if len(typeAndCode) == 1 {
+		return true, nil
+	}
+	icmpCode, err := strconv.Atoi(typeAndCode[1])
+	if err != nil {
+		return false, fmt.Errorf("bad icmp code %q", value)
+	}
+	return icmpCode == -1 || icmpCode == packet.ICMPCode, nil
+}
+
+func parseMark(value string) (uint32, uint32, error) {
+	parts := strings.SplitN(value, "/", 2)
+	mark, err := strconv.ParseUint(parts[0], 0, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("bad mark %q", value)
+	}
+	mask := uint64(0xffffffff)
+	if len(parts) == 2 {
+		mask, err = strconv.ParseUint(parts[1], 0, 32)
+		if err != nil {
+			return 0, 0, fmt.Errorf("bad mark %q", value)
+		}
+	}
+	return uint32(mark), uint32(mask), nil
+}
+
+func setMark(options []Option, packet *Packet) error {
+	for _, option := range options {
+		switch option.Flag {
+		case "--set-xmark":
+			mark, mask, err := parseMark(strings.Join(option.Values, " "))
+			if err != nil {
+				return err
+			}
+			packet.Mark = (packet.Mark &^ mask) ^ mark
+			return nil
+		case "--set-mark":
+			mark, mask, err := parseMark(strings.Join(option.Values, " "))
+			if err != nil {
+				return err
+			}
+			packet.Mark = (packet.Mark &^ mask) | mark
+			return nil
+		}
+	}
+	return fmt.Errorf("MARK without --set-xmark or --set-mark")
+}
+
+func optionValue(options []Option, flag string) string {
+	for _, option := range options {
+		if option.Flag == flag {
+			return strings.Join(option.Values, " ")
+		}
+	}
+	return ""
+}
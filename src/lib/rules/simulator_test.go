@@ -0,0 +1,258 @@
+package rules_test
+
+import (
+	"lib/rules"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Simulator", func() {
+	var simulator *rules.Simulator
+
+	BeforeEach(func() {
+		simulator = rules.NewSimulator()
+	})
+
+	Describe("container to container policies", func() {
+		BeforeEach(func() {
+			Expect(simulator.Load("filter", "vpa--1234",
+				rules.NewMarkSetRule("10.255.0.1", "A", "source-app-guid"),
+			)).To(Succeed())
+			Expect(simulator.Load("filter", "overlay",
+				rules.NewOverlayRelatedEstablishedRule("10.255.0.2"),
+				rules.NewMarkAllowLogRule("10.255.0.2", "tcp", 8080, 8080, "A", "dest-app-guid", 100),
+				rules.NewMarkAllowRule("10.255.0.2", "tcp", 8080, 9000, "A", "source-app-guid", "dest-app-guid"),
+				rules.NewOverlayDefaultRejectLogRule("some-handle", "10.255.0.2", 3),
+				rules.NewOverlayDefaultRejectRule("10.255.0.2"),
+			)).To(Succeed())
+			Expect(simulator.Load("filter", "FORWARD",
+				rules.IPTablesRule{"-j", "vpa--1234"},
+				rules.IPTablesRule{"-j", "overlay"},
+			)).To(Succeed())
+		})
+
+		It("accepts a marked packet allowed by policy and reports the rules it matched", func() {
+			verdict, err := simulator.Evaluate("filter", "FORWARD", rules.Packet{
+				SourceIP: "10.255.0.1", DestinationIP: "10.255.0.2",
+				Protocol: "tcp", SourcePort: 40000, DestinationPort: 8080,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(verdict.Target).To(Equal("ACCEPT"))
+			Expect(verdict.Mark).To(Equal(uint32(0xa)))
+			Expect(verdict.LogPrefixes).To(Equal([]string{"OK_A_dest-app-guid "}))
+			Expect(verdict.Matched).To(Equal([]rules.SimulatedRule{
+				{Table: "filter", Chain: "FORWARD", Position: 1, Rule: rules.IPTablesRule{"-j", "vpa--1234"}},
+				{Table: "filter", Chain: "vpa--1234", Position: 1, Rule: rules.NewMarkSetRule("10.255.0.1", "A", "source-app-guid")},
+				{Table: "filter", Chain: "FORWARD", Position: 2, Rule: rules.IPTablesRule{"-j", "overlay"}},
+				{Table: "filter", Chain: "overlay", Position: 2, Rule: rules.NewMarkAllowLogRule("10.255.0.2", "tcp", 8080, 8080, "A", "dest-app-guid", 100)},
+				{Table: "filter", Chain: "overlay", Position: 3, Rule: rules.NewMarkAllowRule("10.255.0.2", "tcp", 8080, 9000, "A", "source-app-guid", "dest-app-guid")},
+			}))
+		})
+
+		It("rejects a packet from an app without a policy", func() {
+			verdict, err := simulator.Evaluate("filter", "FORWARD", rules.Packet{
+				SourceIP: "10.255.0.9", DestinationIP: "10.255.0.2",
+				Protocol: "tcp", DestinationPort: 8080,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verdict.Target).To(Equal("REJECT"))
+			Expect(verdict.Mark).To(BeZero())
+			Expect(verdict.LogPrefixes).To(Equal([]string{"DENY_C2C_some-handle "}))
+		})
+
+		It("rejects a port outside of the policy", func() {
+			verdict, err := simulator.Evaluate("filter", "FORWARD", rules.Packet{
+				SourceIP: "10.255.0.1", DestinationIP: "10.255.0.2",
+				Protocol: "tcp", DestinationPort: 9001,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verdict.Target).To(Equal("REJECT"))
+		})
+
+		It("accepts replies on established connections", func() {
+			verdict, err := simulator.Evaluate("filter", "FORWARD", rules.Packet{
+				SourceIP: "10.255.0.9", DestinationIP: "10.255.0.2",
+				Protocol: "udp", State: "ESTABLISHED",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verdict.Target).To(Equal("ACCEPT"))
+		})
+	})
+
+	Describe("egress rules", func() {
+		BeforeEach(func() {
+			Expect(simulator.Load("filter", "netout--some-handle",
+				rules.NewNetOutRelatedEstablishedRule(),
+				rules.NewNetOutWithPortsRule("10.0.0.1", "10.0.0.100", 443, 443, "tcp"),
+				rules.NewNetOutICMPRule("10.0.0.1", "10.0.0.100", -1, -1),
+				rules.NewNetOutDefaultNonUDPLogRule("some-handle"),
+				rules.NewNetOutDefaultRejectRule(),
+			)).To(Succeed())
+		})
+
+		DescribeTable("deciding on packets",
+			func(packet rules.Packet, target string) {
+				verdict, err := simulator.Evaluate("filter", "netout--some-handle", packet)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(verdict.Target).To(Equal(target))
+			},
+			Entry("allowed ip range and port", rules.Packet{DestinationIP: "10.0.0.50", Protocol: "tcp", DestinationPort: 443}, "ACCEPT"),
+			Entry("outside of the ip range", rules.Packet{DestinationIP: "10.0.0.101", Protocol: "tcp", DestinationPort: 443}, "REJECT"),
+			Entry("other port", rules.Packet{DestinationIP: "10.0.0.50", Protocol: "tcp", DestinationPort: 80}, "REJECT"),
+			Entry("any icmp", rules.Packet{DestinationIP: "10.0.0.50", Protocol: "icmp", ICMPType: 8}, "ACCEPT"),
+			Entry("established", rules.Packet{DestinationIP: "1.1.1.1", Protocol: "tcp", State: "ESTABLISHED"}, "ACCEPT"),
+		)
+
+		It("returns from a user-defined chain that does not decide", func() {
+			Expect(simulator.Load("filter", "empty")).To(Succeed())
+			verdict, err := simulator.Evaluate("filter", "empty", rules.Packet{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verdict.Target).To(Equal("RETURN"))
+		})
+	})
+
+	Describe("jumps and gotos", func() {
+		It("returns to the rule after a jump, but not after a goto", func() {
+			Expect(simulator.Load("filter", "returns", rules.IPTablesRule{"-j", "RETURN"})).To(Succeed())
+			Expect(simulator.Load("filter", "INPUT",
+				rules.IPTablesRule{"-p", "tcp", "-j", "returns"},
+				rules.IPTablesRule{"-p", "tcp", "-j", "DROP"},
+				rules.IPTablesRule{"-g", "returns"},
+				rules.IPTablesRule{"-j", "REJECT"},
+			)).To(Succeed())
+			Expect(simulator.SetPolicy("filter", "INPUT", "DROP")).To(Succeed())
+
+			verdict, err := simulator.Evaluate("filter", "INPUT", rules.Packet{Protocol: "tcp"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verdict.Target).To(Equal("DROP"))
+			Expect(verdict.Matched).To(HaveLen(3))
+
+			verdict, err = simulator.Evaluate("filter", "INPUT", rules.Packet{Protocol: "udp"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verdict.Target).To(Equal("DROP"))
+			Expect(verdict.Matched[len(verdict.Matched)-1].Chain).To(Equal("returns"))
+		})
+
+		It("stops loops", func() {
+			Expect(simulator.Load("filter", "loop", rules.IPTablesRule{"-j", "loop"})).To(Succeed())
+			_, err := simulator.Evaluate("filter", "loop", rules.Packet{})
+			Expect(err).To(MatchError("too many jumps at chain loop"))
+		})
+	})
+
+	DescribeTable("matching single options",
+		func(rule rules.IPTablesRule, packet rules.Packet, matched bool) {
+			Expect(simulator.Load("filter", "OUTPUT", rule)).To(Succeed())
+			Expect(simulator.SetPolicy("filter", "OUTPUT", "DROP")).To(Succeed())
+			verdict, err := simulator.Evaluate("filter", "OUTPUT", packet)
+			Expect(err).NotTo(HaveOccurred())
+			if matched {
+				Expect(verdict.Target).To(Equal("ACCEPT"))
+			} else {
+				Expect(verdict.Target).To(Equal("DROP"))
+			}
+		},
+		Entry("cidr", rules.IPTablesRule{"-s", "10.255.0.0/16", "-j", "ACCEPT"}, rules.Packet{SourceIP: "10.255.3.4"}, true),
+		Entry("negated cidr", rules.IPTablesRule{"!", "-s", "10.255.0.0/16", "-j", "ACCEPT"}, rules.Packet{SourceIP: "10.255.3.4"}, false),
+		Entry("interface wildcard", rules.IPTablesRule{"-o", "s-+", "-j", "ACCEPT"}, rules.Packet{OutInterface: "s-010255000002"}, true),
+		Entry("mark with a mask", rules.IPTablesRule{"-m", "mark", "--mark", "0x1/0xf", "-j", "ACCEPT"}, rules.Packet{Mark: 0x21}, true),
+		Entry("negated mark", rules.IPTablesRule{"-m", "mark", "!", "--mark", "0x0", "-j", "ACCEPT"}, rules.Packet{}, false),
+		Entry("conntrack state", rules.IPTablesRule{"-m", "conntrack", "--ctstate", "INVALID,NEW,UNTRACKED", "-j", "ACCEPT"}, rules.Packet{}, true),
+		Entry("owner", rules.IPTablesRule{"-m", "owner", "--uid-owner", "1000", "-j", "ACCEPT"}, rules.Packet{UID: 1000}, true),
+		Entry("other owner", rules.IPTablesRule{"-m", "owner", "--gid-owner", "1000", "-j", "ACCEPT"}, rules.Packet{GID: 0}, false),
+		Entry("limit and comment", rules.IPTablesRule{"-m", "limit", "--limit", "2/min", "-m", "comment", "--comment", "hi", "-j", "ACCEPT"}, rules.Packet{}, true),
+		Entry("icmp type and code", rules.IPTablesRule{"-p", "icmp", "-m", "icmp", "--icmp-type", "3/4", "-j", "ACCEPT"}, rules.Packet{Protocol: "icmp", ICMPType: 3, ICMPCode: 1}, false),
+		Entry("ipv6 range", rules.IPTablesRule{"-m", "iprange", "--dst-range", "fd00::1-fd00::9", "-j", "ACCEPT"}, rules.Packet{DestinationIP: "fd00::5"}, true),
+		Entry("ipv4 address against an ipv6 range", rules.IPTablesRule{"-m", "iprange", "--dst-range", "fd00::1-fd00::9", "-j", "ACCEPT"}, rules.Packet{DestinationIP: "10.0.0.1"}, false),
+	)
+
+	DescribeTable("matching sets",
+		func(set rules.IPSet, rule rules.IPTablesRule, packet rules.Packet, matched bool) {
+			simulator.LoadSet(set)
+			Expect(simulator.Load("filter", "OUTPUT", rule)).To(Succeed())
+			Expect(simulator.SetPolicy("filter", "OUTPUT", "DROP")).To(Succeed())
+			verdict, err := simulator.Evaluate("filter", "OUTPUT", packet)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verdict.Target == "ACCEPT").To(Equal(matched))
+		},
+		Entry("address in a hash:net set",
+			rules.IPSet{Name: "s", Type: "hash:net", Entries: []string{"10.0.0.0/24"}},
+			rules.NewNetOutSetRule("s"), rules.Packet{DestinationIP: "10.0.0.9"}, true),
+		Entry("address outside of a hash:net set",
+			rules.IPSet{Name: "s", Type: "hash:net", Entries: []string{"10.0.0.0/24"}},
+			rules.NewNetOutSetRule("s"), rules.Packet{DestinationIP: "10.0.1.9"}, false),
+		Entry("address and port in a hash:net,port set",
+			rules.IPSet{Name: "s", Type: "hash:net,port", Entries: []string{"10.0.0.0/24,tcp:80-90"}},
+			rules.NewNetOutWithPortsSetRule("s"), rules.Packet{DestinationIP: "10.0.0.9", Protocol: "tcp", DestinationPort: 85}, true),
+		Entry("other protocol than the hash:net,port set",
+			rules.IPSet{Name: "s", Type: "hash:net,port", Entries: []string{"10.0.0.0/24,tcp:80-90"}},
+			rules.NewNetOutWithPortsSetRule("s"), rules.Packet{DestinationIP: "10.0.0.9", Protocol: "udp", DestinationPort: 85}, false),
+		Entry("ip range in a hash:ip,port set",
+			rules.IPSet{Name: "s", Type: "hash:ip,port", Entries: []string{"10.0.0.1-10.0.0.3,udp:53"}},
+			rules.NewNetOutWithPortsSetRule("s"), rules.Packet{DestinationIP: "10.0.0.2", Protocol: "udp", DestinationPort: 53}, true),
+	)
+
+	It("sets marks with --set-mark", func() {
+		Expect(simulator.Load("nat", "PREROUTING",
+			rules.NewIngressMarkRule("eth0", 2222, "10.0.0.2", "F"),
+			rules.NewPortForwardingRule(2222, 8080, "10.0.0.2", "10.255.0.2"),
+		)).To(Succeed())
+
+		verdict, err := simulator.Evaluate("nat", "PREROUTING", rules.Packet{
+			InInterface: "eth0", DestinationIP: "10.0.0.2", Protocol: "tcp", DestinationPort: 2222,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(verdict.Target).To(Equal("DNAT"))
+		Expect(verdict.Mark).To(Equal(uint32(0xf)))
+	})
+
+	It("loads iptables-save output", func() {
+		Expect(simulator.LoadSave(`*filter
+:INPUT DROP [0:0]
+:some-chain - [0:0]
+-A INPUT -s 10.0.0.0/8 -j some-chain
+-A some-chain -p tcp -m tcp --dport 22 -j ACCEPT
+COMMIT
+`)).To(Succeed())
+
+		verdict, err := simulator.Evaluate("filter", "INPUT", rules.Packet{SourceIP: "10.1.1.1", Protocol: "tcp", DestinationPort: 22})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(verdict.Target).To(Equal("ACCEPT"))
+
+		verdict, err = simulator.Evaluate("filter", "INPUT", rules.Packet{SourceIP: "10.1.1.1", Protocol: "tcp", DestinationPort: 23})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(verdict.Target).To(Equal("DROP"))
+	})
+
+	Describe("errors", func() {
+		It("fails on unknown chains", func() {
+			_, err := simulator.Evaluate("filter", "nope", rules.Packet{})
+			Expect(err).To(MatchError("chain nope does not exist in table filter"))
+		})
+
+		It("fails on matches it does not understand", func() {
+			Expect(simulator.Load("filter", "INPUT", rules.IPTablesRule{"-m", "physdev", "--physdev-in", "eth0", "-j", "ACCEPT"})).To(Succeed())
+			_, err := simulator.Evaluate("filter", "INPUT", rules.Packet{})
+			Expect(err).To(MatchError("INPUT rule 1: unsupported match --physdev-in"))
+		})
+
+		It("fails on sets that were not loaded", func() {
+			Expect(simulator.Load("filter", "INPUT", rules.NewNetOutSetRule("nope"))).To(Succeed())
+			_, err := simulator.Evaluate("filter", "INPUT", rules.Packet{})
+			Expect(err).To(MatchError("INPUT rule 1: set nope does not exist"))
+		})
+
+		It("fails on jumps to chains that do not exist", func() {
+			Expect(simulator.Load("filter", "INPUT", rules.IPTablesRule{"-j", "nope"})).To(Succeed())
+			_, err := simulator.Evaluate("filter", "INPUT", rules.Packet{})
+			Expect(err).To(MatchError("INPUT rule 1: unsupported target nope"))
+		})
+
+		It("fails to set the policy of a user-defined chain", func() {
+			Expect(simulator.SetPolicy("filter", "nope", "DROP")).To(MatchError("nope is not a built-in chain of table filter"))
+		})
+	})
+})
@@ -0,0 +1,105 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Transaction queues changes to be applied together by Commit. Either all
+// of them are applied, or the tables they touch are put back the way they
+// were.
+type Transaction struct {
+	iptables *LockedIPTables
+	tables   []string
+	lines    map[string][]string
+}
+
+func (l *LockedIPTables) Begin() *Transaction {
+	return &Transaction{
+		iptables: l,
+		tables:   []string{},
+		lines:    map[string][]string{},
+	}
+}
+
+func (t *Transaction) queue(table, line string) {
+	if _, ok := t.lines[table]; !ok {
+		t.tables = append(t.tables, table)
+	}
+	t.lines[table] = append(t.lines[table], line)
+}
+
+func (t *Transaction) NewChain(table, chain string) {
+	t.queue(table, fmt.Sprintf("-N %s", chain))
+}
+
+func (t *Transaction) ClearChain(table, chain string) {
+	t.queue(table, fmt.Sprintf("-F %s", chain))
+}
+
+func (t *Transaction) DeleteChain(table, chain string) {
+	t.queue(table, fmt.Sprintf("-X %s", chain))
+}
+
+func (t *Transaction) BulkInsert(table, chain string, pos int, rulespec ...IPTablesRule) {
+	for _, r := range rulespec {
+		t.queue(table, fmt.Sprintf("-I %s %d %s", chain, pos, strings.Join(r, " ")))
+	}
+}
+
+func (t *Transaction) BulkAppend(table, chain string, rulespec ...IPTablesRule) {
+	for _, r := range rulespec {
+		t.queue(table, fmt.Sprintf("-A %s %s", chain, strings.Join(r, " ")))
+	}
+}
+
+func (t *Transaction) Delete(table, chain string, rulespec IPTablesRule) {
+	t.queue(table, fmt.Sprintf("-D %s %s", chain, strings.Join(rulespec, " ")))
+}
+
+// Commit snapshots the tables the transaction touches, applies all of its
+// changes with one restore, and restores the snapshots if that fails.
+func (t *Transaction) Commit() error {
+	if len(t.tables) == 0 {
+		return nil
+	}
+
+	l := t.iptables
+	if l.Saver == nil || l.TableRestorer == nil {
+		return fmt.Errorf("transactions need a Saver and a TableRestorer")
+	}
+
+	acquiredAt, err := l.lock("Transaction")
+	if err != nil {
+		return fmt.Errorf("lock: %s", err)
+	}
+
+	snapshots := []string{}
+	for _, table := range t.tables {
+		snapshot, err := l.Saver.Save(table)
+		if err != nil {
+			return handleIPTablesError(fmt.Errorf("saving table %s: %s", table, err), l.unlock("Transaction", acquiredAt))
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	input := []string{}
+	for _, table := range t.tables {
+		input = append(input, fmt.Sprintf("*%s\n", table))
+		for _, line := range t.lines[table] {
+			input = append(input, line+"\n")
+		}
+		input = append(input, "COMMIT\n")
+	}
+
+	err = l.Restorer.Restore(strings.Join(input, ""))
+	if err != nil {
+		rollbackErr := l.TableRestorer.Restore(strings.Join(snapshots, ""))
+		if rollbackErr != nil {
+			err = fmt.Errorf("%s and rolling back: %s", err, rollbackErr)
+		}
+		return handleIPTablesError(err, l.unlock("Transaction", acquiredAt))
+	}
+
+	return l.unlock("Transaction", acquiredAt)
+}
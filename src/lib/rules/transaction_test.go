@@ -0,0 +1,146 @@
+package rules_test
+
+import (
+	"errors"
+	"lib/fakes"
+	"lib/rules"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Transaction", func() {
+	var (
+		lockedIPT     *rules.LockedIPTables
+		lock          *fakes.Locker
+		restorer      *fakes.Restorer
+		tableRestorer *fakes.Restorer
+		saver         *fakes.Saver
+		transaction   *rules.Transaction
+	)
+
+	BeforeEach(func() {
+		lock = &fakes.Locker{}
+		restorer = &fakes.Restorer{}
+		tableRestorer = &fakes.Restorer{}
+		saver = &fakes.Saver{}
+		saver.SaveStub = func(table string) (string, error) {
+			return "*" + table + "\n:INPUT ACCEPT [0:0]\nCOMMIT\n", nil
+		}
+		lockedIPT = &rules.LockedIPTables{
+			IPTables:      &fakes.IPTables{},
+			Locker:        lock,
+			Restorer:      restorer,
+			Saver:         saver,
+			TableRestorer: tableRestorer,
+		}
+
+		transaction = lockedIPT.Begin()
+		transaction.NewChain("filter", "some-chain")
+		transaction.BulkAppend("filter", "some-chain", rules.IPTablesRule{"-s", "1.2.3.4", "-j", "ACCEPT"}, rules.IPTablesRule{"-j", "DROP"})
+		transaction.BulkInsert("nat", "PREROUTING", 1, rules.IPTablesRule{"-j", "some-nat-chain"})
+		transaction.Delete("filter", "FORWARD", rules.IPTablesRule{"-j", "old-chain"})
+		transaction.ClearChain("filter", "old-chain")
+		transaction.DeleteChain("filter", "old-chain")
+	})
+
+	It("applies every queued change with one restore under the lock", func() {
+		Expect(transaction.Commit()).To(Succeed())
+
+		Expect(lock.LockCallCount()).To(Equal(1))
+		Expect(lock.UnlockCallCount()).To(Equal(1))
+		Expect(saver.SaveCallCount()).To(Equal(2))
+		Expect(saver.SaveArgsForCall(0)).To(Equal("filter"))
+		Expect(saver.SaveArgsForCall(1)).To(Equal("nat"))
+
+		Expect(restorer.RestoreCallCount()).To(Equal(1))
+		Expect(restorer.RestoreArgsForCall(0)).To(Equal("*filter\n" +
+			"-N some-chain\n" +
+			"-A some-chain -s 1.2.3.4 -j ACCEPT\n" +
+			"-A some-chain -j DROP\n" +
+			"-D FORWARD -j old-chain\n" +
+			"-F old-chain\n" +
+			"-X old-chain\n" +
+			"COMMIT\n" +
+			"*nat\n" +
+			"-I PREROUTING 1 -j some-nat-chain\n" +
+			"COMMIT\n"))
+		Expect(tableRestorer.RestoreCallCount()).To(Equal(0))
+	})
+
+	Context("when nothing is queued", func() {
+		It("does nothing", func() {
+			Expect(lockedIPT.Begin().Commit()).To(Succeed())
+			Expect(lock.LockCallCount()).To(Equal(0))
+			Expect(restorer.RestoreCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when there is no Saver", func() {
+		BeforeEach(func() {
+			lockedIPT.Saver = nil
+		})
+		It("returns an error without locking", func() {
+			Expect(transaction.Commit()).To(MatchError("transactions need a Saver and a TableRestorer"))
+			Expect(lock.LockCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when there is no TableRestorer", func() {
+		BeforeEach(func() {
+			lockedIPT.TableRestorer = nil
+		})
+		It("returns an error without locking", func() {
+			Expect(transaction.Commit()).To(MatchError("transactions need a Saver and a TableRestorer"))
+			Expect(lock.LockCallCount()).To(Equal(0))
+			Expect(saver.SaveCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the lock fails", func() {
+		BeforeEach(func() {
+			lock.LockReturns(errors.New("banana"))
+		})
+		It("returns an error", func() {
+			Expect(transaction.Commit()).To(MatchError("lock: banana"))
+			Expect(saver.SaveCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when saving a table fails", func() {
+		BeforeEach(func() {
+			saver.SaveStub = nil
+			saver.SaveReturns("", errors.New("banana"))
+		})
+		It("applies nothing and unlocks", func() {
+			Expect(transaction.Commit()).To(MatchError("iptables call: saving table filter: banana and unlock: <nil>"))
+			Expect(restorer.RestoreCallCount()).To(Equal(0))
+			Expect(lock.UnlockCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when applying the changes fails", func() {
+		BeforeEach(func() {
+			restorer.RestoreReturns(errors.New("banana"))
+		})
+
+		It("restores the snapshots of the tables and unlocks", func() {
+			Expect(transaction.Commit()).To(MatchError("iptables call: banana and unlock: <nil>"))
+
+			Expect(tableRestorer.RestoreCallCount()).To(Equal(1))
+			Expect(tableRestorer.RestoreArgsForCall(0)).To(Equal(
+				"*filter\n:INPUT ACCEPT [0:0]\nCOMMIT\n*nat\n:INPUT ACCEPT [0:0]\nCOMMIT\n"))
+			Expect(lock.UnlockCallCount()).To(Equal(1))
+		})
+
+		Context("when rolling back fails too", func() {
+			BeforeEach(func() {
+				tableRestorer.RestoreReturns(errors.New("potato"))
+				lock.UnlockReturns(errors.New("kiwi"))
+			})
+			It("returns all the errors", func() {
+				Expect(transaction.Commit()).To(MatchError("iptables call: banana and rolling back: potato and unlock: kiwi"))
+			})
+		})
+	})
+})
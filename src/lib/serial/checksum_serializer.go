@@ -0,0 +1,172 @@
+package serial
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// CorruptError is returned by ChecksumSerial when neither the file nor its
+// backup can be decoded.
+type CorruptError struct {
+	Err error
+}
+
+func (e *CorruptError) Error() string {
+	return fmt.Sprintf("corrupt: %s", e.Err)
+}
+
+type checksumEnvelope struct {
+	Checksum string          `json:"checksum"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// ChecksumSerial stores the JSON together with its checksum, so a file that
+// was only partly written is told apart from a valid one. With a BackupPath,
+// every encoding is first written there atomically, and a file that fails to
+// decode is recovered from the backup. Files written by Serial, without a
+// checksum, are decoded as they are.
+//
+// The file itself is rewritten in place rather than renamed over, because it
+// is also the file that is flocked: a rename would leave the processes that
+// are waiting for the lock holding it on the old, unlinked file. The write is
+// only safe because the backup is complete and synced before the file is
+// truncated, so a crash that leaves the file empty or partly written is
+// recovered from the backup.
+type ChecksumSerial struct {
+	BackupPath string
+}
+
+func (s *ChecksumSerial) DecodeAll(file io.ReadSeeker, outData interface{}) error {
+	_, err := file.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	contents, err := ioutil.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	if len(contents) == 0 {
+		return s.decodeBackupOfEmptyFile(outData)
+	}
+
+	decodeErr := decodeChecksummed(contents, outData)
+	if decodeErr == nil {
+		return nil
+	}
+	if s.BackupPath == "" {
+		return &CorruptError{Err: decodeErr}
+	}
+
+	backup, err := ioutil.ReadFile(s.BackupPath)
+	if err != nil {
+		return &CorruptError{Err: fmt.Errorf("%s and reading backup: %s", decodeErr, err)}
+	}
+	if err := decodeChecksummed(backup, outData); err != nil {
+		return &CorruptError{Err: fmt.Errorf("%s and decoding backup: %s", decodeErr, err)}
+	}
+	return nil
+}
+
+// decodeBackupOfEmptyFile tells a file that was never written, which has no
+// backup, from one that was truncated by an interrupted write.
+func (s *ChecksumSerial) decodeBackupOfEmptyFile(outData interface{}) error {
+	if s.BackupPath == "" {
+		return nil
+	}
+	backup, err := ioutil.ReadFile(s.BackupPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return &CorruptError{Err: fmt.Errorf("empty file and reading backup: %s", err)}
+	}
+	if err := decodeChecksummed(backup, outData); err != nil {
+		return &CorruptError{Err: fmt.Errorf("empty file and decoding backup: %s", err)}
+	}
+	return nil
+}
+
+func (s *ChecksumSerial) EncodeAndOverwrite(file OverwriteableFile, outData interface{}) error {
+	data, err := json.Marshal(outData)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	contents, err := json.Marshal(checksumEnvelope{
+		Checksum: "sha256:" + hex.EncodeToString(sum[:]),
+		Data:     data,
+	})
+	if err != nil {
+		return err // not tested
+	}
+	contents = append(contents, '\n')
+
+	if s.BackupPath != "" {
+		if err := writeAtomically(s.BackupPath, contents); err != nil {
+			return fmt.Errorf("writing backup: %s", err)
+		}
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	err = file.Truncate(0)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(contents)
+	if err != nil {
+		return err
+	}
+	if syncer, ok := file.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+func decodeChecksummed(contents []byte, outData interface{}) error {
+	var envelope checksumEnvelope
+	if err := json.Unmarshal(contents, &envelope); err != nil {
+		return err
+	}
+	if envelope.Checksum == "" && envelope.Data == nil {
+		return json.Unmarshal(contents, outData)
+	}
+
+	sum := sha256.Sum256(envelope.Data)
+	if envelope.Checksum != "sha256:"+hex.EncodeToString(sum[:]) {
+		return ErrChecksumMismatch
+	}
+	return json.Unmarshal(envelope.Data, outData)
+}
+
+func writeAtomically(path string, contents []byte) error {
+	temp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name()) // defer not tested
+
+	if _, err := temp.Write(contents); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
@@ -0,0 +1,181 @@
+package serial_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"lib/fakes"
+	"lib/serial"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ChecksumSerializer", func() {
+	var (
+		serializer *serial.ChecksumSerial
+		dir        string
+		file       *os.File
+		outData    struct{ Some string }
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "checksum-serializer")
+		Expect(err).NotTo(HaveOccurred())
+		file, err = os.Create(filepath.Join(dir, "state.json"))
+		Expect(err).NotTo(HaveOccurred())
+
+		serializer = &serial.ChecksumSerial{
+			BackupPath: filepath.Join(dir, "state.json.backup"),
+		}
+		outData.Some = ""
+	})
+
+	AfterEach(func() {
+		Expect(file.Close()).To(Succeed())
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("round-trips the data", func() {
+		Expect(serializer.EncodeAndOverwrite(file, map[string]string{"some": "data"})).To(Succeed())
+		Expect(serializer.DecodeAll(file, &outData)).To(Succeed())
+		Expect(outData.Some).To(Equal("data"))
+	})
+
+	It("writes the data together with its checksum, to the file and the backup", func() {
+		Expect(serializer.EncodeAndOverwrite(file, map[string]string{"some": "data"})).To(Succeed())
+
+		expected := `{
+			"checksum": "sha256:c67cdc294fe06519bd9b7948e27059b643edf540877335b271957ab95dd551b7",
+			"data": {"some": "data"}
+		}`
+		fileBytes, err := ioutil.ReadFile(file.Name())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fileBytes).To(MatchJSON(expected))
+
+		backupBytes, err := ioutil.ReadFile(serializer.BackupPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backupBytes).To(Equal(fileBytes))
+	})
+
+	Context("when the file is empty", func() {
+		It("succeeds", func() {
+			Expect(serializer.DecodeAll(file, &outData)).To(Succeed())
+			Expect(outData.Some).To(BeEmpty())
+		})
+	})
+
+	Context("when a write was interrupted after the file was truncated", func() {
+		BeforeEach(func() {
+			Expect(serializer.EncodeAndOverwrite(file, map[string]string{"some": "data"})).To(Succeed())
+			Expect(file.Truncate(0)).To(Succeed())
+		})
+
+		It("recovers it from the backup", func() {
+			Expect(serializer.DecodeAll(file, &outData)).To(Succeed())
+			Expect(outData.Some).To(Equal("data"))
+		})
+
+		Context("when the backup is corrupt", func() {
+			BeforeEach(func() {
+				Expect(ioutil.WriteFile(serializer.BackupPath, []byte(`{"chec`), 0600)).To(Succeed())
+			})
+
+			It("returns a corrupt error", func() {
+				err := serializer.DecodeAll(file, &outData)
+				Expect(err).To(BeAssignableToTypeOf(&serial.CorruptError{}))
+				Expect(err).To(MatchError("corrupt: empty file and decoding backup: unexpected end of JSON input"))
+			})
+		})
+	})
+
+	Context("when a write was interrupted part way through", func() {
+		BeforeEach(func() {
+			Expect(serializer.EncodeAndOverwrite(file, map[string]string{"some": "data"})).To(Succeed())
+			Expect(file.Truncate(20)).To(Succeed())
+		})
+
+		It("recovers it from the backup", func() {
+			Expect(serializer.DecodeAll(file, &outData)).To(Succeed())
+			Expect(outData.Some).To(Equal("data"))
+		})
+	})
+
+	Context("when the file was written without a checksum", func() {
+		It("decodes it as it is", func() {
+			Expect(serializer.DecodeAll(strings.NewReader(`{ "some": "data" }`), &outData)).To(Succeed())
+			Expect(outData.Some).To(Equal("data"))
+		})
+	})
+
+	Context("when the file is corrupt", func() {
+		BeforeEach(func() {
+			Expect(serializer.EncodeAndOverwrite(file, map[string]string{"some": "data"})).To(Succeed())
+		})
+
+		It("recovers it from the backup", func() {
+			Expect(serializer.DecodeAll(strings.NewReader(`{"checksum": "sha256:c67cd`), &outData)).To(Succeed())
+			Expect(outData.Some).To(Equal("data"))
+		})
+
+		Context("when the checksum does not match", func() {
+			It("recovers it from the backup", func() {
+				corrupt := strings.NewReader(`{"checksum": "sha256:0000", "data": {"some": "other data"}}`)
+				Expect(serializer.DecodeAll(corrupt, &outData)).To(Succeed())
+				Expect(outData.Some).To(Equal("data"))
+			})
+		})
+
+		Context("when the backup is corrupt too", func() {
+			BeforeEach(func() {
+				Expect(ioutil.WriteFile(serializer.BackupPath, []byte(`{"checksum": "sha256:0000", "data": {}}`), 0600)).To(Succeed())
+			})
+
+			It("returns a corrupt error", func() {
+				err := serializer.DecodeAll(strings.NewReader(`{"chec`), &outData)
+				Expect(err).To(BeAssignableToTypeOf(&serial.CorruptError{}))
+				Expect(err).To(MatchError("corrupt: unexpected end of JSON input and decoding backup: checksum mismatch"))
+			})
+		})
+
+		Context("when there is no backup", func() {
+			BeforeEach(func() {
+				serializer.BackupPath = ""
+			})
+
+			It("returns a corrupt error", func() {
+				err := serializer.DecodeAll(strings.NewReader(`{"chec`), &outData)
+				Expect(err).To(MatchError("corrupt: unexpected end of JSON input"))
+			})
+		})
+	})
+
+	Context("when seek fails", func() {
+		var file *fakes.OverwriteableFile
+		BeforeEach(func() {
+			file = &fakes.OverwriteableFile{}
+			file.SeekReturns(0, errors.New("banana"))
+		})
+		It("returns the error", func() {
+			Expect(serializer.DecodeAll(file, &outData)).To(MatchError("banana"))
+			Expect(serializer.EncodeAndOverwrite(file, outData)).To(MatchError("banana"))
+		})
+	})
+
+	Context("when writing the backup fails", func() {
+		BeforeEach(func() {
+			serializer.BackupPath = filepath.Join(dir, "missing-dir", "state.json.backup")
+		})
+		It("leaves the file alone and returns the error", func() {
+			err := serializer.EncodeAndOverwrite(file, outData)
+			Expect(err).To(MatchError(ContainSubstring("writing backup: ")))
+
+			fileBytes, err := ioutil.ReadFile(file.Name())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fileBytes).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,229 @@
+package testsupport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var builtinChains = map[string][]string{
+	"filter": {"INPUT", "FORWARD", "OUTPUT"},
+	"nat":    {"PREROUTING", "INPUT", "OUTPUT", "POSTROUTING"},
+	"mangle": {"PREROUTING", "INPUT", "FORWARD", "OUTPUT", "POSTROUTING"},
+}
+
+// InMemoryIPTables stands in for the kernel's iptables state. It can be used
+// both as the iptables and as the restorer of a rules.LockedIPTables.
+type InMemoryIPTables struct {
+	mutex  sync.Mutex
+	chains map[string][]string
+}
+
+func NewInMemoryIPTables() *InMemoryIPTables {
+	i := &InMemoryIPTables{chains: map[string][]string{}}
+	for table, chains := range builtinChains {
+		for _, chain := range chains {
+			i.chains[table+" "+chain] = []string{}
+		}
+	}
+	return i
+}
+
+func (i *InMemoryIPTables) chain(table, chain string) ([]string, error) {
+	existing, ok := i.chains[table+" "+chain]
+	if !ok {
+		return nil, fmt.Errorf("chain %s does not exist in table %s", chain, table)
+	}
+	return existing, nil
+}
+
+func (i *InMemoryIPTables) insert(table, chain string, pos int, rule string) error {
+	existing, err := i.chain(table, chain)
+	if err != nil {
+		return err
+	}
+	if pos < 1 || pos > len(existing)+1 {
+		return fmt.Errorf("index of insertion too big")
+	}
+	existing = append(existing, "")
+	copy(existing[pos:], existing[pos-1:])
+	existing[pos-1] = rule
+	i.chains[table+" "+chain] = existing
+	return nil
+}
+
+func (i *InMemoryIPTables) Exists(table, chain string, rulespec ...string) (bool, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	existing, err := i.chain(table, chain)
+	if err != nil {
+		return false, err
+	}
+	return indexOf(existing, strings.Join(rulespec, " ")) != -1, nil
+}
+
+func (i *InMemoryIPTables) Insert(table, chain string, pos int, rulespec ...string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return i.insert(table, chain, pos, strings.Join(rulespec, " "))
+}
+
+func (i *InMemoryIPTables) AppendUnique(table, chain string, rulespec ...string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	existing, err := i.chain(table, chain)
+	if err != nil {
+		return err
+	}
+	rule := strings.Join(rulespec, " ")
+	if indexOf(existing, rule) == -1 {
+		i.chains[table+" "+chain] = append(existing, rule)
+	}
+	return nil
+}
+
+func (i *InMemoryIPTables) Delete(table, chain string, rulespec ...string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	existing, err := i.chain(table, chain)
+	if err != nil {
+		return err
+	}
+	index := indexOf(existing, strings.Join(rulespec, " "))
+	if index == -1 {
+		return fmt.Errorf("bad rule (does a matching rule exist in that chain?)")
+	}
+	i.chains[table+" "+chain] = append(existing[:index], existing[index+1:]...)
+	return nil
+}
+
+// List returns the chain in the format of `iptables -S`.
+func (i *InMemoryIPTables) List(table, chain string) ([]string, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	existing, err := i.chain(table, chain)
+	if err != nil {
+		return nil, err
+	}
+	ret := []string{fmt.Sprintf("-N %s", chain)}
+	for _, builtin := range builtinChains[table] {
+		if builtin == chain {
+			ret = []string{fmt.Sprintf("-P %s ACCEPT", chain)}
+		}
+	}
+	for _, rule := range existing {
+		ret = append(ret, fmt.Sprintf("-A %s %s", chain, rule))
+	}
+	return ret, nil
+}
+
+func (i *InMemoryIPTables) NewChain(table, chain string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if _, err := i.chain(table, chain); err == nil {
+		return fmt.Errorf("chain already exists")
+	}
+	i.chains[table+" "+chain] = []string{}
+	return nil
+}
+
+func (i *InMemoryIPTables) ClearChain(table, chain string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.chains[table+" "+chain] = []string{}
+	return nil
+}
+
+func (i *InMemoryIPTables) DeleteChain(table, chain string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	existing, err := i.chain(table, chain)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("directory not empty")
+	}
+	delete(i.chains, table+" "+chain)
+	return nil
+}
+
+// Restore applies input in the format written by rules.LockedIPTables, or
+// none of it if any line fails.
+func (i *InMemoryIPTables) Restore(input string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	saved := map[string][]string{}
+	for name, existing := range i.chains {
+		saved[name] = append([]string{}, existing...)
+	}
+
+	err := i.restore(input)
+	if err != nil {
+		i.chains = saved
+	}
+	return err
+}
+
+func (i *InMemoryIPTables) restore(input string) error {
+	table := ""
+	for _, line := range strings.Split(input, "\n") {
+		switch {
+		case line == "" || line == "COMMIT":
+		case strings.HasPrefix(line, "*"):
+			table = strings.TrimPrefix(line, "*")
+		case strings.HasPrefix(line, "-A "):
+			fields := strings.SplitN(line, " ", 3)
+			existing, err := i.chain(table, fields[1])
+			if err != nil {
+				return err
+			}
+			i.chains[table+" "+fields[1]] = append(existing, fields[2])
+		case strings.HasPrefix(line, "-D "):
+			fields := strings.SplitN(line, " ", 3)
+			existing, err := i.chain(table, fields[1])
+			if err != nil {
+				return err
+			}
+			index, err := strconv.Atoi(fields[2])
+			if err != nil {
+				index = indexOf(existing, fields[2]) + 1
+			}
+			if index < 1 || index > len(existing) {
+				return fmt.Errorf("bad rule in %q", line)
+			}
+			i.chains[table+" "+fields[1]] = append(existing[:index-1], existing[index:]...)
+		case strings.HasPrefix(line, "-I "):
+			fields := strings.SplitN(line, " ", 4)
+			pos, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return fmt.Errorf("bad position in %q", line)
+			}
+			if err := i.insert(table, fields[1], pos, fields[3]); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported restore line %q", line)
+		}
+	}
+	return nil
+}
+
+func indexOf(existing []string, rule string) int {
+	for i, r := range existing {
+		if r == rule {
+			return i
+		}
+	}
+	return -1
+}
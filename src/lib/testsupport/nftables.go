@@ -0,0 +1,421 @@
+package testsupport
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/expr"
+)
+
+// InMemoryNFT stands in for the kernel's nftables state behind a
+// *nftables.Conn. Changes are queued until Flush, which applies them all or,
+// if any of them fails, none of them, the way the kernel applies a netlink
+// batch. It fails where the kernel would for the changes rules.NFTables and
+// rules.NFTSets make: missing tables, chains and sets, rule handles and
+// positions that do not exist, and chains or sets that are still in use.
+type InMemoryNFT struct {
+	mutex     sync.Mutex
+	state     nftState
+	pending   []func(*nftState) error
+	nextSetID uint32
+}
+
+type nftState struct {
+	tables     map[string]*nftTable
+	nextHandle uint64
+}
+
+type nftTable struct {
+	table  *nftables.Table
+	chains []*nftChain
+	sets   map[string]*nftSet
+}
+
+type nftChain struct {
+	chain *nftables.Chain
+	rules []*nftables.Rule
+}
+
+type nftSet struct {
+	set      *nftables.Set
+	elements []nftables.SetElement
+}
+
+func NewInMemoryNFT() *InMemoryNFT {
+	return &InMemoryNFT{
+		state: nftState{tables: map[string]*nftTable{}},
+	}
+}
+
+func tableKey(family nftables.TableFamily, name string) string {
+	return fmt.Sprintf("%d %s", family, name)
+}
+
+func (s *nftState) copy() nftState {
+	tables := map[string]*nftTable{}
+	for key, t := range s.tables {
+		copied := &nftTable{table: t.table, sets: map[string]*nftSet{}}
+		for _, c := range t.chains {
+			copied.chains = append(copied.chains, &nftChain{chain: c.chain, rules: append([]*nftables.Rule{}, c.rules...)})
+		}
+		for name, set := range t.sets {
+			copied.sets[name] = &nftSet{set: set.set, elements: append([]nftables.SetElement{}, set.elements...)}
+		}
+		tables[key] = copied
+	}
+	return nftState{tables: tables, nextHandle: s.nextHandle}
+}
+
+func (s *nftState) table(t *nftables.Table) (*nftTable, error) {
+	table, ok := s.tables[tableKey(t.Family, t.Name)]
+	if !ok {
+		return nil, fmt.Errorf("table %s does not exist", t.Name)
+	}
+	return table, nil
+}
+
+func (t *nftTable) chain(name string) (*nftChain, error) {
+	for _, c := range t.chains {
+		if c.chain.Name == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("chain %s does not exist", name)
+}
+
+func (s *nftState) chain(t *nftables.Table, name string) (*nftTable, *nftChain, error) {
+	table, err := s.table(t)
+	if err != nil {
+		return nil, nil, err
+	}
+	chain, err := table.chain(name)
+	return table, chain, err
+}
+
+func (s *nftState) set(t *nftables.Table, name string) (*nftSet, error) {
+	table, err := s.table(t)
+	if err != nil {
+		return nil, err
+	}
+	set, ok := table.sets[name]
+	if !ok {
+		return nil, fmt.Errorf("set %s does not exist", name)
+	}
+	return set, nil
+}
+
+// references reports whether a rule of the table jumps to the chain or looks
+// up the set with the name.
+func (t *nftTable) references(name string, isSet bool) bool {
+	for _, c := range t.chains {
+		for _, r := range c.rules {
+			for _, e := range r.Exprs {
+				switch e := e.(type) {
+				case *expr.Lookup:
+					if isSet && e.SetName == name {
+						return true
+					}
+				case *expr.Verdict:
+					if !isSet && (e.Kind == expr.VerdictJump || e.Kind == expr.VerdictGoto) && e.Chain == name {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (t *nftTable) validate(rule *nftables.Rule) error {
+	for _, e := range rule.Exprs {
+		switch e := e.(type) {
+		case *expr.Lookup:
+			if _, ok := t.sets[e.SetName]; !ok {
+				return fmt.Errorf("set %s does not exist", e.SetName)
+			}
+		case *expr.Verdict:
+			if e.Kind == expr.VerdictJump || e.Kind == expr.VerdictGoto {
+				if _, err := t.chain(e.Chain); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (n *InMemoryNFT) queue(change func(*nftState) error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.pending = append(n.pending, change)
+}
+
+func (n *InMemoryNFT) AddTable(t *nftables.Table) *nftables.Table {
+	n.queue(func(s *nftState) error {
+		key := tableKey(t.Family, t.Name)
+		if _, ok := s.tables[key]; !ok {
+			s.tables[key] = &nftTable{table: t, sets: map[string]*nftSet{}}
+		}
+		return nil
+	})
+	return t
+}
+
+func (n *InMemoryNFT) AddChain(c *nftables.Chain) *nftables.Chain {
+	n.queue(func(s *nftState) error {
+		table, err := s.table(c.Table)
+		if err != nil {
+			return err
+		}
+		if _, err := table.chain(c.Name); err != nil {
+			table.chains = append(table.chains, &nftChain{chain: c})
+		}
+		return nil
+	})
+	return c
+}
+
+func (n *InMemoryNFT) FlushChain(c *nftables.Chain) {
+	n.queue(func(s *nftState) error {
+		_, chain, err := s.chain(c.Table, c.Name)
+		if err != nil {
+			return err
+		}
+		chain.rules = nil
+		return nil
+	})
+}
+
+func (n *InMemoryNFT) DelChain(c *nftables.Chain) {
+	n.queue(func(s *nftState) error {
+		table, chain, err := s.chain(c.Table, c.Name)
+		if err != nil {
+			return err
+		}
+		if len(chain.rules) > 0 {
+			return fmt.Errorf("chain %s is not empty", c.Name)
+		}
+		if table.references(c.Name, false) {
+			return fmt.Errorf("chain %s is in use", c.Name)
+		}
+		for i, existing := range table.chains {
+			if existing == chain {
+				table.chains = append(table.chains[:i], table.chains[i+1:]...)
+			}
+		}
+		return nil
+	})
+}
+
+func (n *InMemoryNFT) ListChainsOfTableFamily(family nftables.TableFamily) ([]*nftables.Chain, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	keys := []string{}
+	for key, t := range n.state.tables {
+		if t.table.Family == family {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	chains := []*nftables.Chain{}
+	for _, key := range keys {
+		t := n.state.tables[key]
+		for _, c := range t.chains {
+			chain := *c.chain
+			chain.Table = t.table
+			chains = append(chains, &chain)
+		}
+	}
+	return chains, nil
+}
+
+func (n *InMemoryNFT) GetRules(t *nftables.Table, c *nftables.Chain) ([]*nftables.Rule, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	table, chain, err := n.state.chain(t, c.Name)
+	if err != nil {
+		return nil, err
+	}
+	listed := []*nftables.Rule{}
+	for _, r := range chain.rules {
+		rule := *r
+		rule.Table, rule.Chain, rule.Position = table.table, chain.chain, 0
+		listed = append(listed, &rule)
+	}
+	return listed, nil
+}
+
+func (n *InMemoryNFT) addRule(r *nftables.Rule, insert bool) {
+	rule := *r
+	rule.Exprs = append([]expr.Any{}, r.Exprs...)
+	n.queue(func(s *nftState) error {
+		table, chain, err := s.chain(rule.Table, rule.Chain.Name)
+		if err != nil {
+			return err
+		}
+		if err := table.validate(&rule); err != nil {
+			return err
+		}
+
+		pos := len(chain.rules)
+		if insert {
+			pos = 0
+		}
+		if rule.Position != 0 {
+			pos = -1
+			for i, existing := range chain.rules {
+				if existing.Handle == rule.Position {
+					pos = i
+					if !insert {
+						pos++
+					}
+				}
+			}
+			if pos == -1 {
+				return fmt.Errorf("rule with handle %d does not exist in chain %s", rule.Position, chain.chain.Name)
+			}
+		}
+
+		s.nextHandle++
+		added := rule
+		added.Handle, added.Position = s.nextHandle, 0
+		chain.rules = append(chain.rules[:pos], append([]*nftables.Rule{&added}, chain.rules[pos:]...)...)
+		return nil
+	})
+}
+
+// AddRule appends the rule, or adds it after the rule with the handle in
+// Position.
+func (n *InMemoryNFT) AddRule(r *nftables.Rule) *nftables.Rule {
+	n.addRule(r, false)
+	return r
+}
+
+// InsertRule prepends the rule, or inserts it before the rule with the
+// handle in Position.
+func (n *InMemoryNFT) InsertRule(r *nftables.Rule) *nftables.Rule {
+	n.addRule(r, true)
+	return r
+}
+
+func (n *InMemoryNFT) DelRule(r *nftables.Rule) error {
+	if r.Handle == 0 {
+		return fmt.Errorf("rule must have a handle")
+	}
+	table, chain, handle := r.Table, r.Chain.Name, r.Handle
+	n.queue(func(s *nftState) error {
+		_, c, err := s.chain(table, chain)
+		if err != nil {
+			return err
+		}
+		for i, existing := range c.rules {
+			if existing.Handle == handle {
+				c.rules = append(c.rules[:i], c.rules[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("rule with handle %d does not exist in chain %s", handle, chain)
+	})
+	return nil
+}
+
+func (n *InMemoryNFT) AddSet(set *nftables.Set, vals []nftables.SetElement) error {
+	n.mutex.Lock()
+	if set.ID == 0 {
+		n.nextSetID++
+		set.ID = n.nextSetID
+	}
+	n.mutex.Unlock()
+
+	elements := append([]nftables.SetElement{}, vals...)
+	n.queue(func(s *nftState) error {
+		table, err := s.table(set.Table)
+		if err != nil {
+			return err
+		}
+		existing, ok := table.sets[set.Name]
+		if !ok {
+			existing = &nftSet{set: set}
+			table.sets[set.Name] = existing
+		}
+		existing.elements = append(existing.elements, elements...)
+		return nil
+	})
+	return nil
+}
+
+func (n *InMemoryNFT) FlushSet(set *nftables.Set) {
+	n.queue(func(s *nftState) error {
+		existing, err := s.set(set.Table, set.Name)
+		if err != nil {
+			return err
+		}
+		existing.elements = nil
+		return nil
+	})
+}
+
+func (n *InMemoryNFT) SetAddElements(set *nftables.Set, vals []nftables.SetElement) error {
+	elements := append([]nftables.SetElement{}, vals...)
+	n.queue(func(s *nftState) error {
+		existing, err := s.set(set.Table, set.Name)
+		if err != nil {
+			return err
+		}
+		existing.elements = append(existing.elements, elements...)
+		return nil
+	})
+	return nil
+}
+
+func (n *InMemoryNFT) DelSet(set *nftables.Set) {
+	n.queue(func(s *nftState) error {
+		table, err := s.table(set.Table)
+		if err != nil {
+			return err
+		}
+		if _, ok := table.sets[set.Name]; !ok {
+			return fmt.Errorf("set %s does not exist", set.Name)
+		}
+		if table.references(set.Name, true) {
+			return fmt.Errorf("set %s is in use", set.Name)
+		}
+		delete(table.sets, set.Name)
+		return nil
+	})
+}
+
+// Flush applies the queued changes, or none of them if any fails.
+func (n *InMemoryNFT) Flush() error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	pending := n.pending
+	n.pending = nil
+	state := n.state.copy()
+	for _, change := range pending {
+		if err := change(&state); err != nil {
+			return err
+		}
+	}
+	n.state = state
+	return nil
+}
+
+// SetElements returns the elements of a set, for tests to check what a set
+// holds.
+func (n *InMemoryNFT) SetElements(family nftables.TableFamily, table, name string) ([]nftables.SetElement, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	set, err := n.state.set(&nftables.Table{Family: family, Name: table}, name)
+	if err != nil {
+		return nil, err
+	}
+	return append([]nftables.SetElement{}, set.elements...), nil
+}
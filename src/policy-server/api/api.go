@@ -35,28 +35,34 @@ type EgressPoliciesPayload struct {
 type Policy struct {
 	Source      Source      `json:"source"`
 	Destination Destination `json:"destination"`
+	Log         *bool       `json:"log,omitempty"`
 }
 
 type EgressPolicy struct {
 	ID          string             `json:"id,omitempty"`
 	Source      *EgressSource      `json:"source"`
 	Destination *EgressDestination `json:"destination"`
+	Log         bool               `json:"log,omitempty"`
 }
 
 type EgressSource struct {
-	ID   string `json:"id"`
+	ID   string `json:"id,omitempty"`
 	Type string `json:"type,omitempty"`
 }
 
 type EgressDestination struct {
-	GUID        string    `json:"id,omitempty"`
-	Name        string    `json:"name,omitempty"`
-	Description string    `json:"description,omitempty"`
-	Protocol    string    `json:"protocol,omitempty"`
-	Ports       []Ports   `json:"ports,omitempty"`
-	IPRanges    []IPRange `json:"ips,omitempty"`
-	ICMPType    *int      `json:"icmp_type,omitempty"`
-	ICMPCode    *int      `json:"icmp_code,omitempty"`
+	GUID                string    `json:"id,omitempty"`
+	Name                string    `json:"name,omitempty"`
+	Description         string    `json:"description,omitempty"`
+	FQDN                string    `json:"fqdn,omitempty"`
+	FQDNResolutionError string    `json:"fqdn_resolution_error,omitempty"`
+	OrgGUID             string    `json:"org_id,omitempty"`
+	SelfService         bool      `json:"self_service,omitempty"`
+	Protocol            string    `json:"protocol,omitempty"`
+	Ports               []Ports   `json:"ports,omitempty"`
+	IPRanges            []IPRange `json:"ips,omitempty"`
+	ICMPType            *int      `json:"icmp_type,omitempty"`
+	ICMPCode            *int      `json:"icmp_code,omitempty"`
 }
 
 type Source struct {
@@ -75,8 +81,9 @@ type Destination struct {
 }
 
 type IPRange struct {
-	Start string `json:"start"`
-	End   string `json:"end"`
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+	CIDR  string `json:"cidr,omitempty"`
 }
 
 type Ports struct {
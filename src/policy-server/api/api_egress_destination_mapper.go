@@ -21,18 +21,43 @@ type egressDestinationsValidator interface {
 type DestinationsPayload struct {
 	TotalDestinations  int                 `json:"total_destinations"`
 	EgressDestinations []EgressDestination `json:"destinations"`
+	Warnings           []string            `json:"warnings,omitempty"`
+}
+
+type DestinationOverlapGroup struct {
+	EgressDestinations []EgressDestination `json:"destinations"`
+}
+
+type DestinationOverlapsPayload struct {
+	TotalGroups int                       `json:"total_groups"`
+	Groups      []DestinationOverlapGroup `json:"groups"`
 }
 
 func (p *EgressDestinationMapper) AsBytes(egressDestinations []store.EgressDestination) ([]byte, error) {
-	apiEgressDestinations := make([]EgressDestination, len(egressDestinations))
+	return p.AsBytesWithWarnings(egressDestinations, nil)
+}
 
-	for i, storeEgressDestination := range egressDestinations {
-		apiEgressDestinations[i] = asApiEgressDestination(storeEgressDestination)
+func (p *EgressDestinationMapper) AsBytesWithWarnings(egressDestinations []store.EgressDestination, warnings []string) ([]byte, error) {
+	payload := &DestinationsPayload{
+		TotalDestinations:  len(egressDestinations),
+		EgressDestinations: asApiEgressDestinations(egressDestinations),
+		Warnings:           warnings,
 	}
 
-	payload := &DestinationsPayload{
-		TotalDestinations:  len(apiEgressDestinations),
-		EgressDestinations: apiEgressDestinations,
+	bytes, err := p.Marshaler.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal json: %s", err)
+	}
+	return bytes, nil
+}
+
+func (p *EgressDestinationMapper) AsOverlapGroupsBytes(groups [][]store.EgressDestination) ([]byte, error) {
+	payload := &DestinationOverlapsPayload{
+		TotalGroups: len(groups),
+		Groups:      make([]DestinationOverlapGroup, len(groups)),
+	}
+	for i, group := range groups {
+		payload.Groups[i].EgressDestinations = asApiEgressDestinations(group)
 	}
 
 	bytes, err := p.Marshaler.Marshal(payload)
@@ -61,30 +86,44 @@ func (p *EgressDestinationMapper) AsEgressDestinations(egressDestinations []byte
 	return storeEgressDestinations, nil
 }
 
+func asApiEgressDestinations(egressDestinations []store.EgressDestination) []EgressDestination {
+	apiEgressDestinations := make([]EgressDestination, len(egressDestinations))
+
+	for i, storeEgressDestination := range egressDestinations {
+		apiEgressDestinations[i] = asApiEgressDestination(storeEgressDestination)
+		apiEgressDestinations[i].IPRanges = withCIDRs(apiEgressDestinations[i].IPRanges)
+		apiEgressDestinations[i].FQDN = storeEgressDestination.FQDN
+		apiEgressDestinations[i].FQDNResolutionError = storeEgressDestination.FQDNResolutionError
+	}
+	return apiEgressDestinations
+}
+
 func asApiEgressDestination(storeEgressDestination store.EgressDestination) EgressDestination {
 	var ports []Ports
-
-	if len(storeEgressDestination.Ports) > 0 {
-		ports = []Ports{
-			{
-				Start: storeEgressDestination.Ports[0].Start,
-				End:   storeEgressDestination.Ports[0].End,
-			},
-		}
+	for _, storePorts := range storeEgressDestination.Ports {
+		ports = append(ports, Ports{
+			Start: storePorts.Start,
+			End:   storePorts.End,
+		})
 	}
 
-	firstIPRange := storeEgressDestination.IPRanges[0]
+	var ipRanges []IPRange
+	for _, storeIPRange := range storeEgressDestination.IPRanges {
+		ipRanges = append(ipRanges, IPRange{
+			Start: storeIPRange.Start,
+			End:   storeIPRange.End,
+		})
+	}
 
 	apiEgressDestination := &EgressDestination{
 		GUID:        storeEgressDestination.GUID,
 		Name:        storeEgressDestination.Name,
 		Description: storeEgressDestination.Description,
+		OrgGUID:     storeEgressDestination.OrgGUID,
+		SelfService: storeEgressDestination.SelfService,
 		Protocol:    storeEgressDestination.Protocol,
 		Ports:       ports,
-		IPRanges: []IPRange{{
-			Start: firstIPRange.Start,
-			End:   firstIPRange.End,
-		}},
+		IPRanges:    ipRanges,
 	}
 
 	if storeEgressDestination.Protocol == "icmp" {
@@ -99,10 +138,7 @@ func asApiEgressDestination(storeEgressDestination store.EgressDestination) Egre
 func (d *EgressDestination) asStoreEgressDestination() store.EgressDestination {
 	ipRanges := []store.IPRange{}
 	for _, apiIPRange := range d.IPRanges {
-		ipRanges = append(ipRanges, store.IPRange{
-			Start: apiIPRange.Start,
-			End:   apiIPRange.End,
-		})
+		ipRanges = append(ipRanges, asStoreIPRange(apiIPRange))
 	}
 	ports := []store.Ports{}
 
@@ -117,6 +153,9 @@ func (d *EgressDestination) asStoreEgressDestination() store.EgressDestination {
 		GUID:        d.GUID,
 		Name:        d.Name,
 		Description: d.Description,
+		FQDN:        d.FQDN,
+		OrgGUID:     d.OrgGUID,
+		SelfService: d.SelfService,
 		Protocol:    d.Protocol,
 		Ports:       ports,
 		IPRanges:    ipRanges,
@@ -59,13 +59,29 @@ var _ = Describe("ApiEgressDestinationMapper", func() {
 					ICMPCode: 6,
 				},
 				{
-					GUID:     "3",
-					Protocol: "udp",
+					GUID:        "3",
+					OrgGUID:     "some-org-guid",
+					SelfService: true,
+					Protocol:    "udp",
 					IPRanges: []store.IPRange{{
 						Start: "1.2.3.7",
 						End:   "1.2.3.8",
 					}},
 				},
+				{
+					GUID:     "4",
+					Protocol: "tcp",
+					Ports: []store.Ports{
+						{Start: 443, End: 443},
+						{Start: 8080, End: 8090},
+					},
+					IPRanges: []store.IPRange{
+						{Start: "1.2.3.4", End: "1.2.3.5"},
+						{Start: "10.0.0.0", End: "10.0.0.255"},
+						{Start: "2001:db8::1", End: "2001:db8::ff"},
+						{Start: "2001:db8::", End: "2001:db8::ffff"},
+					},
+				},
 			}
 		})
 
@@ -74,14 +90,14 @@ var _ = Describe("ApiEgressDestinationMapper", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(payload).To(MatchJSON(
 				[]byte(`{
-					"total_destinations": 3,
+					"total_destinations": 4,
 					"destinations": [
 						{
 							"id": "1",
 							"name": " ",
 							"protocol": "tcp",
 							"ports": [{ "start": 8080, "end": 8081 }],
-							"ips": [{ "start": "1.2.3.4", "end": "1.2.3.5" }]
+							"ips": [{ "start": "1.2.3.4", "end": "1.2.3.5", "cidr": "1.2.3.4/31" }]
 						},
 						{
 							"id": "2",
@@ -93,12 +109,139 @@ var _ = Describe("ApiEgressDestinationMapper", func() {
 						},
  						{
 							"id": "3",
+							"org_id": "some-org-guid",
+							"self_service": true,
 							"protocol": "udp",
 							"ips": [{ "start": "1.2.3.7", "end": "1.2.3.8" }]
+						},
+						{
+							"id": "4",
+							"protocol": "tcp",
+							"ports": [{ "start": 443, "end": 443 }, { "start": 8080, "end": 8090 }],
+							"ips": [
+								{ "start": "1.2.3.4", "end": "1.2.3.5", "cidr": "1.2.3.4/31" },
+								{ "start": "10.0.0.0", "end": "10.0.0.255", "cidr": "10.0.0.0/24" },
+								{ "start": "2001:db8::1", "end": "2001:db8::ff" },
+								{ "start": "2001:db8::", "end": "2001:db8::ffff", "cidr": "2001:db8::/112" }
+							]
 						}
 					]
 				}`)))
 		})
+		Context("when a destination has an fqdn", func() {
+			BeforeEach(func() {
+				egressDestinations = []store.EgressDestination{
+					{
+						GUID:                "5",
+						Name:                "saas",
+						FQDN:                "api.example.com",
+						FQDNResolutionError: "lookup api.example.com: no such host",
+						Protocol:            "tcp",
+						Ports:               []store.Ports{{Start: 443, End: 443}},
+						IPRanges:            []store.IPRange{{Start: "192.0.2.10", End: "192.0.2.10"}},
+					},
+				}
+			})
+
+			It("includes the fqdn, the resolution error and the last resolved ips", func() {
+				payload, err := mapper.AsBytes(egressDestinations)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(payload).To(MatchJSON(`{
+					"total_destinations": 1,
+					"destinations": [
+						{
+							"id": "5",
+							"name": "saas",
+							"fqdn": "api.example.com",
+							"fqdn_resolution_error": "lookup api.example.com: no such host",
+							"protocol": "tcp",
+							"ports": [{ "start": 443, "end": 443 }],
+							"ips": [{ "start": "192.0.2.10", "end": "192.0.2.10", "cidr": "192.0.2.10/32" }]
+						}
+					]
+				}`))
+			})
+		})
+	})
+
+	Describe("AsBytesWithWarnings", func() {
+		It("includes the warnings", func() {
+			payload, err := mapper.AsBytesWithWarnings([]store.EgressDestination{
+				{
+					GUID:     "1",
+					Name:     "a",
+					Protocol: "udp",
+					IPRanges: []store.IPRange{{Start: "10.0.0.1", End: "10.0.0.1"}},
+				},
+			}, []string{"destination 'a' overlaps destination 'b'"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payload).To(MatchJSON(`{
+				"total_destinations": 1,
+				"destinations": [
+					{
+						"id": "1",
+						"name": "a",
+						"protocol": "udp",
+						"ips": [{ "start": "10.0.0.1", "end": "10.0.0.1", "cidr": "10.0.0.1/32" }]
+					}
+				],
+				"warnings": ["destination 'a' overlaps destination 'b'"]
+			}`))
+		})
+
+		Context("when marshaling fails", func() {
+			BeforeEach(func() {
+				mapper.Marshaler = marshal.MarshalFunc(func(interface{}) ([]byte, error) {
+					return nil, errors.New("banana")
+				})
+			})
+
+			It("returns an error", func() {
+				_, err := mapper.AsBytesWithWarnings(nil, nil)
+				Expect(err).To(MatchError("marshal json: banana"))
+			})
+		})
+	})
+
+	Describe("AsOverlapGroupsBytes", func() {
+		It("marshals each group of destinations", func() {
+			payload, err := mapper.AsOverlapGroupsBytes([][]store.EgressDestination{
+				{
+					{GUID: "1", Name: "a", Protocol: "tcp", IPRanges: []store.IPRange{{Start: "10.0.0.0", End: "10.0.0.255"}}, Ports: []store.Ports{{Start: 443, End: 443}}},
+					{GUID: "2", Name: "b", Protocol: "tcp", IPRanges: []store.IPRange{{Start: "10.0.0.5", End: "10.0.0.5"}}, Ports: []store.Ports{{Start: 443, End: 443}}},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payload).To(MatchJSON(`{
+				"total_groups": 1,
+				"groups": [
+					{
+						"destinations": [
+							{
+								"id": "1",
+								"name": "a",
+								"protocol": "tcp",
+								"ports": [{ "start": 443, "end": 443 }],
+								"ips": [{ "start": "10.0.0.0", "end": "10.0.0.255", "cidr": "10.0.0.0/24" }]
+							},
+							{
+								"id": "2",
+								"name": "b",
+								"protocol": "tcp",
+								"ports": [{ "start": 443, "end": 443 }],
+								"ips": [{ "start": "10.0.0.5", "end": "10.0.0.5", "cidr": "10.0.0.5/32" }]
+							}
+						]
+					}
+				]
+			}`))
+		})
+
+		It("marshals an empty list of groups", func() {
+			payload, err := mapper.AsOverlapGroupsBytes(nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payload).To(MatchJSON(`{"total_groups": 0, "groups": []}`))
+		})
 	})
 
 	Describe("AsEgressDestinations", func() {
@@ -131,6 +274,8 @@ var _ = Describe("ApiEgressDestinationMapper", func() {
 						},
 						{
 							"id": "4",
+							"org_id": "some-org-guid",
+							"self_service": true,
 							"protocol": "udp",
 							"ips": [{ "start": "1.2.3.7", "end": "1.2.3.8" }]
 						}
@@ -181,9 +326,11 @@ var _ = Describe("ApiEgressDestinationMapper", func() {
 						ICMPCode: -1,
 					},
 					{
-						GUID:     "4",
-						Protocol: "udp",
-						Ports:    []store.Ports{},
+						GUID:        "4",
+						OrgGUID:     "some-org-guid",
+						SelfService: true,
+						Protocol:    "udp",
+						Ports:       []store.Ports{},
 						IPRanges: []store.IPRange{{
 							Start: "1.2.3.7",
 							End:   "1.2.3.8",
@@ -193,6 +340,53 @@ var _ = Describe("ApiEgressDestinationMapper", func() {
 			)
 		})
 
+		Context("when ip ranges are given as cidrs or ipv6 addresses", func() {
+			It("normalizes them to start and end addresses", func() {
+				payload, err := mapper.AsEgressDestinations([]byte(`{
+					"destinations": [
+						{
+							"name": "cidrs",
+							"protocol": "tcp",
+							"ports": [{ "start": 443, "end": 443 }],
+							"ips": [
+								{ "cidr": "10.1.2.3/8" },
+								{ "cidr": "2001:DB8::/32" },
+								{ "start": "2001:0db8:0000:0000:0000:0000:0000:0001", "end": "2001:db8::00ff" }
+							]
+						}
+					]
+				}`))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(payload).To(HaveLen(1))
+				Expect(payload[0].IPRanges).To(Equal([]store.IPRange{
+					{Start: "10.0.0.0", End: "10.255.255.255"},
+					{Start: "2001:db8::", End: "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff"},
+					{Start: "2001:db8::1", End: "2001:db8::ff"},
+				}))
+			})
+		})
+
+		Context("when a destination is given as an fqdn", func() {
+			It("keeps the fqdn and ignores the resolution error", func() {
+				payload, err := mapper.AsEgressDestinations([]byte(`{
+					"destinations": [
+						{
+							"name": "saas",
+							"fqdn": "api.example.com",
+							"fqdn_resolution_error": "ignored",
+							"protocol": "tcp",
+							"ports": [{ "start": 443, "end": 443 }]
+						}
+					]
+				}`))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(payload).To(HaveLen(1))
+				Expect(payload[0].FQDN).To(Equal("api.example.com"))
+				Expect(payload[0].FQDNResolutionError).To(BeEmpty())
+				Expect(payload[0].IPRanges).To(BeEmpty())
+			})
+		})
+
 		Context("when there is a json unmarshalling error", func() {
 			It("returns an error", func() {
 				_, err := mapper.AsEgressDestinations([]byte("%%%"))
@@ -85,10 +85,16 @@ func (p *Policy) asStorePolicy() store.Policy {
 				End:   p.Destination.Ports.End,
 			},
 		},
+		Log:     p.Log != nil && *p.Log,
+		KeepLog: p.Log == nil,
 	}
 }
 
 func mapStorePolicy(storePolicy store.Policy) Policy {
+	var log *bool
+	if storePolicy.Log {
+		log = &storePolicy.Log
+	}
 	return Policy{
 		Source: Source{
 			ID:  storePolicy.Source.ID,
@@ -103,6 +109,7 @@ func mapStorePolicy(storePolicy store.Policy) Policy {
 				End:   storePolicy.Destination.Ports.End,
 			},
 		},
+		Log: log,
 	}
 }
 
@@ -34,6 +34,7 @@ var _ = Describe("ApiPolicyMapper", func() {
 	})
 	Describe("AsStorePolicy", func() {
 		It("maps a payload with api.Policy to a slice of store.Policy", func() {
+			logEnabled := true
 			policies, err := mapper.AsStorePolicy(
 				[]byte(`{
 					"policies": [{
@@ -57,7 +58,8 @@ var _ = Describe("ApiPolicyMapper", func() {
 								"start": 8080,
 								"end": 8080
 							}
-						}
+						},
+						"log": true
 					}]
 				}`),
 			)
@@ -82,6 +84,7 @@ var _ = Describe("ApiPolicyMapper", func() {
 							Protocol: "some-protocol-2",
 							Ports:    api.Ports{Start: 8080, End: 8080},
 						},
+						Log: &logEnabled,
 					},
 				},
 			))
@@ -98,6 +101,7 @@ var _ = Describe("ApiPolicyMapper", func() {
 							End:   9090,
 						},
 					},
+					KeepLog: true,
 				}, {
 					Source: store.Source{ID: "some-src-id-2"},
 					Destination: store.Destination{
@@ -110,10 +114,24 @@ var _ = Describe("ApiPolicyMapper", func() {
 							End:   8080,
 						},
 					},
+					Log: true,
 				},
 			}))
 		})
 
+		It("sets the log flag when the payload turns logging off", func() {
+			policies, err := mapper.AsStorePolicy([]byte(`{
+				"policies": [{
+					"source": { "id": "some-src-id" },
+					"destination": { "id": "some-dst-id", "protocol": "tcp", "ports": { "start": 8080, "end": 8080 } },
+					"log": false
+				}]
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policies[0].Log).To(BeFalse())
+			Expect(policies[0].KeepLog).To(BeFalse())
+		})
+
 		Context("when unmarshalling fails", func() {
 			BeforeEach(func() {
 				fakeUnmarshaler.UnmarshalReturns(errors.New("banana"))
@@ -166,6 +184,7 @@ var _ = Describe("ApiPolicyMapper", func() {
 							End:   8081,
 						},
 					},
+					Log: true,
 				},
 			}
 
@@ -195,7 +214,8 @@ var _ = Describe("ApiPolicyMapper", func() {
 								"start": 8081,
 								"end": 8081
 							}
-						}
+						},
+						"log": true
 					}]
 				}`),
 			))
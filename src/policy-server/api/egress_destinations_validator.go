@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strings"
 )
 
 type EgressDestinationsValidator struct{}
@@ -36,10 +37,6 @@ func (v *EgressDestinationsValidator) ValidateEgressDestinations(destinations []
 			return errors.New("ports are not supported for icmp protocol")
 		}
 
-		if len(destination.Ports) > 1 {
-			return errors.New("only one port range is currently supported")
-		}
-
 		for _, portRange := range destination.Ports {
 			if portRange.Start > portRange.End {
 				return fmt.Errorf("invalid port range %d-%d, start must be less than or equal to end", portRange.Start, portRange.End)
@@ -62,23 +59,45 @@ func (v *EgressDestinationsValidator) ValidateEgressDestinations(destinations []
 			return fmt.Errorf("invalid destination: cannot set icmp_type property for destination with protocol '%s'", destination.Protocol)
 		}
 
-		if len(destination.IPRanges) == 0 {
-			return errors.New("missing destination IP range")
+		if destination.FQDN != "" {
+			if len(destination.IPRanges) > 0 {
+				return fmt.Errorf("invalid destination: cannot set both fqdn '%s' and ips", destination.FQDN)
+			}
+
+			if !isValidFQDN(destination.FQDN) {
+				return fmt.Errorf("invalid fqdn '%s', must be a valid hostname", destination.FQDN)
+			}
+			continue
 		}
 
-		if len(destination.IPRanges) > 1 {
-			return errors.New("only one IP range is currently supported")
+		if len(destination.IPRanges) == 0 {
+			return errors.New("missing destination IP range")
 		}
 
 		for _, ipRange := range destination.IPRanges {
+			if ipRange.CIDR != "" {
+				if ipRange.Start != "" || ipRange.End != "" {
+					return fmt.Errorf("invalid IP range: cidr '%s' cannot be combined with start and end", ipRange.CIDR)
+				}
+
+				if _, _, err := net.ParseCIDR(ipRange.CIDR); err != nil {
+					return fmt.Errorf("invalid cidr '%s', must be a valid IPv4 or IPv6 CIDR", ipRange.CIDR)
+				}
+				continue
+			}
+
 			startIP := net.ParseIP(ipRange.Start)
-			if startIP == nil || startIP.To4() == nil {
-				return fmt.Errorf("invalid ip address '%s', must be a valid IPv4 address", ipRange.Start)
+			if startIP == nil {
+				return fmt.Errorf("invalid ip address '%s', must be a valid IPv4 or IPv6 address", ipRange.Start)
 			}
 
 			endIP := net.ParseIP(ipRange.End)
-			if endIP == nil || endIP.To4() == nil {
-				return fmt.Errorf("invalid ip address '%s', must be a valid IPv4 address", ipRange.End)
+			if endIP == nil {
+				return fmt.Errorf("invalid ip address '%s', must be a valid IPv4 or IPv6 address", ipRange.End)
+			}
+
+			if (startIP.To4() == nil) != (endIP.To4() == nil) {
+				return fmt.Errorf("invalid IP range %s-%s, start and end must be the same IP version", ipRange.Start, ipRange.End)
 			}
 
 			if bytes.Compare(startIP, endIP) > 0 {
@@ -99,3 +118,28 @@ func isValidProtocol(protocol string) bool {
 	}
 	return false
 }
+
+func isValidFQDN(fqdn string) bool {
+	fqdn = strings.TrimSuffix(fqdn, ".")
+	if len(fqdn) == 0 || len(fqdn) > 253 || net.ParseIP(fqdn) != nil {
+		return false
+	}
+
+	for _, label := range strings.Split(fqdn, ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return false
+		}
+
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+
+		for _, c := range label {
+			isAlphanumeric := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+			if !isAlphanumeric && c != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
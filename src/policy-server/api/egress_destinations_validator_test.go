@@ -1,9 +1,12 @@
 package api_test
 
 import (
+	"fmt"
 	"policy-server/api"
+	"strings"
 
 	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
@@ -94,19 +97,34 @@ var _ = Describe("EgressDestinationsValidator", func() {
 			})
 
 			Context("when multiple port ranges are provided", func() {
-				It("returns an error", func() {
+				It("does not error", func() {
+					destinations := []api.EgressDestination{
+						{
+							Name:        "meow",
+							Description: "a cat",
+							Protocol:    "tcp",
+							Ports:       []api.Ports{{Start: 8000, End: 9000}, {Start: 443, End: 443}},
+							IPRanges:    []api.IPRange{{Start: "192.0.2.1", End: "192.0.2.1"}},
+						},
+					}
+
+					err := validator.ValidateEgressDestinations(destinations)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("validates each port range", func() {
 					destinations := []api.EgressDestination{
 						{
 							Name:        "meow",
 							Description: "a cat",
 							Protocol:    "tcp",
-							Ports:       []api.Ports{{Start: 8000, End: 9000}, {Start: 8000, End: 9000}},
+							Ports:       []api.Ports{{Start: 8000, End: 9000}, {Start: 443, End: 70000}},
 							IPRanges:    []api.IPRange{{Start: "192.0.2.1", End: "192.0.2.1"}},
 						},
 					}
 
 					err := validator.ValidateEgressDestinations(destinations)
-					Expect(err).To(MatchError("only one port range is currently supported"))
+					Expect(err).To(MatchError("invalid end port 70000, must be in range 1-65535"))
 				})
 			})
 
@@ -296,6 +314,53 @@ var _ = Describe("EgressDestinationsValidator", func() {
 			})
 		})
 
+		Context("when an fqdn is provided", func() {
+			var destinations []api.EgressDestination
+
+			BeforeEach(func() {
+				destinations = []api.EgressDestination{
+					{
+						Name:     "meow",
+						Protocol: "tcp",
+						Ports:    []api.Ports{{Start: 443, End: 443}},
+						FQDN:     "api.example.com",
+					},
+				}
+			})
+
+			It("does not require ips", func() {
+				err := validator.ValidateEgressDestinations(destinations)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("allows a trailing dot", func() {
+				destinations[0].FQDN = "api.example.com."
+				err := validator.ValidateEgressDestinations(destinations)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			Context("when ips are also provided", func() {
+				It("returns an error", func() {
+					destinations[0].IPRanges = []api.IPRange{{Start: "192.0.2.1", End: "192.0.2.1"}}
+					err := validator.ValidateEgressDestinations(destinations)
+					Expect(err).To(MatchError("invalid destination: cannot set both fqdn 'api.example.com' and ips"))
+				})
+			})
+
+			table.DescribeTable("when the fqdn is not a valid hostname",
+				func(fqdn string) {
+					destinations[0].FQDN = fqdn
+					err := validator.ValidateEgressDestinations(destinations)
+					Expect(err).To(MatchError(fmt.Sprintf("invalid fqdn '%s', must be a valid hostname", fqdn)))
+				},
+				table.Entry("contains invalid characters", "api_example.com"),
+				table.Entry("has an empty label", "api..example.com"),
+				table.Entry("has a label starting with a hyphen", "-api.example.com"),
+				table.Entry("is an ip address", "192.0.2.1"),
+				table.Entry("has a label longer than 63 characters", strings.Repeat("a", 64)+".example.com"),
+			)
+		})
+
 		Context("when the IPRange is invalid", func() {
 
 			Context("when no ips are provided", func() {
@@ -315,20 +380,35 @@ var _ = Describe("EgressDestinationsValidator", func() {
 				})
 			})
 
-			Context("when the End IP is greater than the Start IP ", func() {
-				It("returns an error", func() {
+			Context("when multiple IP ranges are provided", func() {
+				It("does not error", func() {
 					destinations := []api.EgressDestination{
 						{
 							Name:        "meow",
 							Description: "a cat",
 							Protocol:    "tcp",
 							Ports:       []api.Ports{{Start: 8080, End: 8081}},
-							IPRanges:    []api.IPRange{{Start: "192.0.2.10", End: "192.0.2.11"}, {Start: "192.0.2.10", End: "192.0.2.11"}},
+							IPRanges:    []api.IPRange{{Start: "192.0.2.10", End: "192.0.2.11"}, {Start: "198.51.100.1", End: "198.51.100.20"}},
 						},
 					}
 
 					err := validator.ValidateEgressDestinations(destinations)
-					Expect(err).To(MatchError("only one IP range is currently supported"))
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("validates each IP range", func() {
+					destinations := []api.EgressDestination{
+						{
+							Name:        "meow",
+							Description: "a cat",
+							Protocol:    "tcp",
+							Ports:       []api.Ports{{Start: 8080, End: 8081}},
+							IPRanges:    []api.IPRange{{Start: "192.0.2.10", End: "192.0.2.11"}, {Start: "198.51.100.20", End: "198.51.100.1"}},
+						},
+					}
+
+					err := validator.ValidateEgressDestinations(destinations)
+					Expect(err).To(MatchError("invalid IP range 198.51.100.20-198.51.100.1, start must be less than or equal to end"))
 				})
 			})
 
@@ -345,25 +425,105 @@ var _ = Describe("EgressDestinationsValidator", func() {
 					}
 
 					err := validator.ValidateEgressDestinations(destinations)
-					Expect(err).To(MatchError("invalid ip address '192.0.2.500', must be a valid IPv4 address"))
+					Expect(err).To(MatchError("invalid ip address '192.0.2.500', must be a valid IPv4 or IPv6 address"))
 				})
 			})
 
 			Context("when the IP is an IPv6 address", func() {
+				It("does not error", func() {
+					destinations := []api.EgressDestination{
+						{
+							Name:        "meow",
+							Description: "a cat",
+							Protocol:    "tcp",
+							Ports:       []api.Ports{{Start: 8080, End: 8081}},
+							IPRanges:    []api.IPRange{{Start: "2001:0db8:85a3:0000:0000:8a2e:0370:7334", End: "2001:0db8:85a3:0000:0000:8a2e:0370:7334"}},
+						},
+					}
+
+					err := validator.ValidateEgressDestinations(destinations)
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("when the start and end IPs are different IP versions", func() {
 				It("returns an error", func() {
+					destinations := []api.EgressDestination{
+						{
+							Name:        "meow",
+							Description: "a cat",
+							Protocol:    "tcp",
+							Ports:       []api.Ports{{Start: 8080, End: 8081}},
+							IPRanges:    []api.IPRange{{Start: "192.0.2.1", End: "2001:db8::1"}},
+						},
+					}
+
+					err := validator.ValidateEgressDestinations(destinations)
+					Expect(err).To(MatchError("invalid IP range 192.0.2.1-2001:db8::1, start and end must be the same IP version"))
+				})
+			})
 
+			Context("when the IPv6 end IP is before the start IP", func() {
+				It("returns an error", func() {
 					destinations := []api.EgressDestination{
 						{
 							Name:        "meow",
 							Description: "a cat",
 							Protocol:    "tcp",
 							Ports:       []api.Ports{{Start: 8080, End: 8081}},
-							IPRanges:    []api.IPRange{{Start: "2001:0db8:85a3:0000:0000:8a2e:0370:7334", End: "2001:0db8:85a3:0000:0000:8a2e:0370:7334"}},
+							IPRanges:    []api.IPRange{{Start: "2001:db8::10", End: "2001:db8::1"}},
+						},
+					}
+
+					err := validator.ValidateEgressDestinations(destinations)
+					Expect(err).To(MatchError("invalid IP range 2001:db8::10-2001:db8::1, start must be less than or equal to end"))
+				})
+			})
+
+			Context("when a cidr is provided", func() {
+				It("does not error for IPv4 and IPv6 cidrs", func() {
+					destinations := []api.EgressDestination{
+						{
+							Name:        "meow",
+							Description: "a cat",
+							Protocol:    "tcp",
+							Ports:       []api.Ports{{Start: 8080, End: 8081}},
+							IPRanges:    []api.IPRange{{CIDR: "10.0.0.0/8"}, {CIDR: "2001:db8::/32"}},
+						},
+					}
+
+					err := validator.ValidateEgressDestinations(destinations)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("returns an error when the cidr is invalid", func() {
+					destinations := []api.EgressDestination{
+						{
+							Name:        "meow",
+							Description: "a cat",
+							Protocol:    "tcp",
+							Ports:       []api.Ports{{Start: 8080, End: 8081}},
+							IPRanges:    []api.IPRange{{CIDR: "10.0.0.0/33"}},
+						},
+					}
+
+					err := validator.ValidateEgressDestinations(destinations)
+					Expect(err).To(MatchError("invalid cidr '10.0.0.0/33', must be a valid IPv4 or IPv6 CIDR"))
+				})
+
+				It("returns an error when start and end are also provided", func() {
+					destinations := []api.EgressDestination{
+						{
+							Name:        "meow",
+							Description: "a cat",
+							Protocol:    "tcp",
+							Ports:       []api.Ports{{Start: 8080, End: 8081}},
+							IPRanges:    []api.IPRange{{CIDR: "10.0.0.0/8", Start: "10.0.0.1", End: "10.0.0.2"}},
 						},
 					}
 
 					err := validator.ValidateEgressDestinations(destinations)
-					Expect(err).To(MatchError("invalid ip address '2001:0db8:85a3:0000:0000:8a2e:0370:7334', must be a valid IPv4 address"))
+					Expect(err).To(MatchError("invalid IP range: cidr '10.0.0.0/8' cannot be combined with start and end"))
 				})
 			})
 
@@ -32,6 +32,7 @@ func withPopulatedDestinations(storeEgressPolicy store.EgressPolicy) EgressPolic
 			ID:   storeEgressPolicy.Source.ID,
 			Type: storeEgressPolicy.Source.Type,
 		},
+		Log: storeEgressPolicy.Log,
 	}
 }
 
@@ -45,17 +46,28 @@ func withDestinationPointer(storeEgressPolicy store.EgressPolicy) EgressPolicy {
 			ID:   storeEgressPolicy.Source.ID,
 			Type: storeEgressPolicy.Source.Type,
 		},
+		Log: storeEgressPolicy.Log,
 	}
 }
 
 func (p *EgressPolicyMapper) AsBytesWithStrategy(storeEgressPolicies []store.EgressPolicy, mappingStrategy func(store.EgressPolicy) EgressPolicy) ([]byte, error) {
+	return p.asBytes(storeEgressPolicies, len(storeEgressPolicies), mappingStrategy)
+}
+
+// AsBytesWithTotal maps one page of policies with populated destinations,
+// reporting the total number of policies across all pages.
+func (p *EgressPolicyMapper) AsBytesWithTotal(storeEgressPolicies []store.EgressPolicy, total int) ([]byte, error) {
+	return p.asBytes(storeEgressPolicies, total, withPopulatedDestinations)
+}
+
+func (p *EgressPolicyMapper) asBytes(storeEgressPolicies []store.EgressPolicy, total int, mappingStrategy func(store.EgressPolicy) EgressPolicy) ([]byte, error) {
 	apiEgressPolicies := make([]EgressPolicy, len(storeEgressPolicies))
 	for i, storeEgressPolicy := range storeEgressPolicies {
 		apiEgressPolicies[i] = mappingStrategy(storeEgressPolicy)
 	}
 
 	payload := &payload{
-		TotalEgressPolicies: len(apiEgressPolicies),
+		TotalEgressPolicies: total,
 		EgressPolicies:      apiEgressPolicies,
 	}
 
@@ -104,5 +116,6 @@ func asStoreEgressPolicy(apiEgressPolicy EgressPolicy) store.EgressPolicy {
 			ID:   apiEgressPolicy.Source.ID,
 			Type: apiEgressPolicy.Source.Type,
 		},
+		Log: apiEgressPolicy.Log,
 	}
 }
@@ -40,7 +40,8 @@ var _ = Describe("EgressPolicyMapper", func() {
 					},
                     {
 						"source": { "id": "some-src-id-2", "type": "space"  },
-						"destination": { "id": "some-dst-id-2" }
+						"destination": { "id": "some-dst-id-2" },
+						"log": true
 					}
 				]
 			}`)
@@ -54,6 +55,8 @@ var _ = Describe("EgressPolicyMapper", func() {
 			Expect(policies[1].Source.ID).To(Equal("some-src-id-2"))
 			Expect(policies[1].Source.Type).To(Equal("space"))
 			Expect(policies[1].Destination.GUID).To(Equal("some-dst-id-2"))
+			Expect(policies[0].Log).To(BeFalse())
+			Expect(policies[1].Log).To(BeTrue())
 		})
 
 		Context("when unmarshalling fails", func() {
@@ -235,4 +238,54 @@ var _ = Describe("EgressPolicyMapper", func() {
 			})
 		})
 	})
+
+	Describe("AsBytesWithTotal", func() {
+		var egressPolicies []store.EgressPolicy
+
+		BeforeEach(func() {
+			egressPolicies = []store.EgressPolicy{
+				{
+					ID:     "policy-1",
+					Source: store.EgressSource{ID: "some-src-id", Type: "app"},
+					Destination: store.EgressDestination{
+						GUID:     "some-dst-id",
+						Name:     "dest-name",
+						IPRanges: []store.IPRange{{Start: "2.1.1.1", End: "3.2.2.2"}},
+					},
+				},
+			}
+		})
+
+		It("maps the page of policies and reports the given total", func() {
+			mappedBytes, err := mapper.AsBytesWithTotal(egressPolicies, 12)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(mappedBytes)).To(MatchJSON(`{
+					"total_egress_policies": 12,
+					"egress_policies": [
+						{
+							"id": "policy-1",
+							"source": { "id": "some-src-id", "type": "app" },
+							"destination": {
+								"id": "some-dst-id",
+								"name": "dest-name",
+								"ips": [{"start": "2.1.1.1", "end": "3.2.2.2"}]
+							}
+						}
+					]
+				}`))
+		})
+
+		Context("when marshalling fails", func() {
+			BeforeEach(func() {
+				marshaler := &hfakes.Marshaler{}
+				marshaler.MarshalReturns([]byte{}, errors.New("failed to marshal bytes"))
+				mapper.Marshaler = marshaler
+			})
+
+			It("wraps and returns an error", func() {
+				_, err := mapper.AsBytesWithTotal(egressPolicies, 12)
+				Expect(err).To(MatchError(errors.New("marshal json: failed to marshal bytes")))
+			})
+		})
+	})
 })
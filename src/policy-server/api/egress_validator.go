@@ -20,6 +20,7 @@ type EgressDestinationStore interface {
 type ccClient interface {
 	GetLiveAppGUIDs(token string, appGUIDs []string) (map[string]struct{}, error)
 	GetLiveSpaceGUIDs(token string, spaceGUIDs []string) (map[string]struct{}, error)
+	GetLiveOrgGUIDs(token string, orgGUIDs []string) (map[string]struct{}, error)
 }
 
 //go:generate counterfeiter -o fakes/uua_client.go --fake-name UAAClient . uaaClient
@@ -41,11 +42,16 @@ func (v *EgressValidator) ValidateEgressPolicies(policies []EgressPolicy) error
 		if policy.Source == nil {
 			return policyMetadataError("missing egress source", policy)
 		}
-		if policy.Source.ID == "" {
-			return policyMetadataError("missing egress source ID", policy)
+		if policy.Source.Type != "" && policy.Source.Type != "app" && policy.Source.Type != "space" &&
+			policy.Source.Type != "org" && policy.Source.Type != "default" {
+			return policyMetadataError("source type must be app, space, org or default", policy)
 		}
-		if policy.Source.Type != "" && policy.Source.Type != "app" && policy.Source.Type != "space" {
-			return policyMetadataError("source type must be app or space", policy)
+		if policy.Source.Type == "default" {
+			if policy.Source.ID != "" {
+				return policyMetadataError("default egress source must not have an ID", policy)
+			}
+		} else if policy.Source.ID == "" {
+			return policyMetadataError("missing egress source ID", policy)
 		}
 		if policy.Destination == nil {
 			return policyMetadataError("missing egress destination", policy)
@@ -90,6 +96,21 @@ func (v *EgressValidator) ValidateEgressPolicies(policies []EgressPolicy) error
 		}
 	}
 
+	orgGUIDSet := sourceOrgGUIDs(policies)
+
+	if len(orgGUIDSet) > 0 {
+		liveOrgGUIDs, err := v.CCClient.GetLiveOrgGUIDs(token, keys(orgGUIDSet))
+		if err != nil {
+			return fmt.Errorf("failed to get live org guids: %s", err)
+		}
+
+		missingOrgGUIDs := relativeComplement(orgGUIDSet, liveOrgGUIDs)
+
+		if len(missingOrgGUIDs) > 0 {
+			return composeMetadataError("org", missingOrgGUIDs, SourceKeyFunc, policies)
+		}
+	}
+
 	destinationGUIDSet := destinationGUIDs(policies)
 	destinations, err := v.DestinationStore.GetByGUID(keys(destinationGUIDSet)...)
 	if err != nil {
@@ -164,6 +185,16 @@ func sourceSpaceGUIDs(policies []EgressPolicy) map[string]struct{} {
 	return guidSet
 }
 
+func sourceOrgGUIDs(policies []EgressPolicy) map[string]struct{} {
+	guidSet := make(map[string]struct{})
+	for _, policy := range policies {
+		if policy.Source.Type == "org" {
+			guidSet[policy.Source.ID] = struct{}{}
+		}
+	}
+	return guidSet
+}
+
 func keys(set map[string]struct{}) []string {
 	var keys []string
 	for key, _ := range set {
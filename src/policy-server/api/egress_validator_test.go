@@ -38,6 +38,10 @@ var _ = Describe("Egress PolicyValidator", func() {
 			"source-space-id": {},
 		}, nil)
 
+		ccClient.GetLiveOrgGUIDsReturns(map[string]struct{}{
+			"source-org-id": {},
+		}, nil)
+
 		uaaClient.GetTokenReturns("valid-token", nil)
 
 		egressPolicies = []api.EgressPolicy{
@@ -233,13 +237,59 @@ var _ = Describe("Egress PolicyValidator", func() {
 			Expect(err).To(MatchError(ContainSubstring("failed to get uaa token: kilo")))
 		})
 
-		It("type must be app, space or empty", func() {
+		It("requires the source org to exist", func() {
+			egressPolicies = []api.EgressPolicy{
+				{
+					Source: &api.EgressSource{
+						ID:   "source-org-id",
+						Type: "org",
+					},
+					Destination: &api.EgressDestination{
+						GUID: "abc123",
+					},
+				},
+				{
+					Source: &api.EgressSource{
+						ID:   "non-existent-org",
+						Type: "org",
+					},
+					Destination: &api.EgressDestination{
+						GUID: "def456",
+					},
+				},
+			}
+
+			err := validator.ValidateEgressPolicies(egressPolicies)
+			Expect(err).To(MatchError(ContainSubstring("org guids not found: [non-existent-org]")))
+			egressPolicyError, ok := err.(httperror.MetadataError)
+			Expect(ok).To(BeTrue(), "expected error to be of type MetadataError")
+			Expect(egressPolicyError.Metadata()).To(Equal(map[string]interface{}{
+				"policies with missing orgs": egressPolicies[1:],
+			}))
+
+			passedToken, passedOrgGUIDs := ccClient.GetLiveOrgGUIDsArgsForCall(0)
+			Expect(passedToken).To(Equal("valid-token"))
+			Expect(passedOrgGUIDs).To(ConsistOf("source-org-id", "non-existent-org"))
+
+			Expect(ccClient.GetLiveAppGUIDsCallCount()).To(Equal(0))
+			Expect(ccClient.GetLiveSpaceGUIDsCallCount()).To(Equal(0))
+		})
+
+		It("returns an error if it can't query live org guids", func() {
+			egressPolicies[0].Source.Type = "org"
+
+			ccClient.GetLiveOrgGUIDsReturns(nil, errors.New("juliet"))
+			err := validator.ValidateEgressPolicies(egressPolicies)
+			Expect(err).To(MatchError(ContainSubstring("failed to get live org guids: juliet")))
+		})
+
+		It("type must be app, space, org, default or empty", func() {
 			egressPolicies[0].Source.Type = "invalid"
 
 			err := validator.ValidateEgressPolicies(egressPolicies)
-			Expect(err).To(MatchError(ContainSubstring("source type must be app or space")))
+			Expect(err).To(MatchError(ContainSubstring("source type must be app, space, org or default")))
 
-			for _, validType := range []string{"app", "space", ""} {
+			for _, validType := range []string{"app", "space", "org", ""} {
 				egressPolicies[0].Source.Type = validType
 				egressPolicies[0].Source.ID = "source-" + validType + "-id"
 				err := validator.ValidateEgressPolicies(egressPolicies)
@@ -247,6 +297,26 @@ var _ = Describe("Egress PolicyValidator", func() {
 			}
 		})
 
+		Context("when the source is the platform default", func() {
+			BeforeEach(func() {
+				egressPolicies[0].Source = &api.EgressSource{Type: "default"}
+			})
+
+			It("does not require a source guid or look anything up in CC", func() {
+				Expect(validator.ValidateEgressPolicies(egressPolicies)).To(Succeed())
+				Expect(ccClient.GetLiveAppGUIDsCallCount()).To(Equal(0))
+				Expect(ccClient.GetLiveSpaceGUIDsCallCount()).To(Equal(0))
+				Expect(ccClient.GetLiveOrgGUIDsCallCount()).To(Equal(0))
+			})
+
+			It("rejects a source guid", func() {
+				egressPolicies[0].Source.ID = "some-guid"
+
+				err := validator.ValidateEgressPolicies(egressPolicies)
+				Expect(err).To(MatchError(ContainSubstring("default egress source must not have an ID")))
+			})
+		})
+
 		It("requires a source guid", func() {
 			egressPolicies[0].Source.ID = ""
 
@@ -34,6 +34,20 @@ type CCClient struct {
 		result1 map[string]struct{}
 		result2 error
 	}
+	GetLiveOrgGUIDsStub        func(token string, orgGUIDs []string) (map[string]struct{}, error)
+	getLiveOrgGUIDsMutex       sync.RWMutex
+	getLiveOrgGUIDsArgsForCall []struct {
+		token    string
+		orgGUIDs []string
+	}
+	getLiveOrgGUIDsReturns struct {
+		result1 map[string]struct{}
+		result2 error
+	}
+	getLiveOrgGUIDsReturnsOnCall map[int]struct {
+		result1 map[string]struct{}
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -152,6 +166,58 @@ func (fake *CCClient) GetLiveSpaceGUIDsReturnsOnCall(i int, result1 map[string]s
 	}{result1, result2}
 }
 
+func (fake *CCClient) GetLiveOrgGUIDs(token string, orgGUIDs []string) (map[string]struct{}, error) {
+	fake.getLiveOrgGUIDsMutex.Lock()
+	ret, specificReturn := fake.getLiveOrgGUIDsReturnsOnCall[len(fake.getLiveOrgGUIDsArgsForCall)]
+	fake.getLiveOrgGUIDsArgsForCall = append(fake.getLiveOrgGUIDsArgsForCall, struct {
+		token    string
+		orgGUIDs []string
+	}{token, orgGUIDs})
+	fake.recordInvocation("GetLiveOrgGUIDs", []interface{}{token, orgGUIDs})
+	fake.getLiveOrgGUIDsMutex.Unlock()
+	if fake.GetLiveOrgGUIDsStub != nil {
+		return fake.GetLiveOrgGUIDsStub(token, orgGUIDs)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getLiveOrgGUIDsReturns.result1, fake.getLiveOrgGUIDsReturns.result2
+}
+
+func (fake *CCClient) GetLiveOrgGUIDsCallCount() int {
+	fake.getLiveOrgGUIDsMutex.RLock()
+	defer fake.getLiveOrgGUIDsMutex.RUnlock()
+	return len(fake.getLiveOrgGUIDsArgsForCall)
+}
+
+func (fake *CCClient) GetLiveOrgGUIDsArgsForCall(i int) (string, []string) {
+	fake.getLiveOrgGUIDsMutex.RLock()
+	defer fake.getLiveOrgGUIDsMutex.RUnlock()
+	return fake.getLiveOrgGUIDsArgsForCall[i].token, fake.getLiveOrgGUIDsArgsForCall[i].orgGUIDs
+}
+
+func (fake *CCClient) GetLiveOrgGUIDsReturns(result1 map[string]struct{}, result2 error) {
+	fake.GetLiveOrgGUIDsStub = nil
+	fake.getLiveOrgGUIDsReturns = struct {
+		result1 map[string]struct{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CCClient) GetLiveOrgGUIDsReturnsOnCall(i int, result1 map[string]struct{}, result2 error) {
+	fake.GetLiveOrgGUIDsStub = nil
+	if fake.getLiveOrgGUIDsReturnsOnCall == nil {
+		fake.getLiveOrgGUIDsReturnsOnCall = make(map[int]struct {
+			result1 map[string]struct{}
+			result2 error
+		})
+	}
+	fake.getLiveOrgGUIDsReturnsOnCall[i] = struct {
+		result1 map[string]struct{}
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *CCClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -159,6 +225,8 @@ func (fake *CCClient) Invocations() map[string][][]interface{} {
 	defer fake.getLiveAppGUIDsMutex.RUnlock()
 	fake.getLiveSpaceGUIDsMutex.RLock()
 	defer fake.getLiveSpaceGUIDsMutex.RUnlock()
+	fake.getLiveOrgGUIDsMutex.RLock()
+	defer fake.getLiveOrgGUIDsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
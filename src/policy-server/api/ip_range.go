@@ -0,0 +1,86 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"policy-server/store"
+)
+
+// asStoreIPRange converts a validated api IP range into the start/end form
+// stored in ip_ranges. Addresses are written in canonical form so that
+// equivalent IPv6 spellings compare equal.
+func asStoreIPRange(ipRange IPRange) store.IPRange {
+	start, end, err := ipRange.Bounds()
+	if err != nil {
+		return store.IPRange{}
+	}
+
+	return store.IPRange{
+		Start: canonicalIP(start),
+		End:   canonicalIP(end),
+	}
+}
+
+// Bounds returns the first and last address of the range, expanding a CIDR.
+func (r IPRange) Bounds() (string, string, error) {
+	if r.CIDR == "" {
+		return r.Start, r.End, nil
+	}
+
+	_, ipNet, err := net.ParseCIDR(r.CIDR)
+	if err != nil {
+		return "", "", fmt.Errorf("parsing cidr: %s", err)
+	}
+	return ipNet.IP.String(), lastIP(ipNet).String(), nil
+}
+
+// withCIDRs fills in the cidr of every range that covers exactly one CIDR
+// block, so destinations are rendered in both forms.
+func withCIDRs(ipRanges []IPRange) []IPRange {
+	for i, ipRange := range ipRanges {
+		ipRanges[i].CIDR = cidrForRange(ipRange.Start, ipRange.End)
+	}
+	return ipRanges
+}
+
+func canonicalIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ip
+	}
+	return parsed.String()
+}
+
+func cidrForRange(start, end string) string {
+	startIP := net.ParseIP(start)
+	endIP := net.ParseIP(end)
+	if startIP == nil || endIP == nil {
+		return ""
+	}
+
+	if startIP.To4() != nil {
+		startIP = startIP.To4()
+		endIP = endIP.To4()
+		if endIP == nil {
+			return ""
+		}
+	}
+
+	bits := len(startIP) * 8
+	for ones := 0; ones <= bits; ones++ {
+		ipNet := &net.IPNet{IP: startIP, Mask: net.CIDRMask(ones, bits)}
+		if ipNet.IP.Mask(ipNet.Mask).Equal(startIP) && lastIP(ipNet).Equal(endIP) {
+			return ipNet.String()
+		}
+	}
+	return ""
+}
+
+func lastIP(ipNet *net.IPNet) net.IP {
+	ip := ipNet.IP.Mask(ipNet.Mask)
+	last := make(net.IP, len(ip))
+	for i := range ip {
+		last[i] = ip[i] | ^ipNet.Mask[i]
+	}
+	return last
+}
@@ -57,5 +57,6 @@ func mapStoreEgressPolicy(storeEgressPolicy store.EgressPolicy) EgressPolicy {
 			Type: storeEgressPolicy.Source.Type,
 		},
 		Destination: &destination,
+		Log:         storeEgressPolicy.Log,
 	}
 }
@@ -55,6 +55,7 @@ var _ = Describe("PolicyCollectionWriter", func() {
 					Protocol: "tcp",
 					IPRanges: []store.IPRange{{Start: "8.0.8.0", End: "8.0.8.0"}},
 				},
+				Log: true,
 			}}
 
 			payload, err := writer.AsBytes(policies, egressPolicies)
@@ -92,6 +93,52 @@ var _ = Describe("PolicyCollectionWriter", func() {
 							"destination": {
 								"ips": [{"start": "8.0.8.0", "end": "8.0.8.0"}],
 								"protocol": "tcp"
+							},
+							"log": true
+						}
+					]
+				}`),
+			))
+		})
+
+		It("includes org and default egress sources", func() {
+			egressPolicies := []store.EgressPolicy{
+				{
+					Source: store.EgressSource{ID: "some-egress-org-guid", Type: "org"},
+					Destination: store.EgressDestination{
+						Protocol: "tcp",
+						IPRanges: []store.IPRange{{Start: "8.0.8.0", End: "8.0.8.0"}},
+					},
+				},
+				{
+					Source: store.EgressSource{Type: "default"},
+					Destination: store.EgressDestination{
+						Protocol: "udp",
+						IPRanges: []store.IPRange{{Start: "10.0.0.2", End: "10.0.0.2"}},
+					},
+				},
+			}
+
+			payload, err := writer.AsBytes([]store.Policy{}, egressPolicies)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payload).To(MatchJSON(
+				[]byte(`{
+					"total_policies": 0,
+					"policies": [],
+					"total_egress_policies": 2,
+					"egress_policies": [
+						{
+							"source": {"id": "some-egress-org-guid", "type": "org"},
+							"destination": {
+								"ips": [{"start": "8.0.8.0", "end": "8.0.8.0"}],
+								"protocol": "tcp"
+							}
+						},
+						{
+							"source": {"type": "default"},
+							"destination": {
+								"ips": [{"start": "10.0.0.2", "end": "10.0.0.2"}],
+								"protocol": "udp"
 							}
 						}
 					]
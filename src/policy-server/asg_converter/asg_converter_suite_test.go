@@ -0,0 +1,13 @@
+package asg_converter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestAsgConverter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AsgConverter Suite")
+}
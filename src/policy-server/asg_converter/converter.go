@@ -0,0 +1,450 @@
+package asg_converter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"policy-server/cc_client"
+	"policy-server/store"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+)
+
+//go:generate counterfeiter -o fakes/cc_client.go --fake-name CCClient . ccClient
+type ccClient interface {
+	GetSecurityGroups(token string) ([]cc_client.SecurityGroup, error)
+}
+
+//go:generate counterfeiter -o fakes/uaa_client.go --fake-name UAAClient . uaaClient
+type uaaClient interface {
+	GetToken() (string, error)
+}
+
+//go:generate counterfeiter -o fakes/egress_destination_store.go --fake-name EgressDestinationStore . egressDestinationStore
+type egressDestinationStore interface {
+	GetByName(name ...string) ([]store.EgressDestination, error)
+	CreateAllowingOverlaps([]store.EgressDestination) ([]store.EgressDestination, []store.DestinationOverlap, error)
+}
+
+//go:generate counterfeiter -o fakes/egress_policy_store.go --fake-name EgressPolicyStore . egressPolicyStore
+type egressPolicyStore interface {
+	All() ([]store.EgressPolicy, error)
+	Create([]store.EgressPolicy) ([]store.EgressPolicy, error)
+}
+
+const destinationNamePrefix = "asg-"
+
+type Converter struct {
+	Logger           lager.Logger
+	CCClient         ccClient
+	UAAClient        uaaClient
+	DestinationStore egressDestinationStore
+	PolicyStore      egressPolicyStore
+}
+
+type Result struct {
+	DryRun                bool          `json:"dry_run"`
+	NewDestinations       []Destination `json:"new_destinations"`
+	UnchangedDestinations []Destination `json:"unchanged_destinations"`
+	NewPolicies           []Policy      `json:"new_policies"`
+	UnchangedPolicies     []Policy      `json:"unchanged_policies"`
+	SkippedRules          []SkippedRule `json:"skipped_rules"`
+	OverlapWarnings       []string      `json:"overlap_warnings"`
+}
+
+type Destination struct {
+	GUID           string   `json:"id,omitempty"`
+	Name           string   `json:"name"`
+	Protocol       string   `json:"protocol"`
+	IPs            []string `json:"ips"`
+	Ports          []string `json:"ports,omitempty"`
+	ICMPType       *int     `json:"icmp_type,omitempty"`
+	ICMPCode       *int     `json:"icmp_code,omitempty"`
+	SecurityGroups []string `json:"security_groups"`
+}
+
+type Policy struct {
+	SourceType      string `json:"source_type"`
+	SourceID        string `json:"source_id,omitempty"`
+	DestinationName string `json:"destination_name"`
+}
+
+type SkippedRule struct {
+	SecurityGroup string                      `json:"security_group"`
+	Rule          cc_client.SecurityGroupRule `json:"rule"`
+	Reason        string                      `json:"reason"`
+}
+
+type policyKey struct {
+	sourceType      string
+	sourceID        string
+	destinationGUID string
+}
+
+type translatedDestination struct {
+	destination    store.EgressDestination
+	securityGroups []string
+}
+
+// Convert translates every security group in CC into egress destinations and
+// space or default sourced egress policies. Destinations are named after a
+// hash of their contents, so identical rules are merged and re-running the
+// conversion only creates what is missing. When dryRun is true nothing is
+// written and the result describes what would be created.
+func (c *Converter) Convert(dryRun bool) (Result, error) {
+	result := Result{
+		DryRun:                dryRun,
+		NewDestinations:       []Destination{},
+		UnchangedDestinations: []Destination{},
+		NewPolicies:           []Policy{},
+		UnchangedPolicies:     []Policy{},
+		SkippedRules:          []SkippedRule{},
+		OverlapWarnings:       []string{},
+	}
+
+	token, err := c.UAAClient.GetToken()
+	if err != nil {
+		return Result{}, fmt.Errorf("get UAA token: %s", err)
+	}
+
+	securityGroups, err := c.CCClient.GetSecurityGroups(token)
+	if err != nil {
+		return Result{}, fmt.Errorf("get security groups: %s", err)
+	}
+
+	destinationsByName := map[string]*translatedDestination{}
+	destinationNames := []string{}
+	policies := []Policy{}
+	seenPolicies := map[Policy]struct{}{}
+
+	for _, securityGroup := range securityGroups {
+		sources := []store.EgressSource{}
+		for _, space := range securityGroup.Relationships.RunningSpaces.Data {
+			sources = append(sources, store.EgressSource{Type: "space", ID: space.GUID})
+		}
+		if securityGroup.GloballyEnabled.Running {
+			sources = append(sources, store.EgressSource{Type: "default"})
+		}
+
+		for _, rule := range securityGroup.Rules {
+			destinations, err := translateRule(rule)
+			if err != nil {
+				result.SkippedRules = append(result.SkippedRules, SkippedRule{
+					SecurityGroup: securityGroup.Name,
+					Rule:          rule,
+					Reason:        err.Error(),
+				})
+				continue
+			}
+
+			for _, destination := range destinations {
+				translated, ok := destinationsByName[destination.Name]
+				if !ok {
+					translated = &translatedDestination{destination: destination}
+					destinationsByName[destination.Name] = translated
+					destinationNames = append(destinationNames, destination.Name)
+				}
+				if !containsString(translated.securityGroups, securityGroup.Name) {
+					translated.securityGroups = append(translated.securityGroups, securityGroup.Name)
+				}
+
+				for _, source := range sources {
+					policy := Policy{SourceType: source.Type, SourceID: source.ID, DestinationName: destination.Name}
+					if _, ok := seenPolicies[policy]; ok {
+						continue
+					}
+					seenPolicies[policy] = struct{}{}
+					policies = append(policies, policy)
+				}
+			}
+		}
+	}
+
+	if len(destinationNames) == 0 {
+		return result, nil
+	}
+
+	existingDestinations, err := c.DestinationStore.GetByName(destinationNames...)
+	if err != nil {
+		return Result{}, fmt.Errorf("get destinations by name: %s", err)
+	}
+
+	destinationGUIDs := map[string]string{}
+	for _, existing := range existingDestinations {
+		destinationGUIDs[existing.Name] = existing.GUID
+	}
+
+	existingPolicyKeys := map[policyKey]struct{}{}
+	if len(existingDestinations) > 0 {
+		existingPolicies, err := c.PolicyStore.All()
+		if err != nil {
+			return Result{}, fmt.Errorf("get egress policies: %s", err)
+		}
+
+		for _, existing := range existingPolicies {
+			existingPolicyKeys[policyKey{
+				sourceType:      existing.Source.Type,
+				sourceID:        existing.Source.ID,
+				destinationGUID: existing.Destination.GUID,
+			}] = struct{}{}
+		}
+	}
+
+	destinationsToCreate := []store.EgressDestination{}
+	for _, name := range destinationNames {
+		translated := destinationsByName[name]
+		translated.destination.Description = fmt.Sprintf("converted from security groups: %s", strings.Join(translated.securityGroups, ", "))
+
+		summary := summarize(translated)
+		if guid, ok := destinationGUIDs[name]; ok {
+			summary.GUID = guid
+			result.UnchangedDestinations = append(result.UnchangedDestinations, summary)
+			continue
+		}
+		result.NewDestinations = append(result.NewDestinations, summary)
+		destinationsToCreate = append(destinationsToCreate, translated.destination)
+	}
+
+	for _, policy := range policies {
+		guid, ok := destinationGUIDs[policy.DestinationName]
+		if ok {
+			key := policyKey{sourceType: policy.SourceType, sourceID: policy.SourceID, destinationGUID: guid}
+			if _, exists := existingPolicyKeys[key]; exists {
+				result.UnchangedPolicies = append(result.UnchangedPolicies, policy)
+				continue
+			}
+		}
+		result.NewPolicies = append(result.NewPolicies, policy)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	if len(destinationsToCreate) > 0 {
+		createdDestinations, overlaps, err := c.DestinationStore.CreateAllowingOverlaps(destinationsToCreate)
+		if err != nil {
+			return Result{}, fmt.Errorf("create destinations: %s", err)
+		}
+		for _, overlap := range overlaps {
+			result.OverlapWarnings = append(result.OverlapWarnings, overlap.Description())
+		}
+		for i, created := range createdDestinations {
+			destinationGUIDs[created.Name] = created.GUID
+			result.NewDestinations[i].GUID = created.GUID
+		}
+		c.Logger.Info("created-destinations", lager.Data{"count": len(createdDestinations)})
+	}
+
+	if len(result.NewPolicies) > 0 {
+		policiesToCreate := []store.EgressPolicy{}
+		for _, policy := range result.NewPolicies {
+			policiesToCreate = append(policiesToCreate, store.EgressPolicy{
+				Source:      store.EgressSource{Type: policy.SourceType, ID: policy.SourceID},
+				Destination: store.EgressDestination{GUID: destinationGUIDs[policy.DestinationName]},
+			})
+		}
+
+		_, err = c.PolicyStore.Create(policiesToCreate)
+		if err != nil {
+			return Result{}, fmt.Errorf("create egress policies: %s", err)
+		}
+		c.Logger.Info("created-policies", lager.Data{"count": len(policiesToCreate)})
+	}
+
+	return result, nil
+}
+
+func translateRule(rule cc_client.SecurityGroupRule) ([]store.EgressDestination, error) {
+	ipRanges, err := parseDestination(rule.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	icmpType, icmpCode := -1, -1
+	if rule.Type != nil {
+		icmpType = *rule.Type
+	}
+	if rule.Code != nil {
+		icmpCode = *rule.Code
+	}
+
+	var destinations []store.EgressDestination
+	switch rule.Protocol {
+	case "tcp", "udp":
+		ports, err := parsePorts(rule.Ports)
+		if err != nil {
+			return nil, err
+		}
+		destinations = append(destinations, store.EgressDestination{Protocol: rule.Protocol, IPRanges: ipRanges, Ports: ports})
+	case "icmp":
+		destinations = append(destinations, store.EgressDestination{Protocol: "icmp", IPRanges: ipRanges, ICMPType: icmpType, ICMPCode: icmpCode})
+	case "all":
+		allPorts := []store.Ports{{Start: 1, End: 65535}}
+		destinations = append(destinations,
+			store.EgressDestination{Protocol: "tcp", IPRanges: ipRanges, Ports: allPorts},
+			store.EgressDestination{Protocol: "udp", IPRanges: ipRanges, Ports: allPorts},
+			store.EgressDestination{Protocol: "icmp", IPRanges: ipRanges, ICMPType: -1, ICMPCode: -1},
+		)
+	default:
+		return nil, fmt.Errorf("unsupported protocol '%s'", rule.Protocol)
+	}
+
+	for i := range destinations {
+		destinations[i].Name = destinationName(destinations[i])
+	}
+	return destinations, nil
+}
+
+// parseDestination accepts the destination formats allowed by CC: a
+// comma-separated list of single addresses, CIDRs and start-end ranges.
+func parseDestination(destination string) ([]store.IPRange, error) {
+	if strings.TrimSpace(destination) == "" {
+		return nil, fmt.Errorf("missing destination")
+	}
+
+	var ipRanges []store.IPRange
+	for _, part := range strings.Split(destination, ",") {
+		part = strings.TrimSpace(part)
+
+		if strings.Contains(part, "/") {
+			_, ipNet, err := net.ParseCIDR(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cidr '%s'", part)
+			}
+			ipRanges = append(ipRanges, store.IPRange{Start: ipNet.IP.String(), End: lastIP(ipNet).String()})
+			continue
+		}
+
+		bounds := strings.SplitN(part, "-", 2)
+		start := net.ParseIP(strings.TrimSpace(bounds[0]))
+		end := start
+		if len(bounds) == 2 {
+			end = net.ParseIP(strings.TrimSpace(bounds[1]))
+		}
+		if start == nil || end == nil {
+			return nil, fmt.Errorf("invalid destination '%s'", part)
+		}
+		if (start.To4() == nil) != (end.To4() == nil) || bytes.Compare(start.To16(), end.To16()) > 0 {
+			return nil, fmt.Errorf("invalid ip range '%s'", part)
+		}
+		ipRanges = append(ipRanges, store.IPRange{Start: start.String(), End: end.String()})
+	}
+
+	sort.Slice(ipRanges, func(i, j int) bool {
+		if ipRanges[i].Start != ipRanges[j].Start {
+			return ipRanges[i].Start < ipRanges[j].Start
+		}
+		return ipRanges[i].End < ipRanges[j].End
+	})
+	return ipRanges, nil
+}
+
+// parsePorts accepts a comma-separated list of ports and start-end ranges.
+func parsePorts(ports string) ([]store.Ports, error) {
+	if strings.TrimSpace(ports) == "" {
+		return nil, fmt.Errorf("missing ports")
+	}
+
+	var parsed []store.Ports
+	for _, part := range strings.Split(ports, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+		start, err := parsePort(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		end := start
+		if len(bounds) == 2 {
+			end, err = parsePort(bounds[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		if start > end {
+			return nil, fmt.Errorf("invalid port range '%s'", part)
+		}
+		parsed = append(parsed, store.Ports{Start: start, End: end})
+	}
+
+	sort.Slice(parsed, func(i, j int) bool {
+		if parsed[i].Start != parsed[j].Start {
+			return parsed[i].Start < parsed[j].Start
+		}
+		return parsed[i].End < parsed[j].End
+	})
+	return parsed, nil
+}
+
+func parsePort(port string) (int, error) {
+	parsed, err := strconv.Atoi(strings.TrimSpace(port))
+	if err != nil || parsed < 1 || parsed > 65535 {
+		return 0, fmt.Errorf("invalid port '%s'", port)
+	}
+	return parsed, nil
+}
+
+func lastIP(ipNet *net.IPNet) net.IP {
+	ip := make(net.IP, len(ipNet.IP))
+	for i := range ipNet.IP {
+		ip[i] = ipNet.IP[i] | ^ipNet.Mask[i]
+	}
+	return ip
+}
+
+// destinationName is derived from the destination's contents so that the
+// same rule always maps to the same destination.
+func destinationName(destination store.EgressDestination) string {
+	key := destination.Protocol
+	for _, ipRange := range destination.IPRanges {
+		key += fmt.Sprintf("|%s-%s", ipRange.Start, ipRange.End)
+	}
+	for _, ports := range destination.Ports {
+		key += fmt.Sprintf("|%d-%d", ports.Start, ports.End)
+	}
+	if destination.Protocol == "icmp" {
+		key += fmt.Sprintf("|%d/%d", destination.ICMPType, destination.ICMPCode)
+	}
+	return fmt.Sprintf("%s%x", destinationNamePrefix, sha256.Sum256([]byte(key)))[:len(destinationNamePrefix)+16]
+}
+
+func summarize(translated *translatedDestination) Destination {
+	destination := translated.destination
+	summary := Destination{
+		Name:           destination.Name,
+		Protocol:       destination.Protocol,
+		IPs:            []string{},
+		SecurityGroups: translated.securityGroups,
+	}
+	for _, ipRange := range destination.IPRanges {
+		summary.IPs = append(summary.IPs, formatRange(ipRange.Start, ipRange.End))
+	}
+	for _, ports := range destination.Ports {
+		summary.Ports = append(summary.Ports, formatRange(strconv.Itoa(ports.Start), strconv.Itoa(ports.End)))
+	}
+	if destination.Protocol == "icmp" {
+		icmpType, icmpCode := destination.ICMPType, destination.ICMPCode
+		summary.ICMPType = &icmpType
+		summary.ICMPCode = &icmpCode
+	}
+	return summary
+}
+
+func formatRange(start, end string) string {
+	if start == end {
+		return start
+	}
+	return start + "-" + end
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
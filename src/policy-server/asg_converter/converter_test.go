@@ -0,0 +1,325 @@
+package asg_converter_test
+
+import (
+	"errors"
+	"fmt"
+	"policy-server/asg_converter"
+	"policy-server/asg_converter/fakes"
+	"policy-server/cc_client"
+	"policy-server/store"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Converter", func() {
+	var (
+		converter            *asg_converter.Converter
+		fakeCCClient         *fakes.CCClient
+		fakeUAAClient        *fakes.UAAClient
+		fakeDestinationStore *fakes.EgressDestinationStore
+		fakePolicyStore      *fakes.EgressPolicyStore
+		securityGroups       []cc_client.SecurityGroup
+		icmpType             int
+	)
+
+	newSecurityGroup := func(name string, running bool, rules []cc_client.SecurityGroupRule, spaceGUIDs ...string) cc_client.SecurityGroup {
+		securityGroup := cc_client.SecurityGroup{GUID: name + "-guid", Name: name, Rules: rules}
+		securityGroup.GloballyEnabled.Running = running
+		for _, spaceGUID := range spaceGUIDs {
+			securityGroup.Relationships.RunningSpaces.Data = append(securityGroup.Relationships.RunningSpaces.Data, struct {
+				GUID string `json:"guid"`
+			}{GUID: spaceGUID})
+		}
+		return securityGroup
+	}
+
+	BeforeEach(func() {
+		fakeCCClient = &fakes.CCClient{}
+		fakeUAAClient = &fakes.UAAClient{}
+		fakeDestinationStore = &fakes.EgressDestinationStore{}
+		fakePolicyStore = &fakes.EgressPolicyStore{}
+
+		converter = &asg_converter.Converter{
+			Logger:           lagertest.NewTestLogger("test"),
+			CCClient:         fakeCCClient,
+			UAAClient:        fakeUAAClient,
+			DestinationStore: fakeDestinationStore,
+			PolicyStore:      fakePolicyStore,
+		}
+
+		icmpType = 8
+		securityGroups = []cc_client.SecurityGroup{
+			newSecurityGroup("web", false, []cc_client.SecurityGroupRule{
+				{Protocol: "tcp", Destination: "10.0.0.0/24", Ports: "443,80"},
+				{Protocol: "icmp", Destination: "10.0.1.1", Type: &icmpType},
+			}, "space-1", "space-2"),
+			newSecurityGroup("web-copy", true, []cc_client.SecurityGroupRule{
+				{Protocol: "tcp", Destination: "10.0.0.0/24", Ports: "80,443"},
+			}, "space-1"),
+		}
+
+		fakeUAAClient.GetTokenReturns("some-token", nil)
+		fakeCCClient.GetSecurityGroupsReturns(securityGroups, nil)
+		fakeDestinationStore.CreateAllowingOverlapsStub = func(destinations []store.EgressDestination) ([]store.EgressDestination, []store.DestinationOverlap, error) {
+			for i := range destinations {
+				destinations[i].GUID = "created-" + destinations[i].Name
+			}
+			return destinations, nil, nil
+		}
+	})
+
+	It("translates rules into merged destinations and space policies", func() {
+		result, err := converter.Convert(false)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeUAAClient.GetTokenCallCount()).To(Equal(1))
+		Expect(fakeCCClient.GetSecurityGroupsArgsForCall(0)).To(Equal("some-token"))
+
+		Expect(fakeDestinationStore.CreateAllowingOverlapsCallCount()).To(Equal(1))
+		created := fakeDestinationStore.CreateAllowingOverlapsArgsForCall(0)
+		Expect(created).To(HaveLen(2))
+
+		Expect(created[0].Protocol).To(Equal("tcp"))
+		Expect(created[0].IPRanges).To(Equal([]store.IPRange{{Start: "10.0.0.0", End: "10.0.0.255"}}))
+		Expect(created[0].Ports).To(Equal([]store.Ports{{Start: 80, End: 80}, {Start: 443, End: 443}}))
+		Expect(created[0].Description).To(Equal("converted from security groups: web, web-copy"))
+		Expect(created[0].Name).To(HavePrefix("asg-"))
+
+		Expect(created[1].Protocol).To(Equal("icmp"))
+		Expect(created[1].IPRanges).To(Equal([]store.IPRange{{Start: "10.0.1.1", End: "10.0.1.1"}}))
+		Expect(created[1].ICMPType).To(Equal(8))
+		Expect(created[1].ICMPCode).To(Equal(-1))
+
+		tcpGUID := "created-" + created[0].Name
+		icmpGUID := "created-" + created[1].Name
+
+		Expect(fakePolicyStore.CreateCallCount()).To(Equal(1))
+		Expect(fakePolicyStore.CreateArgsForCall(0)).To(Equal([]store.EgressPolicy{
+			{Source: store.EgressSource{Type: "space", ID: "space-1"}, Destination: store.EgressDestination{GUID: tcpGUID}},
+			{Source: store.EgressSource{Type: "space", ID: "space-2"}, Destination: store.EgressDestination{GUID: tcpGUID}},
+			{Source: store.EgressSource{Type: "space", ID: "space-1"}, Destination: store.EgressDestination{GUID: icmpGUID}},
+			{Source: store.EgressSource{Type: "space", ID: "space-2"}, Destination: store.EgressDestination{GUID: icmpGUID}},
+			{Source: store.EgressSource{Type: "default"}, Destination: store.EgressDestination{GUID: tcpGUID}},
+		}))
+
+		Expect(result.DryRun).To(BeFalse())
+		Expect(result.NewDestinations).To(HaveLen(2))
+		Expect(result.NewDestinations[0].GUID).To(Equal(tcpGUID))
+		Expect(result.NewDestinations[0].IPs).To(Equal([]string{"10.0.0.0-10.0.0.255"}))
+		Expect(result.NewDestinations[0].Ports).To(Equal([]string{"80", "443"}))
+		Expect(result.NewDestinations[0].SecurityGroups).To(Equal([]string{"web", "web-copy"}))
+		Expect(result.NewPolicies).To(HaveLen(5))
+		Expect(result.UnchangedDestinations).To(BeEmpty())
+		Expect(result.UnchangedPolicies).To(BeEmpty())
+		Expect(result.SkippedRules).To(BeEmpty())
+		Expect(result.OverlapWarnings).To(BeEmpty())
+	})
+
+	Context("when the protocol is all", func() {
+		BeforeEach(func() {
+			fakeCCClient.GetSecurityGroupsReturns([]cc_client.SecurityGroup{
+				newSecurityGroup("everything", false, []cc_client.SecurityGroupRule{
+					{Protocol: "all", Destination: "0.0.0.0-9.255.255.255,11.0.0.0/8"},
+				}, "space-1"),
+			}, nil)
+		})
+
+		It("creates tcp, udp and icmp destinations", func() {
+			_, err := converter.Convert(false)
+			Expect(err).NotTo(HaveOccurred())
+
+			created := fakeDestinationStore.CreateAllowingOverlapsArgsForCall(0)
+			Expect(created).To(HaveLen(3))
+			for _, destination := range created {
+				Expect(destination.IPRanges).To(Equal([]store.IPRange{
+					{Start: "0.0.0.0", End: "9.255.255.255"},
+					{Start: "11.0.0.0", End: "11.255.255.255"},
+				}))
+			}
+			Expect(created[0].Protocol).To(Equal("tcp"))
+			Expect(created[0].Ports).To(Equal([]store.Ports{{Start: 1, End: 65535}}))
+			Expect(created[1].Protocol).To(Equal("udp"))
+			Expect(created[2].Protocol).To(Equal("icmp"))
+			Expect(created[2].ICMPType).To(Equal(-1))
+			Expect(created[2].ICMPCode).To(Equal(-1))
+		})
+	})
+
+	Context("when a rule cannot be translated", func() {
+		BeforeEach(func() {
+			fakeCCClient.GetSecurityGroupsReturns([]cc_client.SecurityGroup{
+				newSecurityGroup("broken", false, []cc_client.SecurityGroupRule{
+					{Protocol: "tcp", Destination: "not-an-ip", Ports: "80"},
+					{Protocol: "tcp", Destination: "10.0.0.1", Ports: "90-80"},
+					{Protocol: "tcp", Destination: "10.0.0.1"},
+					{Protocol: "sctp", Destination: "10.0.0.1", Ports: "80"},
+				}, "space-1"),
+			}, nil)
+		})
+
+		It("skips the rule and reports why", func() {
+			result, err := converter.Convert(false)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeDestinationStore.CreateAllowingOverlapsCallCount()).To(Equal(0))
+			Expect(fakePolicyStore.CreateCallCount()).To(Equal(0))
+
+			Expect(result.SkippedRules).To(HaveLen(4))
+			Expect(result.SkippedRules[0].SecurityGroup).To(Equal("broken"))
+			Expect(result.SkippedRules[0].Reason).To(Equal("invalid destination 'not-an-ip'"))
+			Expect(result.SkippedRules[1].Reason).To(Equal("invalid port range '90-80'"))
+			Expect(result.SkippedRules[2].Reason).To(Equal("missing ports"))
+			Expect(result.SkippedRules[3].Reason).To(Equal("unsupported protocol 'sctp'"))
+		})
+	})
+
+	Context("when the created destinations overlap other destinations", func() {
+		BeforeEach(func() {
+			fakeDestinationStore.CreateAllowingOverlapsStub = func(destinations []store.EgressDestination) ([]store.EgressDestination, []store.DestinationOverlap, error) {
+				return destinations, []store.DestinationOverlap{{
+					Destination:            destinations[1],
+					OverlappingDestination: store.EgressDestination{Name: "existing"},
+					Relation:               store.OverlapRelationContainedBy,
+				}}, nil
+			}
+		})
+
+		It("creates them anyway and reports the overlaps as warnings", func() {
+			result, err := converter.Convert(false)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.NewDestinations).To(HaveLen(2))
+			Expect(result.OverlapWarnings).To(Equal([]string{
+				fmt.Sprintf("destination '%s' is contained by destination 'existing'", result.NewDestinations[1].Name),
+			}))
+			Expect(fakePolicyStore.CreateCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when dry run is requested", func() {
+		It("reports the changes without writing anything", func() {
+			result, err := converter.Convert(true)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.DryRun).To(BeTrue())
+			Expect(result.NewDestinations).To(HaveLen(2))
+			Expect(result.NewDestinations[0].GUID).To(BeEmpty())
+			Expect(result.NewPolicies).To(HaveLen(5))
+
+			Expect(fakeDestinationStore.CreateAllowingOverlapsCallCount()).To(Equal(0))
+			Expect(fakePolicyStore.CreateCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the conversion has already been run", func() {
+		BeforeEach(func() {
+			dryRunResult, err := converter.Convert(true)
+			Expect(err).NotTo(HaveOccurred())
+
+			existingDestinations := []store.EgressDestination{}
+			for _, destination := range dryRunResult.NewDestinations {
+				existingDestinations = append(existingDestinations, store.EgressDestination{
+					GUID: "existing-" + destination.Name,
+					Name: destination.Name,
+				})
+			}
+			fakeDestinationStore.GetByNameReturns(existingDestinations, nil)
+
+			tcpGUID := existingDestinations[0].GUID
+			fakePolicyStore.AllReturns([]store.EgressPolicy{
+				{Source: store.EgressSource{Type: "space", ID: "space-1"}, Destination: store.EgressDestination{GUID: tcpGUID}},
+				{Source: store.EgressSource{Type: "space", ID: "space-2"}, Destination: store.EgressDestination{GUID: tcpGUID}},
+				{Source: store.EgressSource{Type: "default"}, Destination: store.EgressDestination{GUID: tcpGUID}},
+			}, nil)
+		})
+
+		It("only creates what is missing", func() {
+			result, err := converter.Convert(false)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.NewDestinations).To(BeEmpty())
+			Expect(result.UnchangedDestinations).To(HaveLen(2))
+			Expect(result.UnchangedPolicies).To(HaveLen(3))
+			Expect(result.NewPolicies).To(HaveLen(2))
+
+			Expect(fakeDestinationStore.CreateAllowingOverlapsCallCount()).To(Equal(0))
+			Expect(fakePolicyStore.CreateCallCount()).To(Equal(1))
+			icmpGUID := "existing-" + result.UnchangedDestinations[1].Name
+			Expect(fakePolicyStore.CreateArgsForCall(0)).To(Equal([]store.EgressPolicy{
+				{Source: store.EgressSource{Type: "space", ID: "space-1"}, Destination: store.EgressDestination{GUID: icmpGUID}},
+				{Source: store.EgressSource{Type: "space", ID: "space-2"}, Destination: store.EgressDestination{GUID: icmpGUID}},
+			}))
+		})
+	})
+
+	Context("when getting the UAA token fails", func() {
+		BeforeEach(func() {
+			fakeUAAClient.GetTokenReturns("", errors.New("banana"))
+		})
+
+		It("returns an error", func() {
+			_, err := converter.Convert(false)
+			Expect(err).To(MatchError("get UAA token: banana"))
+		})
+	})
+
+	Context("when getting the security groups fails", func() {
+		BeforeEach(func() {
+			fakeCCClient.GetSecurityGroupsReturns(nil, errors.New("banana"))
+		})
+
+		It("returns an error", func() {
+			_, err := converter.Convert(false)
+			Expect(err).To(MatchError("get security groups: banana"))
+		})
+	})
+
+	Context("when getting the existing destinations fails", func() {
+		BeforeEach(func() {
+			fakeDestinationStore.GetByNameReturns(nil, errors.New("banana"))
+		})
+
+		It("returns an error", func() {
+			_, err := converter.Convert(false)
+			Expect(err).To(MatchError("get destinations by name: banana"))
+		})
+	})
+
+	Context("when getting the existing policies fails", func() {
+		BeforeEach(func() {
+			fakeDestinationStore.GetByNameReturns([]store.EgressDestination{{GUID: "some-guid", Name: "some-name"}}, nil)
+			fakePolicyStore.AllReturns(nil, errors.New("banana"))
+		})
+
+		It("returns an error", func() {
+			_, err := converter.Convert(false)
+			Expect(err).To(MatchError("get egress policies: banana"))
+		})
+	})
+
+	Context("when creating the destinations fails", func() {
+		BeforeEach(func() {
+			fakeDestinationStore.CreateAllowingOverlapsStub = nil
+			fakeDestinationStore.CreateAllowingOverlapsReturns(nil, nil, errors.New("banana"))
+		})
+
+		It("returns an error and does not create policies", func() {
+			_, err := converter.Convert(false)
+			Expect(err).To(MatchError("create destinations: banana"))
+			Expect(fakePolicyStore.CreateCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when creating the policies fails", func() {
+		BeforeEach(func() {
+			fakePolicyStore.CreateReturns(nil, errors.New("banana"))
+		})
+
+		It("returns an error", func() {
+			_, err := converter.Convert(false)
+			Expect(err).To(MatchError("create egress policies: banana"))
+		})
+	})
+})
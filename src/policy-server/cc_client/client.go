@@ -58,6 +58,66 @@ type SpacesV3Response struct {
 	} `json:"resources"`
 }
 
+type OrganizationsV3Response struct {
+	Pagination struct {
+		TotalPages int `json:"total_pages"`
+		First      struct {
+			Href string `json:"href"`
+		} `json:"first"`
+		Last struct {
+			Href string `json:"href"`
+		} `json:"last"`
+		Next struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"pagination"`
+	Resources []struct {
+		GUID string `json:"guid"`
+	} `json:"resources"`
+}
+
+type SecurityGroupsV3Response struct {
+	Pagination struct {
+		TotalPages int `json:"total_pages"`
+		First      struct {
+			Href string `json:"href"`
+		} `json:"first"`
+		Last struct {
+			Href string `json:"href"`
+		} `json:"last"`
+		Next struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"pagination"`
+	Resources []SecurityGroup `json:"resources"`
+}
+
+type SecurityGroup struct {
+	GUID            string `json:"guid"`
+	Name            string `json:"name"`
+	GloballyEnabled struct {
+		Running bool `json:"running"`
+		Staging bool `json:"staging"`
+	} `json:"globally_enabled"`
+	Rules         []SecurityGroupRule `json:"rules"`
+	Relationships struct {
+		RunningSpaces struct {
+			Data []struct {
+				GUID string `json:"guid"`
+			} `json:"data"`
+		} `json:"running_spaces"`
+	} `json:"relationships"`
+}
+
+type SecurityGroupRule struct {
+	Protocol    string `json:"protocol"`
+	Destination string `json:"destination"`
+	Ports       string `json:"ports"`
+	Type        *int   `json:"type"`
+	Code        *int   `json:"code"`
+	Description string `json:"description"`
+}
+
 type SpaceResponse struct {
 	Entity struct {
 		Name             string `json:"name"`
@@ -77,6 +137,17 @@ type SpacesResponse struct {
 	} `json:"resources"`
 }
 
+type OrganizationsResponse struct {
+	Resources []struct {
+		Metadata struct {
+			GUID string `json:"guid"`
+		}
+		Entity struct {
+			Name string `json:"name"`
+		} `json:"entity"`
+	} `json:"resources"`
+}
+
 func (c *Client) GetAllAppGUIDs(token string) (map[string]struct{}, error) {
 	token = fmt.Sprintf("bearer %s", token)
 
@@ -175,6 +246,63 @@ func (c *Client) getAllSpaceGUIDs(token string) (map[string]struct{}, error) {
 	return allSpaceGUIDs, nil
 }
 
+func (c *Client) GetLiveOrgGUIDs(token string, orgGUIDs []string) (map[string]struct{}, error) {
+	token = fmt.Sprintf("bearer %s", token)
+
+	allOrgGUIDs, err := c.getAllOrgGUIDs(token)
+	if err != nil {
+		return nil, err
+	}
+
+	liveOrgGUIDs := make(map[string]struct{})
+	for _, org := range orgGUIDs {
+		if _, ok := allOrgGUIDs[org]; ok {
+			liveOrgGUIDs[org] = struct{}{}
+		}
+	}
+
+	return liveOrgGUIDs, nil
+}
+
+func (c *Client) getAllOrgGUIDs(token string) (map[string]struct{}, error) {
+	allOrgGUIDs := make(map[string]struct{})
+
+	route := "/v3/organizations"
+	for route != "" {
+		var response OrganizationsV3Response
+		err := c.JSONClient.Do("GET", route, nil, &response, token)
+		if err != nil {
+			return nil, fmt.Errorf("json client do: %s", err)
+		}
+
+		for _, org := range response.Resources {
+			allOrgGUIDs[org.GUID] = struct{}{}
+		}
+		route = response.Pagination.Next.Href
+	}
+
+	return allOrgGUIDs, nil
+}
+
+func (c *Client) GetSecurityGroups(token string) ([]SecurityGroup, error) {
+	token = fmt.Sprintf("bearer %s", token)
+
+	securityGroups := []SecurityGroup{}
+	route := "/v3/security_groups"
+	for route != "" {
+		var response SecurityGroupsV3Response
+		err := c.JSONClient.Do("GET", route, nil, &response, token)
+		if err != nil {
+			return nil, fmt.Errorf("json client do: %s", err)
+		}
+
+		securityGroups = append(securityGroups, response.Resources...)
+		route = response.Pagination.Next.Href
+	}
+
+	return securityGroups, nil
+}
+
 func (c *Client) GetSpaceGUIDs(token string, appGUIDs []string) ([]string, error) {
 	mapping, err := c.GetAppSpaces(token, appGUIDs)
 	if err != nil {
@@ -300,3 +428,22 @@ func (c *Client) GetSubjectSpaces(token, subjectId string) (map[string]struct{},
 
 	return subjectSpaces, nil
 }
+
+func (c *Client) GetSubjectManagedOrgs(token, subjectId string) (map[string]struct{}, error) {
+	token = fmt.Sprintf("bearer %s", token)
+
+	route := fmt.Sprintf("/v2/users/%s/managed_organizations", subjectId)
+
+	var response OrganizationsResponse
+	err := c.JSONClient.Do("GET", route, nil, &response, token)
+	if err != nil {
+		return nil, fmt.Errorf("json client do: %s", err)
+	}
+
+	managedOrgs := map[string]struct{}{}
+	for _, org := range response.Resources {
+		managedOrgs[org.Metadata.GUID] = struct{}{}
+	}
+
+	return managedOrgs, nil
+}
@@ -218,6 +218,111 @@ var _ = Describe("Client", func() {
 		})
 	})
 
+	Describe("GetLiveOrgGUIDs", func() {
+		var (
+			passedToken  string
+			passedRoutes []string
+		)
+
+		BeforeEach(func() {
+			passedRoutes = []string{}
+			fakeJSONClient.DoStub = func(method, route string, reqData, respData interface{}, token string) error {
+				passedToken = token
+				passedRoutes = append(passedRoutes, route)
+				if route == "/v3/organizations?page=2" {
+					_ = json.Unmarshal([]byte(fixtures.LiveOrgsPage2), respData)
+				} else {
+					_ = json.Unmarshal([]byte(fixtures.LiveOrgsPage1), respData)
+				}
+				return nil
+			}
+		})
+
+		It("returns the live org guids filtered by given org guids", func() {
+			liveOrgGUIDs, err := client.GetLiveOrgGUIDs("some-token", []string{"live-org-1-guid", "live-org-2-guid", "dead-org-1-guid"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(liveOrgGUIDs).To(Equal(map[string]struct{}{
+				"live-org-1-guid": {},
+				"live-org-2-guid": {},
+			}))
+
+			Expect(passedToken).To(Equal("bearer some-token"))
+			Expect(passedRoutes).To(Equal([]string{"/v3/organizations", "/v3/organizations?page=2"}))
+		})
+
+		Context("when the json client returns an error", func() {
+			BeforeEach(func() {
+				fakeJSONClient.DoStub = nil
+				fakeJSONClient.DoReturns(errors.New("banana"))
+			})
+
+			It("returns the error", func() {
+				_, err := client.GetLiveOrgGUIDs("some-token", []string{})
+				Expect(err).To(MatchError(ContainSubstring("json client do: banana")))
+			})
+		})
+	})
+
+	Describe("GetSecurityGroups", func() {
+		var (
+			passedToken  string
+			passedRoutes []string
+		)
+
+		BeforeEach(func() {
+			passedRoutes = []string{}
+			fakeJSONClient.DoStub = func(method, route string, reqData, respData interface{}, token string) error {
+				passedToken = token
+				passedRoutes = append(passedRoutes, route)
+				if route == "/v3/security_groups?page=2" {
+					_ = json.Unmarshal([]byte(fixtures.SecurityGroupsPage2), respData)
+				} else {
+					_ = json.Unmarshal([]byte(fixtures.SecurityGroupsPage1), respData)
+				}
+				return nil
+			}
+		})
+
+		It("returns the security groups from every page", func() {
+			securityGroups, err := client.GetSecurityGroups("some-token")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(passedToken).To(Equal("bearer some-token"))
+			Expect(passedRoutes).To(Equal([]string{"/v3/security_groups", "/v3/security_groups?page=2"}))
+
+			Expect(securityGroups).To(HaveLen(2))
+			Expect(securityGroups[0].GUID).To(Equal("asg-1-guid"))
+			Expect(securityGroups[0].Name).To(Equal("public-networks"))
+			Expect(securityGroups[0].GloballyEnabled.Running).To(BeTrue())
+			Expect(securityGroups[0].Rules).To(HaveLen(2))
+			Expect(securityGroups[0].Rules[0]).To(Equal(cc_client.SecurityGroupRule{
+				Protocol:    "tcp",
+				Destination: "10.10.10.0/24",
+				Ports:       "443,80",
+			}))
+			Expect(*securityGroups[0].Rules[1].Type).To(Equal(8))
+			Expect(*securityGroups[0].Rules[1].Code).To(Equal(0))
+			Expect(securityGroups[0].Relationships.RunningSpaces.Data).To(HaveLen(1))
+			Expect(securityGroups[0].Relationships.RunningSpaces.Data[0].GUID).To(Equal("space-1-guid"))
+
+			Expect(securityGroups[1].GUID).To(Equal("asg-2-guid"))
+			Expect(securityGroups[1].GloballyEnabled.Running).To(BeFalse())
+			Expect(securityGroups[1].Relationships.RunningSpaces.Data).To(HaveLen(2))
+		})
+
+		Context("when the json client returns an error", func() {
+			BeforeEach(func() {
+				fakeJSONClient.DoStub = nil
+				fakeJSONClient.DoReturns(errors.New("banana"))
+			})
+
+			It("returns the error", func() {
+				_, err := client.GetSecurityGroups("some-token")
+				Expect(err).To(MatchError("json client do: banana"))
+			})
+		})
+	})
+
 	Describe("GetSpaceGUIDs", func() {
 		BeforeEach(func() {
 			fakeJSONClient.DoStub = func(method, route string, reqData, respData interface{}, token string) error {
@@ -459,6 +564,45 @@ var _ = Describe("Client", func() {
 		})
 	})
 
+	Describe("GetSubjectManagedOrgs", func() {
+		BeforeEach(func() {
+			fakeJSONClient.DoStub = func(method, route string, reqData, respData interface{}, token string) error {
+				_ = json.Unmarshal([]byte(fixtures.SubjectManagedOrgs), respData)
+				return nil
+			}
+		})
+
+		It("returns the orgs the subject manages", func() {
+			managedOrgs, err := client.GetSubjectManagedOrgs("some-token", "some-subject-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeJSONClient.DoCallCount()).To(Equal(1))
+
+			method, route, reqData, _, token := fakeJSONClient.DoArgsForCall(0)
+
+			Expect(method).To(Equal("GET"))
+			Expect(route).To(Equal("/v2/users/some-subject-id/managed_organizations"))
+			Expect(reqData).To(BeNil())
+			Expect(token).To(Equal("bearer some-token"))
+
+			Expect(managedOrgs).To(Equal(map[string]struct{}{
+				"org-1-guid": {},
+				"org-2-guid": {},
+			}))
+		})
+
+		Context("when the json client returns an error", func() {
+			BeforeEach(func() {
+				fakeJSONClient.DoReturns(errors.New("banana"))
+			})
+
+			It("returns a helpful error", func() {
+				_, err := client.GetSubjectManagedOrgs("some-token", "some-subject-id")
+				Expect(err).To(MatchError("json client do: banana"))
+			})
+		})
+	})
+
 	Describe("GetSubjectSpace", func() {
 		space := api.Space{
 			Name:    "some-space-name",
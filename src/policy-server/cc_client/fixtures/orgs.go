@@ -0,0 +1,57 @@
+package fixtures
+
+const LiveOrgsPage1 = `{
+   "pagination": {
+      "total_results": 3,
+      "total_pages": 2,
+      "first": {
+         "href": "/v3/organizations?page=1"
+      },
+      "last": {
+         "href": "/v3/organizations?page=2"
+      },
+      "next": {
+         "href": "/v3/organizations?page=2"
+      },
+      "previous": null
+   },
+   "resources": [
+      {
+         "guid": "live-org-1-guid",
+         "created_at": "2018-07-24T17:49:02Z",
+         "updated_at": "2018-07-24T17:49:02Z",
+         "name": "org-1"
+      },
+      {
+         "guid": "filtered-org-1-guid",
+         "created_at": "2018-07-24T17:49:02Z",
+         "updated_at": "2018-07-24T17:49:02Z",
+         "name": "org-2"
+      }
+   ]
+}`
+
+const LiveOrgsPage2 = `{
+   "pagination": {
+      "total_results": 3,
+      "total_pages": 2,
+      "first": {
+         "href": "/v3/organizations?page=1"
+      },
+      "last": {
+         "href": "/v3/organizations?page=2"
+      },
+      "next": null,
+      "previous": {
+         "href": "/v3/organizations?page=1"
+      }
+   },
+   "resources": [
+      {
+         "guid": "live-org-2-guid",
+         "created_at": "2018-07-24T17:49:02Z",
+         "updated_at": "2018-07-24T17:49:02Z",
+         "name": "org-3"
+      }
+   ]
+}`
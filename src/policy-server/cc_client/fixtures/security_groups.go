@@ -0,0 +1,107 @@
+package fixtures
+
+const SecurityGroupsPage1 = `{
+   "pagination": {
+      "total_results": 2,
+      "total_pages": 2,
+      "first": {
+         "href": "/v3/security_groups?page=1"
+      },
+      "last": {
+         "href": "/v3/security_groups?page=2"
+      },
+      "next": {
+         "href": "/v3/security_groups?page=2"
+      },
+      "previous": null
+   },
+   "resources": [
+      {
+         "guid": "asg-1-guid",
+         "created_at": "2018-07-24T17:49:02Z",
+         "updated_at": "2018-07-24T17:49:02Z",
+         "name": "public-networks",
+         "globally_enabled": {
+            "running": true,
+            "staging": false
+         },
+         "rules": [
+            {
+               "protocol": "tcp",
+               "destination": "10.10.10.0/24",
+               "ports": "443,80"
+            },
+            {
+               "protocol": "icmp",
+               "destination": "10.10.11.1",
+               "type": 8,
+               "code": 0,
+               "description": "ping"
+            }
+         ],
+         "relationships": {
+            "staging_spaces": {
+               "data": []
+            },
+            "running_spaces": {
+               "data": [
+                  {
+                     "guid": "space-1-guid"
+                  }
+               ]
+            }
+         }
+      }
+   ]
+}`
+
+const SecurityGroupsPage2 = `{
+   "pagination": {
+      "total_results": 2,
+      "total_pages": 2,
+      "first": {
+         "href": "/v3/security_groups?page=1"
+      },
+      "last": {
+         "href": "/v3/security_groups?page=2"
+      },
+      "next": null,
+      "previous": {
+         "href": "/v3/security_groups?page=1"
+      }
+   },
+   "resources": [
+      {
+         "guid": "asg-2-guid",
+         "created_at": "2018-07-24T17:49:02Z",
+         "updated_at": "2018-07-24T17:49:02Z",
+         "name": "dns",
+         "globally_enabled": {
+            "running": false,
+            "staging": false
+         },
+         "rules": [
+            {
+               "protocol": "udp",
+               "destination": "8.8.8.8-8.8.8.9",
+               "ports": "53"
+            }
+         ],
+         "relationships": {
+            "staging_spaces": {
+               "data": []
+            },
+            "running_spaces": {
+               "data": [
+                  {
+                     "guid": "space-2-guid"
+                  },
+                  {
+                     "guid": "space-3-guid"
+                  }
+               ]
+            }
+         }
+      }
+   ]
+}`
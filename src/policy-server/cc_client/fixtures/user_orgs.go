@@ -0,0 +1,50 @@
+package fixtures
+
+const SubjectManagedOrgs = `{
+  "total_results": 2,
+  "total_pages": 1,
+  "prev_url": null,
+  "next_url": null,
+  "resources": [
+    {
+      "metadata": {
+        "guid": "org-1-guid",
+        "url": "/v2/organizations/org-1-guid",
+        "created_at": "2016-06-08T16:41:33Z",
+        "updated_at": "2016-06-08T16:41:26Z"
+      },
+      "entity": {
+        "name": "org-1-name",
+        "billing_enabled": false,
+        "quota_definition_guid": "quota-guid",
+        "status": "active",
+        "spaces_url": "/v2/organizations/org-1-guid/spaces",
+        "managers_url": "/v2/organizations/org-1-guid/managers"
+      }
+    },
+    {
+      "metadata": {
+        "guid": "org-2-guid",
+        "url": "/v2/organizations/org-2-guid",
+        "created_at": "2016-06-08T16:41:33Z",
+        "updated_at": "2016-06-08T16:41:26Z"
+      },
+      "entity": {
+        "name": "org-2-name",
+        "billing_enabled": false,
+        "quota_definition_guid": "quota-guid",
+        "status": "active",
+        "spaces_url": "/v2/organizations/org-2-guid/spaces",
+        "managers_url": "/v2/organizations/org-2-guid/managers"
+      }
+    }
+  ]
+}`
+
+const SubjectManagedOrgsEmpty = `{
+  "total_results": 0,
+  "total_pages": 1,
+  "prev_url": null,
+  "next_url": null,
+  "resources": []
+}`
@@ -17,6 +17,7 @@ type uaaClient interface {
 type ccClient interface {
 	GetLiveAppGUIDs(token string, appGUIDs []string) (map[string]struct{}, error)
 	GetLiveSpaceGUIDs(token string, spaceGUIDs []string) (map[string]struct{}, error)
+	GetLiveOrgGUIDs(token string, orgGUIDs []string) (map[string]struct{}, error)
 }
 
 //go:generate counterfeiter -o fakes/policy_store.go --fake-name PolicyStore . policyStore
@@ -137,10 +138,11 @@ func (p *PolicyCleaner) getC2CPoliciesToDelete(policies []store.Policy, token st
 }
 
 func (p *PolicyCleaner) getEgressPoliciesToDelete(egressPolicies []store.EgressPolicy, token string) ([]store.EgressPolicy, error) {
-	var spaceEgressPolicyGUIDs, appEgressPolicyGUIDs []string
+	var spaceEgressPolicyGUIDs, appEgressPolicyGUIDs, orgEgressPolicyGUIDs []string
 	spaceEgressPolicies := make(map[string][]store.EgressPolicy)
 	var egressPoliciesToDelete []store.EgressPolicy
 	appEgressPolicies := make(map[string][]store.EgressPolicy)
+	orgEgressPolicies := make(map[string][]store.EgressPolicy)
 
 	for _, egressPolicy := range egressPolicies {
 		if egressPolicy.Source.Type == "space" {
@@ -151,6 +153,10 @@ func (p *PolicyCleaner) getEgressPoliciesToDelete(egressPolicies []store.EgressP
 			appEgressPolicyGUIDs = append(appEgressPolicyGUIDs, egressPolicy.Source.ID)
 			appEgressPolicies[egressPolicy.Source.ID] = append(appEgressPolicies[egressPolicy.Source.ID], egressPolicy)
 		}
+		if egressPolicy.Source.Type == "org" {
+			orgEgressPolicyGUIDs = append(orgEgressPolicyGUIDs, egressPolicy.Source.ID)
+			orgEgressPolicies[egressPolicy.Source.ID] = append(orgEgressPolicies[egressPolicy.Source.ID], egressPolicy)
+		}
 	}
 
 	appGUIDchunks := getChunks(appEgressPolicyGUIDs, p.CCAppRequestChunkSize)
@@ -171,20 +177,30 @@ func (p *PolicyCleaner) getEgressPoliciesToDelete(egressPolicies []store.EgressP
 		p.Logger.Error("get-live-space-guids-failed", err)
 		return nil, fmt.Errorf("get live space guids failed: %s", err)
 	}
-	egressPoliciesToDelete = append(egressPoliciesToDelete, getStaleEgressSpacePolicies(spaceEgressPolicies, liveSpaceGUIDs)...)
+	egressPoliciesToDelete = append(egressPoliciesToDelete, getStaleEgressPoliciesBySource(spaceEgressPolicies, liveSpaceGUIDs)...)
+
+	if len(orgEgressPolicyGUIDs) > 0 {
+		liveOrgGUIDs, err := p.CCClient.GetLiveOrgGUIDs(token, orgEgressPolicyGUIDs)
+		if err != nil {
+			p.Logger.Error("get-live-org-guids-failed", err)
+			return nil, fmt.Errorf("get live org guids failed: %s", err)
+		}
+		egressPoliciesToDelete = append(egressPoliciesToDelete, getStaleEgressPoliciesBySource(orgEgressPolicies, liveOrgGUIDs)...)
+	}
+
 	return egressPoliciesToDelete, nil
 }
 
-func getStaleEgressSpacePolicies(spacePolicies map[string][]store.EgressPolicy, liveSpaceGUIDs map[string]struct{}) []store.EgressPolicy {
-	var staleSpaceEgressPolicies []store.EgressPolicy
-	for spaceGUID := range liveSpaceGUIDs {
-		delete(spacePolicies, spaceGUID)
+func getStaleEgressPoliciesBySource(sourcePolicies map[string][]store.EgressPolicy, liveSourceGUIDs map[string]struct{}) []store.EgressPolicy {
+	var staleEgressPolicies []store.EgressPolicy
+	for sourceGUID := range liveSourceGUIDs {
+		delete(sourcePolicies, sourceGUID)
 	}
-	for _, policies := range spacePolicies {
-		staleSpaceEgressPolicies = append(staleSpaceEgressPolicies, policies...)
+	for _, policies := range sourcePolicies {
+		staleEgressPolicies = append(staleEgressPolicies, policies...)
 	}
 
-	return staleSpaceEgressPolicies
+	return staleEgressPolicies
 }
 
 func getStaleEgressAppPolicies(appPolicies map[string][]store.EgressPolicy, staleAppGUIDs map[string]struct{}) []store.EgressPolicy {
@@ -237,6 +237,55 @@ var _ = Describe("PolicyCleaner", func() {
 		})
 	})
 
+	Context("when there are org and default egress policies", func() {
+		BeforeEach(func() {
+			egressPolicies = []store.EgressPolicy{
+				{
+					ID:     "live-org-egress-policy-guid",
+					Source: store.EgressSource{ID: "live-egress-org-guid", Type: "org"},
+				},
+				{
+					ID:     "dead-org-egress-policy-guid",
+					Source: store.EgressSource{ID: "dead-egress-org-guid", Type: "org"},
+				},
+				{
+					ID:     "default-egress-policy-guid",
+					Source: store.EgressSource{Type: "default"},
+				},
+			}
+			fakeEgressStore.AllReturns(egressPolicies, nil)
+			fakeCCClient.GetLiveOrgGUIDsReturns(map[string]struct{}{"live-egress-org-guid": {}}, nil)
+		})
+
+		It("deletes org policies whose org no longer exists and keeps default policies", func() {
+			_, deletedEgressPolicies, err := policyCleaner.DeleteStalePolicies()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeCCClient.GetLiveOrgGUIDsCallCount()).To(Equal(1))
+			token, guids := fakeCCClient.GetLiveOrgGUIDsArgsForCall(0)
+			Expect(token).To(Equal("valid-token"))
+			Expect(guids).To(ConsistOf("live-egress-org-guid", "dead-egress-org-guid"))
+
+			Expect(fakeEgressStore.DeleteCallCount()).To(Equal(1))
+			Expect(fakeEgressStore.DeleteArgsForCall(0)).To(Equal([]string{"dead-org-egress-policy-guid"}))
+			Expect(deletedEgressPolicies).To(Equal(egressPolicies[1:2]))
+		})
+
+		It("returns a helpful error when get live org guids call fails", func() {
+			fakeCCClient.GetLiveOrgGUIDsReturns(nil, errors.New("zulu"))
+
+			_, _, err := policyCleaner.DeleteStalePolicies()
+			Expect(err).To(MatchError("get live org guids failed: zulu"))
+			Expect(logger).To(gbytes.Say("get-live-org-guids-failed.*zulu"))
+		})
+	})
+
+	It("does not look up orgs when there are no org egress policies", func() {
+		_, _, err := policyCleaner.DeleteStalePolicies()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeCCClient.GetLiveOrgGUIDsCallCount()).To(Equal(0))
+	})
+
 	It("returns a helpful error when get live space guids call fails", func() {
 		fakeCCClient.GetLiveSpaceGUIDsReturns(nil, errors.New("yankee"))
 
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"lib/rules"
+	"os"
+	"policy-server/api"
+	"policy-server/policy_compiler"
+)
+
+const (
+	jobPrefix = "compile-iptables-rules"
+	logPrefix = "cfnetworking"
+)
+
+func main() {
+	err := mainWithError()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s.%s: %s\n", logPrefix, jobPrefix, err)
+		os.Exit(1)
+	}
+}
+
+func mainWithError() error {
+	policiesFile := flag.String("policies-file", "", "path to the response of GET /networking/v1/internal/policies")
+	containersFile := flag.String("containers-file", "", `path to a json object of container ips to containers, e.g. {"10.255.0.2": {"app_guid": "some-app-guid", "space_guid": "some-space-guid", "org_guid": "some-org-guid"}}`)
+	chainPrefix := flag.String("chain-prefix", "vpa--", "prefix of the compiled chain names")
+	acceptedUDPLogsPerSec := flag.Int("accepted-udp-logs-per-sec", 100, "rate limit of logs for accepted udp packets")
+	ip6tablesFile := flag.String("ip6tables-file", "", "path to write the ip6tables-restore document to, required when there are IPv6 rules")
+	flag.Parse()
+
+	if *policiesFile == "" || *containersFile == "" {
+		return fmt.Errorf("-policies-file and -containers-file are required")
+	}
+
+	var payload api.PolicyCollectionPayload
+	if err := readJSON(*policiesFile, &payload); err != nil {
+		return fmt.Errorf("reading policies: %s", err)
+	}
+
+	containersByIP := map[string]rules.PolicyContainer{}
+	if err := readJSON(*containersFile, &containersByIP); err != nil {
+		return fmt.Errorf("reading containers: %s", err)
+	}
+
+	compiler := policy_compiler.PolicyCompiler{
+		ChainPrefix:           *chainPrefix,
+		AcceptedUDPLogsPerSec: *acceptedUDPLogsPerSec,
+	}
+	plan, err := compiler.Compile(payload, containersByIP)
+	if err != nil {
+		return fmt.Errorf("compiling: %s", err)
+	}
+	for _, policy := range plan.Skipped {
+		fmt.Fprintf(os.Stderr, "%s.%s: skipped egress policy %s: %s\n", logPrefix, jobPrefix, policy.ID, policy.Reason)
+	}
+
+	if *ip6tablesFile != "" {
+		err = ioutil.WriteFile(*ip6tablesFile, []byte(rules.RestoreDocument("filter", plan.IPv6Chains)), 0644)
+		if err != nil {
+			return fmt.Errorf("writing ip6tables rules: %s", err)
+		}
+	} else if hasRules(plan.IPv6Chains) {
+		return fmt.Errorf("-ip6tables-file is required, the policies have IPv6 rules")
+	}
+
+	_, err = fmt.Fprint(os.Stdout, rules.RestoreDocument("filter", plan.IPv4Chains))
+	return err
+}
+
+func hasRules(chains []rules.CompiledChain) bool {
+	for _, chain := range chains {
+		if len(chain.Rules) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func readJSON(path string, v interface{}) error {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(contents, v)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"lib/policy_client"
+	"lib/rules"
+	"net/http"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/mutualtls"
+	"code.cloudfoundry.org/lager"
+)
+
+const (
+	jobPrefix = "decode-iptables-logs"
+	logPrefix = "cfnetworking"
+)
+
+func main() {
+	err := mainWithError()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s.%s: %s\n", logPrefix, jobPrefix, err)
+		os.Exit(1)
+	}
+}
+
+func mainWithError() error {
+	policyServerURL := flag.String("policy-server-url", "", "internal policy server url, e.g. https://policy-server.service.cf.internal:4003, used to resolve tags to app guids")
+	caCertFile := flag.String("ca-cert-file", "", "path to the ca cert of the internal policy server")
+	clientCertFile := flag.String("client-cert-file", "", "path to a client cert for the internal policy server")
+	clientKeyFile := flag.String("client-key-file", "", "path to the key of the client cert")
+	tagLength := flag.Int("tag-length", rules.DefaultTagLength, "tag_length of the policy server, in bytes")
+	flag.Parse()
+
+	input := io.Reader(os.Stdin)
+	if flag.NArg() > 0 && flag.Arg(0) != "-" {
+		file, err := os.Open(flag.Arg(0))
+		if err != nil {
+			return fmt.Errorf("opening log file: %s", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var idsByTag map[string]string
+	if *policyServerURL != "" {
+		var err error
+		idsByTag, err = getTags(*policyServerURL, *caCertFile, *clientCertFile, *clientKeyFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	return decode(input, os.Stdout, rules.LogDecoder{TagLength: *tagLength}, idsByTag)
+}
+
+func getTags(policyServerURL, caCertFile, clientCertFile, clientKeyFile string) (map[string]string, error) {
+	tlsConfig, err := mutualtls.NewClientTLSConfig(clientCertFile, clientKeyFile, caCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("mutual tls config: %s", err)
+	}
+	httpClient := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		Timeout:   10 * time.Second,
+	}
+
+	logger := lager.NewLogger(fmt.Sprintf("%s.%s", logPrefix, jobPrefix))
+	client := policy_client.NewInternal(logger, httpClient, policyServerURL)
+	tags, err := client.GetTags()
+	if err != nil {
+		return nil, fmt.Errorf("getting tags: %s", err)
+	}
+
+	idsByTag := map[string]string{}
+	for _, tag := range tags {
+		idsByTag[tag.Tag] = tag.ID
+	}
+	return idsByTag, nil
+}
+
+func decode(input io.Reader, output io.Writer, decoder rules.LogDecoder, idsByTag map[string]string) error {
+	encoder := json.NewEncoder(output)
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		event, ok := decoder.Decode(scanner.Text())
+		if !ok {
+			continue
+		}
+		event.ResolveTag(idsByTag)
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("writing event: %s", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading logs: %s", err)
+	}
+	return nil
+}
@@ -113,6 +113,8 @@ func main() {
 		ErrorResponse: errorResponse,
 	}
 
+	tagsIndexHandlerV1 := handlers.NewTagsIndex(wrappedStore, marshal.MarshalFunc(json.Marshal), errorResponse)
+
 	metricsWrap := func(name string, handler http.Handler) http.Handler {
 		metricsWrapper := middleware.MetricWrapper{
 			Name:          name,
@@ -139,11 +141,13 @@ func main() {
 	internalRoutes := rata.Routes{
 		{Name: "internal_policies", Method: "GET", Path: "/networking/:version/internal/policies"},
 		{Name: "create_tags", Method: "PUT", Path: "/networking/v1/internal/tags"},
+		{Name: "tags_index", Method: "GET", Path: "/networking/v1/internal/tags"},
 	}
 
 	internalHandlers := rata.Handlers{
 		"internal_policies": metricsWrap("InternalPolicies", logWrap(internalPoliciesHandlerV1)),
 		"create_tags":       metricsWrap("CreateTags", logWrap(createTagsHandlerV1)),
+		"tags_index":        metricsWrap("InternalTagsIndex", logWrap(tagsIndexHandlerV1)),
 	}
 
 	tlsConfig, err := mutualtls.NewServerTLSConfig(conf.ServerCertFile, conf.ServerKeyFile, conf.CACertFile)
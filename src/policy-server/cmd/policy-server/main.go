@@ -17,9 +17,11 @@ import (
 	"policy-server/adapter"
 	"policy-server/api"
 	"policy-server/api/api_v0"
+	"policy-server/asg_converter"
 	"policy-server/cc_client"
 	"policy-server/cleaner"
 	"policy-server/config"
+	"policy-server/fqdn"
 	"policy-server/handlers"
 	psmiddleware "policy-server/middleware"
 	"policy-server/store"
@@ -32,6 +34,7 @@ import (
 	"code.cloudfoundry.org/cf-networking-helpers/metrics"
 	"code.cloudfoundry.org/cf-networking-helpers/middleware"
 	middlewareAdapter "code.cloudfoundry.org/cf-networking-helpers/middleware/adapter"
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/debugserver"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagerflags"
@@ -194,9 +197,20 @@ func main() {
 		EgressDestinationRepo:   &store.EgressDestinationTable{},
 		TerminalsRepo:           terminalsTable,
 		DestinationMetadataRepo: &store.DestinationMetadataTable{},
+		OverlapMode:             conf.EgressDestinationOverlapMode,
+	}
+
+	fqdnResolver := fqdn.NewResolver(logger.Session("fqdn-resolver"), egressDestinationStore, metricsSender)
+
+	egressGuard := &handlers.EgressGuard{
+		CCClient:                  ccClient,
+		UAAClient:                 uaaClient,
+		DestinationStore:          egressDestinationStore,
+		SpaceDeveloperSelfService: conf.EnableSpaceDeveloperSelfService,
 	}
 
 	destinationsIndexHandlerV1 := &handlers.DestinationsIndex{
+		EgressGuard:             egressGuard,
 		ErrorResponse:           errorResponse,
 		EgressDestinationStore:  egressDestinationStore,
 		EgressDestinationMapper: egressDestinationMapper,
@@ -204,13 +218,24 @@ func main() {
 	}
 
 	createDestinationsHandlerV1 := &handlers.DestinationsCreate{
+		EgressGuard:             egressGuard,
 		ErrorResponse:           errorResponse,
 		EgressDestinationStore:  egressDestinationStore,
 		EgressDestinationMapper: egressDestinationMapper,
+		DestinationResolver:     fqdnResolver,
 		Logger:                  logger,
 	}
 
 	updateDestinationsHandlerV1 := &handlers.DestinationsUpdate{
+		EgressGuard:             egressGuard,
+		ErrorResponse:           errorResponse,
+		EgressDestinationStore:  egressDestinationStore,
+		EgressDestinationMapper: egressDestinationMapper,
+		DestinationResolver:     fqdnResolver,
+		Logger:                  logger,
+	}
+
+	destinationsOverlapsHandlerV1 := &handlers.DestinationsOverlaps{
 		ErrorResponse:           errorResponse,
 		EgressDestinationStore:  egressDestinationStore,
 		EgressDestinationMapper: egressDestinationMapper,
@@ -218,6 +243,7 @@ func main() {
 	}
 
 	deleteDestinationHandlerV1 := &handlers.DestinationDelete{
+		EgressGuard:             egressGuard,
 		ErrorResponse:           errorResponse,
 		EgressDestinationStore:  egressDestinationStore,
 		EgressDestinationMapper: egressDestinationMapper,
@@ -237,6 +263,7 @@ func main() {
 	}
 
 	indexEgressPolicyHandlerV1 := &handlers.EgressPolicyIndex{
+		EgressGuard:   egressGuard,
 		ErrorResponse: errorResponse,
 		Store:         egressPolicyStore,
 		Mapper:        egressPolicyMapper,
@@ -244,6 +271,7 @@ func main() {
 	}
 
 	createEgressPolicyHandlerV1 := &handlers.EgressPolicyCreate{
+		EgressGuard:   egressGuard,
 		Store:         egressPolicyStore,
 		Mapper:        egressPolicyMapper,
 		ErrorResponse: errorResponse,
@@ -251,6 +279,23 @@ func main() {
 	}
 
 	deleteEgressPolicyHandlerV1 := &handlers.EgressPolicyDelete{
+		EgressGuard:   egressGuard,
+		Store:         egressPolicyStore,
+		Mapper:        egressPolicyMapper,
+		ErrorResponse: errorResponse,
+		Logger:        logger,
+	}
+
+	showEgressPolicyHandlerV1 := &handlers.EgressPolicyShow{
+		EgressGuard:   egressGuard,
+		Store:         egressPolicyStore,
+		Mapper:        egressPolicyMapper,
+		ErrorResponse: errorResponse,
+		Logger:        logger,
+	}
+
+	updateEgressPolicyHandlerV1 := &handlers.EgressPolicyUpdate{
+		EgressGuard:   egressGuard,
 		Store:         egressPolicyStore,
 		Mapper:        egressPolicyMapper,
 		ErrorResponse: errorResponse,
@@ -263,6 +308,18 @@ func main() {
 	policyCollectionWriter := api.NewPolicyCollectionWriter(marshal.MarshalFunc(json.Marshal))
 	policiesCleanupHandler := handlers.NewPoliciesCleanup(policyCollectionWriter, policyCleaner, errorResponse)
 
+	securityGroupsConvertHandler := &handlers.SecurityGroupsConvert{
+		Converter: &asg_converter.Converter{
+			Logger:           logger.Session("asg-converter"),
+			CCClient:         ccClient,
+			UAAClient:        uaaClient,
+			DestinationStore: egressDestinationStore,
+			PolicyStore:      egressPolicyStore,
+		},
+		Marshaler:     marshal.MarshalFunc(json.Marshal),
+		ErrorResponse: errorResponse,
+	}
+
 	tagsIndexHandler := handlers.NewTagsIndex(wrappedStore, marshal.MarshalFunc(json.Marshal), errorResponse)
 
 	healthHandler := handlers.NewHealth(wrappedStore, errorResponse)
@@ -315,6 +372,31 @@ func main() {
 		return networkWriteAuthenticator.Wrap(handler)
 	}
 
+	authOrgManagerWrap := func(handler http.Handler) http.Handler {
+		orgManagerAuthenticator := handlers.Authenticator{
+			Client:        uaaClient,
+			Scopes:        []string{"network.admin"},
+			ErrorResponse: errorResponse,
+			ScopeChecking: false,
+		}
+		return orgManagerAuthenticator.Wrap(handler)
+	}
+
+	rateLimiters := map[string]*handlers.RateLimiter{}
+	rateLimitWrap := func(route, name string, handler http.Handler) http.Handler {
+		limit, ok := conf.RateLimits[route]
+		if !ok {
+			return handler
+		}
+		rateLimiter, ok := rateLimiters[route]
+		if !ok {
+			rateLimiter = handlers.NewRateLimiter(name, limit.RequestsPerSecond, limit.Burst,
+				conf.RateLimitExemptNetworkAdmin, metricsSender, clock.NewClock())
+			rateLimiters[route] = rateLimiter
+		}
+		return rateLimiter.Wrap(handler)
+	}
+
 	externalRoutes := rata.Routes{
 		{Name: "uptime", Method: "GET", Path: "/"},
 		{Name: "uptime", Method: "GET", Path: "/networking"},
@@ -326,11 +408,15 @@ func main() {
 		{Name: "destinations_index", Method: "GET", Path: "/networking/:version/external/destinations"},
 		{Name: "destinations_create", Method: "POST", Path: "/networking/:version/external/destinations"},
 		{Name: "destinations_update", Method: "PUT", Path: "/networking/:version/external/destinations"},
+		{Name: "destinations_overlaps", Method: "GET", Path: "/networking/:version/external/destinations/overlaps"},
 		{Name: "destination_delete", Method: "DELETE", Path: "/networking/:version/external/destinations/:id"},
 		{Name: "egress_policies_index", Method: "GET", Path: "/networking/:version/external/egress_policies"},
 		{Name: "egress_policies_create", Method: "POST", Path: "/networking/:version/external/egress_policies"},
+		{Name: "egress_policies_show", Method: "GET", Path: "/networking/:version/external/egress_policies/:id"},
+		{Name: "egress_policies_update", Method: "PUT", Path: "/networking/:version/external/egress_policies/:id"},
 		{Name: "egress_policies_delete", Method: "DELETE", Path: "/networking/:version/external/egress_policies/:id"},
 		{Name: "cleanup", Method: "POST", Path: "/networking/:version/external/policies/cleanup"},
+		{Name: "security_groups_convert", Method: "POST", Path: "/networking/:version/external/security_groups/convert"},
 		{Name: "tags_index", Method: "GET", Path: "/networking/:version/external/tags"},
 	}
 
@@ -352,43 +438,75 @@ func main() {
 		"health": corsOptionsWrapper(metricsWrap("Health", logWrap(healthHandler))),
 
 		"create_policies": corsOptionsWrapper(metricsWrap("CreatePolicies",
-			logWrap(versionWrap(authWriteWrap(createPolicyHandlerV1), authWriteWrap(createPolicyHandlerV0))))),
+			logWrap(versionWrap(
+				authWriteWrap(rateLimitWrap("create_policies", "CreatePolicies", createPolicyHandlerV1)),
+				authWriteWrap(rateLimitWrap("create_policies", "CreatePolicies", createPolicyHandlerV0)))))),
 
 		"delete_policies": corsOptionsWrapper(metricsWrap("DeletePolicies",
-			logWrap(versionWrap(authWriteWrap(deletePolicyHandlerV1), authWriteWrap(deletePolicyHandlerV0))))),
+			logWrap(versionWrap(
+				authWriteWrap(rateLimitWrap("delete_policies", "DeletePolicies", deletePolicyHandlerV1)),
+				authWriteWrap(rateLimitWrap("delete_policies", "DeletePolicies", deletePolicyHandlerV0)))))),
 
 		"policies_index": corsOptionsWrapper(metricsWrap("PoliciesIndex",
-			logWrap(versionWrap(authWriteWrap(policiesIndexHandlerV1), authWriteWrap(policiesIndexHandlerV0))))),
+			logWrap(versionWrap(
+				authWriteWrap(rateLimitWrap("policies_index", "PoliciesIndex", policiesIndexHandlerV1)),
+				authWriteWrap(rateLimitWrap("policies_index", "PoliciesIndex", policiesIndexHandlerV0)))))),
 
 		"destinations_index": corsOptionsWrapper(metricsWrap("DestinationsIndex",
-			logWrap(versionWrap(authAdminWrap(destinationsIndexHandlerV1), authAdminWrap(destinationsIndexHandlerV1))))),
+			logWrap(versionWrap(
+				authOrgManagerWrap(rateLimitWrap("destinations_index", "DestinationsIndex", destinationsIndexHandlerV1)),
+				authOrgManagerWrap(rateLimitWrap("destinations_index", "DestinationsIndex", destinationsIndexHandlerV1)))))),
 
 		"destinations_create": corsOptionsWrapper(metricsWrap("DestinationsCreate",
-			logWrap(authAdminWrap(createDestinationsHandlerV1)))),
+			logWrap(authOrgManagerWrap(rateLimitWrap("destinations_create", "DestinationsCreate", createDestinationsHandlerV1))))),
 
 		"destinations_update": corsOptionsWrapper(metricsWrap("DestinationsUpdate",
-			logWrap(authAdminWrap(updateDestinationsHandlerV1)))),
+			logWrap(authOrgManagerWrap(rateLimitWrap("destinations_update", "DestinationsUpdate", updateDestinationsHandlerV1))))),
+
+		"destinations_overlaps": corsOptionsWrapper(metricsWrap("DestinationsOverlaps",
+			logWrap(authAdminWrap(rateLimitWrap("destinations_overlaps", "DestinationsOverlaps", destinationsOverlapsHandlerV1))))),
 
 		"destination_delete": corsOptionsWrapper(metricsWrap("DestinationDelete",
-			logWrap(authAdminWrap(deleteDestinationHandlerV1)))),
+			logWrap(authOrgManagerWrap(rateLimitWrap("destination_delete", "DestinationDelete", deleteDestinationHandlerV1))))),
 
 		"egress_policies_index": corsOptionsWrapper(metricsWrap("EgressPoliciesIndex",
-			logWrap(authAdminWrap(indexEgressPolicyHandlerV1)))),
+			logWrap(authOrgManagerWrap(rateLimitWrap("egress_policies_index", "EgressPoliciesIndex", indexEgressPolicyHandlerV1))))),
 
 		"egress_policies_create": corsOptionsWrapper(metricsWrap("EgressPoliciesCreate",
-			logWrap(authAdminWrap(createEgressPolicyHandlerV1)))),
+			logWrap(authOrgManagerWrap(rateLimitWrap("egress_policies_create", "EgressPoliciesCreate", createEgressPolicyHandlerV1))))),
+
+		"egress_policies_show": corsOptionsWrapper(metricsWrap("EgressPoliciesShow",
+			logWrap(authOrgManagerWrap(rateLimitWrap("egress_policies_show", "EgressPoliciesShow", showEgressPolicyHandlerV1))))),
+
+		"egress_policies_update": corsOptionsWrapper(metricsWrap("EgressPoliciesUpdate",
+			logWrap(authOrgManagerWrap(rateLimitWrap("egress_policies_update", "EgressPoliciesUpdate", updateEgressPolicyHandlerV1))))),
 
 		"egress_policies_delete": corsOptionsWrapper(metricsWrap("EgressPoliciesDelete",
-			logWrap(authAdminWrap(deleteEgressPolicyHandlerV1)))),
+			logWrap(authOrgManagerWrap(rateLimitWrap("egress_policies_delete", "EgressPoliciesDelete", deleteEgressPolicyHandlerV1))))),
 
 		"cleanup": corsOptionsWrapper(metricsWrap("Cleanup",
-			logWrap(versionWrap(authAdminWrap(policiesCleanupHandler), authAdminWrap(policiesCleanupHandler))))),
+			logWrap(versionWrap(
+				authAdminWrap(rateLimitWrap("cleanup", "Cleanup", policiesCleanupHandler)),
+				authAdminWrap(rateLimitWrap("cleanup", "Cleanup", policiesCleanupHandler)))))),
+
+		"security_groups_convert": corsOptionsWrapper(metricsWrap("SecurityGroupsConvert",
+			logWrap(authAdminWrap(rateLimitWrap("security_groups_convert", "SecurityGroupsConvert", securityGroupsConvertHandler))))),
 
 		"tags_index": corsOptionsWrapper(metricsWrap("TagsIndex",
-			logWrap(versionWrap(authAdminWrap(tagsIndexHandler), authAdminWrap(tagsIndexHandler))))),
+			logWrap(versionWrap(
+				authAdminWrap(rateLimitWrap("tags_index", "TagsIndex", tagsIndexHandler)),
+				authAdminWrap(rateLimitWrap("tags_index", "TagsIndex", tagsIndexHandler)))))),
 
 		"whoami": corsOptionsWrapper(metricsWrap("WhoAmI",
-			logWrap(versionWrap(authAdminWrap(whoamiHandler), authAdminWrap(whoamiHandler))))),
+			logWrap(versionWrap(
+				authAdminWrap(rateLimitWrap("whoami", "WhoAmI", whoamiHandler)),
+				authAdminWrap(rateLimitWrap("whoami", "WhoAmI", whoamiHandler)))))),
+	}
+
+	for route := range conf.RateLimits {
+		if _, ok := rateLimiters[route]; !ok {
+			log.Fatalf("%s.%s: rate limit configured for unknown route: %s", logPrefix, jobPrefix, route)
+		}
 	}
 
 	err = dropsonde.Initialize(conf.MetronAddress, dropsondeOrigin)
@@ -399,12 +517,18 @@ func main() {
 	metricsEmitter := common.InitMetricsEmitter(logger, wrappedStore, connectionPool, connectionPool.Monitor)
 	externalServer := common.InitServer(logger, nil, conf.ListenHost, conf.ListenPort, externalHandlers, externalRoutesWithOptions)
 	policyPoller := initPoller(logger, conf, policyCleaner)
+	fqdnPoller := &poller.Poller{
+		Logger:          logger.Session("fqdn-resolver-poller"),
+		PollInterval:    time.Duration(conf.FQDNResolutionInterval) * time.Second,
+		SingleCycleFunc: fqdnResolver.RefreshDestinations,
+	}
 	debugServer := debugserver.Runner(fmt.Sprintf("%s:%d", conf.DebugServerHost, conf.DebugServerPort), reconfigurableSink)
 
 	members := grouper.Members{
 		{"metrics_emitter", metricsEmitter},
 		{"http_server", externalServer},
 		{"policy-cleaner-poller", policyPoller},
+		{"fqdn-resolver-poller", fqdnPoller},
 		{"debug-server", debugServer},
 	}
 
@@ -11,37 +11,64 @@ import (
 )
 
 type Config struct {
-	ListenHost                      string    `json:"listen_host" validate:"nonzero"`
-	ListenPort                      int       `json:"listen_port" validate:"nonzero"`
-	LogPrefix                       string    `json:"log_prefix" validate:"nonzero"`
-	DebugServerHost                 string    `json:"debug_server_host" validate:"nonzero"`
-	DebugServerPort                 int       `json:"debug_server_port" validate:"nonzero"`
-	UAAClient                       string    `json:"uaa_client" validate:"nonzero"`
-	UAAClientSecret                 string    `json:"uaa_client_secret" validate:"nonzero"`
-	UAACA                           string    `json:"uaa_ca"`
-	UAAURL                          string    `json:"uaa_url" validate:"nonzero"`
-	UAAPort                         int       `json:"uaa_port" validate:"nonzero"`
-	CCURL                           string    `json:"cc_url" validate:"nonzero"`
-	CCCA                            string    `json:"cc_ca_cert" validate:"nonzero"`
-	SkipSSLValidation               bool      `json:"skip_ssl_validation"`
-	Database                        db.Config `json:"database" validate:"nonzero"`
-	DatabaseMigrationTimeout        int       `json:"database_migration_timeout" validate:"min=1"`
-	TagLength                       int       `json:"tag_length" validate:"nonzero"`
-	MetronAddress                   string    `json:"metron_address" validate:"nonzero"`
-	LogLevel                        string    `json:"log_level"`
-	CleanupInterval                 int       `json:"cleanup_interval" validate:"min=1"`
-	CCAppRequestChunkSize           int       `json:"cc_app_request_chunk_size"`
-	RequestTimeout                  int       `json:"request_timeout" validate:"min=1"`
-	MaxPolicies                     int       `json:"max_policies" validate:"min=1"`
-	EnableSpaceDeveloperSelfService bool      `json:"enable_space_developer_self_service"`
-	AllowedCORSDomains              []string  `json:"allowed_cors_domains"`
-	MaxIdleConnections              int       `json:"max_idle_connections" validate:"min=0"`
-	MaxOpenConnections              int       `json:"max_open_connections" validate:"min=0"`
-	MaxConnectionsLifetimeSeconds   int       `json:"connections_max_lifetime_seconds" validate:"min=0"`
+	ListenHost                      string               `json:"listen_host" validate:"nonzero"`
+	ListenPort                      int                  `json:"listen_port" validate:"nonzero"`
+	LogPrefix                       string               `json:"log_prefix" validate:"nonzero"`
+	DebugServerHost                 string               `json:"debug_server_host" validate:"nonzero"`
+	DebugServerPort                 int                  `json:"debug_server_port" validate:"nonzero"`
+	UAAClient                       string               `json:"uaa_client" validate:"nonzero"`
+	UAAClientSecret                 string               `json:"uaa_client_secret" validate:"nonzero"`
+	UAACA                           string               `json:"uaa_ca"`
+	UAAURL                          string               `json:"uaa_url" validate:"nonzero"`
+	UAAPort                         int                  `json:"uaa_port" validate:"nonzero"`
+	CCURL                           string               `json:"cc_url" validate:"nonzero"`
+	CCCA                            string               `json:"cc_ca_cert" validate:"nonzero"`
+	SkipSSLValidation               bool                 `json:"skip_ssl_validation"`
+	Database                        db.Config            `json:"database" validate:"nonzero"`
+	DatabaseMigrationTimeout        int                  `json:"database_migration_timeout" validate:"min=1"`
+	TagLength                       int                  `json:"tag_length" validate:"nonzero"`
+	MetronAddress                   string               `json:"metron_address" validate:"nonzero"`
+	LogLevel                        string               `json:"log_level"`
+	CleanupInterval                 int                  `json:"cleanup_interval" validate:"min=1"`
+	FQDNResolutionInterval          int                  `json:"fqdn_resolution_interval" validate:"min=1"`
+	EgressDestinationOverlapMode    string               `json:"egress_destination_overlap_mode"`
+	CCAppRequestChunkSize           int                  `json:"cc_app_request_chunk_size"`
+	RequestTimeout                  int                  `json:"request_timeout" validate:"min=1"`
+	MaxPolicies                     int                  `json:"max_policies" validate:"min=1"`
+	EnableSpaceDeveloperSelfService bool                 `json:"enable_space_developer_self_service"`
+	AllowedCORSDomains              []string             `json:"allowed_cors_domains"`
+	MaxIdleConnections              int                  `json:"max_idle_connections" validate:"min=0"`
+	MaxOpenConnections              int                  `json:"max_open_connections" validate:"min=0"`
+	MaxConnectionsLifetimeSeconds   int                  `json:"connections_max_lifetime_seconds" validate:"min=0"`
+	RateLimits                      map[string]RateLimit `json:"rate_limits"`
+	RateLimitExemptNetworkAdmin     bool                 `json:"rate_limit_exempt_network_admin"`
+}
+
+type RateLimit struct {
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	Burst             int     `json:"burst"`
 }
 
 func (c *Config) Validate() error {
-	return validator.Validate(c)
+	if err := validator.Validate(c); err != nil {
+		return err
+	}
+
+	switch c.EgressDestinationOverlapMode {
+	case "", "warn", "reject":
+	default:
+		return fmt.Errorf("EgressDestinationOverlapMode: must be warn or reject")
+	}
+
+	for route, limit := range c.RateLimits {
+		if limit.RequestsPerSecond <= 0 {
+			return fmt.Errorf("RateLimits.%s.RequestsPerSecond: must be greater than 0", route)
+		}
+		if limit.Burst < 0 {
+			return fmt.Errorf("RateLimits.%s.Burst: less than min", route)
+		}
+	}
+	return nil
 }
 
 func New(path string) (*Config, error) {
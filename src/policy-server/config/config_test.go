@@ -60,10 +60,16 @@ var _ = Describe("Config", func() {
 					"metron_address": "http://1.2.3.4:9999",
 					"log_level": "debug",
 					"cleanup_interval": 2,
+					"fqdn_resolution_interval": 30,
+					"egress_destination_overlap_mode": "reject",
 					"request_timeout": 5,
 					"max_policies": 3,
 					"enable_space_developer_self_service": true,
-					"allowed_cors_domains": ["https://foo.bar", "https://bar.foo"]
+					"allowed_cors_domains": ["https://foo.bar", "https://bar.foo"],
+					"rate_limits": {
+						"create_policies": {"requests_per_second": 0.5, "burst": 10}
+					},
+					"rate_limit_exempt_network_admin": true
 				}`)
 				c, err := config.New(file.Name())
 				Expect(err).NotTo(HaveOccurred())
@@ -97,6 +103,8 @@ var _ = Describe("Config", func() {
 				Expect(c.MetronAddress).To(Equal("http://1.2.3.4:9999"))
 				Expect(c.LogLevel).To(Equal("debug"))
 				Expect(c.CleanupInterval).To(Equal(2))
+				Expect(c.FQDNResolutionInterval).To(Equal(30))
+				Expect(c.EgressDestinationOverlapMode).To(Equal("reject"))
 				Expect(c.RequestTimeout).To(Equal(5))
 				Expect(c.MaxPolicies).To(Equal(3))
 				Expect(c.EnableSpaceDeveloperSelfService).To(BeTrue())
@@ -104,6 +112,10 @@ var _ = Describe("Config", func() {
 					"https://foo.bar",
 					"https://bar.foo",
 				}))
+				Expect(c.RateLimits).To(Equal(map[string]config.RateLimit{
+					"create_policies": {RequestsPerSecond: 0.5, Burst: 10},
+				}))
+				Expect(c.RateLimitExemptNetworkAdmin).To(BeTrue())
 			})
 		})
 
@@ -164,6 +176,7 @@ var _ = Describe("Config", func() {
 					"tag_length":                 2,
 					"metron_address":             "http://1.2.3.4:9999",
 					"cleanup_interval":           2,
+					"fqdn_resolution_interval":   30,
 					"request_timeout":            5,
 					"max_policies":               3,
 				}
@@ -187,11 +200,85 @@ var _ = Describe("Config", func() {
 			Entry("missing tag length", "tag_length", "TagLength: zero value"),
 			Entry("missing metron address", "metron_address", "MetronAddress: zero value"),
 			Entry("missing cleanup interval", "cleanup_interval", "CleanupInterval: less than min"),
+			Entry("missing fqdn resolution interval", "fqdn_resolution_interval", "FQDNResolutionInterval: less than min"),
 			Entry("missing request timeout", "request_timeout", "RequestTimeout: less than min"),
 			Entry("missing max policies", "max_policies", "MaxPolicies: less than min"),
 			Entry("missing database migration timeout", "database_migration_timeout", "DatabaseMigrationTimeout: less than min"),
 		)
 
+		Describe("rate limits", func() {
+			var allData map[string]interface{}
+			BeforeEach(func() {
+				allData = map[string]interface{}{
+					"listen_host":         "http://1.2.3.4",
+					"listen_port":         1234,
+					"log_prefix":          "cfnetworking",
+					"debug_server_host":   "http://4.4.4.4",
+					"debug_server_port":   3333,
+					"uaa_client":          "some-uaa-client",
+					"uaa_client_secret":   "some-uaa-client-secret",
+					"uaa_url":             "http://uaa.example.com",
+					"uaa_port":            5555,
+					"cc_url":              "http://ccapi.example.com",
+					"cc_ca_cert":          "some/cc/ca/cert",
+					"skip_ssl_validation": true,
+					"database": map[string]interface{}{
+						"type":          "mysql",
+						"user":          "root",
+						"password":      "password",
+						"host":          "127.0.0.1",
+						"port":          3306,
+						"timeout":       5,
+						"database_name": "network_policy",
+					},
+					"database_migration_timeout": 88,
+					"tag_length":                 2,
+					"metron_address":             "http://1.2.3.4:9999",
+					"cleanup_interval":           2,
+					"fqdn_resolution_interval":   30,
+					"request_timeout":            5,
+					"max_policies":               3,
+				}
+			})
+
+			Context("when a route has a non-positive requests per second", func() {
+				BeforeEach(func() {
+					allData["rate_limits"] = map[string]interface{}{
+						"create_policies": map[string]interface{}{"requests_per_second": 0, "burst": 1},
+					}
+					Expect(json.NewEncoder(file).Encode(allData)).To(Succeed())
+				})
+				It("returns an error", func() {
+					_, err = config.New(file.Name())
+					Expect(err).To(MatchError("invalid config: RateLimits.create_policies.RequestsPerSecond: must be greater than 0"))
+				})
+			})
+
+			Context("when the egress destination overlap mode is unknown", func() {
+				BeforeEach(func() {
+					allData["egress_destination_overlap_mode"] = "ignore"
+					Expect(json.NewEncoder(file).Encode(allData)).To(Succeed())
+				})
+				It("returns an error", func() {
+					_, err = config.New(file.Name())
+					Expect(err).To(MatchError("invalid config: EgressDestinationOverlapMode: must be warn or reject"))
+				})
+			})
+
+			Context("when a route has a negative burst", func() {
+				BeforeEach(func() {
+					allData["rate_limits"] = map[string]interface{}{
+						"create_policies": map[string]interface{}{"requests_per_second": 1, "burst": -1},
+					}
+					Expect(json.NewEncoder(file).Encode(allData)).To(Succeed())
+				})
+				It("returns an error", func() {
+					_, err = config.New(file.Name())
+					Expect(err).To(MatchError("invalid config: RateLimits.create_policies.Burst: less than min"))
+				})
+			})
+		})
+
 		Describe("database config", func() {
 			var allData map[string]interface{}
 			BeforeEach(func() {
@@ -226,6 +313,7 @@ var _ = Describe("Config", func() {
 					"metron_address":             "http://1.2.3.4:9999",
 					"log_level":                  "info",
 					"cleanup_interval":           2,
+					"fqdn_resolution_interval":   30,
 					"request_timeout":            5,
 					"max_policies":               3,
 				}
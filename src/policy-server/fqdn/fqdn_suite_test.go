@@ -0,0 +1,13 @@
+package fqdn_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestFQDN(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "FQDN Suite")
+}
@@ -0,0 +1,131 @@
+package fqdn
+
+import (
+	"fmt"
+	"net"
+	"policy-server/store"
+	"reflect"
+	"sort"
+
+	"code.cloudfoundry.org/lager"
+)
+
+//go:generate counterfeiter -o fakes/egress_destination_store.go --fake-name EgressDestinationStore . egressDestinationStore
+type egressDestinationStore interface {
+	GetWithFQDN() ([]store.EgressDestination, error)
+	UpdateResolvedIPRanges(guid string, ipRanges []store.IPRange) error
+	RecordFQDNResolutionError(guid, resolutionError string) error
+}
+
+//go:generate counterfeiter -o fakes/metrics_sender.go --fake-name MetricsSender . metricsSender
+type metricsSender interface {
+	IncrementCounter(string)
+}
+
+type Resolver struct {
+	Logger        lager.Logger
+	Store         egressDestinationStore
+	MetricsSender metricsSender
+	LookupIP      func(host string) ([]net.IP, error)
+}
+
+func NewResolver(logger lager.Logger, store egressDestinationStore, metricsSender metricsSender) *Resolver {
+	return &Resolver{
+		Logger:        logger,
+		Store:         store,
+		MetricsSender: metricsSender,
+		LookupIP:      net.LookupIP,
+	}
+}
+
+// ResolveDestinations fills in the IP ranges of any destination that is
+// defined by an FQDN. Destinations without an FQDN are returned unchanged.
+func (r *Resolver) ResolveDestinations(destinations []store.EgressDestination) ([]store.EgressDestination, error) {
+	resolved := make([]store.EgressDestination, len(destinations))
+	for i, destination := range destinations {
+		if destination.FQDN != "" {
+			ipRanges, err := r.resolve(destination.FQDN)
+			if err != nil {
+				return nil, err
+			}
+			destination.IPRanges = ipRanges
+		}
+		resolved[i] = destination
+	}
+	return resolved, nil
+}
+
+// RefreshDestinations re-resolves every FQDN destination and stores the new
+// addresses. When a lookup fails the error is recorded on the destination and
+// its last resolved addresses are kept.
+func (r *Resolver) RefreshDestinations() error {
+	destinations, err := r.Store.GetWithFQDN()
+	if err != nil {
+		r.Logger.Error("store-list-fqdn-destinations-failed", err)
+		return fmt.Errorf("database read failed: %s", err)
+	}
+
+	for _, destination := range destinations {
+		logger := r.Logger.WithData(lager.Data{"destination_guid": destination.GUID, "fqdn": destination.FQDN})
+
+		ipRanges, err := r.resolve(destination.FQDN)
+		if err != nil {
+			logger.Error("resolve-fqdn-failed", err)
+			r.MetricsSender.IncrementCounter("FQDNResolutionFailures")
+
+			err = r.Store.RecordFQDNResolutionError(destination.GUID, err.Error())
+			if err != nil {
+				logger.Error("store-record-resolution-error-failed", err)
+			}
+			continue
+		}
+
+		if destination.FQDNResolutionError == "" && reflect.DeepEqual(destination.IPRanges, ipRanges) {
+			continue
+		}
+
+		err = r.Store.UpdateResolvedIPRanges(destination.GUID, ipRanges)
+		if err != nil {
+			logger.Error("store-update-resolved-ips-failed", err)
+			continue
+		}
+		logger.Info("updated-resolved-ips", lager.Data{"ips": ipRanges})
+	}
+
+	return nil
+}
+
+// resolve looks up the addresses of the given host and returns them as
+// single-address IP ranges in a stable order.
+func (r *Resolver) resolve(fqdn string) ([]store.IPRange, error) {
+	ips, err := r.LookupIP(fqdn)
+	if err != nil {
+		return nil, fmt.Errorf("resolving fqdn '%s': %s", fqdn, err)
+	}
+
+	seen := map[string]struct{}{}
+	var addresses []string
+	for _, ip := range ips {
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		address := ip.String()
+		if _, ok := seen[address]; ok {
+			continue
+		}
+		seen[address] = struct{}{}
+		addresses = append(addresses, address)
+	}
+
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("resolving fqdn '%s': no addresses found", fqdn)
+	}
+
+	sort.Strings(addresses)
+
+	ipRanges := make([]store.IPRange, len(addresses))
+	for i, address := range addresses {
+		ipRanges[i] = store.IPRange{Start: address, End: address}
+	}
+	return ipRanges, nil
+}
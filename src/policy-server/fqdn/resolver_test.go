@@ -0,0 +1,221 @@
+package fqdn_test
+
+import (
+	"errors"
+	"net"
+	"policy-server/fqdn"
+	"policy-server/fqdn/fakes"
+	"policy-server/store"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Resolver", func() {
+	var (
+		resolver          *fqdn.Resolver
+		fakeStore         *fakes.EgressDestinationStore
+		fakeMetricsSender *fakes.MetricsSender
+		logger            *lagertest.TestLogger
+		lookups           map[string][]net.IP
+		lookupErrors      map[string]error
+	)
+
+	BeforeEach(func() {
+		fakeStore = &fakes.EgressDestinationStore{}
+		fakeMetricsSender = &fakes.MetricsSender{}
+		logger = lagertest.NewTestLogger("test")
+
+		lookups = map[string][]net.IP{
+			"api.example.com": {
+				net.ParseIP("192.0.2.20"),
+				net.ParseIP("192.0.2.10"),
+				net.ParseIP("2001:db8::1"),
+				net.ParseIP("192.0.2.10"),
+			},
+		}
+		lookupErrors = map[string]error{}
+
+		resolver = fqdn.NewResolver(logger, fakeStore, fakeMetricsSender)
+		resolver.LookupIP = func(host string) ([]net.IP, error) {
+			if err, ok := lookupErrors[host]; ok {
+				return nil, err
+			}
+			return lookups[host], nil
+		}
+	})
+
+	Describe("ResolveDestinations", func() {
+		It("fills in the ip ranges of fqdn destinations", func() {
+			destinations, err := resolver.ResolveDestinations([]store.EgressDestination{
+				{
+					Name:     "saas",
+					FQDN:     "api.example.com",
+					Protocol: "tcp",
+				},
+				{
+					Name:     "ips",
+					Protocol: "tcp",
+					IPRanges: []store.IPRange{{Start: "10.0.0.1", End: "10.0.0.2"}},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(destinations).To(Equal([]store.EgressDestination{
+				{
+					Name:     "saas",
+					FQDN:     "api.example.com",
+					Protocol: "tcp",
+					IPRanges: []store.IPRange{
+						{Start: "192.0.2.10", End: "192.0.2.10"},
+						{Start: "192.0.2.20", End: "192.0.2.20"},
+						{Start: "2001:db8::1", End: "2001:db8::1"},
+					},
+				},
+				{
+					Name:     "ips",
+					Protocol: "tcp",
+					IPRanges: []store.IPRange{{Start: "10.0.0.1", End: "10.0.0.2"}},
+				},
+			}))
+		})
+
+		Context("when the lookup fails", func() {
+			BeforeEach(func() {
+				lookupErrors["api.example.com"] = errors.New("no such host")
+			})
+
+			It("returns an error", func() {
+				_, err := resolver.ResolveDestinations([]store.EgressDestination{{FQDN: "api.example.com"}})
+				Expect(err).To(MatchError("resolving fqdn 'api.example.com': no such host"))
+			})
+		})
+
+		Context("when the lookup returns no addresses", func() {
+			It("returns an error", func() {
+				_, err := resolver.ResolveDestinations([]store.EgressDestination{{FQDN: "empty.example.com"}})
+				Expect(err).To(MatchError("resolving fqdn 'empty.example.com': no addresses found"))
+			})
+		})
+	})
+
+	Describe("RefreshDestinations", func() {
+		BeforeEach(func() {
+			fakeStore.GetWithFQDNReturns([]store.EgressDestination{
+				{
+					GUID:     "some-guid",
+					FQDN:     "api.example.com",
+					IPRanges: []store.IPRange{{Start: "192.0.2.1", End: "192.0.2.1"}},
+				},
+			}, nil)
+		})
+
+		It("stores the newly resolved addresses", func() {
+			Expect(resolver.RefreshDestinations()).To(Succeed())
+
+			Expect(fakeStore.UpdateResolvedIPRangesCallCount()).To(Equal(1))
+			guid, ipRanges := fakeStore.UpdateResolvedIPRangesArgsForCall(0)
+			Expect(guid).To(Equal("some-guid"))
+			Expect(ipRanges).To(Equal([]store.IPRange{
+				{Start: "192.0.2.10", End: "192.0.2.10"},
+				{Start: "192.0.2.20", End: "192.0.2.20"},
+				{Start: "2001:db8::1", End: "2001:db8::1"},
+			}))
+
+			Expect(logger.Logs()).To(HaveLen(1))
+			Expect(logger.Logs()[0].Message).To(Equal("test.updated-resolved-ips"))
+		})
+
+		Context("when the resolved addresses have not changed", func() {
+			BeforeEach(func() {
+				lookups["api.example.com"] = []net.IP{net.ParseIP("192.0.2.1")}
+			})
+
+			It("does not update the store", func() {
+				Expect(resolver.RefreshDestinations()).To(Succeed())
+				Expect(fakeStore.UpdateResolvedIPRangesCallCount()).To(Equal(0))
+			})
+
+			Context("when the previous resolution failed", func() {
+				BeforeEach(func() {
+					fakeStore.GetWithFQDNReturns([]store.EgressDestination{
+						{
+							GUID:                "some-guid",
+							FQDN:                "api.example.com",
+							FQDNResolutionError: "no such host",
+							IPRanges:            []store.IPRange{{Start: "192.0.2.1", End: "192.0.2.1"}},
+						},
+					}, nil)
+				})
+
+				It("updates the store to clear the error", func() {
+					Expect(resolver.RefreshDestinations()).To(Succeed())
+					Expect(fakeStore.UpdateResolvedIPRangesCallCount()).To(Equal(1))
+				})
+			})
+		})
+
+		Context("when the lookup fails", func() {
+			BeforeEach(func() {
+				lookupErrors["api.example.com"] = errors.New("no such host")
+			})
+
+			It("records the error and keeps the last resolved addresses", func() {
+				Expect(resolver.RefreshDestinations()).To(Succeed())
+
+				Expect(fakeStore.UpdateResolvedIPRangesCallCount()).To(Equal(0))
+				Expect(fakeStore.RecordFQDNResolutionErrorCallCount()).To(Equal(1))
+				guid, resolutionError := fakeStore.RecordFQDNResolutionErrorArgsForCall(0)
+				Expect(guid).To(Equal("some-guid"))
+				Expect(resolutionError).To(Equal("resolving fqdn 'api.example.com': no such host"))
+			})
+
+			It("emits a metric and logs the failure", func() {
+				Expect(resolver.RefreshDestinations()).To(Succeed())
+
+				Expect(fakeMetricsSender.IncrementCounterCallCount()).To(Equal(1))
+				Expect(fakeMetricsSender.IncrementCounterArgsForCall(0)).To(Equal("FQDNResolutionFailures"))
+
+				Expect(logger.Logs()).To(HaveLen(1))
+				Expect(logger.Logs()[0].Message).To(Equal("test.resolve-fqdn-failed"))
+				Expect(logger.Logs()[0].LogLevel).To(Equal(lager.ERROR))
+				Expect(logger.Logs()[0].Data).To(HaveKeyWithValue("destination_guid", "some-guid"))
+			})
+
+			Context("when recording the error fails", func() {
+				BeforeEach(func() {
+					fakeStore.RecordFQDNResolutionErrorReturns(errors.New("banana"))
+				})
+
+				It("logs and carries on", func() {
+					Expect(resolver.RefreshDestinations()).To(Succeed())
+					Expect(logger.Logs()).To(HaveLen(2))
+					Expect(logger.Logs()[1].Message).To(Equal("test.store-record-resolution-error-failed"))
+				})
+			})
+		})
+
+		Context("when updating the store fails", func() {
+			BeforeEach(func() {
+				fakeStore.UpdateResolvedIPRangesReturns(errors.New("banana"))
+			})
+
+			It("logs and carries on", func() {
+				Expect(resolver.RefreshDestinations()).To(Succeed())
+				Expect(logger.Logs()).To(HaveLen(1))
+				Expect(logger.Logs()[0].Message).To(Equal("test.store-update-resolved-ips-failed"))
+			})
+		})
+
+		Context("when listing the destinations fails", func() {
+			BeforeEach(func() {
+				fakeStore.GetWithFQDNReturns(nil, errors.New("banana"))
+			})
+
+			It("returns an error", func() {
+				Expect(resolver.RefreshDestinations()).To(MatchError("database read failed: banana"))
+			})
+		})
+	})
+})
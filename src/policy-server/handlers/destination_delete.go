@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"policy-server/store"
 
@@ -9,6 +10,7 @@ import (
 
 //go:generate counterfeiter -o fakes/egress_destination_store_deleter.go --fake-name EgressDestinationStoreDeleter . EgressDestinationStoreDeleter
 type EgressDestinationStoreDeleter interface {
+	GetByGUID(guid ...string) ([]store.EgressDestination, error)
 	Delete(string) (store.EgressDestination, error)
 }
 
@@ -16,6 +18,7 @@ type DestinationDelete struct {
 	ErrorResponse           errorResponse
 	EgressDestinationStore  EgressDestinationStoreDeleter
 	EgressDestinationMapper EgressDestinationMarshaller
+	EgressGuard             egressGuard
 	Logger                  lager.Logger
 }
 
@@ -23,6 +26,26 @@ func (d *DestinationDelete) ServeHTTP(w http.ResponseWriter, req *http.Request)
 	guid := req.URL.Query().Get(":id")
 	logger := getLogger(req)
 
+	tokenData := getTokenData(req)
+	if !d.EgressGuard.IsNetworkAdmin(tokenData) {
+		existingDestinations, err := d.EgressDestinationStore.GetByGUID(guid)
+		if err != nil {
+			d.ErrorResponse.InternalServerError(logger, w, err, "error getting egress destination")
+			return
+		}
+
+		authorized, err := d.EgressGuard.CanManageDestinations(existingDestinations, tokenData)
+		if err != nil {
+			d.ErrorResponse.InternalServerError(logger, w, err, "error checking destination access")
+			return
+		}
+		if !authorized || len(existingDestinations) == 0 {
+			err := errors.New("not authorized to manage destination")
+			d.ErrorResponse.Forbidden(logger, w, err, "destinations can only be managed by network admins or managers of their org")
+			return
+		}
+	}
+
 	deletedDestination, err := d.EgressDestinationStore.Delete(guid)
 	if err != nil {
 		switch err.(type) {
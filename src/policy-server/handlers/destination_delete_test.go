@@ -18,6 +18,7 @@ import (
 var _ = Describe("DestinationDelete", func() {
 
 	var (
+		fakeEgressGuard      *fakes.EgressGuard
 		expectedResponseBody []byte
 		request              *http.Request
 		handler              *handlers.DestinationDelete
@@ -56,7 +57,11 @@ var _ = Describe("DestinationDelete", func() {
 			MetricsSender: fakeMetricsSender,
 		}
 
+		fakeEgressGuard = &fakes.EgressGuard{}
+		fakeEgressGuard.IsNetworkAdminReturns(true)
+
 		handler = &handlers.DestinationDelete{
+			EgressGuard:             fakeEgressGuard,
 			ErrorResponse:           errorResponse,
 			EgressDestinationStore:  fakeStore,
 			EgressDestinationMapper: fakeMarshaller,
@@ -98,4 +103,53 @@ var _ = Describe("DestinationDelete", func() {
 		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
 		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error serializing egress destination"}`))
 	})
+	Context("when the user is not a network admin", func() {
+		var existingDestinations []store.EgressDestination
+
+		BeforeEach(func() {
+			existingDestinations = []store.EgressDestination{{GUID: "destguid", OrgGUID: "some-org-guid"}}
+			fakeEgressGuard.IsNetworkAdminReturns(false)
+			fakeEgressGuard.CanManageDestinationsReturns(true, nil)
+			fakeStore.GetByGUIDReturns(existingDestinations, nil)
+		})
+
+		It("deletes destinations of the orgs the user manages", func() {
+			MakeRequestWithLogger(handler.ServeHTTP, resp, request, logger)
+
+			Expect(fakeStore.GetByGUIDArgsForCall(0)).To(Equal([]string{"destguid"}))
+			destinations, _ := fakeEgressGuard.CanManageDestinationsArgsForCall(0)
+			Expect(destinations).To(Equal(existingDestinations))
+			Expect(fakeStore.DeleteCallCount()).To(Equal(1))
+			Expect(resp.Code).To(Equal(http.StatusOK))
+		})
+
+		It("returns forbidden when the user may not manage the destination", func() {
+			fakeEgressGuard.CanManageDestinationsReturns(false, nil)
+			MakeRequestWithLogger(handler.ServeHTTP, resp, request, logger)
+			Expect(fakeStore.DeleteCallCount()).To(Equal(0))
+			Expect(resp.Code).To(Equal(http.StatusForbidden))
+			Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "destinations can only be managed by network admins or managers of their org"}`))
+		})
+
+		It("returns forbidden when the destination does not exist", func() {
+			fakeStore.GetByGUIDReturns([]store.EgressDestination{}, nil)
+			MakeRequestWithLogger(handler.ServeHTTP, resp, request, logger)
+			Expect(fakeStore.DeleteCallCount()).To(Equal(0))
+			Expect(resp.Code).To(Equal(http.StatusForbidden))
+		})
+
+		It("returns an error when the destination cannot be fetched", func() {
+			fakeStore.GetByGUIDReturns(nil, errors.New("whoa"))
+			MakeRequestWithLogger(handler.ServeHTTP, resp, request, logger)
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error getting egress destination"}`))
+		})
+
+		It("returns an error when the access check fails", func() {
+			fakeEgressGuard.CanManageDestinationsReturns(false, errors.New("whoa"))
+			MakeRequestWithLogger(handler.ServeHTTP, resp, request, logger)
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error checking destination access"}`))
+		})
+	})
 })
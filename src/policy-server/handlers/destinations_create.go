@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"policy-server/store"
+	"policy-server/uaa_client"
 	"strings"
 
 	"code.cloudfoundry.org/lager"
@@ -14,16 +16,23 @@ type DestinationsCreate struct {
 	ErrorResponse           errorResponse
 	EgressDestinationStore  EgressDestinationStoreCreator
 	EgressDestinationMapper EgressDestinationMarshaller
+	DestinationResolver     DestinationResolver
+	EgressGuard             egressGuard
 	Logger                  lager.Logger
 }
 
 //go:generate counterfeiter -o fakes/egress_destination_store_creator.go --fake-name EgressDestinationStoreCreator . EgressDestinationStoreCreator
 type EgressDestinationStoreCreator interface {
-	Create([]store.EgressDestination) ([]store.EgressDestination, error)
+	CreateWithOverlaps([]store.EgressDestination) ([]store.EgressDestination, []store.DestinationOverlap, error)
+}
+
+//go:generate counterfeiter -o fakes/destination_resolver.go --fake-name DestinationResolver . DestinationResolver
+type DestinationResolver interface {
+	ResolveDestinations([]store.EgressDestination) ([]store.EgressDestination, error)
 }
 
 func (d *DestinationsCreate) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	var destinations, createdDestinations []store.EgressDestination
+	var destinations []store.EgressDestination
 	var requestBytes, responseBytes []byte
 	var err error
 
@@ -39,9 +48,36 @@ func (d *DestinationsCreate) ServeHTTP(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	createdDestinations, err = d.EgressDestinationStore.Create(destinations)
+	tokenData := getTokenData(req)
+	authorized, err := d.EgressGuard.CanManageDestinations(destinations, tokenData)
+	if err != nil {
+		d.ErrorResponse.InternalServerError(d.Logger, w, err, "error checking destination access")
+		return
+	}
+	if !authorized {
+		err := errors.New("not authorized to manage destinations")
+		d.ErrorResponse.Forbidden(d.Logger, w, err, "destinations can only be managed by network admins or managers of their org")
+		return
+	}
+
+	destinations, err = d.DestinationResolver.ResolveDestinations(destinations)
+	if err != nil {
+		d.ErrorResponse.BadRequest(d.Logger, w, err, fmt.Sprintf("error resolving egress destinations: %s", err))
+		return
+	}
+
+	createdDestinations, overlaps, err := d.EgressDestinationStore.CreateWithOverlaps(destinations)
+	if overlapErr, ok := err.(store.OverlappingDestinationError); ok {
+		canSee, err := destinationVisibility(d.EgressGuard, tokenData)
+		if err != nil {
+			d.ErrorResponse.InternalServerError(d.Logger, w, err, "error getting managed orgs")
+			return
+		}
+		d.ErrorResponse.BadRequest(d.Logger, w, overlapErr, fmt.Sprintf("error creating egress destinations: %s", overlapErrorMessage(overlapErr, canSee)))
+		return
+	}
 	if err != nil {
-		if strings.Contains(err.Error(), "duplicate name error") {
+		if strings.Contains(err.Error(), "duplicate name error") || strings.Contains(err.Error(), "overlapping destination error") {
 			d.ErrorResponse.BadRequest(d.Logger, w, err, fmt.Sprintf("error creating egress destinations: %s", err))
 			return
 		}
@@ -49,7 +85,13 @@ func (d *DestinationsCreate) ServeHTTP(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	responseBytes, err = d.EgressDestinationMapper.AsBytes(createdDestinations)
+	warnings, err := overlapWarnings(overlaps, d.EgressGuard, tokenData)
+	if err != nil {
+		d.ErrorResponse.InternalServerError(d.Logger, w, err, "error getting managed orgs")
+		return
+	}
+
+	responseBytes, err = d.EgressDestinationMapper.AsBytesWithWarnings(createdDestinations, warnings)
 	if err != nil {
 		d.ErrorResponse.InternalServerError(d.Logger, w, err, "error serializing egress destinations")
 		return
@@ -58,3 +100,47 @@ func (d *DestinationsCreate) ServeHTTP(w http.ResponseWriter, req *http.Request)
 	w.WriteHeader(http.StatusCreated)
 	w.Write(responseBytes)
 }
+
+// destinationVisibility reports which destinations the user may see. Network
+// admins see all of them, other users the ones listed to them by the index.
+func destinationVisibility(guard egressGuard, tokenData uaa_client.CheckTokenResponse) (func(store.EgressDestination) bool, error) {
+	if guard.IsNetworkAdmin(tokenData) {
+		return func(store.EgressDestination) bool { return true }, nil
+	}
+	managedOrgs, err := guard.ManagedOrgGUIDs(tokenData)
+	if err != nil {
+		return nil, err
+	}
+	return func(destination store.EgressDestination) bool {
+		return len(visibleDestinations([]store.EgressDestination{destination}, managedOrgs)) == 1
+	}, nil
+}
+
+// overlapWarnings describes the overlaps, naming the overlapping destination
+// only when the user may see it.
+func overlapWarnings(overlaps []store.DestinationOverlap, guard egressGuard, tokenData uaa_client.CheckTokenResponse) ([]string, error) {
+	if len(overlaps) == 0 {
+		return nil, nil
+	}
+	canSee, err := destinationVisibility(guard, tokenData)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	for _, overlap := range overlaps {
+		if canSee(overlap.OverlappingDestination) {
+			warnings = append(warnings, overlap.Description())
+		} else {
+			warnings = append(warnings, overlap.AnonymousDescription())
+		}
+	}
+	return warnings, nil
+}
+
+func overlapErrorMessage(err store.OverlappingDestinationError, canSee func(store.EgressDestination) bool) string {
+	if canSee(err.Overlap.OverlappingDestination) {
+		return err.Error()
+	}
+	return err.AnonymousError()
+}
@@ -32,6 +32,7 @@ func (f *failingReader) Close() (err error) {
 
 var _ = Describe("Destinations create handler", func() {
 	var (
+		fakeEgressGuard       *fakes.EgressGuard
 		expectedResponseBody  []byte
 		request               *http.Request
 		handler               *handlers.DestinationsCreate
@@ -39,6 +40,7 @@ var _ = Describe("Destinations create handler", func() {
 		fakeMetricsSender     *storeFakes.MetricsSender
 		fakeStore             *fakes.EgressDestinationStoreCreator
 		fakeMarshaller        *fakes.EgressDestinationMarshaller
+		fakeResolver          *fakes.DestinationResolver
 		logger                *lagertest.TestLogger
 		createdDestinations   []store.EgressDestination
 		requestedDestinations []store.EgressDestination
@@ -75,10 +77,10 @@ var _ = Describe("Destinations create handler", func() {
 		}
 
 		fakeStore = &fakes.EgressDestinationStoreCreator{}
-		fakeStore.CreateReturns(createdDestinations, nil)
+		fakeStore.CreateWithOverlapsReturns(createdDestinations, nil, nil)
 
 		fakeMarshaller = &fakes.EgressDestinationMarshaller{}
-		fakeMarshaller.AsBytesReturns(expectedResponseBody, nil)
+		fakeMarshaller.AsBytesWithWarningsReturns(expectedResponseBody, nil)
 
 		requestedDestinations = []store.EgressDestination{
 			{GUID: "req-one"},
@@ -86,6 +88,11 @@ var _ = Describe("Destinations create handler", func() {
 		}
 		fakeMarshaller.AsEgressDestinationsReturns(requestedDestinations, nil)
 
+		fakeResolver = &fakes.DestinationResolver{}
+		fakeResolver.ResolveDestinationsStub = func(destinations []store.EgressDestination) ([]store.EgressDestination, error) {
+			return destinations, nil
+		}
+
 		logger = lagertest.NewTestLogger("test")
 
 		fakeMetricsSender = &storeFakes.MetricsSender{}
@@ -94,30 +101,61 @@ var _ = Describe("Destinations create handler", func() {
 			MetricsSender: fakeMetricsSender,
 		}
 
+		fakeEgressGuard = &fakes.EgressGuard{}
+		fakeEgressGuard.IsNetworkAdminReturns(true)
+		fakeEgressGuard.CanManageDestinationsReturns(true, nil)
+
 		handler = &handlers.DestinationsCreate{
+			EgressGuard:             fakeEgressGuard,
 			ErrorResponse:           errorResponse,
 			EgressDestinationStore:  fakeStore,
 			EgressDestinationMapper: fakeMarshaller,
+			DestinationResolver:     fakeResolver,
 			Logger:                  logger,
 		}
 		resp = httptest.NewRecorder()
 
 		token = uaa_client.CheckTokenResponse{
-			Scope:    []string{"some-scope", "network.admin"},
+			Scope: []string{"some-scope", "network.admin"},
 		}
 	})
 
 	It("creates destinations", func() {
 		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
 
-		Expect(fakeStore.CreateCallCount()).To(Equal(1))
-		Expect(fakeStore.CreateArgsForCall(0)).To(Equal(requestedDestinations))
-		Expect(fakeMarshaller.AsBytesCallCount()).To(Equal(1))
-		Expect(fakeMarshaller.AsBytesArgsForCall(0)).To(Equal(createdDestinations))
+		Expect(fakeStore.CreateWithOverlapsCallCount()).To(Equal(1))
+		Expect(fakeStore.CreateWithOverlapsArgsForCall(0)).To(Equal(requestedDestinations))
+		Expect(fakeMarshaller.AsBytesWithWarningsCallCount()).To(Equal(1))
+		destinations, warnings := fakeMarshaller.AsBytesWithWarningsArgsForCall(0)
+		Expect(destinations).To(Equal(createdDestinations))
+		Expect(warnings).To(BeEmpty())
 		Expect(resp.Code).To(Equal(http.StatusCreated))
 		Expect(resp.Body.Bytes()).To(Equal(expectedResponseBody))
 	})
 
+	It("creates destinations with the resolved addresses of any fqdns", func() {
+		resolvedDestinations := []store.EgressDestination{
+			{GUID: "req-one", FQDN: "api.example.com", IPRanges: []store.IPRange{{Start: "192.0.2.10", End: "192.0.2.10"}}},
+			{GUID: "req-two"},
+		}
+		fakeResolver.ResolveDestinationsReturns(resolvedDestinations, nil)
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+		Expect(fakeResolver.ResolveDestinationsCallCount()).To(Equal(1))
+		Expect(fakeResolver.ResolveDestinationsArgsForCall(0)).To(Equal(requestedDestinations))
+		Expect(fakeStore.CreateWithOverlapsArgsForCall(0)).To(Equal(resolvedDestinations))
+		Expect(resp.Code).To(Equal(http.StatusCreated))
+	})
+
+	It("returns a bad request when an fqdn cannot be resolved", func() {
+		fakeResolver.ResolveDestinationsReturns(nil, errors.New("resolving fqdn 'api.example.com': no such host"))
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+		Expect(fakeStore.CreateWithOverlapsCallCount()).To(Equal(0))
+		Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error resolving egress destinations: resolving fqdn 'api.example.com': no such host"}`))
+	})
+
 	It("returns an error request body can't be read", func() {
 		request.Body = &failingReader{}
 		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
@@ -127,19 +165,104 @@ var _ = Describe("Destinations create handler", func() {
 	})
 
 	It("returns an error when the store returns an error", func() {
-		fakeStore.CreateReturns(nil, errors.New("can't create"))
+		fakeStore.CreateWithOverlapsReturns(nil, nil, errors.New("can't create"))
 		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
 		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
 		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error creating egress destinations"}`))
 	})
 
 	It("returns an duplicate name error when the store returns duplicate entry error", func() {
-		fakeStore.CreateReturns(nil, errors.New("egress destination store create destination metadata: duplicate name error: entry with name 'dupe' already exists"))
+		fakeStore.CreateWithOverlapsReturns(nil, nil, errors.New("egress destination store create destination metadata: duplicate name error: entry with name 'dupe' already exists"))
 		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
 		Expect(resp.Code).To(Equal(http.StatusBadRequest))
 		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error creating egress destinations: egress destination store create destination metadata: duplicate name error: entry with name 'dupe' already exists"}`))
 	})
 
+	It("returns the overlaps with other destinations as warnings", func() {
+		fakeStore.CreateWithOverlapsReturns(createdDestinations, []store.DestinationOverlap{
+			{
+				Destination:            store.EgressDestination{Name: "my service"},
+				OverlappingDestination: store.EgressDestination{Name: "cloud infra"},
+				Relation:               store.OverlapRelationEqual,
+			},
+		}, nil)
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+		Expect(resp.Code).To(Equal(http.StatusCreated))
+		_, warnings := fakeMarshaller.AsBytesWithWarningsArgsForCall(0)
+		Expect(warnings).To(Equal([]string{"destination 'my service' covers the same traffic as destination 'cloud infra'"}))
+	})
+
+	It("returns a bad request when the store rejects an overlapping destination", func() {
+		fakeStore.CreateWithOverlapsReturns(nil, nil, errors.New("egress destination store create: overlapping destination error: destination 'a' overlaps destination 'b'"))
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error creating egress destinations: egress destination store create: overlapping destination error: destination 'a' overlaps destination 'b'"}`))
+	})
+
+	It("names the overlapping destination when the store rejects the overlap", func() {
+		fakeStore.CreateWithOverlapsReturns(nil, nil, store.NewOverlappingDestinationError("create", store.DestinationOverlap{
+			Destination:            store.EgressDestination{Name: "a"},
+			OverlappingDestination: store.EgressDestination{Name: "b", OrgGUID: "other-org-guid"},
+			Relation:               store.OverlapRelationContainedBy,
+		}))
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error creating egress destinations: egress destination store create: overlapping destination error: destination 'a' is contained by destination 'b'"}`))
+		Expect(fakeEgressGuard.ManagedOrgGUIDsCallCount()).To(Equal(0))
+	})
+
+	Context("when the user is not a network admin", func() {
+		var theirs store.DestinationOverlap
+
+		BeforeEach(func() {
+			fakeEgressGuard.IsNetworkAdminReturns(false)
+			fakeEgressGuard.ManagedOrgGUIDsReturns(map[string]struct{}{"some-org-guid": {}}, nil)
+			theirs = store.DestinationOverlap{
+				Destination:            store.EgressDestination{Name: "my service"},
+				OverlappingDestination: store.EgressDestination{Name: "cloud infra", OrgGUID: "other-org-guid"},
+				Relation:               store.OverlapRelationContainedBy,
+			}
+		})
+
+		It("only names the overlapping destinations the user can see", func() {
+			fakeStore.CreateWithOverlapsReturns(createdDestinations, []store.DestinationOverlap{
+				{
+					Destination:            store.EgressDestination{Name: "my service"},
+					OverlappingDestination: store.EgressDestination{Name: "my other service", OrgGUID: "some-org-guid"},
+					Relation:               store.OverlapRelationEqual,
+				},
+				theirs,
+			}, nil)
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+			Expect(resp.Code).To(Equal(http.StatusCreated))
+			Expect(fakeEgressGuard.ManagedOrgGUIDsArgsForCall(0)).To(Equal(token))
+			_, warnings := fakeMarshaller.AsBytesWithWarningsArgsForCall(0)
+			Expect(warnings).To(Equal([]string{
+				"destination 'my service' covers the same traffic as destination 'my other service'",
+				"destination 'my service' is contained by another destination",
+			}))
+		})
+
+		It("does not name a destination the user cannot see when the store rejects the overlap", func() {
+			fakeStore.CreateWithOverlapsReturns(nil, nil, store.NewOverlappingDestinationError("create", theirs))
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+			Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error creating egress destinations: egress destination store create: overlapping destination error: destination 'my service' is contained by another destination"}`))
+		})
+
+		It("returns an error when the managed orgs cannot be fetched", func() {
+			fakeStore.CreateWithOverlapsReturns(createdDestinations, []store.DestinationOverlap{theirs}, nil)
+			fakeEgressGuard.ManagedOrgGUIDsReturns(nil, errors.New("whoa"))
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error getting managed orgs"}`))
+		})
+	})
+
 	It("returns an error when the mapper returns an error", func() {
 		fakeMarshaller.AsEgressDestinationsReturns(nil, errors.New("whoa"))
 		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
@@ -148,9 +271,30 @@ var _ = Describe("Destinations create handler", func() {
 	})
 
 	It("returns an error when the marshalling created destinations", func() {
-		fakeMarshaller.AsBytesReturns(nil, errors.New("can't serialize"))
+		fakeMarshaller.AsBytesWithWarningsReturns(nil, errors.New("can't serialize"))
 		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
 		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
 		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error serializing egress destinations"}`))
 	})
+	It("checks that the user may manage the requested destinations", func() {
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		destinations, tokenData := fakeEgressGuard.CanManageDestinationsArgsForCall(0)
+		Expect(destinations).To(Equal(requestedDestinations))
+		Expect(tokenData).To(Equal(token))
+	})
+
+	It("returns forbidden when the user may not manage the destinations", func() {
+		fakeEgressGuard.CanManageDestinationsReturns(false, nil)
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(fakeStore.CreateWithOverlapsCallCount()).To(Equal(0))
+		Expect(resp.Code).To(Equal(http.StatusForbidden))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "destinations can only be managed by network admins or managers of their org"}`))
+	})
+
+	It("returns an error when the access check fails", func() {
+		fakeEgressGuard.CanManageDestinationsReturns(false, errors.New("whoa"))
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error checking destination access"}`))
+	})
 })
@@ -14,12 +14,14 @@ type DestinationsIndex struct {
 	ErrorResponse           errorResponse
 	EgressDestinationStore  EgressDestinationStoreLister
 	EgressDestinationMapper EgressDestinationMarshaller
+	EgressGuard             egressGuard
 	Logger                  lager.Logger
 }
 
 //go:generate counterfeiter -o fakes/egress_destination_marshaller.go --fake-name EgressDestinationMarshaller . EgressDestinationMarshaller
 type EgressDestinationMarshaller interface {
 	AsBytes(egressDestinations []store.EgressDestination) ([]byte, error)
+	AsBytesWithWarnings(egressDestinations []store.EgressDestination, warnings []string) ([]byte, error)
 	AsEgressDestinations([]byte) ([]store.EgressDestination, error)
 }
 
@@ -50,6 +52,16 @@ func (d *DestinationsIndex) ServeHTTP(w http.ResponseWriter, req *http.Request)
 		d.ErrorResponse.InternalServerError(d.Logger, w, err, "error getting egress destinations")
 		return
 	}
+
+	tokenData := getTokenData(req)
+	if !d.EgressGuard.IsNetworkAdmin(tokenData) {
+		managedOrgs, err := d.EgressGuard.ManagedOrgGUIDs(tokenData)
+		if err != nil {
+			d.ErrorResponse.InternalServerError(d.Logger, w, err, "error getting managed orgs")
+			return
+		}
+		egressDestinations = visibleDestinations(egressDestinations, managedOrgs)
+	}
 	responseBytes, err := d.EgressDestinationMapper.AsBytes(egressDestinations)
 	if err != nil {
 		d.ErrorResponse.InternalServerError(d.Logger, w, err, "error mapping egress destinations")
@@ -59,6 +71,18 @@ func (d *DestinationsIndex) ServeHTTP(w http.ResponseWriter, req *http.Request)
 	w.Write(responseBytes)
 }
 
+// visibleDestinations keeps the destinations of the given orgs and the self
+// service destinations, which every user may attach to their apps and spaces.
+func visibleDestinations(destinations []store.EgressDestination, orgGUIDs map[string]struct{}) []store.EgressDestination {
+	filtered := []store.EgressDestination{}
+	for _, destination := range destinations {
+		if _, ok := orgGUIDs[destination.OrgGUID]; (ok && destination.OrgGUID != "") || destination.SelfService {
+			filtered = append(filtered, destination)
+		}
+	}
+	return filtered
+}
+
 func parseQueryParam(queryValues url.Values, queryParam string) []string {
 	var values []string
 	v, ok := queryValues[queryParam]
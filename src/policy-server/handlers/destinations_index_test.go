@@ -21,6 +21,7 @@ import (
 
 var _ = Describe("Destinations index handler", func() {
 	var (
+		fakeEgressGuard      *fakes.EgressGuard
 		allDestinations      []store.EgressDestination
 		expectedResponseBody []byte
 		request              *http.Request
@@ -54,7 +55,11 @@ var _ = Describe("Destinations index handler", func() {
 			MetricsSender: fakeMetricsSender,
 		}
 
+		fakeEgressGuard = &fakes.EgressGuard{}
+		fakeEgressGuard.IsNetworkAdminReturns(true)
+
 		handler = &handlers.DestinationsIndex{
+			EgressGuard:             fakeEgressGuard,
 			ErrorResponse:           errorResponse,
 			EgressDestinationMapper: fakeMapper,
 			EgressDestinationStore:  fakeStore,
@@ -62,7 +67,7 @@ var _ = Describe("Destinations index handler", func() {
 		}
 
 		token = uaa_client.CheckTokenResponse{
-			Scope:    []string{"some-scope", "some-other-scope"},
+			Scope: []string{"some-scope", "some-other-scope"},
 		}
 		resp = httptest.NewRecorder()
 	})
@@ -129,4 +134,35 @@ var _ = Describe("Destinations index handler", func() {
 			Expect(fakeStore.GetByNameArgsForCall(0)).To(Equal([]string{"some-name", "some-name-2"}))
 		})
 	})
+
+	Context("when the user is not a network admin", func() {
+		BeforeEach(func() {
+			fakeEgressGuard.IsNetworkAdminReturns(false)
+			fakeEgressGuard.ManagedOrgGUIDsReturns(map[string]struct{}{"some-org-guid": {}}, nil)
+			fakeStore.AllReturns([]store.EgressDestination{
+				{GUID: "global-guid"},
+				{GUID: "org-guid", OrgGUID: "some-org-guid"},
+				{GUID: "other-org-guid", OrgGUID: "some-other-org-guid"},
+				{GUID: "self-service-guid", SelfService: true},
+			}, nil)
+		})
+
+		It("returns only the destinations of the orgs the user manages and self service destinations", func() {
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(fakeEgressGuard.ManagedOrgGUIDsArgsForCall(0)).To(Equal(token))
+			Expect(fakeMapper.AsBytesArgsForCall(0)).To(Equal([]store.EgressDestination{
+				{GUID: "org-guid", OrgGUID: "some-org-guid"},
+				{GUID: "self-service-guid", SelfService: true},
+			}))
+		})
+
+		It("returns an error when the managed orgs cannot be fetched", func() {
+			fakeEgressGuard.ManagedOrgGUIDsReturns(nil, errors.New("things went askew"))
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error getting managed orgs"}`))
+		})
+	})
 })
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+	"policy-server/store"
+
+	"code.cloudfoundry.org/lager"
+)
+
+type DestinationsOverlaps struct {
+	ErrorResponse           errorResponse
+	EgressDestinationStore  EgressDestinationOverlapsLister
+	EgressDestinationMapper EgressDestinationOverlapsMarshaller
+	Logger                  lager.Logger
+}
+
+//go:generate counterfeiter -o fakes/egress_destination_overlaps_lister.go --fake-name EgressDestinationOverlapsLister . EgressDestinationOverlapsLister
+type EgressDestinationOverlapsLister interface {
+	OverlappingGroups() ([][]store.EgressDestination, error)
+}
+
+//go:generate counterfeiter -o fakes/egress_destination_overlaps_marshaller.go --fake-name EgressDestinationOverlapsMarshaller . EgressDestinationOverlapsMarshaller
+type EgressDestinationOverlapsMarshaller interface {
+	AsOverlapGroupsBytes(groups [][]store.EgressDestination) ([]byte, error)
+}
+
+func (d *DestinationsOverlaps) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	groups, err := d.EgressDestinationStore.OverlappingGroups()
+	if err != nil {
+		d.ErrorResponse.InternalServerError(d.Logger, w, err, "error getting overlapping egress destinations")
+		return
+	}
+
+	responseBytes, err := d.EgressDestinationMapper.AsOverlapGroupsBytes(groups)
+	if err != nil {
+		d.ErrorResponse.InternalServerError(d.Logger, w, err, "error mapping overlapping egress destinations")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseBytes)
+}
@@ -0,0 +1,88 @@
+package handlers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"policy-server/handlers"
+	"policy-server/handlers/fakes"
+	"policy-server/store"
+	storeFakes "policy-server/store/fakes"
+	"policy-server/uaa_client"
+
+	"code.cloudfoundry.org/cf-networking-helpers/httperror"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Destinations overlaps handler", func() {
+	var (
+		overlappingGroups    [][]store.EgressDestination
+		expectedResponseBody []byte
+		request              *http.Request
+		handler              *handlers.DestinationsOverlaps
+		resp                 *httptest.ResponseRecorder
+		fakeStore            *fakes.EgressDestinationOverlapsLister
+		fakeMapper           *fakes.EgressDestinationOverlapsMarshaller
+		logger               *lagertest.TestLogger
+		token                uaa_client.CheckTokenResponse
+	)
+
+	BeforeEach(func() {
+		expectedResponseBody = []byte("some-response")
+
+		var err error
+		request, err = http.NewRequest("GET", "/networking/v1/external/destinations/overlaps", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		overlappingGroups = [][]store.EgressDestination{
+			{{GUID: "guid-1"}, {GUID: "guid-2"}},
+		}
+
+		fakeStore = &fakes.EgressDestinationOverlapsLister{}
+		fakeStore.OverlappingGroupsReturns(overlappingGroups, nil)
+
+		fakeMapper = &fakes.EgressDestinationOverlapsMarshaller{}
+		fakeMapper.AsOverlapGroupsBytesReturns(expectedResponseBody, nil)
+
+		logger = lagertest.NewTestLogger("test")
+
+		handler = &handlers.DestinationsOverlaps{
+			ErrorResponse: &httperror.ErrorResponse{
+				MetricsSender: &storeFakes.MetricsSender{},
+			},
+			EgressDestinationStore:  fakeStore,
+			EgressDestinationMapper: fakeMapper,
+			Logger:                  logger,
+		}
+
+		token = uaa_client.CheckTokenResponse{
+			Scope: []string{"network.admin"},
+		}
+		resp = httptest.NewRecorder()
+	})
+
+	It("returns the groups of overlapping destinations", func() {
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+		Expect(fakeStore.OverlappingGroupsCallCount()).To(Equal(1))
+		Expect(fakeMapper.AsOverlapGroupsBytesArgsForCall(0)).To(Equal(overlappingGroups))
+		Expect(resp.Code).To(Equal(http.StatusOK))
+		Expect(resp.Body.Bytes()).To(Equal(expectedResponseBody))
+	})
+
+	It("returns an error when the store returns an error", func() {
+		fakeStore.OverlappingGroupsReturns(nil, errors.New("things went askew"))
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error getting overlapping egress destinations"}`))
+	})
+
+	It("returns an error when the mapper returns an error", func() {
+		fakeMapper.AsOverlapGroupsBytesReturns(nil, errors.New("things went askew"))
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error mapping overlapping egress destinations"}`))
+	})
+})
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -14,16 +15,19 @@ type DestinationsUpdate struct {
 	ErrorResponse           errorResponse
 	EgressDestinationStore  EgressDestinationStoreUpdater
 	EgressDestinationMapper EgressDestinationMarshaller
+	DestinationResolver     DestinationResolver
+	EgressGuard             egressGuard
 	Logger                  lager.Logger
 }
 
 //go:generate counterfeiter -o fakes/egress_destination_store_updater.go --fake-name EgressDestinationStoreUpdater . EgressDestinationStoreUpdater
 type EgressDestinationStoreUpdater interface {
-	Update([]store.EgressDestination) ([]store.EgressDestination, error)
+	GetByGUID(guid ...string) ([]store.EgressDestination, error)
+	UpdateWithOverlaps([]store.EgressDestination) ([]store.EgressDestination, []store.DestinationOverlap, error)
 }
 
 func (d *DestinationsUpdate) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	var destinations, updatedDestinations []store.EgressDestination
+	var destinations []store.EgressDestination
 	var requestBytes, responseBytes []byte
 	var err error
 
@@ -53,9 +57,50 @@ func (d *DestinationsUpdate) ServeHTTP(w http.ResponseWriter, req *http.Request)
 		seenGUIDs[destination.GUID] = struct{}{}
 	}
 
-	updatedDestinations, err = d.EgressDestinationStore.Update(destinations)
+	tokenData := getTokenData(req)
+	destinationsToAuthorize := destinations
+	if !d.EgressGuard.IsNetworkAdmin(tokenData) {
+		guids := make([]string, len(destinations))
+		for i, destination := range destinations {
+			guids[i] = destination.GUID
+		}
+		existingDestinations, err := d.EgressDestinationStore.GetByGUID(guids...)
+		if err != nil {
+			d.ErrorResponse.InternalServerError(d.Logger, w, err, "error getting egress destinations")
+			return
+		}
+		destinationsToAuthorize = append(existingDestinations, destinations...)
+	}
+
+	authorized, err := d.EgressGuard.CanManageDestinations(destinationsToAuthorize, tokenData)
+	if err != nil {
+		d.ErrorResponse.InternalServerError(d.Logger, w, err, "error checking destination access")
+		return
+	}
+	if !authorized {
+		err := errors.New("not authorized to manage destinations")
+		d.ErrorResponse.Forbidden(d.Logger, w, err, "destinations can only be managed by network admins or managers of their org")
+		return
+	}
+
+	destinations, err = d.DestinationResolver.ResolveDestinations(destinations)
 	if err != nil {
-		if strings.Contains(err.Error(), "duplicate name error") {
+		d.ErrorResponse.BadRequest(d.Logger, w, err, fmt.Sprintf("error resolving egress destination: %s", err))
+		return
+	}
+
+	updatedDestinations, overlaps, err := d.EgressDestinationStore.UpdateWithOverlaps(destinations)
+	if overlapErr, ok := err.(store.OverlappingDestinationError); ok {
+		canSee, err := destinationVisibility(d.EgressGuard, tokenData)
+		if err != nil {
+			d.ErrorResponse.InternalServerError(d.Logger, w, err, "error getting managed orgs")
+			return
+		}
+		d.ErrorResponse.BadRequest(d.Logger, w, overlapErr, fmt.Sprintf("error updating egress destination: %s", overlapErrorMessage(overlapErr, canSee)))
+		return
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate name error") || strings.Contains(err.Error(), "overlapping destination error") {
 			d.ErrorResponse.BadRequest(d.Logger, w, err, fmt.Sprintf("error updating egress destination: %s", err))
 			return
 		}
@@ -66,7 +111,13 @@ func (d *DestinationsUpdate) ServeHTTP(w http.ResponseWriter, req *http.Request)
 		d.ErrorResponse.InternalServerError(d.Logger, w, err, "error updating egress destination")
 		return
 	}
-	responseBytes, err = d.EgressDestinationMapper.AsBytes(updatedDestinations)
+	warnings, err := overlapWarnings(overlaps, d.EgressGuard, tokenData)
+	if err != nil {
+		d.ErrorResponse.InternalServerError(d.Logger, w, err, "error getting managed orgs")
+		return
+	}
+
+	responseBytes, err = d.EgressDestinationMapper.AsBytesWithWarnings(updatedDestinations, warnings)
 	if err != nil {
 		d.ErrorResponse.InternalServerError(d.Logger, w, err, "error serializing egress destinations")
 		return
@@ -21,6 +21,7 @@ import (
 
 var _ = Describe("Destinations update handler", func() {
 	var (
+		fakeEgressGuard       *fakes.EgressGuard
 		expectedResponseBody  []byte
 		request               *http.Request
 		handler               *handlers.DestinationsUpdate
@@ -28,6 +29,7 @@ var _ = Describe("Destinations update handler", func() {
 		fakeMetricsSender     *storeFakes.MetricsSender
 		fakeStore             *fakes.EgressDestinationStoreUpdater
 		fakeMarshaller        *fakes.EgressDestinationMarshaller
+		fakeResolver          *fakes.DestinationResolver
 		logger                *lagertest.TestLogger
 		updatedDestinations   []store.EgressDestination
 		requestedDestinations []store.EgressDestination
@@ -67,7 +69,7 @@ var _ = Describe("Destinations update handler", func() {
 		updatedDestinations = []store.EgressDestination{updatedDestination1, updatedDestination2}
 
 		fakeStore = &fakes.EgressDestinationStoreUpdater{}
-		fakeStore.UpdateReturns(updatedDestinations, nil)
+		fakeStore.UpdateWithOverlapsReturns(updatedDestinations, nil, nil)
 
 		fakeMarshaller = &fakes.EgressDestinationMarshaller{}
 
@@ -77,7 +79,12 @@ var _ = Describe("Destinations update handler", func() {
 		}
 		fakeMarshaller.AsEgressDestinationsReturns(requestedDestinations, nil)
 
-		fakeMarshaller.AsBytesReturns(expectedResponseBody, nil)
+		fakeMarshaller.AsBytesWithWarningsReturns(expectedResponseBody, nil)
+
+		fakeResolver = &fakes.DestinationResolver{}
+		fakeResolver.ResolveDestinationsStub = func(destinations []store.EgressDestination) ([]store.EgressDestination, error) {
+			return destinations, nil
+		}
 
 		logger = lagertest.NewTestLogger("test")
 
@@ -87,10 +94,16 @@ var _ = Describe("Destinations update handler", func() {
 			MetricsSender: fakeMetricsSender,
 		}
 
+		fakeEgressGuard = &fakes.EgressGuard{}
+		fakeEgressGuard.IsNetworkAdminReturns(true)
+		fakeEgressGuard.CanManageDestinationsReturns(true, nil)
+
 		handler = &handlers.DestinationsUpdate{
+			EgressGuard:             fakeEgressGuard,
 			ErrorResponse:           errorResponse,
 			EgressDestinationStore:  fakeStore,
 			EgressDestinationMapper: fakeMarshaller,
+			DestinationResolver:     fakeResolver,
 			Logger:                  logger,
 		}
 		resp = httptest.NewRecorder()
@@ -105,14 +118,27 @@ var _ = Describe("Destinations update handler", func() {
 	It("updates destinations", func() {
 		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
 
-		Expect(fakeStore.UpdateCallCount()).To(Equal(1))
-		Expect(fakeStore.UpdateArgsForCall(0)).To(Equal(requestedDestinations))
-		Expect(fakeMarshaller.AsBytesCallCount()).To(Equal(1))
-		Expect(fakeMarshaller.AsBytesArgsForCall(0)).To(Equal(updatedDestinations))
+		Expect(fakeStore.UpdateWithOverlapsCallCount()).To(Equal(1))
+		Expect(fakeStore.UpdateWithOverlapsArgsForCall(0)).To(Equal(requestedDestinations))
+		Expect(fakeMarshaller.AsBytesWithWarningsCallCount()).To(Equal(1))
+		destinations, warnings := fakeMarshaller.AsBytesWithWarningsArgsForCall(0)
+		Expect(destinations).To(Equal(updatedDestinations))
+		Expect(warnings).To(BeEmpty())
 		Expect(resp.Code).To(Equal(http.StatusOK))
 		Expect(resp.Body.Bytes()).To(Equal(expectedResponseBody))
 	})
 
+	It("returns a bad request when an fqdn cannot be resolved", func() {
+		fakeResolver.ResolveDestinationsReturns(nil, errors.New("resolving fqdn 'api.example.com': no such host"))
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+		Expect(fakeResolver.ResolveDestinationsCallCount()).To(Equal(1))
+		Expect(fakeResolver.ResolveDestinationsArgsForCall(0)).To(Equal(requestedDestinations))
+		Expect(fakeStore.UpdateWithOverlapsCallCount()).To(Equal(0))
+		Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error resolving egress destination: resolving fqdn 'api.example.com': no such host"}`))
+	})
+
 	It("returns an error when the request body can't be read", func() {
 		request.Body = &failingReader{}
 		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
@@ -129,21 +155,43 @@ var _ = Describe("Destinations update handler", func() {
 	})
 
 	It("returns an error when the store returns an error", func() {
-		fakeStore.UpdateReturns([]store.EgressDestination{}, errors.New("oh noes"))
+		fakeStore.UpdateWithOverlapsReturns([]store.EgressDestination{}, nil, errors.New("oh noes"))
 		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
 		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
 		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error updating egress destination"}`))
 	})
 
 	It("returns an duplicate name error when the store returns duplicate entry error", func() {
-		fakeStore.UpdateReturns([]store.EgressDestination{}, errors.New("blah blah: duplicate name error: blah"))
+		fakeStore.UpdateWithOverlapsReturns([]store.EgressDestination{}, nil, errors.New("blah blah: duplicate name error: blah"))
 		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
 		Expect(resp.Code).To(Equal(http.StatusBadRequest))
 		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error updating egress destination: blah blah: duplicate name error: blah"}`))
 	})
 
+	It("returns the overlaps with other destinations as warnings", func() {
+		fakeStore.UpdateWithOverlapsReturns(updatedDestinations, []store.DestinationOverlap{
+			{
+				Destination:            store.EgressDestination{Name: "a"},
+				OverlappingDestination: store.EgressDestination{Name: "b"},
+				Relation:               store.OverlapRelationContains,
+			},
+		}, nil)
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+		Expect(resp.Code).To(Equal(http.StatusOK))
+		_, warnings := fakeMarshaller.AsBytesWithWarningsArgsForCall(0)
+		Expect(warnings).To(Equal([]string{"destination 'a' contains destination 'b'"}))
+	})
+
+	It("returns a bad request when the store rejects an overlapping destination", func() {
+		fakeStore.UpdateWithOverlapsReturns(nil, nil, errors.New("blah blah: overlapping destination error: blah"))
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error updating egress destination: blah blah: overlapping destination error: blah"}`))
+	})
+
 	It("returns an error when marshalling the updated destination fails", func() {
-		fakeMarshaller.AsBytesReturns(nil, errors.New("can't serialize"))
+		fakeMarshaller.AsBytesWithWarningsReturns(nil, errors.New("can't serialize"))
 		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
 		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
 		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error serializing egress destinations"}`))
@@ -174,10 +222,110 @@ var _ = Describe("Destinations update handler", func() {
 	})
 
 	It("returns an error when requested update destination is not in the database", func() {
-		fakeStore.UpdateReturns([]store.EgressDestination{}, errors.New("blah blah: destination GUID not found: blah blah"))
+		fakeStore.UpdateWithOverlapsReturns([]store.EgressDestination{}, nil, errors.New("blah blah: destination GUID not found: blah blah"))
 
 		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
 		Expect(resp.Code).To(Equal(http.StatusNotFound))
 		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error updating egress destination: blah blah: destination GUID not found: blah blah"}`))
 	})
+	Context("when the user is not a network admin", func() {
+		var existingDestinations []store.EgressDestination
+
+		BeforeEach(func() {
+			existingDestinations = []store.EgressDestination{
+				{GUID: "req-one", OrgGUID: "some-org-guid"},
+				{GUID: "req-two", OrgGUID: "some-org-guid"},
+			}
+			fakeEgressGuard.IsNetworkAdminReturns(false)
+			fakeStore.GetByGUIDReturns(existingDestinations, nil)
+		})
+
+		It("checks access to both the existing and the updated destinations", func() {
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(fakeStore.GetByGUIDArgsForCall(0)).To(Equal([]string{"req-one", "req-two"}))
+			destinations, tokenData := fakeEgressGuard.CanManageDestinationsArgsForCall(0)
+			Expect(destinations).To(Equal(append(existingDestinations, requestedDestinations...)))
+			Expect(tokenData).To(Equal(token))
+		})
+
+		It("returns forbidden when the user may not manage the destinations", func() {
+			fakeEgressGuard.CanManageDestinationsReturns(false, nil)
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+			Expect(fakeStore.UpdateWithOverlapsCallCount()).To(Equal(0))
+			Expect(resp.Code).To(Equal(http.StatusForbidden))
+			Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "destinations can only be managed by network admins or managers of their org"}`))
+		})
+
+		It("returns an error when the existing destinations cannot be fetched", func() {
+			fakeStore.GetByGUIDReturns(nil, errors.New("whoa"))
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error getting egress destinations"}`))
+		})
+
+		It("returns an error when the access check fails", func() {
+			fakeEgressGuard.CanManageDestinationsReturns(false, errors.New("whoa"))
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error checking destination access"}`))
+		})
+
+		Context("when the destinations overlap others", func() {
+			var theirs store.DestinationOverlap
+
+			BeforeEach(func() {
+				fakeEgressGuard.ManagedOrgGUIDsReturns(map[string]struct{}{"some-org-guid": {}}, nil)
+				theirs = store.DestinationOverlap{
+					Destination:            store.EgressDestination{Name: "a"},
+					OverlappingDestination: store.EgressDestination{Name: "theirs", OrgGUID: "other-org-guid"},
+					Relation:               store.OverlapRelationOverlaps,
+				}
+			})
+
+			It("only names the overlapping destinations the user can see", func() {
+				fakeStore.UpdateWithOverlapsReturns(updatedDestinations, []store.DestinationOverlap{
+					{
+						Destination:            store.EgressDestination{Name: "a"},
+						OverlappingDestination: store.EgressDestination{Name: "mine", OrgGUID: "some-org-guid"},
+						Relation:               store.OverlapRelationContains,
+					},
+					{
+						Destination:            store.EgressDestination{Name: "a"},
+						OverlappingDestination: store.EgressDestination{Name: "shared", SelfService: true},
+						Relation:               store.OverlapRelationEqual,
+					},
+					theirs,
+				}, nil)
+				MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(fakeEgressGuard.ManagedOrgGUIDsArgsForCall(0)).To(Equal(token))
+				_, warnings := fakeMarshaller.AsBytesWithWarningsArgsForCall(0)
+				Expect(warnings).To(Equal([]string{
+					"destination 'a' contains destination 'mine'",
+					"destination 'a' covers the same traffic as destination 'shared'",
+					"destination 'a' overlaps another destination",
+				}))
+			})
+
+			It("does not name a destination the user cannot see when the store rejects the overlap", func() {
+				fakeStore.UpdateWithOverlapsReturns(nil, nil, store.NewOverlappingDestinationError("update", theirs))
+				MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error updating egress destination: egress destination store update: overlapping destination error: destination 'a' overlaps another destination"}`))
+			})
+
+			It("returns an error when the managed orgs cannot be fetched", func() {
+				fakeStore.UpdateWithOverlapsReturns(updatedDestinations, []store.DestinationOverlap{theirs}, nil)
+				fakeEgressGuard.ManagedOrgGUIDsReturns(nil, errors.New("whoa"))
+				MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error getting managed orgs"}`))
+			})
+		})
+	})
 })
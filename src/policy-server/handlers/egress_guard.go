@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"fmt"
+	"policy-server/api"
+	"policy-server/store"
+	"policy-server/uaa_client"
+)
+
+//go:generate counterfeiter -o fakes/egress_guard_cc_client.go --fake-name EgressGuardCCClient . egressGuardCCClient
+type egressGuardCCClient interface {
+	GetAppSpaces(token string, appGUIDs []string) (map[string]string, error)
+	GetSpace(token, spaceGUID string) (*api.Space, error)
+	GetSubjectSpace(token, subjectId string, space api.Space) (*api.Space, error)
+	GetSubjectManagedOrgs(token, subjectId string) (map[string]struct{}, error)
+}
+
+//go:generate counterfeiter -o fakes/egress_destination_getter.go --fake-name EgressDestinationGetter . egressDestinationGetter
+type egressDestinationGetter interface {
+	GetByGUID(guid ...string) ([]store.EgressDestination, error)
+}
+
+//go:generate counterfeiter -o fakes/egress_guard.go --fake-name EgressGuard . egressGuard
+type egressGuard interface {
+	IsNetworkAdmin(subjectToken uaa_client.CheckTokenResponse) bool
+	ManagedOrgGUIDs(subjectToken uaa_client.CheckTokenResponse) (map[string]struct{}, error)
+	CanManageDestinations(destinations []store.EgressDestination, subjectToken uaa_client.CheckTokenResponse) (bool, error)
+	CanManageEgressPolicies(policies []store.EgressPolicy, subjectToken uaa_client.CheckTokenResponse) (bool, error)
+}
+
+// EgressGuard decides which egress destinations and policies a user may
+// manage. Network admins may manage all of them. Org managers may manage the
+// destinations owned by their orgs, and the policies that attach those
+// destinations to the org itself or to spaces and apps inside it. Space
+// developers may manage the policies that attach self service destinations to
+// their apps and spaces, when they have network.write or
+// SpaceDeveloperSelfService is enabled.
+type EgressGuard struct {
+	CCClient                  egressGuardCCClient
+	UAAClient                 uaaClient
+	DestinationStore          egressDestinationGetter
+	SpaceDeveloperSelfService bool
+}
+
+func (g *EgressGuard) IsNetworkAdmin(subjectToken uaa_client.CheckTokenResponse) bool {
+	return isNetworkAdmin(subjectToken.Scope)
+}
+
+func (g *EgressGuard) ManagedOrgGUIDs(subjectToken uaa_client.CheckTokenResponse) (map[string]struct{}, error) {
+	_, managedOrgs, err := g.managedOrgGUIDs(subjectToken)
+	return managedOrgs, err
+}
+
+func (g *EgressGuard) CanManageDestinations(destinations []store.EgressDestination, subjectToken uaa_client.CheckTokenResponse) (bool, error) {
+	if g.IsNetworkAdmin(subjectToken) {
+		return true, nil
+	}
+
+	_, managedOrgs, err := g.managedOrgGUIDs(subjectToken)
+	if err != nil {
+		return false, err
+	}
+
+	for _, destination := range destinations {
+		if destination.SelfService {
+			return false, nil
+		}
+		if _, ok := managedOrgs[destination.OrgGUID]; !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (g *EgressGuard) CanManageEgressPolicies(policies []store.EgressPolicy, subjectToken uaa_client.CheckTokenResponse) (bool, error) {
+	if g.IsNetworkAdmin(subjectToken) {
+		return true, nil
+	}
+
+	token, managedOrgs, err := g.managedOrgGUIDs(subjectToken)
+	if err != nil {
+		return false, err
+	}
+
+	destinationGUIDs := make([]string, len(policies))
+	for i, policy := range policies {
+		destinationGUIDs[i] = policy.Destination.GUID
+	}
+	destinations, err := g.DestinationStore.GetByGUID(destinationGUIDs...)
+	if err != nil {
+		return false, fmt.Errorf("getting destinations: %s", err)
+	}
+	destinationsByGUID := map[string]store.EgressDestination{}
+	for _, destination := range destinations {
+		destinationsByGUID[destination.GUID] = destination
+	}
+
+	for _, policy := range policies {
+		destination, ok := destinationsByGUID[policy.Destination.GUID]
+		if !ok {
+			return false, nil
+		}
+
+		authorized, err := g.canManageEgressPolicy(token, subjectToken, managedOrgs, policy.Source, destination)
+		if err != nil {
+			return false, err
+		}
+		if !authorized {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (g *EgressGuard) canManageEgressPolicy(token string, subjectToken uaa_client.CheckTokenResponse, managedOrgs map[string]struct{}, source store.EgressSource, destination store.EgressDestination) (bool, error) {
+	if _, ok := managedOrgs[destination.OrgGUID]; ok {
+		sourceOrg, err := g.sourceOrgGUID(token, source)
+		if err != nil {
+			return false, err
+		}
+		if sourceOrg == destination.OrgGUID {
+			return true, nil
+		}
+	}
+
+	if !destination.SelfService || !g.canSelfService(subjectToken) {
+		return false, nil
+	}
+
+	space, err := g.sourceSpace(token, source)
+	if err != nil || space == nil {
+		return false, err
+	}
+	if destination.OrgGUID != "" && space.OrgGUID != destination.OrgGUID {
+		return false, nil
+	}
+
+	subjectSpace, err := g.CCClient.GetSubjectSpace(token, subjectToken.Subject, *space)
+	if err != nil {
+		return false, fmt.Errorf("getting subject space %s: %s", space.Name, err)
+	}
+	return subjectSpace != nil, nil
+}
+
+func (g *EgressGuard) canSelfService(subjectToken uaa_client.CheckTokenResponse) bool {
+	return g.SpaceDeveloperSelfService || isAuthorized(subjectToken.Scope, []string{"network.write"})
+}
+
+func (g *EgressGuard) managedOrgGUIDs(subjectToken uaa_client.CheckTokenResponse) (string, map[string]struct{}, error) {
+	token, err := g.UAAClient.GetToken()
+	if err != nil {
+		return "", nil, fmt.Errorf("getting token: %s", err)
+	}
+
+	managedOrgs, err := g.CCClient.GetSubjectManagedOrgs(token, subjectToken.Subject)
+	if err != nil {
+		return "", nil, fmt.Errorf("getting managed orgs: %s", err)
+	}
+	delete(managedOrgs, "")
+	return token, managedOrgs, nil
+}
+
+// sourceOrgGUID returns the org the source lives in, or an empty string when
+// the source is not inside any org or no longer exists.
+func (g *EgressGuard) sourceOrgGUID(token string, source store.EgressSource) (string, error) {
+	if source.Type == "org" {
+		return source.ID, nil
+	}
+
+	space, err := g.sourceSpace(token, source)
+	if err != nil || space == nil {
+		return "", err
+	}
+	return space.OrgGUID, nil
+}
+
+// sourceSpace returns the space an app or space source lives in, or nil when
+// the source is an org, the default source or no longer exists.
+func (g *EgressGuard) sourceSpace(token string, source store.EgressSource) (*api.Space, error) {
+	spaceGUID := source.ID
+	switch source.Type {
+	case "space":
+	case "", "app":
+		appSpaces, err := g.CCClient.GetAppSpaces(token, []string{source.ID})
+		if err != nil {
+			return nil, fmt.Errorf("getting app spaces: %s", err)
+		}
+		spaceGUID = appSpaces[source.ID]
+		if spaceGUID == "" {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+
+	space, err := g.CCClient.GetSpace(token, spaceGUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting space with guid %s: %s", spaceGUID, err)
+	}
+	return space, nil
+}
@@ -0,0 +1,301 @@
+package handlers_test
+
+import (
+	"errors"
+	"policy-server/api"
+	"policy-server/handlers"
+	"policy-server/handlers/fakes"
+	"policy-server/store"
+	"policy-server/uaa_client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EgressGuard", func() {
+	var (
+		egressGuard          *handlers.EgressGuard
+		fakeCCClient         *fakes.EgressGuardCCClient
+		fakeUAAClient        *fakes.UAAClient
+		fakeDestinationStore *fakes.EgressDestinationGetter
+		tokenData            uaa_client.CheckTokenResponse
+		adminTokenData       uaa_client.CheckTokenResponse
+	)
+
+	BeforeEach(func() {
+		fakeCCClient = &fakes.EgressGuardCCClient{}
+		fakeUAAClient = &fakes.UAAClient{}
+		fakeDestinationStore = &fakes.EgressDestinationGetter{}
+		egressGuard = &handlers.EgressGuard{
+			CCClient:         fakeCCClient,
+			UAAClient:        fakeUAAClient,
+			DestinationStore: fakeDestinationStore,
+		}
+
+		tokenData = uaa_client.CheckTokenResponse{
+			Scope:   []string{"cloud_controller.read"},
+			Subject: "some-org-manager-guid",
+		}
+		adminTokenData = uaa_client.CheckTokenResponse{
+			Scope: []string{"network.admin"},
+		}
+
+		fakeUAAClient.GetTokenReturns("policy-server-token", nil)
+		fakeCCClient.GetSubjectManagedOrgsReturns(map[string]struct{}{"org-guid-1": {}}, nil)
+	})
+
+	Describe("IsNetworkAdmin", func() {
+		It("checks for the network.admin scope", func() {
+			Expect(egressGuard.IsNetworkAdmin(adminTokenData)).To(BeTrue())
+			Expect(egressGuard.IsNetworkAdmin(tokenData)).To(BeFalse())
+		})
+	})
+
+	Describe("ManagedOrgGUIDs", func() {
+		It("returns the orgs the subject manages", func() {
+			orgs, err := egressGuard.ManagedOrgGUIDs(tokenData)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orgs).To(Equal(map[string]struct{}{"org-guid-1": {}}))
+
+			token, subject := fakeCCClient.GetSubjectManagedOrgsArgsForCall(0)
+			Expect(token).To(Equal("policy-server-token"))
+			Expect(subject).To(Equal("some-org-manager-guid"))
+		})
+
+		Context("when getting a token fails", func() {
+			BeforeEach(func() {
+				fakeUAAClient.GetTokenReturns("", errors.New("banana"))
+			})
+
+			It("returns an error", func() {
+				_, err := egressGuard.ManagedOrgGUIDs(tokenData)
+				Expect(err).To(MatchError("getting token: banana"))
+			})
+		})
+
+		Context("when getting the managed orgs fails", func() {
+			BeforeEach(func() {
+				fakeCCClient.GetSubjectManagedOrgsReturns(nil, errors.New("banana"))
+			})
+
+			It("returns an error", func() {
+				_, err := egressGuard.ManagedOrgGUIDs(tokenData)
+				Expect(err).To(MatchError("getting managed orgs: banana"))
+			})
+		})
+	})
+
+	Describe("CanManageDestinations", func() {
+		It("allows network admins to manage any destination", func() {
+			authorized, err := egressGuard.CanManageDestinations([]store.EgressDestination{{Name: "global"}}, adminTokenData)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authorized).To(BeTrue())
+			Expect(fakeCCClient.GetSubjectManagedOrgsCallCount()).To(Equal(0))
+		})
+
+		It("allows org managers to manage destinations of their orgs", func() {
+			authorized, err := egressGuard.CanManageDestinations([]store.EgressDestination{{OrgGUID: "org-guid-1"}}, tokenData)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authorized).To(BeTrue())
+		})
+
+		It("does not allow org managers to manage global destinations", func() {
+			authorized, err := egressGuard.CanManageDestinations([]store.EgressDestination{{OrgGUID: "org-guid-1"}, {}}, tokenData)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authorized).To(BeFalse())
+		})
+
+		It("does not allow org managers to manage self service destinations", func() {
+			authorized, err := egressGuard.CanManageDestinations([]store.EgressDestination{{OrgGUID: "org-guid-1", SelfService: true}}, tokenData)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authorized).To(BeFalse())
+		})
+
+		It("does not allow org managers to manage destinations of other orgs", func() {
+			authorized, err := egressGuard.CanManageDestinations([]store.EgressDestination{{OrgGUID: "org-guid-2"}}, tokenData)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authorized).To(BeFalse())
+		})
+
+		Context("when getting the managed orgs fails", func() {
+			BeforeEach(func() {
+				fakeCCClient.GetSubjectManagedOrgsReturns(nil, errors.New("banana"))
+			})
+
+			It("returns an error", func() {
+				_, err := egressGuard.CanManageDestinations([]store.EgressDestination{{OrgGUID: "org-guid-1"}}, tokenData)
+				Expect(err).To(MatchError("getting managed orgs: banana"))
+			})
+		})
+	})
+
+	Describe("CanManageEgressPolicies", func() {
+		BeforeEach(func() {
+			fakeDestinationStore.GetByGUIDReturns([]store.EgressDestination{
+				{GUID: "org-destination-guid", OrgGUID: "org-guid-1"},
+				{GUID: "other-org-destination-guid", OrgGUID: "org-guid-2"},
+				{GUID: "global-destination-guid"},
+				{GUID: "self-service-destination-guid", SelfService: true},
+				{GUID: "org-self-service-destination-guid", OrgGUID: "org-guid-2", SelfService: true},
+			}, nil)
+			fakeCCClient.GetAppSpacesReturns(map[string]string{"some-app-guid": "some-space-guid"}, nil)
+			fakeCCClient.GetSpaceStub = func(token, spaceGUID string) (*api.Space, error) {
+				switch spaceGUID {
+				case "some-space-guid":
+					return &api.Space{Name: "some-space", OrgGUID: "org-guid-1"}, nil
+				case "other-space-guid":
+					return &api.Space{Name: "other-space", OrgGUID: "org-guid-2"}, nil
+				}
+				return nil, nil
+			}
+		})
+
+		policyTo := func(sourceType, sourceID, destinationGUID string) store.EgressPolicy {
+			return store.EgressPolicy{
+				Source:      store.EgressSource{Type: sourceType, ID: sourceID},
+				Destination: store.EgressDestination{GUID: destinationGUID},
+			}
+		}
+
+		It("allows network admins to manage any policy", func() {
+			authorized, err := egressGuard.CanManageEgressPolicies([]store.EgressPolicy{policyTo("default", "", "global-destination-guid")}, adminTokenData)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authorized).To(BeTrue())
+			Expect(fakeDestinationStore.GetByGUIDCallCount()).To(Equal(0))
+		})
+
+		It("allows org managers to attach their destinations to apps, spaces and the org itself", func() {
+			authorized, err := egressGuard.CanManageEgressPolicies([]store.EgressPolicy{
+				policyTo("app", "some-app-guid", "org-destination-guid"),
+				policyTo("", "some-app-guid", "org-destination-guid"),
+				policyTo("space", "some-space-guid", "org-destination-guid"),
+				policyTo("org", "org-guid-1", "org-destination-guid"),
+			}, tokenData)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authorized).To(BeTrue())
+
+			Expect(fakeDestinationStore.GetByGUIDArgsForCall(0)).To(Equal([]string{
+				"org-destination-guid", "org-destination-guid", "org-destination-guid", "org-destination-guid",
+			}))
+			token, appGUIDs := fakeCCClient.GetAppSpacesArgsForCall(0)
+			Expect(token).To(Equal("policy-server-token"))
+			Expect(appGUIDs).To(Equal([]string{"some-app-guid"}))
+		})
+
+		DescribeTable("policies org managers may not manage",
+			func(policy store.EgressPolicy) {
+				authorized, err := egressGuard.CanManageEgressPolicies([]store.EgressPolicy{policy}, tokenData)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(authorized).To(BeFalse())
+			},
+			Entry("a global destination", policyTo("org", "org-guid-1", "global-destination-guid")),
+			Entry("a destination of another org", policyTo("org", "org-guid-2", "other-org-destination-guid")),
+			Entry("a destination that does not exist", policyTo("org", "org-guid-1", "missing-destination-guid")),
+			Entry("a source in another org", policyTo("space", "other-space-guid", "org-destination-guid")),
+			Entry("a source that does not exist", policyTo("space", "missing-space-guid", "org-destination-guid")),
+			Entry("an app that does not exist", policyTo("app", "missing-app-guid", "org-destination-guid")),
+			Entry("the default source", policyTo("default", "", "org-destination-guid")),
+			Entry("a self service destination without network.write", policyTo("space", "some-space-guid", "self-service-destination-guid")),
+		)
+
+		Context("when the user may use self service destinations", func() {
+			BeforeEach(func() {
+				tokenData.Scope = []string{"network.write"}
+				fakeCCClient.GetSubjectManagedOrgsReturns(map[string]struct{}{}, nil)
+				fakeCCClient.GetSubjectSpaceStub = func(token, subjectId string, space api.Space) (*api.Space, error) {
+					return &space, nil
+				}
+			})
+
+			It("allows space developers to attach self service destinations to their apps and spaces", func() {
+				authorized, err := egressGuard.CanManageEgressPolicies([]store.EgressPolicy{
+					policyTo("app", "some-app-guid", "self-service-destination-guid"),
+					policyTo("space", "other-space-guid", "self-service-destination-guid"),
+					policyTo("space", "other-space-guid", "org-self-service-destination-guid"),
+				}, tokenData)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(authorized).To(BeTrue())
+
+				Expect(fakeCCClient.GetSubjectSpaceCallCount()).To(Equal(3))
+				token, subject, space := fakeCCClient.GetSubjectSpaceArgsForCall(0)
+				Expect(token).To(Equal("policy-server-token"))
+				Expect(subject).To(Equal("some-org-manager-guid"))
+				Expect(space).To(Equal(api.Space{Name: "some-space", OrgGUID: "org-guid-1"}))
+			})
+
+			It("allows everyone to use self service destinations when space developer self service is enabled", func() {
+				tokenData.Scope = []string{}
+				egressGuard.SpaceDeveloperSelfService = true
+
+				authorized, err := egressGuard.CanManageEgressPolicies([]store.EgressPolicy{policyTo("space", "some-space-guid", "self-service-destination-guid")}, tokenData)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(authorized).To(BeTrue())
+			})
+
+			DescribeTable("policies space developers may not manage",
+				func(policy store.EgressPolicy) {
+					authorized, err := egressGuard.CanManageEgressPolicies([]store.EgressPolicy{policy}, tokenData)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(authorized).To(BeFalse())
+				},
+				Entry("a destination that is not self service", policyTo("space", "some-space-guid", "global-destination-guid")),
+				Entry("a self service destination of another org", policyTo("space", "some-space-guid", "org-self-service-destination-guid")),
+				Entry("an org source", policyTo("org", "org-guid-1", "self-service-destination-guid")),
+				Entry("the default source", policyTo("default", "", "self-service-destination-guid")),
+				Entry("a source that does not exist", policyTo("space", "missing-space-guid", "self-service-destination-guid")),
+			)
+
+			It("does not allow managing policies of spaces the user is not a developer of", func() {
+				fakeCCClient.GetSubjectSpaceStub = nil
+				fakeCCClient.GetSubjectSpaceReturns(nil, nil)
+
+				authorized, err := egressGuard.CanManageEgressPolicies([]store.EgressPolicy{policyTo("space", "some-space-guid", "self-service-destination-guid")}, tokenData)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(authorized).To(BeFalse())
+			})
+
+			It("returns an error when the subject space cannot be fetched", func() {
+				fakeCCClient.GetSubjectSpaceStub = nil
+				fakeCCClient.GetSubjectSpaceReturns(nil, errors.New("banana"))
+
+				_, err := egressGuard.CanManageEgressPolicies([]store.EgressPolicy{policyTo("space", "some-space-guid", "self-service-destination-guid")}, tokenData)
+				Expect(err).To(MatchError("getting subject space some-space: banana"))
+			})
+		})
+
+		Context("when getting the destinations fails", func() {
+			BeforeEach(func() {
+				fakeDestinationStore.GetByGUIDReturns(nil, errors.New("banana"))
+			})
+
+			It("returns an error", func() {
+				_, err := egressGuard.CanManageEgressPolicies([]store.EgressPolicy{policyTo("org", "org-guid-1", "org-destination-guid")}, tokenData)
+				Expect(err).To(MatchError("getting destinations: banana"))
+			})
+		})
+
+		Context("when getting the app spaces fails", func() {
+			BeforeEach(func() {
+				fakeCCClient.GetAppSpacesReturns(nil, errors.New("banana"))
+			})
+
+			It("returns an error", func() {
+				_, err := egressGuard.CanManageEgressPolicies([]store.EgressPolicy{policyTo("app", "some-app-guid", "org-destination-guid")}, tokenData)
+				Expect(err).To(MatchError("getting app spaces: banana"))
+			})
+		})
+
+		Context("when getting the space fails", func() {
+			BeforeEach(func() {
+				fakeCCClient.GetSpaceStub = nil
+				fakeCCClient.GetSpaceReturns(nil, errors.New("banana"))
+			})
+
+			It("returns an error", func() {
+				_, err := egressGuard.CanManageEgressPolicies([]store.EgressPolicy{policyTo("space", "some-space-guid", "org-destination-guid")}, tokenData)
+				Expect(err).To(MatchError("getting space with guid some-space-guid: banana"))
+			})
+		})
+	})
+})
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"policy-server/store"
@@ -19,6 +20,7 @@ type egressPolicyMapper interface {
 type EgressPolicyCreate struct {
 	Store         egressPolicyStore
 	Mapper        egressPolicyMapper
+	EgressGuard   egressGuard
 	ErrorResponse errorResponse
 	Logger        lager.Logger
 }
@@ -36,6 +38,17 @@ func (e *EgressPolicyCreate) ServeHTTP(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
+	authorized, err := e.EgressGuard.CanManageEgressPolicies(storeEgressPolicies, getTokenData(req))
+	if err != nil {
+		e.ErrorResponse.InternalServerError(e.Logger, w, err, "error checking egress policy access")
+		return
+	}
+	if !authorized {
+		err := errors.New("not authorized to manage egress policies")
+		e.ErrorResponse.Forbidden(e.Logger, w, err, "egress policies can only be managed by network admins, managers of the org of both the source and the destination, or space developers of the source when the destination is self service")
+		return
+	}
+
 	createdPolicies, err := e.Store.Create(storeEgressPolicies)
 	if err != nil {
 		e.ErrorResponse.InternalServerError(e.Logger, w, err, "error creating egress policy")
@@ -23,6 +23,7 @@ import (
 
 var _ = Describe("EgressPoliciesCreate", func() {
 	var (
+		fakeEgressGuard             *fakes.EgressGuard
 		expectedStoreEgressPolicies []store.EgressPolicy
 		fakeMapper                  *fakes.EgressPolicyMapper
 		fakeStore                   *fakes.EgressPolicyStore
@@ -48,7 +49,12 @@ var _ = Describe("EgressPoliciesCreate", func() {
 
 		logger = lagertest.NewTestLogger("test")
 
+		fakeEgressGuard = &fakes.EgressGuard{}
+		fakeEgressGuard.IsNetworkAdminReturns(true)
+		fakeEgressGuard.CanManageEgressPoliciesReturns(true, nil)
+
 		handler = &handlers.EgressPolicyCreate{
+			EgressGuard:   fakeEgressGuard,
 			Store:         fakeStore,
 			Mapper:        fakeMapper,
 			ErrorResponse: errorResponse,
@@ -153,4 +159,27 @@ var _ = Describe("EgressPoliciesCreate", func() {
 		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
 		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error serializing response"}`))
 	})
+	It("checks that the user may manage the requested policies", func() {
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		policies, tokenData := fakeEgressGuard.CanManageEgressPoliciesArgsForCall(0)
+		Expect(policies).To(Equal(expectedStoreEgressPolicies))
+		Expect(tokenData).To(Equal(token))
+	})
+
+	It("returns forbidden when the user may not manage the policies", func() {
+		fakeEgressGuard.CanManageEgressPoliciesReturns(false, nil)
+
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(fakeStore.CreateCallCount()).To(Equal(0))
+		Expect(resp.Code).To(Equal(http.StatusForbidden))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "egress policies can only be managed by network admins, managers of the org of both the source and the destination, or space developers of the source when the destination is self service"}`))
+	})
+
+	It("returns an error when the access check fails", func() {
+		fakeEgressGuard.CanManageEgressPoliciesReturns(false, errors.New("didn't go well"))
+
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error checking egress policy access"}`))
+	})
 })
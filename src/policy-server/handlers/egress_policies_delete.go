@@ -1,14 +1,23 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"policy-server/store"
 
 	"code.cloudfoundry.org/lager"
 )
 
+//go:generate counterfeiter -o fakes/egress_policy_store_deleter.go --fake-name EgressPolicyStoreDeleter . EgressPolicyStoreDeleter
+type EgressPolicyStoreDeleter interface {
+	GetByGUID(ids ...string) ([]store.EgressPolicy, error)
+	Delete(guids ...string) ([]store.EgressPolicy, error)
+}
+
 type EgressPolicyDelete struct {
-	Store         egressPolicyStore
+	Store         EgressPolicyStoreDeleter
 	Mapper        egressPolicyMapper
+	EgressGuard   egressGuard
 	ErrorResponse errorResponse
 	Logger        lager.Logger
 }
@@ -16,6 +25,26 @@ type EgressPolicyDelete struct {
 func (e *EgressPolicyDelete) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	guid := req.URL.Query().Get(":id")
 
+	tokenData := getTokenData(req)
+	if !e.EgressGuard.IsNetworkAdmin(tokenData) {
+		policies, err := e.Store.GetByGUID(guid)
+		if err != nil {
+			e.ErrorResponse.InternalServerError(e.Logger, w, err, "error getting egress policy")
+			return
+		}
+
+		authorized, err := e.EgressGuard.CanManageEgressPolicies(policies, tokenData)
+		if err != nil {
+			e.ErrorResponse.InternalServerError(e.Logger, w, err, "error checking egress policy access")
+			return
+		}
+		if !authorized || len(policies) == 0 {
+			err := errors.New("not authorized to manage egress policies")
+			e.ErrorResponse.Forbidden(e.Logger, w, err, "egress policies can only be managed by network admins, managers of the org of both the source and the destination, or space developers of the source when the destination is self service")
+			return
+		}
+	}
+
 	deletedPolicies, err := e.Store.Delete(guid)
 	if err != nil {
 		e.ErrorResponse.InternalServerError(e.Logger, w, err, "error deleting egress policy")
@@ -22,8 +22,9 @@ import (
 
 var _ = Describe("EgressPoliciesDelete", func() {
 	var (
+		fakeEgressGuard   *fakes.EgressGuard
 		fakeMapper        *fakes.EgressPolicyMapper
-		fakeStore         *fakes.EgressPolicyStore
+		fakeStore         *fakes.EgressPolicyStoreDeleter
 		logger            *lagertest.TestLogger
 		fakeMetricsSender *storeFakes.MetricsSender
 		handler           *handlers.EgressPolicyDelete
@@ -35,7 +36,7 @@ var _ = Describe("EgressPoliciesDelete", func() {
 	)
 
 	BeforeEach(func() {
-		fakeStore = &fakes.EgressPolicyStore{}
+		fakeStore = &fakes.EgressPolicyStoreDeleter{}
 		fakeMapper = &fakes.EgressPolicyMapper{}
 
 		fakeMetricsSender = &storeFakes.MetricsSender{}
@@ -45,7 +46,11 @@ var _ = Describe("EgressPoliciesDelete", func() {
 
 		logger = lagertest.NewTestLogger("test")
 
+		fakeEgressGuard = &fakes.EgressGuard{}
+		fakeEgressGuard.IsNetworkAdminReturns(true)
+
 		handler = &handlers.EgressPolicyDelete{
+			EgressGuard:   fakeEgressGuard,
 			Store:         fakeStore,
 			Mapper:        fakeMapper,
 			ErrorResponse: errorResponse,
@@ -116,4 +121,50 @@ var _ = Describe("EgressPoliciesDelete", func() {
 		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
 		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error serializing response"}`))
 	})
+	Context("when the user is not a network admin", func() {
+		var existingPolicies []store.EgressPolicy
+
+		BeforeEach(func() {
+			existingPolicies = []store.EgressPolicy{{ID: "abc-123"}}
+			fakeEgressGuard.IsNetworkAdminReturns(false)
+			fakeEgressGuard.CanManageEgressPoliciesReturns(true, nil)
+			fakeStore.GetByGUIDReturns(existingPolicies, nil)
+		})
+
+		It("deletes policies the user may manage", func() {
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+			Expect(fakeStore.GetByGUIDArgsForCall(0)).To(Equal([]string{"abc-123"}))
+			policies, tokenData := fakeEgressGuard.CanManageEgressPoliciesArgsForCall(0)
+			Expect(policies).To(Equal(existingPolicies))
+			Expect(tokenData).To(Equal(token))
+			Expect(fakeStore.DeleteCallCount()).To(Equal(1))
+			Expect(resp.Code).To(Equal(http.StatusOK))
+		})
+
+		It("returns forbidden when the user may not manage the policy", func() {
+			fakeEgressGuard.CanManageEgressPoliciesReturns(false, nil)
+
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+			Expect(fakeStore.DeleteCallCount()).To(Equal(0))
+			Expect(resp.Code).To(Equal(http.StatusForbidden))
+			Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "egress policies can only be managed by network admins, managers of the org of both the source and the destination, or space developers of the source when the destination is self service"}`))
+		})
+
+		It("returns forbidden when the policy does not exist", func() {
+			fakeStore.GetByGUIDReturns([]store.EgressPolicy{}, nil)
+
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+			Expect(fakeStore.DeleteCallCount()).To(Equal(0))
+			Expect(resp.Code).To(Equal(http.StatusForbidden))
+		})
+
+		It("returns an error when the policy cannot be fetched", func() {
+			fakeStore.GetByGUIDReturns(nil, errors.New("didn't go well"))
+
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error getting egress policy"}`))
+		})
+	})
 })
@@ -1,26 +1,101 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"policy-server/store"
+	"sort"
+	"strconv"
 
 	"code.cloudfoundry.org/lager"
 )
 
+//go:generate counterfeiter -o fakes/egress_policy_store_lister.go --fake-name EgressPolicyStoreLister . EgressPolicyStoreLister
+type EgressPolicyStoreLister interface {
+	GetByFilter(filter store.EgressPolicyFilter) ([]store.EgressPolicy, int, error)
+}
+
+//go:generate counterfeiter -o fakes/egress_policy_page_mapper.go --fake-name EgressPolicyPageMapper . egressPolicyPageMapper
+type egressPolicyPageMapper interface {
+	AsBytesWithTotal(storeEgressPolicies []store.EgressPolicy, total int) ([]byte, error)
+}
+
+// EgressPolicyIndex lists egress policies. Users other than network admins
+// only see the policies whose destination belongs to an org they manage.
+// Policies that space developers attached to self service destinations are
+// not listed to them; they can get each one by its id.
 type EgressPolicyIndex struct {
-	Store         egressPolicyStore
-	Mapper        egressPolicyMapper
+	Store         EgressPolicyStoreLister
+	Mapper        egressPolicyPageMapper
+	EgressGuard   egressGuard
 	ErrorResponse errorResponse
 	Logger        lager.Logger
 }
 
+var validSourceTypes = map[string]bool{"app": true, "space": true, "org": true, "default": true}
+
 func (e *EgressPolicyIndex) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	policies, err := e.Store.All()
+	queryParameters := req.URL.Query()
+	filter := store.EgressPolicyFilter{
+		SourceIDs:        parseQueryParam(queryParameters, "source_id"),
+		SourceTypes:      parseQueryParam(queryParameters, "source_type"),
+		DestinationIDs:   parseQueryParam(queryParameters, "destination_id"),
+		DestinationNames: parseQueryParam(queryParameters, "destination_name"),
+	}
+
+	for _, sourceType := range filter.SourceTypes {
+		if !validSourceTypes[sourceType] {
+			err := fmt.Errorf("invalid source_type '%s'", sourceType)
+			e.ErrorResponse.BadRequest(e.Logger, w, err, "source_type must be app, space, org or default")
+			return
+		}
+	}
+
+	perPage, err := parsePositiveInt(queryParameters.Get("per_page"))
+	if err != nil {
+		e.ErrorResponse.BadRequest(e.Logger, w, err, "per_page must be a positive integer")
+		return
+	}
+
+	page, err := parsePositiveInt(queryParameters.Get("page"))
 	if err != nil {
-		e.ErrorResponse.InternalServerError(e.Logger, w, err, "error listing egress policies")
+		e.ErrorResponse.BadRequest(e.Logger, w, err, "page must be a positive integer")
 		return
 	}
 
-	bytes, err := e.Mapper.AsBytesWithPopulatedDestinations(policies)
+	if perPage > 0 {
+		if page == 0 {
+			page = 1
+		}
+		filter.Limit = perPage
+		filter.Offset = (page - 1) * perPage
+	}
+
+	tokenData := getTokenData(req)
+	isNetworkAdmin := e.EgressGuard.IsNetworkAdmin(tokenData)
+	if !isNetworkAdmin {
+		managedOrgs, err := e.EgressGuard.ManagedOrgGUIDs(tokenData)
+		if err != nil {
+			e.ErrorResponse.InternalServerError(e.Logger, w, err, "error getting managed orgs")
+			return
+		}
+		for orgGUID := range managedOrgs {
+			filter.DestinationOrgGUIDs = append(filter.DestinationOrgGUIDs, orgGUID)
+		}
+		sort.Strings(filter.DestinationOrgGUIDs)
+	}
+
+	policies := []store.EgressPolicy{}
+	total := 0
+	if isNetworkAdmin || len(filter.DestinationOrgGUIDs) > 0 {
+		policies, total, err = e.Store.GetByFilter(filter)
+		if err != nil {
+			e.ErrorResponse.InternalServerError(e.Logger, w, err, "error listing egress policies")
+			return
+		}
+	}
+
+	bytes, err := e.Mapper.AsBytesWithTotal(policies, total)
 	if err != nil {
 		e.ErrorResponse.InternalServerError(e.Logger, w, err, "error serializing response")
 		return
@@ -29,3 +104,15 @@ func (e *EgressPolicyIndex) ServeHTTP(w http.ResponseWriter, req *http.Request)
 	w.WriteHeader(http.StatusOK)
 	w.Write(bytes)
 }
+
+func parsePositiveInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 1 {
+		return 0, fmt.Errorf("invalid value '%s'", value)
+	}
+	return parsed, nil
+}
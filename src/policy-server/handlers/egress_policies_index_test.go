@@ -17,13 +17,15 @@ import (
 
 	"code.cloudfoundry.org/lager/lagertest"
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("EgressPoliciesIndex", func() {
 	var (
-		fakeMapper        *fakes.EgressPolicyMapper
-		fakeStore         *fakes.EgressPolicyStore
+		fakeEgressGuard   *fakes.EgressGuard
+		fakeMapper        *fakes.EgressPolicyPageMapper
+		fakeStore         *fakes.EgressPolicyStoreLister
 		logger            *lagertest.TestLogger
 		fakeMetricsSender *storeFakes.MetricsSender
 		handler           *handlers.EgressPolicyIndex
@@ -35,8 +37,8 @@ var _ = Describe("EgressPoliciesIndex", func() {
 	)
 
 	BeforeEach(func() {
-		fakeStore = &fakes.EgressPolicyStore{}
-		fakeMapper = &fakes.EgressPolicyMapper{}
+		fakeStore = &fakes.EgressPolicyStoreLister{}
+		fakeMapper = &fakes.EgressPolicyPageMapper{}
 
 		fakeMetricsSender = &storeFakes.MetricsSender{}
 		errorResponse := &httperror.ErrorResponse{
@@ -45,7 +47,11 @@ var _ = Describe("EgressPoliciesIndex", func() {
 
 		logger = lagertest.NewTestLogger("test")
 
+		fakeEgressGuard = &fakes.EgressGuard{}
+		fakeEgressGuard.IsNetworkAdminReturns(true)
+
 		handler = &handlers.EgressPolicyIndex{
+			EgressGuard:   fakeEgressGuard,
 			Store:         fakeStore,
 			Mapper:        fakeMapper,
 			ErrorResponse: errorResponse,
@@ -57,7 +63,7 @@ var _ = Describe("EgressPoliciesIndex", func() {
 				ID: "abc-123",
 			},
 		}
-		fakeStore.AllReturns(policies, nil)
+		fakeStore.GetByFilterReturns(policies, 1, nil)
 
 		responseBody = `{
 			"egress_policies": [
@@ -69,7 +75,7 @@ var _ = Describe("EgressPoliciesIndex", func() {
 			]
 		}`
 
-		fakeMapper.AsBytesWithPopulatedDestinationsReturns([]byte(responseBody), nil)
+		fakeMapper.AsBytesWithTotalReturns([]byte(responseBody), nil)
 
 		var err error
 		request, err = http.NewRequest("GET", "/networking/v1/external/egress_policies", nil)
@@ -83,15 +89,18 @@ var _ = Describe("EgressPoliciesIndex", func() {
 	It("lists egress policies", func() {
 		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
 
-		Expect(fakeStore.AllCallCount()).To(Equal(1))
+		Expect(fakeStore.GetByFilterCallCount()).To(Equal(1))
+		Expect(fakeStore.GetByFilterArgsForCall(0)).To(Equal(store.EgressPolicyFilter{}))
 
-		Expect(fakeMapper.AsBytesWithPopulatedDestinationsCallCount()).To(Equal(1))
-		Expect(fakeMapper.AsBytesWithPopulatedDestinationsArgsForCall(0)).To(Equal(policies))
+		Expect(fakeMapper.AsBytesWithTotalCallCount()).To(Equal(1))
+		mappedPolicies, total := fakeMapper.AsBytesWithTotalArgsForCall(0)
+		Expect(mappedPolicies).To(Equal(policies))
+		Expect(total).To(Equal(1))
 
 		Expect(resp.Code).To(Equal(http.StatusOK))
 	})
 
-	It("returns a response that includes the deleted policy", func() {
+	It("returns a response that includes the listed policies", func() {
 		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
 
 		body, err := ioutil.ReadAll(resp.Body)
@@ -99,18 +108,115 @@ var _ = Describe("EgressPoliciesIndex", func() {
 		Expect(string(body)).To(Equal(responseBody))
 	})
 
+	Context("when filters are given", func() {
+		BeforeEach(func() {
+			request.URL.RawQuery = "source_id=app-1,space-1&source_type=app,space&destination_id=dest-1&destination_name=a,b"
+		})
+
+		It("passes them to the store", func() {
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(fakeStore.GetByFilterArgsForCall(0)).To(Equal(store.EgressPolicyFilter{
+				SourceIDs:        []string{"app-1", "space-1"},
+				SourceTypes:      []string{"app", "space"},
+				DestinationIDs:   []string{"dest-1"},
+				DestinationNames: []string{"a", "b"},
+			}))
+		})
+	})
+
+	Context("when pagination is requested", func() {
+		BeforeEach(func() {
+			request.URL.RawQuery = "per_page=10&page=3"
+			fakeStore.GetByFilterReturns(policies, 21, nil)
+		})
+
+		It("requests the page from the store and reports the total", func() {
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(fakeStore.GetByFilterArgsForCall(0)).To(Equal(store.EgressPolicyFilter{Limit: 10, Offset: 20}))
+
+			_, total := fakeMapper.AsBytesWithTotalArgsForCall(0)
+			Expect(total).To(Equal(21))
+		})
+
+		Context("when the page is not given", func() {
+			BeforeEach(func() {
+				request.URL.RawQuery = "per_page=10"
+			})
+
+			It("returns the first page", func() {
+				MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+				Expect(fakeStore.GetByFilterArgsForCall(0)).To(Equal(store.EgressPolicyFilter{Limit: 10, Offset: 0}))
+			})
+		})
+	})
+
+	DescribeTable("invalid query parameters",
+		func(query, expectedError string) {
+			request.URL.RawQuery = query
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+			Expect(fakeStore.GetByFilterCallCount()).To(Equal(0))
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+			Expect(resp.Body.Bytes()).To(MatchJSON(expectedError))
+		},
+		Entry("unknown source type", "source_type=app,cell", `{"error": "source_type must be app, space, org or default"}`),
+		Entry("non-numeric per_page", "per_page=lots", `{"error": "per_page must be a positive integer"}`),
+		Entry("zero per_page", "per_page=0", `{"error": "per_page must be a positive integer"}`),
+		Entry("negative page", "per_page=5&page=-1", `{"error": "page must be a positive integer"}`),
+	)
+
 	It("returns an error when the store returns an error", func() {
-		fakeStore.AllReturns([]store.EgressPolicy{}, errors.New("can't create"))
+		fakeStore.GetByFilterReturns([]store.EgressPolicy{}, 0, errors.New("can't create"))
 		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
 		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
 		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error listing egress policies"}`))
 	})
 
 	It("returns an error the mapper cannot serialize the output", func() {
-		fakeMapper.AsBytesWithPopulatedDestinationsReturns(nil, errors.New("didn't go well"))
+		fakeMapper.AsBytesWithTotalReturns(nil, errors.New("didn't go well"))
 
 		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
 		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
 		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error serializing response"}`))
 	})
+	Context("when the user is not a network admin", func() {
+		BeforeEach(func() {
+			fakeEgressGuard.IsNetworkAdminReturns(false)
+			fakeEgressGuard.ManagedOrgGUIDsReturns(map[string]struct{}{"org-2": {}, "org-1": {}}, nil)
+		})
+
+		It("only lists policies to destinations of the orgs the user manages", func() {
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(fakeEgressGuard.ManagedOrgGUIDsArgsForCall(0)).To(Equal(token))
+			Expect(fakeStore.GetByFilterArgsForCall(0)).To(Equal(store.EgressPolicyFilter{
+				DestinationOrgGUIDs: []string{"org-1", "org-2"},
+			}))
+		})
+
+		It("returns no policies when the user manages no orgs", func() {
+			fakeEgressGuard.ManagedOrgGUIDsReturns(map[string]struct{}{}, nil)
+
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(fakeStore.GetByFilterCallCount()).To(Equal(0))
+			mappedPolicies, total := fakeMapper.AsBytesWithTotalArgsForCall(0)
+			Expect(mappedPolicies).To(BeEmpty())
+			Expect(total).To(Equal(0))
+		})
+
+		It("returns an error when the managed orgs cannot be fetched", func() {
+			fakeEgressGuard.ManagedOrgGUIDsReturns(nil, errors.New("didn't go well"))
+
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error getting managed orgs"}`))
+		})
+	})
 })
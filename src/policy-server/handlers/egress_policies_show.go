@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"policy-server/store"
+
+	"code.cloudfoundry.org/lager"
+)
+
+//go:generate counterfeiter -o fakes/egress_policy_store_getter.go --fake-name EgressPolicyStoreGetter . EgressPolicyStoreGetter
+type EgressPolicyStoreGetter interface {
+	GetByGUID(ids ...string) ([]store.EgressPolicy, error)
+}
+
+type EgressPolicyShow struct {
+	Store         EgressPolicyStoreGetter
+	Mapper        egressPolicyMapper
+	EgressGuard   egressGuard
+	ErrorResponse errorResponse
+	Logger        lager.Logger
+}
+
+func (e *EgressPolicyShow) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	guid := req.URL.Query().Get(":id")
+
+	policies, err := e.Store.GetByGUID(guid)
+	if err != nil {
+		e.ErrorResponse.InternalServerError(e.Logger, w, err, "error getting egress policy")
+		return
+	}
+
+	if len(policies) == 0 {
+		e.ErrorResponse.NotFound(e.Logger, w, errors.New("egress policy not found"), "egress policy not found")
+		return
+	}
+
+	authorized, err := e.EgressGuard.CanManageEgressPolicies(policies, getTokenData(req))
+	if err != nil {
+		e.ErrorResponse.InternalServerError(e.Logger, w, err, "error checking egress policy access")
+		return
+	}
+	if !authorized {
+		err := errors.New("not authorized to manage egress policies")
+		e.ErrorResponse.Forbidden(e.Logger, w, err, "egress policies can only be managed by network admins, managers of the org of both the source and the destination, or space developers of the source when the destination is self service")
+		return
+	}
+
+	bytes, err := e.Mapper.AsBytesWithPopulatedDestinations(policies)
+	if err != nil {
+		e.ErrorResponse.InternalServerError(e.Logger, w, err, "error serializing response")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(bytes)
+}
@@ -0,0 +1,143 @@
+package handlers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"policy-server/handlers"
+	"policy-server/handlers/fakes"
+	storeFakes "policy-server/store/fakes"
+
+	"code.cloudfoundry.org/cf-networking-helpers/httperror"
+
+	"policy-server/uaa_client"
+
+	"policy-server/store"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EgressPolicyShow", func() {
+	var (
+		fakeEgressGuard   *fakes.EgressGuard
+		fakeMapper        *fakes.EgressPolicyMapper
+		fakeStore         *fakes.EgressPolicyStoreGetter
+		logger            *lagertest.TestLogger
+		fakeMetricsSender *storeFakes.MetricsSender
+		handler           *handlers.EgressPolicyShow
+		resp              *httptest.ResponseRecorder
+		request           *http.Request
+		responseBody      string
+		token             uaa_client.CheckTokenResponse
+		foundPolicies     []store.EgressPolicy
+	)
+
+	BeforeEach(func() {
+		fakeStore = &fakes.EgressPolicyStoreGetter{}
+		fakeMapper = &fakes.EgressPolicyMapper{}
+
+		fakeMetricsSender = &storeFakes.MetricsSender{}
+		errorResponse := &httperror.ErrorResponse{
+			MetricsSender: fakeMetricsSender,
+		}
+
+		logger = lagertest.NewTestLogger("test")
+
+		fakeEgressGuard = &fakes.EgressGuard{}
+		fakeEgressGuard.IsNetworkAdminReturns(true)
+		fakeEgressGuard.CanManageEgressPoliciesReturns(true, nil)
+
+		handler = &handlers.EgressPolicyShow{
+			EgressGuard:   fakeEgressGuard,
+			Store:         fakeStore,
+			Mapper:        fakeMapper,
+			ErrorResponse: errorResponse,
+			Logger:        logger,
+		}
+
+		foundPolicies = []store.EgressPolicy{
+			{
+				ID: "abc-123",
+			},
+		}
+		fakeStore.GetByGUIDReturns(foundPolicies, nil)
+
+		responseBody = `{
+			"total_egress_policies": 1,
+			"egress_policies": [
+				{
+					"id": "abc-123",
+					"source": { "id": "AN-APP-GUID", "type": "app" },
+					"destination": {"id": "A-DEST-GUID", "name": "a-dest", "protocol": "tcp", "ips": [{"start": "10.0.0.1", "end": "10.0.0.1"}] }
+				}
+			]
+		}`
+		fakeMapper.AsBytesWithPopulatedDestinationsReturns([]byte(responseBody), nil)
+
+		var err error
+		request, err = http.NewRequest("GET", "/networking/v1/external/egress_policies/abc-123", nil)
+		request.URL.RawQuery = ":id=abc-123"
+		Expect(err).NotTo(HaveOccurred())
+
+		resp = httptest.NewRecorder()
+
+		token = uaa_client.CheckTokenResponse{Scope: []string{"some-scope"}}
+	})
+
+	It("returns the requested egress policy", func() {
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+		Expect(fakeStore.GetByGUIDCallCount()).To(Equal(1))
+		Expect(fakeStore.GetByGUIDArgsForCall(0)).To(Equal([]string{"abc-123"}))
+
+		Expect(fakeMapper.AsBytesWithPopulatedDestinationsCallCount()).To(Equal(1))
+		Expect(fakeMapper.AsBytesWithPopulatedDestinationsArgsForCall(0)).To(Equal(foundPolicies))
+
+		Expect(resp.Code).To(Equal(http.StatusOK))
+		Expect(resp.Body.Bytes()).To(MatchJSON(responseBody))
+	})
+
+	It("returns not found when the policy doesn't exist", func() {
+		fakeStore.GetByGUIDReturns([]store.EgressPolicy{}, nil)
+
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(resp.Code).To(Equal(http.StatusNotFound))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "egress policy not found"}`))
+	})
+
+	It("returns an error when the store returns an error", func() {
+		fakeStore.GetByGUIDReturns(nil, errors.New("can't get"))
+
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error getting egress policy"}`))
+	})
+
+	It("returns an error the mapper cannot serialize the output", func() {
+		fakeMapper.AsBytesWithPopulatedDestinationsReturns(nil, errors.New("didn't go well"))
+
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error serializing response"}`))
+	})
+	It("returns forbidden when the user may not manage the policy", func() {
+		fakeEgressGuard.CanManageEgressPoliciesReturns(false, nil)
+
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		policies, tokenData := fakeEgressGuard.CanManageEgressPoliciesArgsForCall(0)
+		Expect(policies).To(Equal(foundPolicies))
+		Expect(tokenData).To(Equal(token))
+		Expect(resp.Code).To(Equal(http.StatusForbidden))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "egress policies can only be managed by network admins, managers of the org of both the source and the destination, or space developers of the source when the destination is self service"}`))
+	})
+
+	It("returns an error when the access check fails", func() {
+		fakeEgressGuard.CanManageEgressPoliciesReturns(false, errors.New("didn't go well"))
+
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error checking egress policy access"}`))
+	})
+})
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"policy-server/store"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+)
+
+//go:generate counterfeiter -o fakes/egress_policy_store_updater.go --fake-name EgressPolicyStoreUpdater . EgressPolicyStoreUpdater
+type EgressPolicyStoreUpdater interface {
+	GetByGUID(ids ...string) ([]store.EgressPolicy, error)
+	Update(policy store.EgressPolicy) (store.EgressPolicy, error)
+}
+
+type EgressPolicyUpdate struct {
+	Store         EgressPolicyStoreUpdater
+	Mapper        egressPolicyMapper
+	EgressGuard   egressGuard
+	ErrorResponse errorResponse
+	Logger        lager.Logger
+}
+
+func (e *EgressPolicyUpdate) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	guid := req.URL.Query().Get(":id")
+
+	requestBytes, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		e.ErrorResponse.InternalServerError(e.Logger, w, err, "error reading request")
+		return
+	}
+
+	policies, err := e.Mapper.AsStoreEgressPolicy(requestBytes)
+	if err != nil {
+		e.ErrorResponse.BadRequest(e.Logger, w, err, fmt.Sprintf("error parsing egress policies: %s", err))
+		return
+	}
+
+	if len(policies) != 1 {
+		e.ErrorResponse.BadRequest(e.Logger, w, nil, "exactly one egress policy must be provided")
+		return
+	}
+
+	policy := policies[0]
+	policy.ID = guid
+
+	tokenData := getTokenData(req)
+	policiesToAuthorize := []store.EgressPolicy{policy}
+	if !e.EgressGuard.IsNetworkAdmin(tokenData) {
+		existingPolicies, err := e.Store.GetByGUID(guid)
+		if err != nil {
+			e.ErrorResponse.InternalServerError(e.Logger, w, err, "error getting egress policy")
+			return
+		}
+		policiesToAuthorize = append(existingPolicies, policy)
+	}
+
+	authorized, err := e.EgressGuard.CanManageEgressPolicies(policiesToAuthorize, tokenData)
+	if err != nil {
+		e.ErrorResponse.InternalServerError(e.Logger, w, err, "error checking egress policy access")
+		return
+	}
+	if !authorized {
+		err := errors.New("not authorized to manage egress policies")
+		e.ErrorResponse.Forbidden(e.Logger, w, err, "egress policies can only be managed by network admins, managers of the org of both the source and the destination, or space developers of the source when the destination is self service")
+		return
+	}
+
+	updatedPolicy, err := e.Store.Update(policy)
+	if err != nil {
+		if strings.Contains(err.Error(), "egress policy GUID not found") {
+			e.ErrorResponse.NotFound(e.Logger, w, err, fmt.Sprintf("error updating egress policy: %s", err))
+			return
+		}
+		if strings.Contains(err.Error(), "policy already exists") {
+			e.ErrorResponse.BadRequest(e.Logger, w, err, fmt.Sprintf("error updating egress policy: %s", err))
+			return
+		}
+		e.ErrorResponse.InternalServerError(e.Logger, w, err, "error updating egress policy")
+		return
+	}
+
+	bytes, err := e.Mapper.AsBytes([]store.EgressPolicy{updatedPolicy})
+	if err != nil {
+		e.ErrorResponse.InternalServerError(e.Logger, w, err, "error serializing response")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(bytes)
+}
@@ -0,0 +1,220 @@
+package handlers_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"policy-server/handlers"
+	"policy-server/handlers/fakes"
+	storeFakes "policy-server/store/fakes"
+
+	"code.cloudfoundry.org/cf-networking-helpers/httperror"
+
+	"policy-server/uaa_client"
+
+	"policy-server/store"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EgressPolicyUpdate", func() {
+	var (
+		fakeEgressGuard   *fakes.EgressGuard
+		fakeMapper        *fakes.EgressPolicyMapper
+		fakeStore         *fakes.EgressPolicyStoreUpdater
+		logger            *lagertest.TestLogger
+		fakeMetricsSender *storeFakes.MetricsSender
+		handler           *handlers.EgressPolicyUpdate
+		resp              *httptest.ResponseRecorder
+		request           *http.Request
+		requestBody       string
+		responseBody      string
+		token             uaa_client.CheckTokenResponse
+		parsedPolicy      store.EgressPolicy
+		updatedPolicy     store.EgressPolicy
+	)
+
+	BeforeEach(func() {
+		fakeStore = &fakes.EgressPolicyStoreUpdater{}
+		fakeMapper = &fakes.EgressPolicyMapper{}
+
+		fakeMetricsSender = &storeFakes.MetricsSender{}
+		errorResponse := &httperror.ErrorResponse{
+			MetricsSender: fakeMetricsSender,
+		}
+
+		logger = lagertest.NewTestLogger("test")
+
+		fakeEgressGuard = &fakes.EgressGuard{}
+		fakeEgressGuard.IsNetworkAdminReturns(true)
+		fakeEgressGuard.CanManageEgressPoliciesReturns(true, nil)
+
+		handler = &handlers.EgressPolicyUpdate{
+			EgressGuard:   fakeEgressGuard,
+			Store:         fakeStore,
+			Mapper:        fakeMapper,
+			ErrorResponse: errorResponse,
+			Logger:        logger,
+		}
+
+		parsedPolicy = store.EgressPolicy{
+			Source:      store.EgressSource{ID: "AN-APP-GUID", Type: "app"},
+			Destination: store.EgressDestination{GUID: "A-DEST-GUID"},
+		}
+		fakeMapper.AsStoreEgressPolicyReturns([]store.EgressPolicy{parsedPolicy}, nil)
+
+		updatedPolicy = parsedPolicy
+		updatedPolicy.ID = "abc-123"
+		fakeStore.UpdateReturns(updatedPolicy, nil)
+
+		responseBody = `{
+			"total_egress_policies": 1,
+			"egress_policies": [
+				{
+					"id": "abc-123",
+					"source": { "id": "AN-APP-GUID", "type": "app" },
+					"destination": {"id": "A-DEST-GUID" }
+				}
+			]
+		}`
+		fakeMapper.AsBytesReturns([]byte(responseBody), nil)
+
+		requestBody = `{
+			"egress_policies": [
+				{
+					"source": { "id": "AN-APP-GUID", "type": "app" },
+					"destination": {"id": "A-DEST-GUID" }
+				}
+			]
+		}`
+
+		var err error
+		request, err = http.NewRequest("PUT", "/networking/v1/external/egress_policies/abc-123", bytes.NewBuffer([]byte(requestBody)))
+		request.URL.RawQuery = ":id=abc-123"
+		Expect(err).NotTo(HaveOccurred())
+
+		resp = httptest.NewRecorder()
+
+		token = uaa_client.CheckTokenResponse{Scope: []string{"some-scope"}}
+	})
+
+	It("updates the egress policy identified by the path", func() {
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+		Expect(fakeMapper.AsStoreEgressPolicyCallCount()).To(Equal(1))
+		Expect(fakeMapper.AsStoreEgressPolicyArgsForCall(0)).To(MatchJSON(requestBody))
+
+		Expect(fakeStore.UpdateCallCount()).To(Equal(1))
+		Expect(fakeStore.UpdateArgsForCall(0)).To(Equal(updatedPolicy))
+
+		Expect(fakeMapper.AsBytesCallCount()).To(Equal(1))
+		Expect(fakeMapper.AsBytesArgsForCall(0)).To(Equal([]store.EgressPolicy{updatedPolicy}))
+
+		Expect(resp.Code).To(Equal(http.StatusOK))
+		body, err := ioutil.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(body)).To(Equal(responseBody))
+	})
+
+	It("returns a bad request when the request cannot be parsed", func() {
+		fakeMapper.AsStoreEgressPolicyReturns(nil, errors.New("banana"))
+
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error parsing egress policies: banana"}`))
+		Expect(fakeStore.UpdateCallCount()).To(Equal(0))
+	})
+
+	It("returns a bad request when more than one policy is provided", func() {
+		fakeMapper.AsStoreEgressPolicyReturns([]store.EgressPolicy{parsedPolicy, parsedPolicy}, nil)
+
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "exactly one egress policy must be provided"}`))
+		Expect(fakeStore.UpdateCallCount()).To(Equal(0))
+	})
+
+	It("returns not found when the policy doesn't exist", func() {
+		fakeStore.UpdateReturns(store.EgressPolicy{}, errors.New("egress policy GUID not found: abc-123"))
+
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(resp.Code).To(Equal(http.StatusNotFound))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error updating egress policy: egress policy GUID not found: abc-123"}`))
+	})
+
+	It("returns a bad request when the update would duplicate a policy", func() {
+		fakeStore.UpdateReturns(store.EgressPolicy{}, errors.New("failed to update egress policy: policy already exists for source 'AN-APP-GUID' and destination 'A-DEST-GUID'"))
+
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error updating egress policy: failed to update egress policy: policy already exists for source 'AN-APP-GUID' and destination 'A-DEST-GUID'"}`))
+	})
+
+	It("returns an error when the store returns an error", func() {
+		fakeStore.UpdateReturns(store.EgressPolicy{}, errors.New("can't update"))
+
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error updating egress policy"}`))
+	})
+
+	It("returns an error the mapper cannot serialize the output", func() {
+		fakeMapper.AsBytesReturns(nil, errors.New("didn't go well"))
+
+		MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+		Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error serializing response"}`))
+	})
+	Context("when the user is not a network admin", func() {
+		var existingPolicy store.EgressPolicy
+
+		BeforeEach(func() {
+			existingPolicy = store.EgressPolicy{
+				ID:          "abc-123",
+				Source:      store.EgressSource{ID: "OTHER-APP-GUID", Type: "app"},
+				Destination: store.EgressDestination{GUID: "OTHER-DEST-GUID"},
+			}
+			fakeEgressGuard.IsNetworkAdminReturns(false)
+			fakeStore.GetByGUIDReturns([]store.EgressPolicy{existingPolicy}, nil)
+		})
+
+		It("checks access to both the existing and the updated policy", func() {
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(fakeStore.GetByGUIDArgsForCall(0)).To(Equal([]string{"abc-123"}))
+			policies, tokenData := fakeEgressGuard.CanManageEgressPoliciesArgsForCall(0)
+			Expect(policies).To(Equal([]store.EgressPolicy{existingPolicy, updatedPolicy}))
+			Expect(tokenData).To(Equal(token))
+		})
+
+		It("returns forbidden when the user may not manage the policies", func() {
+			fakeEgressGuard.CanManageEgressPoliciesReturns(false, nil)
+
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+			Expect(fakeStore.UpdateCallCount()).To(Equal(0))
+			Expect(resp.Code).To(Equal(http.StatusForbidden))
+			Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "egress policies can only be managed by network admins, managers of the org of both the source and the destination, or space developers of the source when the destination is self service"}`))
+		})
+
+		It("returns an error when the existing policy cannot be fetched", func() {
+			fakeStore.GetByGUIDReturns(nil, errors.New("banana"))
+
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error getting egress policy"}`))
+		})
+
+		It("returns an error when the access check fails", func() {
+			fakeEgressGuard.CanManageEgressPoliciesReturns(false, errors.New("banana"))
+
+			MakeRequestWithLoggerAndAuth(handler.ServeHTTP, resp, request, logger, token)
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			Expect(resp.Body.Bytes()).To(MatchJSON(`{"error": "error checking egress policy access"}`))
+		})
+	})
+})
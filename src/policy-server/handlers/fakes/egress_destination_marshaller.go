@@ -34,6 +34,20 @@ type EgressDestinationMarshaller struct {
 		result1 []store.EgressDestination
 		result2 error
 	}
+	AsBytesWithWarningsStub        func(egressDestinations []store.EgressDestination, warnings []string) ([]byte, error)
+	asBytesWithWarningsMutex       sync.RWMutex
+	asBytesWithWarningsArgsForCall []struct {
+		egressDestinations []store.EgressDestination
+		warnings           []string
+	}
+	asBytesWithWarningsReturns struct {
+		result1 []byte
+		result2 error
+	}
+	asBytesWithWarningsReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -150,6 +164,58 @@ func (fake *EgressDestinationMarshaller) AsEgressDestinationsReturnsOnCall(i int
 	}{result1, result2}
 }
 
+func (fake *EgressDestinationMarshaller) AsBytesWithWarnings(egressDestinations []store.EgressDestination, warnings []string) ([]byte, error) {
+	fake.asBytesWithWarningsMutex.Lock()
+	ret, specificReturn := fake.asBytesWithWarningsReturnsOnCall[len(fake.asBytesWithWarningsArgsForCall)]
+	fake.asBytesWithWarningsArgsForCall = append(fake.asBytesWithWarningsArgsForCall, struct {
+		egressDestinations []store.EgressDestination
+		warnings           []string
+	}{egressDestinations, warnings})
+	fake.recordInvocation("AsBytesWithWarnings", []interface{}{egressDestinations, warnings})
+	fake.asBytesWithWarningsMutex.Unlock()
+	if fake.AsBytesWithWarningsStub != nil {
+		return fake.AsBytesWithWarningsStub(egressDestinations, warnings)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.asBytesWithWarningsReturns.result1, fake.asBytesWithWarningsReturns.result2
+}
+
+func (fake *EgressDestinationMarshaller) AsBytesWithWarningsCallCount() int {
+	fake.asBytesWithWarningsMutex.RLock()
+	defer fake.asBytesWithWarningsMutex.RUnlock()
+	return len(fake.asBytesWithWarningsArgsForCall)
+}
+
+func (fake *EgressDestinationMarshaller) AsBytesWithWarningsArgsForCall(i int) ([]store.EgressDestination, []string) {
+	fake.asBytesWithWarningsMutex.RLock()
+	defer fake.asBytesWithWarningsMutex.RUnlock()
+	return fake.asBytesWithWarningsArgsForCall[i].egressDestinations, fake.asBytesWithWarningsArgsForCall[i].warnings
+}
+
+func (fake *EgressDestinationMarshaller) AsBytesWithWarningsReturns(result1 []byte, result2 error) {
+	fake.AsBytesWithWarningsStub = nil
+	fake.asBytesWithWarningsReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *EgressDestinationMarshaller) AsBytesWithWarningsReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.AsBytesWithWarningsStub = nil
+	if fake.asBytesWithWarningsReturnsOnCall == nil {
+		fake.asBytesWithWarningsReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.asBytesWithWarningsReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *EgressDestinationMarshaller) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -157,6 +223,8 @@ func (fake *EgressDestinationMarshaller) Invocations() map[string][][]interface{
 	defer fake.asBytesMutex.RUnlock()
 	fake.asEgressDestinationsMutex.RLock()
 	defer fake.asEgressDestinationsMutex.RUnlock()
+	fake.asBytesWithWarningsMutex.RLock()
+	defer fake.asBytesWithWarningsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
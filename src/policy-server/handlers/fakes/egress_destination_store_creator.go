@@ -8,84 +8,102 @@ import (
 )
 
 type EgressDestinationStoreCreator struct {
-	CreateStub        func([]store.EgressDestination) ([]store.EgressDestination, error)
-	createMutex       sync.RWMutex
-	createArgsForCall []struct {
+	CreateWithOverlapsStub        func([]store.EgressDestination) ([]store.EgressDestination, []store.DestinationOverlap, error)
+	createWithOverlapsMutex       sync.RWMutex
+	createWithOverlapsArgsForCall []struct {
 		arg1 []store.EgressDestination
 	}
-	createReturns struct {
+	createWithOverlapsReturns struct {
 		result1 []store.EgressDestination
-		result2 error
+		result2 []store.DestinationOverlap
+		result3 error
 	}
-	createReturnsOnCall map[int]struct {
+	createWithOverlapsReturnsOnCall map[int]struct {
 		result1 []store.EgressDestination
-		result2 error
+		result2 []store.DestinationOverlap
+		result3 error
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *EgressDestinationStoreCreator) Create(arg1 []store.EgressDestination) ([]store.EgressDestination, error) {
+func (fake *EgressDestinationStoreCreator) CreateWithOverlaps(arg1 []store.EgressDestination) ([]store.EgressDestination, []store.DestinationOverlap, error) {
 	var arg1Copy []store.EgressDestination
 	if arg1 != nil {
 		arg1Copy = make([]store.EgressDestination, len(arg1))
 		copy(arg1Copy, arg1)
 	}
-	fake.createMutex.Lock()
-	ret, specificReturn := fake.createReturnsOnCall[len(fake.createArgsForCall)]
-	fake.createArgsForCall = append(fake.createArgsForCall, struct {
+	fake.createWithOverlapsMutex.Lock()
+	ret, specificReturn := fake.createWithOverlapsReturnsOnCall[len(fake.createWithOverlapsArgsForCall)]
+	fake.createWithOverlapsArgsForCall = append(fake.createWithOverlapsArgsForCall, struct {
 		arg1 []store.EgressDestination
 	}{arg1Copy})
-	fake.recordInvocation("Create", []interface{}{arg1Copy})
-	fake.createMutex.Unlock()
-	if fake.CreateStub != nil {
-		return fake.CreateStub(arg1)
+	stub := fake.CreateWithOverlapsStub
+	fakeReturns := fake.createWithOverlapsReturns
+	fake.recordInvocation("CreateWithOverlaps", []interface{}{arg1Copy})
+	fake.createWithOverlapsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
-		return ret.result1, ret.result2
+		return ret.result1, ret.result2, ret.result3
 	}
-	return fake.createReturns.result1, fake.createReturns.result2
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
 }
 
-func (fake *EgressDestinationStoreCreator) CreateCallCount() int {
-	fake.createMutex.RLock()
-	defer fake.createMutex.RUnlock()
-	return len(fake.createArgsForCall)
+func (fake *EgressDestinationStoreCreator) CreateWithOverlapsCallCount() int {
+	fake.createWithOverlapsMutex.RLock()
+	defer fake.createWithOverlapsMutex.RUnlock()
+	return len(fake.createWithOverlapsArgsForCall)
 }
 
-func (fake *EgressDestinationStoreCreator) CreateArgsForCall(i int) []store.EgressDestination {
-	fake.createMutex.RLock()
-	defer fake.createMutex.RUnlock()
-	return fake.createArgsForCall[i].arg1
+func (fake *EgressDestinationStoreCreator) CreateWithOverlapsCalls(stub func([]store.EgressDestination) ([]store.EgressDestination, []store.DestinationOverlap, error)) {
+	fake.createWithOverlapsMutex.Lock()
+	defer fake.createWithOverlapsMutex.Unlock()
+	fake.CreateWithOverlapsStub = stub
 }
 
-func (fake *EgressDestinationStoreCreator) CreateReturns(result1 []store.EgressDestination, result2 error) {
-	fake.CreateStub = nil
-	fake.createReturns = struct {
+func (fake *EgressDestinationStoreCreator) CreateWithOverlapsArgsForCall(i int) []store.EgressDestination {
+	fake.createWithOverlapsMutex.RLock()
+	defer fake.createWithOverlapsMutex.RUnlock()
+	argsForCall := fake.createWithOverlapsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *EgressDestinationStoreCreator) CreateWithOverlapsReturns(result1 []store.EgressDestination, result2 []store.DestinationOverlap, result3 error) {
+	fake.createWithOverlapsMutex.Lock()
+	defer fake.createWithOverlapsMutex.Unlock()
+	fake.CreateWithOverlapsStub = nil
+	fake.createWithOverlapsReturns = struct {
 		result1 []store.EgressDestination
-		result2 error
-	}{result1, result2}
+		result2 []store.DestinationOverlap
+		result3 error
+	}{result1, result2, result3}
 }
 
-func (fake *EgressDestinationStoreCreator) CreateReturnsOnCall(i int, result1 []store.EgressDestination, result2 error) {
-	fake.CreateStub = nil
-	if fake.createReturnsOnCall == nil {
-		fake.createReturnsOnCall = make(map[int]struct {
+func (fake *EgressDestinationStoreCreator) CreateWithOverlapsReturnsOnCall(i int, result1 []store.EgressDestination, result2 []store.DestinationOverlap, result3 error) {
+	fake.createWithOverlapsMutex.Lock()
+	defer fake.createWithOverlapsMutex.Unlock()
+	fake.CreateWithOverlapsStub = nil
+	if fake.createWithOverlapsReturnsOnCall == nil {
+		fake.createWithOverlapsReturnsOnCall = make(map[int]struct {
 			result1 []store.EgressDestination
-			result2 error
+			result2 []store.DestinationOverlap
+			result3 error
 		})
 	}
-	fake.createReturnsOnCall[i] = struct {
+	fake.createWithOverlapsReturnsOnCall[i] = struct {
 		result1 []store.EgressDestination
-		result2 error
-	}{result1, result2}
+		result2 []store.DestinationOverlap
+		result3 error
+	}{result1, result2, result3}
 }
 
 func (fake *EgressDestinationStoreCreator) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.createMutex.RLock()
-	defer fake.createMutex.RUnlock()
+	fake.createWithOverlapsMutex.RLock()
+	defer fake.createWithOverlapsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -21,6 +21,19 @@ type EgressDestinationStoreDeleter struct {
 		result1 store.EgressDestination
 		result2 error
 	}
+	GetByGUIDStub        func(...string) ([]store.EgressDestination, error)
+	getByGUIDMutex       sync.RWMutex
+	getByGUIDArgsForCall []struct {
+		arg1 []string
+	}
+	getByGUIDReturns struct {
+		result1 []store.EgressDestination
+		result2 error
+	}
+	getByGUIDReturnsOnCall map[int]struct {
+		result1 []store.EgressDestination
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -31,15 +44,17 @@ func (fake *EgressDestinationStoreDeleter) Delete(arg1 string) (store.EgressDest
 	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.DeleteStub
+	fakeReturns := fake.deleteReturns
 	fake.recordInvocation("Delete", []interface{}{arg1})
 	fake.deleteMutex.Unlock()
-	if fake.DeleteStub != nil {
-		return fake.DeleteStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	return fake.deleteReturns.result1, fake.deleteReturns.result2
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *EgressDestinationStoreDeleter) DeleteCallCount() int {
@@ -48,13 +63,22 @@ func (fake *EgressDestinationStoreDeleter) DeleteCallCount() int {
 	return len(fake.deleteArgsForCall)
 }
 
+func (fake *EgressDestinationStoreDeleter) DeleteCalls(stub func(string) (store.EgressDestination, error)) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
+	fake.DeleteStub = stub
+}
+
 func (fake *EgressDestinationStoreDeleter) DeleteArgsForCall(i int) string {
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
-	return fake.deleteArgsForCall[i].arg1
+	argsForCall := fake.deleteArgsForCall[i]
+	return argsForCall.arg1
 }
 
 func (fake *EgressDestinationStoreDeleter) DeleteReturns(result1 store.EgressDestination, result2 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
 	fake.DeleteStub = nil
 	fake.deleteReturns = struct {
 		result1 store.EgressDestination
@@ -63,6 +87,8 @@ func (fake *EgressDestinationStoreDeleter) DeleteReturns(result1 store.EgressDes
 }
 
 func (fake *EgressDestinationStoreDeleter) DeleteReturnsOnCall(i int, result1 store.EgressDestination, result2 error) {
+	fake.deleteMutex.Lock()
+	defer fake.deleteMutex.Unlock()
 	fake.DeleteStub = nil
 	if fake.deleteReturnsOnCall == nil {
 		fake.deleteReturnsOnCall = make(map[int]struct {
@@ -76,11 +102,77 @@ func (fake *EgressDestinationStoreDeleter) DeleteReturnsOnCall(i int, result1 st
 	}{result1, result2}
 }
 
+func (fake *EgressDestinationStoreDeleter) GetByGUID(arg1 ...string) ([]store.EgressDestination, error) {
+	fake.getByGUIDMutex.Lock()
+	ret, specificReturn := fake.getByGUIDReturnsOnCall[len(fake.getByGUIDArgsForCall)]
+	fake.getByGUIDArgsForCall = append(fake.getByGUIDArgsForCall, struct {
+		arg1 []string
+	}{arg1})
+	stub := fake.GetByGUIDStub
+	fakeReturns := fake.getByGUIDReturns
+	fake.recordInvocation("GetByGUID", []interface{}{arg1})
+	fake.getByGUIDMutex.Unlock()
+	if stub != nil {
+		return stub(arg1...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *EgressDestinationStoreDeleter) GetByGUIDCallCount() int {
+	fake.getByGUIDMutex.RLock()
+	defer fake.getByGUIDMutex.RUnlock()
+	return len(fake.getByGUIDArgsForCall)
+}
+
+func (fake *EgressDestinationStoreDeleter) GetByGUIDCalls(stub func(...string) ([]store.EgressDestination, error)) {
+	fake.getByGUIDMutex.Lock()
+	defer fake.getByGUIDMutex.Unlock()
+	fake.GetByGUIDStub = stub
+}
+
+func (fake *EgressDestinationStoreDeleter) GetByGUIDArgsForCall(i int) []string {
+	fake.getByGUIDMutex.RLock()
+	defer fake.getByGUIDMutex.RUnlock()
+	argsForCall := fake.getByGUIDArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *EgressDestinationStoreDeleter) GetByGUIDReturns(result1 []store.EgressDestination, result2 error) {
+	fake.getByGUIDMutex.Lock()
+	defer fake.getByGUIDMutex.Unlock()
+	fake.GetByGUIDStub = nil
+	fake.getByGUIDReturns = struct {
+		result1 []store.EgressDestination
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *EgressDestinationStoreDeleter) GetByGUIDReturnsOnCall(i int, result1 []store.EgressDestination, result2 error) {
+	fake.getByGUIDMutex.Lock()
+	defer fake.getByGUIDMutex.Unlock()
+	fake.GetByGUIDStub = nil
+	if fake.getByGUIDReturnsOnCall == nil {
+		fake.getByGUIDReturnsOnCall = make(map[int]struct {
+			result1 []store.EgressDestination
+			result2 error
+		})
+	}
+	fake.getByGUIDReturnsOnCall[i] = struct {
+		result1 []store.EgressDestination
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *EgressDestinationStoreDeleter) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
+	fake.getByGUIDMutex.RLock()
+	defer fake.getByGUIDMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -8,84 +8,181 @@ import (
 )
 
 type EgressDestinationStoreUpdater struct {
-	UpdateStub        func([]store.EgressDestination) ([]store.EgressDestination, error)
-	updateMutex       sync.RWMutex
-	updateArgsForCall []struct {
-		arg1 []store.EgressDestination
+	GetByGUIDStub        func(...string) ([]store.EgressDestination, error)
+	getByGUIDMutex       sync.RWMutex
+	getByGUIDArgsForCall []struct {
+		arg1 []string
 	}
-	updateReturns struct {
+	getByGUIDReturns struct {
 		result1 []store.EgressDestination
 		result2 error
 	}
-	updateReturnsOnCall map[int]struct {
+	getByGUIDReturnsOnCall map[int]struct {
 		result1 []store.EgressDestination
 		result2 error
 	}
+	UpdateWithOverlapsStub        func([]store.EgressDestination) ([]store.EgressDestination, []store.DestinationOverlap, error)
+	updateWithOverlapsMutex       sync.RWMutex
+	updateWithOverlapsArgsForCall []struct {
+		arg1 []store.EgressDestination
+	}
+	updateWithOverlapsReturns struct {
+		result1 []store.EgressDestination
+		result2 []store.DestinationOverlap
+		result3 error
+	}
+	updateWithOverlapsReturnsOnCall map[int]struct {
+		result1 []store.EgressDestination
+		result2 []store.DestinationOverlap
+		result3 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *EgressDestinationStoreUpdater) Update(arg1 []store.EgressDestination) ([]store.EgressDestination, error) {
+func (fake *EgressDestinationStoreUpdater) GetByGUID(arg1 ...string) ([]store.EgressDestination, error) {
+	fake.getByGUIDMutex.Lock()
+	ret, specificReturn := fake.getByGUIDReturnsOnCall[len(fake.getByGUIDArgsForCall)]
+	fake.getByGUIDArgsForCall = append(fake.getByGUIDArgsForCall, struct {
+		arg1 []string
+	}{arg1})
+	stub := fake.GetByGUIDStub
+	fakeReturns := fake.getByGUIDReturns
+	fake.recordInvocation("GetByGUID", []interface{}{arg1})
+	fake.getByGUIDMutex.Unlock()
+	if stub != nil {
+		return stub(arg1...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *EgressDestinationStoreUpdater) GetByGUIDCallCount() int {
+	fake.getByGUIDMutex.RLock()
+	defer fake.getByGUIDMutex.RUnlock()
+	return len(fake.getByGUIDArgsForCall)
+}
+
+func (fake *EgressDestinationStoreUpdater) GetByGUIDCalls(stub func(...string) ([]store.EgressDestination, error)) {
+	fake.getByGUIDMutex.Lock()
+	defer fake.getByGUIDMutex.Unlock()
+	fake.GetByGUIDStub = stub
+}
+
+func (fake *EgressDestinationStoreUpdater) GetByGUIDArgsForCall(i int) []string {
+	fake.getByGUIDMutex.RLock()
+	defer fake.getByGUIDMutex.RUnlock()
+	argsForCall := fake.getByGUIDArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *EgressDestinationStoreUpdater) GetByGUIDReturns(result1 []store.EgressDestination, result2 error) {
+	fake.getByGUIDMutex.Lock()
+	defer fake.getByGUIDMutex.Unlock()
+	fake.GetByGUIDStub = nil
+	fake.getByGUIDReturns = struct {
+		result1 []store.EgressDestination
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *EgressDestinationStoreUpdater) GetByGUIDReturnsOnCall(i int, result1 []store.EgressDestination, result2 error) {
+	fake.getByGUIDMutex.Lock()
+	defer fake.getByGUIDMutex.Unlock()
+	fake.GetByGUIDStub = nil
+	if fake.getByGUIDReturnsOnCall == nil {
+		fake.getByGUIDReturnsOnCall = make(map[int]struct {
+			result1 []store.EgressDestination
+			result2 error
+		})
+	}
+	fake.getByGUIDReturnsOnCall[i] = struct {
+		result1 []store.EgressDestination
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *EgressDestinationStoreUpdater) UpdateWithOverlaps(arg1 []store.EgressDestination) ([]store.EgressDestination, []store.DestinationOverlap, error) {
 	var arg1Copy []store.EgressDestination
 	if arg1 != nil {
 		arg1Copy = make([]store.EgressDestination, len(arg1))
 		copy(arg1Copy, arg1)
 	}
-	fake.updateMutex.Lock()
-	ret, specificReturn := fake.updateReturnsOnCall[len(fake.updateArgsForCall)]
-	fake.updateArgsForCall = append(fake.updateArgsForCall, struct {
+	fake.updateWithOverlapsMutex.Lock()
+	ret, specificReturn := fake.updateWithOverlapsReturnsOnCall[len(fake.updateWithOverlapsArgsForCall)]
+	fake.updateWithOverlapsArgsForCall = append(fake.updateWithOverlapsArgsForCall, struct {
 		arg1 []store.EgressDestination
 	}{arg1Copy})
-	fake.recordInvocation("Update", []interface{}{arg1Copy})
-	fake.updateMutex.Unlock()
-	if fake.UpdateStub != nil {
-		return fake.UpdateStub(arg1)
+	stub := fake.UpdateWithOverlapsStub
+	fakeReturns := fake.updateWithOverlapsReturns
+	fake.recordInvocation("UpdateWithOverlaps", []interface{}{arg1Copy})
+	fake.updateWithOverlapsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
-		return ret.result1, ret.result2
+		return ret.result1, ret.result2, ret.result3
 	}
-	return fake.updateReturns.result1, fake.updateReturns.result2
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *EgressDestinationStoreUpdater) UpdateWithOverlapsCallCount() int {
+	fake.updateWithOverlapsMutex.RLock()
+	defer fake.updateWithOverlapsMutex.RUnlock()
+	return len(fake.updateWithOverlapsArgsForCall)
 }
 
-func (fake *EgressDestinationStoreUpdater) UpdateCallCount() int {
-	fake.updateMutex.RLock()
-	defer fake.updateMutex.RUnlock()
-	return len(fake.updateArgsForCall)
+func (fake *EgressDestinationStoreUpdater) UpdateWithOverlapsCalls(stub func([]store.EgressDestination) ([]store.EgressDestination, []store.DestinationOverlap, error)) {
+	fake.updateWithOverlapsMutex.Lock()
+	defer fake.updateWithOverlapsMutex.Unlock()
+	fake.UpdateWithOverlapsStub = stub
 }
 
-func (fake *EgressDestinationStoreUpdater) UpdateArgsForCall(i int) []store.EgressDestination {
-	fake.updateMutex.RLock()
-	defer fake.updateMutex.RUnlock()
-	return fake.updateArgsForCall[i].arg1
+func (fake *EgressDestinationStoreUpdater) UpdateWithOverlapsArgsForCall(i int) []store.EgressDestination {
+	fake.updateWithOverlapsMutex.RLock()
+	defer fake.updateWithOverlapsMutex.RUnlock()
+	argsForCall := fake.updateWithOverlapsArgsForCall[i]
+	return argsForCall.arg1
 }
 
-func (fake *EgressDestinationStoreUpdater) UpdateReturns(result1 []store.EgressDestination, result2 error) {
-	fake.UpdateStub = nil
-	fake.updateReturns = struct {
+func (fake *EgressDestinationStoreUpdater) UpdateWithOverlapsReturns(result1 []store.EgressDestination, result2 []store.DestinationOverlap, result3 error) {
+	fake.updateWithOverlapsMutex.Lock()
+	defer fake.updateWithOverlapsMutex.Unlock()
+	fake.UpdateWithOverlapsStub = nil
+	fake.updateWithOverlapsReturns = struct {
 		result1 []store.EgressDestination
-		result2 error
-	}{result1, result2}
+		result2 []store.DestinationOverlap
+		result3 error
+	}{result1, result2, result3}
 }
 
-func (fake *EgressDestinationStoreUpdater) UpdateReturnsOnCall(i int, result1 []store.EgressDestination, result2 error) {
-	fake.UpdateStub = nil
-	if fake.updateReturnsOnCall == nil {
-		fake.updateReturnsOnCall = make(map[int]struct {
+func (fake *EgressDestinationStoreUpdater) UpdateWithOverlapsReturnsOnCall(i int, result1 []store.EgressDestination, result2 []store.DestinationOverlap, result3 error) {
+	fake.updateWithOverlapsMutex.Lock()
+	defer fake.updateWithOverlapsMutex.Unlock()
+	fake.UpdateWithOverlapsStub = nil
+	if fake.updateWithOverlapsReturnsOnCall == nil {
+		fake.updateWithOverlapsReturnsOnCall = make(map[int]struct {
 			result1 []store.EgressDestination
-			result2 error
+			result2 []store.DestinationOverlap
+			result3 error
 		})
 	}
-	fake.updateReturnsOnCall[i] = struct {
+	fake.updateWithOverlapsReturnsOnCall[i] = struct {
 		result1 []store.EgressDestination
-		result2 error
-	}{result1, result2}
+		result2 []store.DestinationOverlap
+		result3 error
+	}{result1, result2, result3}
 }
 
 func (fake *EgressDestinationStoreUpdater) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.updateMutex.RLock()
-	defer fake.updateMutex.RUnlock()
+	fake.getByGUIDMutex.RLock()
+	defer fake.getByGUIDMutex.RUnlock()
+	fake.updateWithOverlapsMutex.RLock()
+	defer fake.updateWithOverlapsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
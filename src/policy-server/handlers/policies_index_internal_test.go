@@ -120,6 +120,28 @@ var _ = Describe("PoliciesIndexInternal", func() {
 		Expect(resp.Body.Bytes()).To(Equal(expectedResponseBody))
 	})
 
+	Context("when org policies are requested", func() {
+		BeforeEach(func() {
+			fakeEgressStore.GetBySourceGuidsReturns([]store.EgressPolicy{{
+				Source:      store.EgressSource{ID: "some-org-guid", Type: "org"},
+				Destination: store.EgressDestination{GUID: "some-destination-guid"},
+			}}, nil)
+		})
+
+		It("matches them by the org guids in the filter, without expanding them", func() {
+			request, err := http.NewRequest("GET", "/networking/v0/internal/policies?id=some-app-guid,some-space-guid,some-org-guid", nil)
+			Expect(err).NotTo(HaveOccurred())
+			MakeRequestWithLogger(handler.ServeHTTP, resp, request, logger)
+
+			Expect(fakeEgressStore.GetBySourceGuidsArgsForCall(0)).To(Equal([]string{"some-app-guid", "some-space-guid", "some-org-guid"}))
+			_, passedEgressPolicies := fakePolicyCollectionWriter.AsBytesArgsForCall(0)
+			Expect(passedEgressPolicies).To(Equal([]store.EgressPolicy{{
+				Source:      store.EgressSource{ID: "some-org-guid", Type: "org"},
+				Destination: store.EgressDestination{GUID: "some-destination-guid"},
+			}}))
+		})
+	})
+
 	Context("when enforce experimental dynamic egress policies is off", func() {
 		BeforeEach(func() {
 			handler = &handlers.PoliciesIndexInternal{
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+const maxIdleBuckets = 1000
+
+type metricsSender interface {
+	IncrementCounter(string)
+}
+
+type RateLimiter struct {
+	Name               string
+	RequestsPerSecond  float64
+	Burst              int
+	ExemptNetworkAdmin bool
+	MetricsSender      metricsSender
+	Clock              clock.Clock
+
+	mutex   sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+func NewRateLimiter(name string, requestsPerSecond float64, burst int, exemptNetworkAdmin bool,
+	metricsSender metricsSender, clock clock.Clock) *RateLimiter {
+	if burst < 1 {
+		burst = int(math.Max(1, math.Ceil(requestsPerSecond)))
+	}
+	return &RateLimiter{
+		Name:               name,
+		RequestsPerSecond:  requestsPerSecond,
+		Burst:              burst,
+		ExemptNetworkAdmin: exemptNetworkAdmin,
+		MetricsSender:      metricsSender,
+		Clock:              clock,
+		buckets:            map[string]*tokenBucket{},
+	}
+}
+
+// Wrap must be applied inside the Authenticator so that the token data is
+// available on the request context.
+func (r *RateLimiter) Wrap(handle http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		tokenData := getTokenData(req)
+		if r.ExemptNetworkAdmin && isNetworkAdmin(tokenData.Scope) {
+			handle.ServeHTTP(w, req)
+			return
+		}
+
+		key := rateLimitKey(tokenData.Subject, tokenData.ClientID, req.RemoteAddr)
+		allowed, retryAfter := r.take(key)
+		if !allowed {
+			logger := getLogger(req).Session("rate-limit")
+			err := errors.New("rate limit exceeded")
+			logger.Error("request-throttled", err, lager.Data{
+				"route":       r.Name,
+				"subject":     tokenData.Subject,
+				"client_id":   tokenData.ClientID,
+				"retry_after": retryAfter,
+			})
+			r.MetricsSender.IncrementCounter(fmt.Sprintf("%sRateLimited", r.Name))
+
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": "rate limit exceeded"}`))
+			return
+		}
+
+		handle.ServeHTTP(w, req)
+	})
+}
+
+// rateLimitKey picks the bucket for a request. Tokens with neither a subject
+// nor a client id fall back to the remote address, so that they do not all
+// share one bucket.
+func rateLimitKey(subject, clientID, remoteAddr string) string {
+	if subject != "" {
+		return "subject:" + subject
+	}
+	if clientID != "" {
+		return "client:" + clientID
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return "addr:" + host
+}
+
+// take removes a token from the bucket for the given key. When the bucket
+// is empty it returns false along with the number of seconds until a token
+// will be available.
+func (r *RateLimiter) take(key string) (bool, int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.buckets == nil {
+		r.buckets = map[string]*tokenBucket{}
+	}
+
+	now := r.Clock.Now()
+	burst := float64(r.Burst)
+
+	bucket, ok := r.buckets[key]
+	if !ok {
+		if len(r.buckets) >= maxIdleBuckets {
+			r.pruneFullBuckets(now)
+		}
+		bucket = &tokenBucket{tokens: burst, lastRefill: now}
+		r.buckets[key] = bucket
+	}
+
+	bucket.refill(now, r.RequestsPerSecond, burst)
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	wait := (1 - bucket.tokens) / r.RequestsPerSecond
+	return false, int(math.Max(1, math.Ceil(wait)))
+}
+
+// pruneFullBuckets drops buckets that have refilled completely, since they
+// behave the same as a bucket that has never been created.
+func (r *RateLimiter) pruneFullBuckets(now time.Time) {
+	burst := float64(r.Burst)
+	for key, bucket := range r.buckets {
+		bucket.refill(now, r.RequestsPerSecond, burst)
+		if bucket.tokens >= burst {
+			delete(r.buckets, key)
+		}
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time, rate, burst float64) {
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed*rate)
+		b.lastRefill = now
+	}
+}
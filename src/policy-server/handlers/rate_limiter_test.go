@@ -0,0 +1,193 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"policy-server/handlers"
+	"policy-server/uaa_client"
+	"time"
+
+	storeFakes "policy-server/store/fakes"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RateLimiter", func() {
+	var (
+		rateLimiter       *handlers.RateLimiter
+		fakeMetricsSender *storeFakes.MetricsSender
+		fakeClock         *fakeclock.FakeClock
+		logger            *lagertest.TestLogger
+		wrapped           http.Handler
+		innerCallCount    int
+		token             uaa_client.CheckTokenResponse
+	)
+
+	makeRequestFrom := func(remoteAddr string, token uaa_client.CheckTokenResponse) *httptest.ResponseRecorder {
+		request, err := http.NewRequest("POST", "/networking/v1/external/policies", nil)
+		Expect(err).NotTo(HaveOccurred())
+		request.RemoteAddr = remoteAddr
+		resp := httptest.NewRecorder()
+		MakeRequestWithLoggerAndAuth(wrapped.ServeHTTP, resp, request, logger, token)
+		return resp
+	}
+
+	makeRequest := func(token uaa_client.CheckTokenResponse) *httptest.ResponseRecorder {
+		return makeRequestFrom("10.0.0.1:12345", token)
+	}
+
+	BeforeEach(func() {
+		fakeMetricsSender = &storeFakes.MetricsSender{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		logger = lagertest.NewTestLogger("test")
+		innerCallCount = 0
+
+		rateLimiter = handlers.NewRateLimiter("CreatePolicies", 0.5, 2, false, fakeMetricsSender, fakeClock)
+		wrapped = rateLimiter.Wrap(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			innerCallCount++
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		token = uaa_client.CheckTokenResponse{
+			Subject:  "some-subject",
+			ClientID: "some-client",
+			Scope:    []string{"network.write"},
+		}
+	})
+
+	It("allows requests up to the burst size", func() {
+		Expect(makeRequest(token).Code).To(Equal(http.StatusOK))
+		Expect(makeRequest(token).Code).To(Equal(http.StatusOK))
+		Expect(innerCallCount).To(Equal(2))
+	})
+
+	Context("when the bucket is empty", func() {
+		BeforeEach(func() {
+			makeRequest(token)
+			makeRequest(token)
+		})
+
+		It("returns a 429 with a Retry-After header", func() {
+			resp := makeRequest(token)
+			Expect(resp.Code).To(Equal(http.StatusTooManyRequests))
+			Expect(resp.Header().Get("Retry-After")).To(Equal("2"))
+			Expect(resp.Body.String()).To(MatchJSON(`{"error": "rate limit exceeded"}`))
+			Expect(innerCallCount).To(Equal(2))
+		})
+
+		It("emits a metric for the throttled request", func() {
+			makeRequest(token)
+			Expect(fakeMetricsSender.IncrementCounterCallCount()).To(Equal(1))
+			Expect(fakeMetricsSender.IncrementCounterArgsForCall(0)).To(Equal("CreatePoliciesRateLimited"))
+		})
+
+		It("logs the throttled request", func() {
+			makeRequest(token)
+			Expect(logger.Logs()).To(HaveLen(1))
+			Expect(logger.Logs()[0]).To(SatisfyAll(
+				LogsWith(lager.ERROR, "test.rate-limit.request-throttled"),
+				HaveLogData(SatisfyAll(
+					HaveKeyWithValue("route", "CreatePolicies"),
+					HaveKeyWithValue("subject", "some-subject"),
+				)),
+			))
+		})
+
+		It("allows requests again once tokens have been refilled", func() {
+			fakeClock.Increment(2 * time.Second)
+			Expect(makeRequest(token).Code).To(Equal(http.StatusOK))
+			Expect(makeRequest(token).Code).To(Equal(http.StatusTooManyRequests))
+		})
+
+		It("keeps a separate bucket per token subject", func() {
+			otherToken := token
+			otherToken.Subject = "some-other-subject"
+			Expect(makeRequest(otherToken).Code).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("when the token has no subject", func() {
+		BeforeEach(func() {
+			token.Subject = ""
+		})
+
+		It("keys the bucket on the client id", func() {
+			makeRequest(token)
+			makeRequest(token)
+			Expect(makeRequest(token).Code).To(Equal(http.StatusTooManyRequests))
+
+			otherToken := token
+			otherToken.ClientID = "some-other-client"
+			Expect(makeRequest(otherToken).Code).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("when the token has neither a subject nor a client id", func() {
+		BeforeEach(func() {
+			token.Subject = ""
+			token.ClientID = ""
+		})
+
+		It("keys the bucket on the remote address", func() {
+			makeRequestFrom("10.0.0.1:12345", token)
+			makeRequestFrom("10.0.0.1:23456", token)
+			Expect(makeRequestFrom("10.0.0.1:34567", token).Code).To(Equal(http.StatusTooManyRequests))
+
+			Expect(makeRequestFrom("10.0.0.2:12345", token).Code).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("when network admins are exempt", func() {
+		BeforeEach(func() {
+			rateLimiter.ExemptNetworkAdmin = true
+			token.Scope = []string{"network.admin"}
+		})
+
+		It("does not throttle network admins", func() {
+			for i := 0; i < 5; i++ {
+				Expect(makeRequest(token).Code).To(Equal(http.StatusOK))
+			}
+			Expect(fakeMetricsSender.IncrementCounterCallCount()).To(Equal(0))
+		})
+
+		It("still throttles other users", func() {
+			token.Scope = []string{"network.write"}
+			makeRequest(token)
+			makeRequest(token)
+			Expect(makeRequest(token).Code).To(Equal(http.StatusTooManyRequests))
+		})
+	})
+
+	Context("when network admins are not exempt", func() {
+		BeforeEach(func() {
+			token.Scope = []string{"network.admin"}
+		})
+
+		It("throttles network admins", func() {
+			makeRequest(token)
+			makeRequest(token)
+			Expect(makeRequest(token).Code).To(Equal(http.StatusTooManyRequests))
+		})
+	})
+
+	Context("when no burst is configured", func() {
+		BeforeEach(func() {
+			rateLimiter = handlers.NewRateLimiter("CreatePolicies", 3, 0, false, fakeMetricsSender, fakeClock)
+			wrapped = rateLimiter.Wrap(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+		})
+
+		It("defaults the burst to the per-second rate", func() {
+			Expect(rateLimiter.Burst).To(Equal(3))
+			for i := 0; i < 3; i++ {
+				Expect(makeRequest(token).Code).To(Equal(http.StatusOK))
+			}
+			resp := makeRequest(token)
+			Expect(resp.Code).To(Equal(http.StatusTooManyRequests))
+			Expect(resp.Header().Get("Retry-After")).To(Equal("1"))
+		})
+	})
+})
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"policy-server/asg_converter"
+	"strconv"
+
+	"code.cloudfoundry.org/cf-networking-helpers/marshal"
+)
+
+//go:generate counterfeiter -o fakes/security_group_converter.go --fake-name SecurityGroupConverter . securityGroupConverter
+type securityGroupConverter interface {
+	Convert(dryRun bool) (asg_converter.Result, error)
+}
+
+type SecurityGroupsConvert struct {
+	Converter     securityGroupConverter
+	Marshaler     marshal.Marshaler
+	ErrorResponse errorResponse
+}
+
+func (h *SecurityGroupsConvert) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	logger := getLogger(req)
+	logger = logger.Session("convert-security-groups")
+
+	dryRun := false
+	if value := req.URL.Query().Get("dry_run"); value != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			h.ErrorResponse.BadRequest(logger, w, err, fmt.Sprintf("invalid dry_run value '%s'", value))
+			return
+		}
+	}
+
+	result, err := h.Converter.Convert(dryRun)
+	if err != nil {
+		h.ErrorResponse.InternalServerError(logger, w, err, "security groups conversion failed")
+		return
+	}
+
+	responseJSON, err := h.Marshaler.Marshal(result)
+	if err != nil {
+		h.ErrorResponse.InternalServerError(logger, w, err, "marshaling response failed")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseJSON)
+}
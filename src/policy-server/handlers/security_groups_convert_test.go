@@ -0,0 +1,147 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"policy-server/asg_converter"
+	"policy-server/handlers"
+	"policy-server/handlers/fakes"
+
+	"code.cloudfoundry.org/cf-networking-helpers/marshal"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SecurityGroupsConvert", func() {
+	var (
+		request           *http.Request
+		handler           *handlers.SecurityGroupsConvert
+		resp              *httptest.ResponseRecorder
+		logger            *lagertest.TestLogger
+		expectedLogger    lager.Logger
+		fakeConverter     *fakes.SecurityGroupConverter
+		fakeErrorResponse *fakes.ErrorResponse
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		expectedLogger = lager.NewLogger("test").Session("convert-security-groups")
+
+		testSink := lagertest.NewTestSink()
+		expectedLogger.RegisterSink(testSink)
+		expectedLogger.RegisterSink(lager.NewWriterSink(GinkgoWriter, lager.DEBUG))
+
+		fakeConverter = &fakes.SecurityGroupConverter{}
+		fakeErrorResponse = &fakes.ErrorResponse{}
+
+		handler = &handlers.SecurityGroupsConvert{
+			Converter:     fakeConverter,
+			Marshaler:     marshal.MarshalFunc(json.Marshal),
+			ErrorResponse: fakeErrorResponse,
+		}
+
+		fakeConverter.ConvertReturns(asg_converter.Result{
+			DryRun:                true,
+			NewDestinations:       []asg_converter.Destination{{Name: "asg-1234", Protocol: "tcp", IPs: []string{"10.0.0.1"}, Ports: []string{"443"}, SecurityGroups: []string{"web"}}},
+			UnchangedDestinations: []asg_converter.Destination{},
+			NewPolicies:           []asg_converter.Policy{{SourceType: "space", SourceID: "space-1", DestinationName: "asg-1234"}},
+			UnchangedPolicies:     []asg_converter.Policy{},
+			SkippedRules:          []asg_converter.SkippedRule{},
+			OverlapWarnings:       []string{},
+		}, nil)
+
+		resp = httptest.NewRecorder()
+		request, _ = http.NewRequest("POST", "/networking/v1/external/security_groups/convert?dry_run=true", nil)
+	})
+
+	It("converts the security groups and returns the result", func() {
+		MakeRequestWithLogger(handler.ServeHTTP, resp, request, logger)
+
+		Expect(fakeConverter.ConvertCallCount()).To(Equal(1))
+		Expect(fakeConverter.ConvertArgsForCall(0)).To(BeTrue())
+
+		Expect(resp.Code).To(Equal(http.StatusOK))
+		Expect(resp.Body.String()).To(MatchJSON(`{
+			"dry_run": true,
+			"new_destinations": [
+				{"name": "asg-1234", "protocol": "tcp", "ips": ["10.0.0.1"], "ports": ["443"], "security_groups": ["web"]}
+			],
+			"unchanged_destinations": [],
+			"new_policies": [
+				{"source_type": "space", "source_id": "space-1", "destination_name": "asg-1234"}
+			],
+			"unchanged_policies": [],
+			"skipped_rules": [],
+			"overlap_warnings": []
+		}`))
+	})
+
+	Context("when dry_run is not given", func() {
+		BeforeEach(func() {
+			request, _ = http.NewRequest("POST", "/networking/v1/external/security_groups/convert", nil)
+		})
+
+		It("applies the conversion", func() {
+			MakeRequestWithLogger(handler.ServeHTTP, resp, request, logger)
+			Expect(fakeConverter.ConvertArgsForCall(0)).To(BeFalse())
+		})
+	})
+
+	Context("when dry_run is invalid", func() {
+		BeforeEach(func() {
+			request, _ = http.NewRequest("POST", "/networking/v1/external/security_groups/convert?dry_run=maybe", nil)
+		})
+
+		It("calls the bad request handler", func() {
+			MakeRequestWithLogger(handler.ServeHTTP, resp, request, logger)
+
+			Expect(fakeConverter.ConvertCallCount()).To(Equal(0))
+			Expect(fakeErrorResponse.BadRequestCallCount()).To(Equal(1))
+
+			l, w, _, description := fakeErrorResponse.BadRequestArgsForCall(0)
+			Expect(l).To(Equal(expectedLogger))
+			Expect(w).To(Equal(resp))
+			Expect(description).To(Equal("invalid dry_run value 'maybe'"))
+		})
+	})
+
+	Context("when the conversion fails", func() {
+		BeforeEach(func() {
+			fakeConverter.ConvertReturns(asg_converter.Result{}, errors.New("potato"))
+		})
+
+		It("calls the internal server error handler", func() {
+			MakeRequestWithLogger(handler.ServeHTTP, resp, request, logger)
+
+			Expect(fakeErrorResponse.InternalServerErrorCallCount()).To(Equal(1))
+
+			l, w, err, description := fakeErrorResponse.InternalServerErrorArgsForCall(0)
+			Expect(l).To(Equal(expectedLogger))
+			Expect(w).To(Equal(resp))
+			Expect(err).To(MatchError("potato"))
+			Expect(description).To(Equal("security groups conversion failed"))
+		})
+	})
+
+	Context("when marshaling the response fails", func() {
+		BeforeEach(func() {
+			handler.Marshaler = marshal.MarshalFunc(func(input interface{}) ([]byte, error) {
+				return nil, errors.New("potato")
+			})
+		})
+
+		It("calls the internal server error handler", func() {
+			MakeRequestWithLogger(handler.ServeHTTP, resp, request, logger)
+
+			Expect(fakeErrorResponse.InternalServerErrorCallCount()).To(Equal(1))
+
+			_, _, err, description := fakeErrorResponse.InternalServerErrorArgsForCall(0)
+			Expect(err).To(MatchError("potato"))
+			Expect(description).To(Equal("marshaling response failed"))
+		})
+	})
+})
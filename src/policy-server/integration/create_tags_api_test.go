@@ -88,6 +88,20 @@ var _ = Describe("Create Tags API", func() {
 			Expect(string(responseBody)).To(MatchJSON(`{"type":"router-type","id":"router-guid","tag":"0001"}`))
 		})
 
+		It("lists the new tag", func() {
+			resp = helpers.MakeAndDoHTTPSRequest(
+				"GET",
+				fmt.Sprintf("https://%s:%d/networking/v1/internal/tags", internalConf.ListenHost, internalConf.InternalListenPort),
+				nil,
+				tlsConfig,
+			)
+
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			responseBody, err := ioutil.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(responseBody)).To(MatchJSON(`{"tags":[{"type":"router-type","id":"router-guid","tag":"0001"}]}`))
+		})
+
 		Context("when creating a tag with the same parameters", func() {
 			BeforeEach(func() {
 				body := strings.NewReader(`{"type": "router-type", "id": "router-guid" }`)
@@ -113,7 +113,7 @@ var _ = Describe("External API Egress Policies", func() {
 		_, err = uuid.ParseHex(policyGUID)
 		Expect(err).NotTo(HaveOccurred())
 
-		egressPolicyList, err := client.ListEgressPolicies(token)
+		egressPolicyList, err := client.ListEgressPolicies(token, psclient.ListEgressPoliciesOptions{})
 		Expect(err).NotTo(HaveOccurred())
 		egressPolicies := egressPolicyList.EgressPolicies
 		Expect(egressPolicies).To(HaveLen(1))
@@ -144,7 +144,7 @@ var _ = Describe("External API Egress Policies", func() {
 		somePolicy.GUID = policyGUID
 		Expect(somePolicy).To(Equal(deletedEgressPolicy))
 
-		egressPolicyList, err = client.ListEgressPolicies(token)
+		egressPolicyList, err = client.ListEgressPolicies(token, psclient.ListEgressPoliciesOptions{})
 		Expect(err).NotTo(HaveOccurred())
 		egressPolicies = egressPolicyList.EgressPolicies
 		Expect(egressPolicies).To(HaveLen(0))
@@ -14,6 +14,7 @@ type ConfigurableMockCCServer struct {
 
 	apps   map[string]struct{}
 	spaces map[string]struct{}
+	orgs   map[string]struct{}
 }
 
 type resource struct {
@@ -24,6 +25,7 @@ func NewConfigurableMockCCServer() *ConfigurableMockCCServer {
 	c := &ConfigurableMockCCServer{
 		apps:   make(map[string]struct{}),
 		spaces: make(map[string]struct{}),
+		orgs:   make(map[string]struct{}),
 	}
 	c.server = httptest.NewUnstartedServer(c)
 
@@ -50,6 +52,10 @@ func (c *ConfigurableMockCCServer) AddSpace(guid string) {
 	c.spaces[guid] = struct{}{}
 }
 
+func (c *ConfigurableMockCCServer) AddOrg(guid string) {
+	c.orgs[guid] = struct{}{}
+}
+
 func (c *ConfigurableMockCCServer) DeleteApp(guid string) {
 	delete(c.apps, guid)
 }
@@ -58,6 +64,10 @@ func (c *ConfigurableMockCCServer) DeleteSpace(guid string) {
 	delete(c.spaces, guid)
 }
 
+func (c *ConfigurableMockCCServer) DeleteOrg(guid string) {
+	delete(c.orgs, guid)
+}
+
 func (c *ConfigurableMockCCServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Header["Authorization"][0] != "bearer valid-token" {
 		w.WriteHeader(http.StatusUnauthorized)
@@ -76,6 +86,12 @@ func (c *ConfigurableMockCCServer) ServeHTTP(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if r.URL.Path == "/v3/organizations" {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(buildCCResponse(c.orgs)))
+		return
+	}
+
 	w.WriteHeader(http.StatusTeapot)
 	return
 }
@@ -95,6 +95,12 @@ var MockCCServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWrite
 		return
 	}
 
+	if r.URL.Path == "/v2/users/some-user-or-client-id/managed_organizations" {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(fixtures.SubjectManagedOrgsEmpty))
+		return
+	}
+
 	w.WriteHeader(http.StatusTeapot)
 	return
 }))
@@ -187,6 +193,7 @@ func DefaultTestConfigWithCCServer(dbConfig db.Config, metronAddress string, fix
 		Database:                        dbConfig,
 		MetronAddress:                   metronAddress,
 		CleanupInterval:                 60,
+		FQDNResolutionInterval:          60,
 		CCAppRequestChunkSize:           100,
 		RequestTimeout:                  10,
 		MaxPolicies:                     2,
@@ -347,33 +347,38 @@ var _ = Describe("External API Space Developer", func() {
 		})
 
 		Describe("Egress Policy and Destination Endpoints", func() {
-			It("does not allow access", func() {
+			It("only lists destinations and egress policies of orgs they manage", func() {
 				req := makeNewRequest("GET", "networking/v1/external/destinations", "")
 				resp, err := http.DefaultClient.Do(req)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
-
-				req = makeNewRequest("POST", "networking/v1/external/destinations", "{}")
-				resp, err = http.DefaultClient.Do(req)
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				responseString, err := ioutil.ReadAll(resp.Body)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+				Expect(responseString).To(MatchJSON(`{"total_destinations": 0, "destinations": []}`))
 
-				req = makeNewRequest("PUT", "networking/v1/external/destinations", "{}")
+				req = makeNewRequest("GET", "networking/v1/external/egress_policies", "")
 				resp, err = http.DefaultClient.Do(req)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				responseString, err = ioutil.ReadAll(resp.Body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(responseString).To(MatchJSON(`{"total_egress_policies": 0, "egress_policies": []}`))
+			})
 
-				req = makeNewRequest("DELETE", "networking/v1/external/destinations/meow", "")
-				resp, err = http.DefaultClient.Do(req)
+			It("does not allow managing destinations or egress policies outside of orgs they manage", func() {
+				destination := `{"destinations": [{"name": "meow", "protocol": "tcp", "ips": [{"start": "10.0.0.1", "end": "10.0.0.1"}]}]}`
+				req := makeNewRequest("POST", "networking/v1/external/destinations", destination)
+				resp, err := http.DefaultClient.Do(req)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
 
-				req = makeNewRequest("GET", "networking/v1/external/egress_policies", "")
+				destination = `{"destinations": [{"id": "meow", "name": "meow", "protocol": "tcp", "ips": [{"start": "10.0.0.1", "end": "10.0.0.1"}]}]}`
+				req = makeNewRequest("PUT", "networking/v1/external/destinations", destination)
 				resp, err = http.DefaultClient.Do(req)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
 
-				req = makeNewRequest("POST", "networking/v1/external/egress_policies", "{}")
+				req = makeNewRequest("DELETE", "networking/v1/external/destinations/meow", "")
 				resp, err = http.DefaultClient.Do(req)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
@@ -0,0 +1,91 @@
+package policy_compiler
+
+import (
+	"fmt"
+	"lib/rules"
+	"policy-server/api"
+)
+
+// PolicyCompiler turns the policies served by the internal policy server into
+// the filter chains a cell should have for the containers running on it. The
+// chains are planned by rules.PolicyPlanner; the compiler only converts the
+// payload of the policy server into the planner's input.
+type PolicyCompiler struct {
+	ChainPrefix           string
+	AcceptedUDPLogsPerSec int
+}
+
+// Compile returns the iptables and ip6tables chains for the payload. Egress
+// policies that cannot be compiled are returned in the plan.
+func (c PolicyCompiler) Compile(payload api.PolicyCollectionPayload, containersByIP map[string]rules.PolicyContainer) (rules.PolicyPlan, error) {
+	c2cPolicies := []rules.C2CPolicy{}
+	for _, policy := range payload.Policies {
+		c2cPolicies = append(c2cPolicies, rules.C2CPolicy{
+			SourceAppGUID:      policy.Source.ID,
+			SourceTag:          policy.Source.Tag,
+			DestinationAppGUID: policy.Destination.ID,
+			Protocol:           policy.Destination.Protocol,
+			StartPort:          policy.Destination.Ports.Start,
+			EndPort:            policy.Destination.Ports.End,
+			Log:                policy.Log != nil && *policy.Log,
+		})
+	}
+
+	skipped := []rules.SkippedEgressPolicy{}
+	egressPolicies := []rules.EgressPolicy{}
+	for _, policy := range payload.EgressPolicies {
+		if policy.Source == nil || policy.Destination == nil {
+			skipped = append(skipped, rules.SkippedEgressPolicy{ID: policy.ID, Reason: "missing source or destination"})
+			continue
+		}
+
+		egressPolicy, err := asEgressPolicy(policy)
+		if err != nil {
+			return rules.PolicyPlan{}, fmt.Errorf("egress policy %s: %s", policy.ID, err)
+		}
+		egressPolicies = append(egressPolicies, egressPolicy)
+	}
+
+	planner := rules.PolicyPlanner{
+		ChainPrefix:           c.ChainPrefix,
+		AcceptedUDPLogsPerSec: c.AcceptedUDPLogsPerSec,
+	}
+	plan, err := planner.Plan(c2cPolicies, egressPolicies, containersByIP)
+	if err != nil {
+		return rules.PolicyPlan{}, err
+	}
+	plan.Skipped = append(skipped, plan.Skipped...)
+	return plan, nil
+}
+
+func asEgressPolicy(policy api.EgressPolicy) (rules.EgressPolicy, error) {
+	destination := policy.Destination
+	egressPolicy := rules.EgressPolicy{
+		ID:         policy.ID,
+		SourceType: policy.Source.Type,
+		SourceID:   policy.Source.ID,
+		Protocol:   destination.Protocol,
+		IPRanges:   []rules.IPRange{},
+		Ports:      []rules.PortRange{},
+		ICMPType:   api.ICMPDefault,
+		ICMPCode:   api.ICMPDefault,
+		Log:        policy.Log,
+	}
+	if destination.ICMPType != nil {
+		egressPolicy.ICMPType = *destination.ICMPType
+	}
+	if destination.ICMPCode != nil {
+		egressPolicy.ICMPCode = *destination.ICMPCode
+	}
+	for _, ports := range destination.Ports {
+		egressPolicy.Ports = append(egressPolicy.Ports, rules.PortRange{Start: ports.Start, End: ports.End})
+	}
+	for _, ipRange := range destination.IPRanges {
+		start, end, err := ipRange.Bounds()
+		if err != nil {
+			return rules.EgressPolicy{}, err
+		}
+		egressPolicy.IPRanges = append(egressPolicy.IPRanges, rules.IPRange{Start: start, End: end})
+	}
+	return egressPolicy, nil
+}
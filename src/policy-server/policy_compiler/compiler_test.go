@@ -0,0 +1,103 @@
+package policy_compiler_test
+
+import (
+	"lib/rules"
+	"policy-server/api"
+	"policy-server/policy_compiler"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PolicyCompiler", func() {
+	var (
+		compiler       policy_compiler.PolicyCompiler
+		payload        api.PolicyCollectionPayload
+		containersByIP map[string]rules.PolicyContainer
+	)
+
+	BeforeEach(func() {
+		compiler = policy_compiler.PolicyCompiler{}
+		logEnabled := true
+		payload = api.PolicyCollectionPayload{
+			Policies: []api.Policy{
+				{
+					Source:      api.Source{ID: "app-a", Tag: "000A"},
+					Destination: api.Destination{ID: "app-b", Tag: "000B", Protocol: "udp", Ports: api.Ports{Start: 9000, End: 9001}},
+					Log:         &logEnabled,
+				},
+			},
+			EgressPolicies: []api.EgressPolicy{
+				{
+					ID:     "egress-1",
+					Source: &api.EgressSource{ID: "app-a", Type: "app"},
+					Destination: &api.EgressDestination{
+						Protocol: "tcp",
+						Ports:    []api.Ports{{Start: 443, End: 443}},
+						IPRanges: []api.IPRange{{CIDR: "10.1.0.0/24"}, {CIDR: "2001:db8::/120"}},
+					},
+				},
+				{
+					ID:     "egress-2",
+					Source: &api.EgressSource{ID: "default-guid", Type: "default"},
+					Destination: &api.EgressDestination{
+						Protocol: "icmp",
+						IPRanges: []api.IPRange{{Start: "8.8.8.8", End: "8.8.8.8"}},
+					},
+				},
+			},
+		}
+		containersByIP = map[string]rules.PolicyContainer{
+			"10.255.0.3": {AppGUID: "app-b"},
+			"10.255.0.2": {AppGUID: "app-a"},
+			"fd00::2":    {AppGUID: "app-a"},
+		}
+	})
+
+	It("plans the chains for the payload", func() {
+		plan, err := compiler.Compile(payload, containersByIP)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.Skipped).To(BeEmpty())
+
+		Expect(plan.IPv4Chains[0].Rules).To(Equal([]rules.IPTablesRule{
+			rules.NewMarkSetRule("10.255.0.2", "000A", "app-a"),
+		}))
+		Expect(plan.IPv4Chains[1].Rules).To(Equal([]rules.IPTablesRule{
+			rules.NewMarkAllowLogRule("10.255.0.3", "udp", 9000, 9001, "000A", "app-b", 100),
+			rules.NewMarkAllowRule("10.255.0.3", "udp", 9000, 9001, "000A", "app-a", "app-b"),
+		}))
+		Expect(plan.IPv4Chains[2].Rules).To(Equal([]rules.IPTablesRule{
+			append(rules.IPTablesRule{"-s", "10.255.0.2"}, rules.NewNetOutWithPortsRule("10.1.0.0", "10.1.0.255", 443, 443, "tcp")...),
+			append(rules.IPTablesRule{"-s", "10.255.0.2"}, rules.NewNetOutICMPRule("8.8.8.8", "8.8.8.8", api.ICMPDefault, api.ICMPDefault)...),
+			append(rules.IPTablesRule{"-s", "10.255.0.3"}, rules.NewNetOutICMPRule("8.8.8.8", "8.8.8.8", api.ICMPDefault, api.ICMPDefault)...),
+		}))
+		Expect(plan.IPv6Chains[0].Rules).To(Equal([]rules.IPTablesRule{
+			rules.NewMarkSetRule("fd00::2", "000A", "app-a"),
+		}))
+		Expect(plan.IPv6Chains[2].Rules).To(Equal([]rules.IPTablesRule{
+			append(rules.IPTablesRule{"-s", "fd00::2"}, rules.NewNetOutWithPortsRule("2001:db8::", "2001:db8::ff", 443, 443, "tcp")...),
+		}))
+	})
+
+	It("uses the configured chain prefix and udp log rate", func() {
+		compiler = policy_compiler.PolicyCompiler{ChainPrefix: "cell--", AcceptedUDPLogsPerSec: 7}
+		plan, err := compiler.Compile(payload, containersByIP)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(plan.IPv4Chains[0].Name).To(Equal("cell--tag"))
+		Expect(plan.IPv4Chains[1].Rules[0]).To(Equal(rules.NewMarkAllowLogRule("10.255.0.3", "udp", 9000, 9001, "000A", "app-b", 7)))
+	})
+
+	It("skips egress policies without a source or destination", func() {
+		payload.EgressPolicies[0].Destination = nil
+		plan, err := compiler.Compile(payload, containersByIP)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.Skipped).To(Equal([]rules.SkippedEgressPolicy{{ID: "egress-1", Reason: "missing source or destination"}}))
+	})
+
+	It("fails on bad cidrs", func() {
+		payload.EgressPolicies[0].Destination.IPRanges[0].CIDR = "banana"
+		_, err := compiler.Compile(payload, containersByIP)
+		Expect(err).To(MatchError("egress policy egress-1: parsing cidr: invalid CIDR address: banana"))
+	})
+})
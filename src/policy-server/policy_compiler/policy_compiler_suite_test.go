@@ -0,0 +1,13 @@
+package policy_compiler_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestPolicyCompiler(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "PolicyCompiler Suite")
+}
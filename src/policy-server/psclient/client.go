@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"code.cloudfoundry.org/cf-networking-helpers/json_client"
@@ -15,28 +16,29 @@ type Client struct {
 }
 
 type IPRange struct {
-	Start string
-	End   string
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+	CIDR  string `json:"cidr,omitempty"`
 }
 
 type Port struct {
-	Start int
-	End   int
+	Start int `json:"start"`
+	End   int `json:"end"`
 }
 
 type Destination struct {
-	GUID        string `json:"id,omitempty"`
-	Protocol    string
-	IPs         []IPRange
-	Ports       []Port
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	ICMPType    *int   `json:"icmp_type,omitempty"`
-	ICMPCode    *int   `json:"icmp_code,omitempty"`
+	GUID        string    `json:"id,omitempty"`
+	Protocol    string    `json:"protocol"`
+	IPs         []IPRange `json:"ips"`
+	Ports       []Port    `json:"ports,omitempty"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	ICMPType    *int      `json:"icmp_type,omitempty"`
+	ICMPCode    *int      `json:"icmp_code,omitempty"`
 }
 
 type DestinationList struct {
-	Destinations []Destination
+	Destinations []Destination `json:"destinations"`
 }
 
 type ListDestinationsOptions struct {
@@ -59,6 +61,15 @@ type EgressPolicyDestination struct {
 	ID string `json:"id"`
 }
 
+type ListEgressPoliciesOptions struct {
+	SourceIDs        []string
+	SourceTypes      []string
+	DestinationIDs   []string
+	DestinationNames []string
+	PerPage          int
+	Page             int
+}
+
 type EgressPolicyList struct {
 	TotalEgressPolicies int            `json:"total_egress_policies,omitempty"`
 	EgressPolicies      []EgressPolicy `json:"egress_policies"`
@@ -143,6 +154,34 @@ func (c *Client) CreateEgressPolicy(egressPolicy EgressPolicy, token string) (st
 	return response.EgressPolicies[0].GUID, nil
 }
 
+func (c *Client) GetEgressPolicy(egressPolicyGUID, token string) (EgressPolicy, error) {
+	var response EgressPolicyList
+	err := c.JsonClient.Do("GET", fmt.Sprintf("/networking/v1/external/egress_policies/%s", egressPolicyGUID), "", &response, "Bearer "+token)
+	if err != nil {
+		return EgressPolicy{}, fmt.Errorf("json client do: %s", err)
+	}
+
+	return response.EgressPolicies[0], nil
+}
+
+func (c *Client) UpdateEgressPolicy(egressPolicy EgressPolicy, token string) (EgressPolicy, error) {
+	if egressPolicy.GUID == "" {
+		return EgressPolicy{}, errors.New("egress policy to be updated must have an ID")
+	}
+
+	var response EgressPolicyList
+	err := c.JsonClient.Do("PUT", fmt.Sprintf("/networking/v1/external/egress_policies/%s", egressPolicy.GUID), EgressPolicyList{
+		EgressPolicies: []EgressPolicy{
+			egressPolicy,
+		},
+	}, &response, "Bearer "+token)
+	if err != nil {
+		return EgressPolicy{}, fmt.Errorf("json client do: %s", err)
+	}
+
+	return response.EgressPolicies[0], nil
+}
+
 func (c *Client) DeleteEgressPolicy(egressPolicyGUID, token string) (EgressPolicy, error) {
 	var response EgressPolicyList
 	err := c.JsonClient.Do("DELETE", fmt.Sprintf("/networking/v1/external/egress_policies/%s", egressPolicyGUID), "", &response, "Bearer "+token)
@@ -153,9 +192,34 @@ func (c *Client) DeleteEgressPolicy(egressPolicyGUID, token string) (EgressPolic
 	return response.EgressPolicies[0], nil
 }
 
-func (c *Client) ListEgressPolicies(token string) (EgressPolicyList, error) {
+func (c *Client) ListEgressPolicies(token string, options ListEgressPoliciesOptions) (EgressPolicyList, error) {
+	query := url.Values{}
+	if len(options.SourceIDs) > 0 {
+		query.Set("source_id", strings.Join(options.SourceIDs, ","))
+	}
+	if len(options.SourceTypes) > 0 {
+		query.Set("source_type", strings.Join(options.SourceTypes, ","))
+	}
+	if len(options.DestinationIDs) > 0 {
+		query.Set("destination_id", strings.Join(options.DestinationIDs, ","))
+	}
+	if len(options.DestinationNames) > 0 {
+		query.Set("destination_name", strings.Join(options.DestinationNames, ","))
+	}
+	if options.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(options.PerPage))
+	}
+	if options.Page > 0 {
+		query.Set("page", strconv.Itoa(options.Page))
+	}
+
+	route := "/networking/v1/external/egress_policies"
+	if len(query) > 0 {
+		route = fmt.Sprintf("%s?%s", route, query.Encode())
+	}
+
 	var response EgressPolicyList
-	err := c.JsonClient.Do("GET", "/networking/v1/external/egress_policies", "", &response, "Bearer "+token)
+	err := c.JsonClient.Do("GET", route, "", &response, "Bearer "+token)
 	if err != nil {
 		return EgressPolicyList{}, fmt.Errorf("list egress policies api call: %s", err)
 	}
@@ -69,6 +69,27 @@ var _ = Describe("Client", func() {
 			}
 		})
 
+		Describe("marshaling", func() {
+			It("uses the api field names for multiple ip and port ranges", func() {
+				destination1.IPs = append(destination1.IPs, psclient.IPRange{Start: "10.0.0.1", End: "10.0.0.10"})
+				destination1.Ports = append(destination1.Ports, psclient.Port{Start: 443, End: 443})
+
+				payload, err := json.Marshal(psclient.DestinationList{
+					Destinations: []psclient.Destination{destination1},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(payload).To(MatchJSON(`{
+					"destinations": [{
+						"name": "meow-dest",
+						"description": "cats rule",
+						"protocol": "tcp",
+						"ips": [{"start": "1.2.3.4", "end": "1.2.3.5"}, {"start": "10.0.0.1", "end": "10.0.0.10"}],
+						"ports": [{"start": 8080, "end": 9090}, {"start": 443, "end": 443}]
+					}]
+				}`))
+			})
+		})
+
 		Describe("create destinations", func() {
 			BeforeEach(func() {
 				jsonClient.DoStub = func(method, route string, reqData, respData interface{}, token string) error {
@@ -371,6 +392,135 @@ var _ = Describe("Client", func() {
 		})
 	})
 
+	Describe("GetEgressPolicy", func() {
+		BeforeEach(func() {
+			jsonClient.DoStub = func(method, route string, reqData, respData interface{}, token string) error {
+				respBytes := []byte(`{
+					"total_egress_policies": 1,
+					"egress_policies": [
+						{
+							"id": "some-egress-policy-guid",
+							"source": {
+								"type": "app",
+								"id":   "some-app-guid"
+							},
+							"destination": {
+								"id": "some-dest-guid",
+								"name": "some-dest",
+								"protocol": "tcp",
+								"ips": [{"start": "10.0.0.1", "end": "10.0.0.1"}]
+							}
+						}
+					]
+				}`)
+				err := json.Unmarshal(respBytes, respData)
+				Expect(err).NotTo(HaveOccurred())
+				return nil
+			}
+		})
+
+		It("gets the egress policy with the given guid", func() {
+			egressPolicy, err := client.GetEgressPolicy("some-egress-policy-guid", token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(egressPolicy).To(Equal(psclient.EgressPolicy{
+				GUID: "some-egress-policy-guid",
+				Source: psclient.EgressPolicySource{
+					Type: "app",
+					ID:   "some-app-guid",
+				},
+				Destination: psclient.Destination{
+					GUID:     "some-dest-guid",
+					Name:     "some-dest",
+					Protocol: "tcp",
+					IPs:      []psclient.IPRange{{Start: "10.0.0.1", End: "10.0.0.1"}},
+				},
+			}))
+
+			Expect(jsonClient.DoCallCount()).To(Equal(1))
+			passedMethod, passedRoute, passedReqData, _, passedToken := jsonClient.DoArgsForCall(0)
+			Expect(passedMethod).To(Equal("GET"))
+			Expect(passedRoute).To(Equal("/networking/v1/external/egress_policies/some-egress-policy-guid"))
+			Expect(passedReqData).To(BeEmpty())
+			Expect(passedToken).To(Equal("Bearer some-token"))
+		})
+
+		It("returns an error when the json client do fails", func() {
+			jsonClient.DoStub = nil
+			jsonClient.DoReturns(errors.New("failed to do"))
+			_, err := client.GetEgressPolicy("some-egress-policy-guid", token)
+			Expect(err).To(MatchError("json client do: failed to do"))
+		})
+	})
+
+	Describe("UpdateEgressPolicy", func() {
+		var egressPolicy psclient.EgressPolicy
+
+		BeforeEach(func() {
+			jsonClient.DoStub = func(method, route string, reqData, respData interface{}, token string) error {
+				respBytes := []byte(`{
+					"total_egress_policies": 1,
+					"egress_policies": [
+						{
+							"id": "some-egress-policy-guid",
+							"source": {
+								"type": "space",
+								"id":   "some-space-guid"
+							},
+							"destination": {
+								"id": "some-other-dest-guid"
+							}
+						}
+					]
+				}`)
+				err := json.Unmarshal(respBytes, respData)
+				Expect(err).NotTo(HaveOccurred())
+				return nil
+			}
+
+			egressPolicy = psclient.EgressPolicy{
+				GUID: "some-egress-policy-guid",
+				Source: psclient.EgressPolicySource{
+					Type: "space",
+					ID:   "some-space-guid",
+				},
+				Destination: psclient.Destination{
+					GUID: "some-other-dest-guid",
+				},
+			}
+		})
+
+		It("updates the egress policy and returns it", func() {
+			updatedPolicy, err := client.UpdateEgressPolicy(egressPolicy, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updatedPolicy).To(Equal(egressPolicy))
+
+			Expect(jsonClient.DoCallCount()).To(Equal(1))
+			passedMethod, passedRoute, passedReqData, _, passedToken := jsonClient.DoArgsForCall(0)
+			Expect(passedMethod).To(Equal("PUT"))
+			Expect(passedRoute).To(Equal("/networking/v1/external/egress_policies/some-egress-policy-guid"))
+			Expect(passedReqData).To(Equal(psclient.EgressPolicyList{
+				EgressPolicies: []psclient.EgressPolicy{egressPolicy},
+			}))
+			Expect(passedToken).To(Equal("Bearer some-token"))
+		})
+
+		Context("when the caller forgets to set the GUID field on the EgressPolicy", func() {
+			It("returns an error", func() {
+				egressPolicy.GUID = ""
+				_, err := client.UpdateEgressPolicy(egressPolicy, token)
+				Expect(err).To(MatchError("egress policy to be updated must have an ID"))
+				Expect(jsonClient.DoCallCount()).To(Equal(0))
+			})
+		})
+
+		It("returns an error when the json client do fails", func() {
+			jsonClient.DoStub = nil
+			jsonClient.DoReturns(errors.New("failed to do"))
+			_, err := client.UpdateEgressPolicy(egressPolicy, token)
+			Expect(err).To(MatchError("json client do: failed to do"))
+		})
+	})
+
 	Describe("DeleteEgressPolicy", func() {
 		var (
 			expectedEgressPolicy psclient.EgressPolicy
@@ -456,7 +606,7 @@ var _ = Describe("Client", func() {
 		})
 
 		It("lists all egress policies", func() {
-			policyList, err := client.ListEgressPolicies(token)
+			policyList, err := client.ListEgressPolicies(token, psclient.ListEgressPoliciesOptions{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(policyList.TotalEgressPolicies).To(Equal(1))
 			Expect(policyList.EgressPolicies).To(ConsistOf(psclient.EgressPolicy{
@@ -478,10 +628,28 @@ var _ = Describe("Client", func() {
 			Expect(passedToken).To(Equal("Bearer some-token"))
 		})
 
+		Context("when options are given", func() {
+			It("passes them as query parameters", func() {
+				_, err := client.ListEgressPolicies(token, psclient.ListEgressPoliciesOptions{
+					SourceIDs:        []string{"app-1", "space-1"},
+					SourceTypes:      []string{"app", "space"},
+					DestinationIDs:   []string{"dest-1"},
+					DestinationNames: []string{"dest-name"},
+					PerPage:          10,
+					Page:             2,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, passedRoute, _, _, _ := jsonClient.DoArgsForCall(0)
+				Expect(passedRoute).To(Equal("/networking/v1/external/egress_policies?" +
+					"destination_id=dest-1&destination_name=dest-name&page=2&per_page=10&source_id=app-1%2Cspace-1&source_type=app%2Cspace"))
+			})
+		})
+
 		It("returns an error when the json client do fails", func() {
 			jsonClient.DoStub = nil
 			jsonClient.DoReturns(errors.New("failed to do"))
-			_, err := client.ListEgressPolicies(token)
+			_, err := client.ListEgressPolicies(token, psclient.ListEgressPoliciesOptions{})
 			Expect(err).To(MatchError("list egress policies api call: failed to do"))
 		})
 	})
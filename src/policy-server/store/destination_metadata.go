@@ -6,7 +6,7 @@ import (
 
 type DestinationMetadataTable struct{}
 
-func (d *DestinationMetadataTable) Upsert(tx db.Transaction, terminalGUID, name, description string) error {
+func (d *DestinationMetadataTable) Upsert(tx db.Transaction, terminalGUID, name, description, fqdn, orgGUID string, selfService bool) error {
 	var count int64
 	err := tx.QueryRow(tx.Rebind(`
 		SELECT COUNT(*) FROM destination_metadatas WHERE terminal_guid=?
@@ -17,26 +17,50 @@ func (d *DestinationMetadataTable) Upsert(tx db.Transaction, terminalGUID, name,
 
 	if count == 0 {
 		_, err := tx.Exec(tx.Rebind(`
-			INSERT INTO destination_metadatas (terminal_guid, name, description)
-			VALUES (?,?,?)
+			INSERT INTO destination_metadatas (terminal_guid, name, description, fqdn, org_guid, self_service)
+			VALUES (?,?,?,?,?,?)
 		`),
 			terminalGUID,
 			name,
 			description,
+			fqdn,
+			nullIfEmpty(orgGUID),
+			selfService,
 		)
 		return err
 	} else {
 		_, err = tx.Exec(tx.Rebind(`
-			UPDATE destination_metadatas SET name=?, description=? WHERE terminal_guid=?
+			UPDATE destination_metadatas SET name=?, description=?, fqdn=?, org_guid=?, self_service=?, fqdn_resolution_error=NULL WHERE terminal_guid=?
 		`),
 			name,
 			description,
+			fqdn,
+			nullIfEmpty(orgGUID),
+			selfService,
 			terminalGUID,
 		)
 		return err
 	}
 }
 
+func (d *DestinationMetadataTable) UpdateFQDNResolutionError(tx db.Transaction, terminalGUID, resolutionError string) error {
+	_, err := tx.Exec(tx.Rebind(`
+		UPDATE destination_metadatas SET fqdn_resolution_error=? WHERE terminal_guid=?
+	`),
+		resolutionError,
+		terminalGUID,
+	)
+	return err
+}
+
+// nullIfEmpty stores global destinations, which have no org, as NULL.
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
 func (d *DestinationMetadataTable) Delete(tx db.Transaction, guid string) error {
 	_, err := tx.Exec(tx.Rebind(`DELETE FROM destination_metadatas WHERE terminal_guid=?`), guid)
 	return err
@@ -0,0 +1,256 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+)
+
+const (
+	OverlapModeWarn   = "warn"
+	OverlapModeReject = "reject"
+
+	OverlapRelationEqual       = "equal"
+	OverlapRelationContains    = "contains"
+	OverlapRelationContainedBy = "contained_by"
+	OverlapRelationOverlaps    = "overlaps"
+)
+
+// DestinationOverlap describes how a destination relates to another
+// destination whose protocol, addresses and ports intersect with it.
+type DestinationOverlap struct {
+	Destination            EgressDestination
+	OverlappingDestination EgressDestination
+	Relation               string
+}
+
+func (o DestinationOverlap) Description() string {
+	switch o.Relation {
+	case OverlapRelationEqual:
+		return fmt.Sprintf("destination '%s' covers the same traffic as destination '%s'", o.Destination.Name, o.OverlappingDestination.Name)
+	case OverlapRelationContains:
+		return fmt.Sprintf("destination '%s' contains destination '%s'", o.Destination.Name, o.OverlappingDestination.Name)
+	case OverlapRelationContainedBy:
+		return fmt.Sprintf("destination '%s' is contained by destination '%s'", o.Destination.Name, o.OverlappingDestination.Name)
+	default:
+		return fmt.Sprintf("destination '%s' overlaps destination '%s'", o.Destination.Name, o.OverlappingDestination.Name)
+	}
+}
+
+// AnonymousDescription describes the overlap without naming the overlapping
+// destination, for users who may not see it.
+func (o DestinationOverlap) AnonymousDescription() string {
+	switch o.Relation {
+	case OverlapRelationEqual:
+		return fmt.Sprintf("destination '%s' covers the same traffic as another destination", o.Destination.Name)
+	case OverlapRelationContains:
+		return fmt.Sprintf("destination '%s' contains another destination", o.Destination.Name)
+	case OverlapRelationContainedBy:
+		return fmt.Sprintf("destination '%s' is contained by another destination", o.Destination.Name)
+	default:
+		return fmt.Sprintf("destination '%s' overlaps another destination", o.Destination.Name)
+	}
+}
+
+// FindOverlaps returns an overlap for every one of the others that shares
+// traffic with destination.
+func FindOverlaps(destination EgressDestination, others []EgressDestination) []DestinationOverlap {
+	var overlaps []DestinationOverlap
+	for _, other := range others {
+		if !destinationsIntersect(destination, other) {
+			continue
+		}
+
+		overlaps = append(overlaps, DestinationOverlap{
+			Destination:            destination,
+			OverlappingDestination: other,
+			Relation:               overlapRelation(destination, other),
+		})
+	}
+	return overlaps
+}
+
+// GroupOverlappingDestinations partitions the destinations into groups of
+// destinations that overlap each other, directly or through another member
+// of the group. Destinations that overlap nothing are left out.
+func GroupOverlappingDestinations(destinations []EgressDestination) [][]EgressDestination {
+	parents := make([]int, len(destinations))
+	for i := range parents {
+		parents[i] = i
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+
+	for i := range destinations {
+		for j := i + 1; j < len(destinations); j++ {
+			if destinationsIntersect(destinations[i], destinations[j]) {
+				parents[find(j)] = find(i)
+			}
+		}
+	}
+
+	groupIndexes := map[int]int{}
+	var groups [][]EgressDestination
+	for i, destination := range destinations {
+		root := find(i)
+		index, ok := groupIndexes[root]
+		if !ok {
+			index = len(groups)
+			groupIndexes[root] = index
+			groups = append(groups, nil)
+		}
+		groups[index] = append(groups[index], destination)
+	}
+
+	var overlapping [][]EgressDestination
+	for _, group := range groups {
+		if len(group) > 1 {
+			overlapping = append(overlapping, group)
+		}
+	}
+	return overlapping
+}
+
+func overlapRelation(a, b EgressDestination) string {
+	aCoversB := destinationCovers(a, b)
+	bCoversA := destinationCovers(b, a)
+	switch {
+	case aCoversB && bCoversA:
+		return OverlapRelationEqual
+	case aCoversB:
+		return OverlapRelationContains
+	case bCoversA:
+		return OverlapRelationContainedBy
+	default:
+		return OverlapRelationOverlaps
+	}
+}
+
+func destinationsIntersect(a, b EgressDestination) bool {
+	if a.Protocol != b.Protocol {
+		return false
+	}
+
+	ipsIntersect := false
+	for _, aRange := range a.IPRanges {
+		for _, bRange := range b.IPRanges {
+			if ipRangesIntersect(aRange, bRange) {
+				ipsIntersect = true
+			}
+		}
+	}
+	if !ipsIntersect {
+		return false
+	}
+
+	if a.Protocol == "icmp" {
+		return icmpValuesIntersect(a.ICMPType, b.ICMPType) && icmpValuesIntersect(a.ICMPCode, b.ICMPCode)
+	}
+
+	for _, aPorts := range a.Ports {
+		for _, bPorts := range b.Ports {
+			if aPorts.Start <= bPorts.End && bPorts.Start <= aPorts.End {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// destinationCovers reports whether all the traffic allowed by b is also
+// allowed by a.
+func destinationCovers(a, b EgressDestination) bool {
+	if a.Protocol != b.Protocol {
+		return false
+	}
+
+	for _, bRange := range b.IPRanges {
+		covered := false
+		for _, aRange := range a.IPRanges {
+			if ipRangeCovers(aRange, bRange) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+
+	if a.Protocol == "icmp" {
+		return icmpValueCovers(a.ICMPType, b.ICMPType) && icmpValueCovers(a.ICMPCode, b.ICMPCode)
+	}
+
+	for _, bPorts := range b.Ports {
+		covered := false
+		for _, aPorts := range a.Ports {
+			if aPorts.Start <= bPorts.Start && bPorts.End <= aPorts.End {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+func icmpValuesIntersect(a, b int) bool {
+	return a == -1 || b == -1 || a == b
+}
+
+func icmpValueCovers(a, b int) bool {
+	return a == -1 || a == b
+}
+
+func ipRangesIntersect(a, b IPRange) bool {
+	aStart, aEnd, ok := parseIPRange(a)
+	if !ok {
+		return false
+	}
+	bStart, bEnd, ok := parseIPRange(b)
+	if !ok || len(aStart) != len(bStart) {
+		return false
+	}
+	return bytes.Compare(aStart, bEnd) <= 0 && bytes.Compare(bStart, aEnd) <= 0
+}
+
+func ipRangeCovers(a, b IPRange) bool {
+	aStart, aEnd, ok := parseIPRange(a)
+	if !ok {
+		return false
+	}
+	bStart, bEnd, ok := parseIPRange(b)
+	if !ok || len(aStart) != len(bStart) {
+		return false
+	}
+	return bytes.Compare(aStart, bStart) <= 0 && bytes.Compare(bEnd, aEnd) <= 0
+}
+
+// parseIPRange returns the bounds of the range in their shortest form, so
+// that IPv4 and IPv6 ranges can be told apart by length.
+func parseIPRange(ipRange IPRange) (net.IP, net.IP, bool) {
+	start := normalizeIP(net.ParseIP(ipRange.Start))
+	end := normalizeIP(net.ParseIP(ipRange.End))
+	if start == nil || end == nil || len(start) != len(end) {
+		return nil, nil, false
+	}
+	return start, end, true
+}
+
+func normalizeIP(ip net.IP) net.IP {
+	if ip == nil {
+		return nil
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4
+	}
+	return ip
+}
@@ -0,0 +1,157 @@
+package store_test
+
+import (
+	"policy-server/store"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DestinationOverlap", func() {
+	tcpDestination := func(name, start, end string, startPort, endPort int) store.EgressDestination {
+		return store.EgressDestination{
+			Name:     name,
+			Protocol: "tcp",
+			IPRanges: []store.IPRange{{Start: start, End: end}},
+			Ports:    []store.Ports{{Start: startPort, End: endPort}},
+		}
+	}
+
+	icmpDestination := func(name string, icmpType, icmpCode int) store.EgressDestination {
+		return store.EgressDestination{
+			Name:     name,
+			Protocol: "icmp",
+			IPRanges: []store.IPRange{{Start: "10.0.0.1", End: "10.0.0.1"}},
+			ICMPType: icmpType,
+			ICMPCode: icmpCode,
+		}
+	}
+
+	Describe("FindOverlaps", func() {
+		DescribeTable("relation between destinations",
+			func(destination, other store.EgressDestination, expectedRelation string) {
+				overlaps := store.FindOverlaps(destination, []store.EgressDestination{other})
+				Expect(overlaps).To(HaveLen(1))
+				Expect(overlaps[0].Destination).To(Equal(destination))
+				Expect(overlaps[0].OverlappingDestination).To(Equal(other))
+				Expect(overlaps[0].Relation).To(Equal(expectedRelation))
+			},
+			Entry("identical destinations",
+				tcpDestination("a", "10.0.0.0", "10.0.0.255", 80, 443),
+				tcpDestination("b", "10.0.0.0", "10.0.0.255", 80, 443),
+				store.OverlapRelationEqual),
+			Entry("a destination containing the other",
+				tcpDestination("a", "10.0.0.0", "10.0.0.255", 1, 65535),
+				tcpDestination("b", "10.0.0.5", "10.0.0.5", 443, 443),
+				store.OverlapRelationContains),
+			Entry("a destination contained by the other",
+				tcpDestination("a", "10.0.0.5", "10.0.0.5", 443, 443),
+				tcpDestination("b", "10.0.0.0", "10.0.0.255", 1, 65535),
+				store.OverlapRelationContainedBy),
+			Entry("partially overlapping ip ranges",
+				tcpDestination("a", "10.0.0.0", "10.0.0.10", 80, 80),
+				tcpDestination("b", "10.0.0.5", "10.0.0.20", 80, 80),
+				store.OverlapRelationOverlaps),
+			Entry("partially overlapping port ranges",
+				tcpDestination("a", "10.0.0.1", "10.0.0.1", 80, 90),
+				tcpDestination("b", "10.0.0.1", "10.0.0.1", 85, 100),
+				store.OverlapRelationOverlaps),
+			Entry("ipv6 ranges",
+				tcpDestination("a", "2001:db8::", "2001:db8::ffff", 80, 80),
+				tcpDestination("b", "2001:db8::10", "2001:db8::10", 80, 80),
+				store.OverlapRelationContains),
+			Entry("icmp wildcard type and code",
+				icmpDestination("a", -1, -1),
+				icmpDestination("b", 8, 0),
+				store.OverlapRelationContains),
+			Entry("icmp wildcard code with different types",
+				icmpDestination("a", 8, -1),
+				icmpDestination("b", -1, 0),
+				store.OverlapRelationOverlaps),
+		)
+
+		DescribeTable("destinations that do not overlap",
+			func(destination, other store.EgressDestination) {
+				Expect(store.FindOverlaps(destination, []store.EgressDestination{other})).To(BeEmpty())
+			},
+			Entry("different protocols",
+				tcpDestination("a", "10.0.0.1", "10.0.0.1", 80, 80),
+				store.EgressDestination{Name: "b", Protocol: "udp", IPRanges: []store.IPRange{{Start: "10.0.0.1", End: "10.0.0.1"}}, Ports: []store.Ports{{Start: 80, End: 80}}}),
+			Entry("disjoint ip ranges",
+				tcpDestination("a", "10.0.0.0", "10.0.0.10", 80, 80),
+				tcpDestination("b", "10.0.0.11", "10.0.0.20", 80, 80)),
+			Entry("disjoint port ranges",
+				tcpDestination("a", "10.0.0.1", "10.0.0.1", 80, 90),
+				tcpDestination("b", "10.0.0.1", "10.0.0.1", 91, 100)),
+			Entry("ipv4 and ipv6 ranges",
+				tcpDestination("a", "0.0.0.0", "255.255.255.255", 80, 80),
+				tcpDestination("b", "::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", 80, 80)),
+			Entry("different icmp types",
+				icmpDestination("a", 8, 0),
+				icmpDestination("b", 0, 0)),
+			Entry("destinations without ip ranges",
+				store.EgressDestination{Name: "a", Protocol: "tcp", Ports: []store.Ports{{Start: 80, End: 80}}},
+				tcpDestination("b", "10.0.0.1", "10.0.0.1", 80, 80)),
+		)
+	})
+
+	Describe("Description", func() {
+		DescribeTable("describes the relation",
+			func(relation, expectedDescription string) {
+				overlap := store.DestinationOverlap{
+					Destination:            store.EgressDestination{Name: "a"},
+					OverlappingDestination: store.EgressDestination{Name: "b"},
+					Relation:               relation,
+				}
+				Expect(overlap.Description()).To(Equal(expectedDescription))
+			},
+			Entry("equal", store.OverlapRelationEqual, "destination 'a' covers the same traffic as destination 'b'"),
+			Entry("contains", store.OverlapRelationContains, "destination 'a' contains destination 'b'"),
+			Entry("contained by", store.OverlapRelationContainedBy, "destination 'a' is contained by destination 'b'"),
+			Entry("overlaps", store.OverlapRelationOverlaps, "destination 'a' overlaps destination 'b'"),
+		)
+	})
+
+	Describe("AnonymousDescription", func() {
+		DescribeTable("describes the relation without naming the other destination",
+			func(relation, expectedDescription string) {
+				overlap := store.DestinationOverlap{
+					Destination:            store.EgressDestination{Name: "a"},
+					OverlappingDestination: store.EgressDestination{Name: "b"},
+					Relation:               relation,
+				}
+				Expect(overlap.AnonymousDescription()).To(Equal(expectedDescription))
+			},
+			Entry("equal", store.OverlapRelationEqual, "destination 'a' covers the same traffic as another destination"),
+			Entry("contains", store.OverlapRelationContains, "destination 'a' contains another destination"),
+			Entry("contained by", store.OverlapRelationContainedBy, "destination 'a' is contained by another destination"),
+			Entry("overlaps", store.OverlapRelationOverlaps, "destination 'a' overlaps another destination"),
+		)
+	})
+
+	Describe("GroupOverlappingDestinations", func() {
+		It("groups destinations that overlap directly or transitively", func() {
+			a := tcpDestination("a", "10.0.0.0", "10.0.0.10", 80, 80)
+			b := tcpDestination("b", "10.0.0.10", "10.0.0.20", 80, 80)
+			c := tcpDestination("c", "10.0.0.20", "10.0.0.30", 80, 80)
+			d := tcpDestination("d", "10.0.1.0", "10.0.1.10", 80, 80)
+			e := tcpDestination("e", "10.0.2.0", "10.0.2.10", 80, 80)
+			f := tcpDestination("f", "10.0.2.5", "10.0.2.5", 80, 80)
+
+			groups := store.GroupOverlappingDestinations([]store.EgressDestination{a, d, e, b, f, c})
+			Expect(groups).To(Equal([][]store.EgressDestination{
+				{a, b, c},
+				{e, f},
+			}))
+		})
+
+		It("returns nothing when no destinations overlap", func() {
+			groups := store.GroupOverlappingDestinations([]store.EgressDestination{
+				tcpDestination("a", "10.0.0.1", "10.0.0.1", 80, 80),
+				tcpDestination("b", "10.0.0.2", "10.0.0.2", 80, 80),
+			})
+			Expect(groups).To(BeEmpty())
+		})
+	})
+})
@@ -39,27 +39,18 @@ func (e *EgressDestinationTable) GetByName(tx db.Transaction, names ...string) (
 	return convertRowsToEgressDestinations(rows)
 }
 
-func (e *EgressDestinationTable) Delete(tx db.Transaction, guid string) error {
-	_, err := tx.Exec(tx.Rebind(`DELETE FROM ip_ranges WHERE terminal_guid = ?`), guid)
-	return err
+func (e *EgressDestinationTable) GetWithFQDN(tx db.Transaction) ([]EgressDestination, error) {
+	query := egressDestinationsQuery("WHERE d_m.fqdn IS NOT NULL AND d_m.fqdn <> ''")
+	rows, err := tx.Queryx(tx.Rebind(query))
+	if err != nil {
+		return []EgressDestination{}, fmt.Errorf("running query: %s", err)
+	}
+	defer rows.Close()
+	return convertRowsToEgressDestinations(rows)
 }
 
-func (e *EgressDestinationTable) UpdateIPRange(tx db.Transaction, destinationTerminalGUID, startIP, endIP, protocol string, startPort, endPort, icmpType, icmpCode int64) error {
-	_, err := tx.Exec(tx.Rebind(`
-	  UPDATE ip_ranges
-		SET protocol=?, start_ip=?, end_ip=?, start_port=?, end_port=?, icmp_type=?, icmp_code=?
-		WHERE terminal_guid=?
-	`),
-		protocol,
-		startIP,
-		endIP,
-		startPort,
-		endPort,
-		icmpType,
-		icmpCode,
-		destinationTerminalGUID,
-	)
-
+func (e *EgressDestinationTable) Delete(tx db.Transaction, guid string) error {
+	_, err := tx.Exec(tx.Rebind(`DELETE FROM ip_ranges WHERE terminal_guid = ?`), guid)
 	return err
 }
 
@@ -115,39 +106,86 @@ func (e *EgressDestinationTable) CreateIPRange(tx db.Transaction, destinationTer
 
 func convertRowsToEgressDestinations(rows sqlRows) ([]EgressDestination, error) {
 	var foundEgressDestinations []EgressDestination
+	destinationIndexes := map[string]int{}
 
 	for rows.Next() {
 		var (
 			startPort, endPort, icmpType, icmpCode                    int
 			terminalGUID, name, description, protocol, startIP, endIP *string
-			ports                                                     []Ports
+			fqdn, fqdnResolutionError, orgGUID                        *string
+			selfService                                               bool
 		)
 
-		err := rows.Scan(&protocol, &startIP, &endIP, &startPort, &endPort, &icmpType, &icmpCode, &terminalGUID, &name, &description)
+		err := rows.Scan(&protocol, &startIP, &endIP, &startPort, &endPort, &icmpType, &icmpCode, &terminalGUID, &name, &description, &fqdn, &fqdnResolutionError, &orgGUID, &selfService)
 
 		if err != nil {
 			return []EgressDestination{}, err
 		}
 
-		ports = []Ports{}
-		if startPort != 0 && endPort != 0 {
-			ports = []Ports{{Start: startPort, End: endPort}}
+		index, ok := destinationIndexes[*terminalGUID]
+		if !ok {
+			index = len(foundEgressDestinations)
+			destinationIndexes[*terminalGUID] = index
+			foundEgressDestinations = append(foundEgressDestinations, EgressDestination{
+				GUID:                *terminalGUID,
+				Name:                *name,
+				Description:         *description,
+				FQDN:                *fqdn,
+				FQDNResolutionError: *fqdnResolutionError,
+				OrgGUID:             *orgGUID,
+				SelfService:         selfService,
+				Protocol:            *protocol,
+				Ports:               []Ports{},
+				IPRanges:            []IPRange{},
+				ICMPType:            icmpType,
+				ICMPCode:            icmpCode,
+			})
 		}
 
-		foundEgressDestinations = append(foundEgressDestinations, EgressDestination{
-			GUID:        *terminalGUID,
-			Name:        *name,
-			Description: *description,
-			Protocol:    *protocol,
-			Ports:       ports,
-			IPRanges:    []IPRange{{Start: *startIP, End: *endIP}},
-			ICMPType:    icmpType,
-			ICMPCode:    icmpCode,
-		})
+		destination := &foundEgressDestinations[index]
+		destination.IPRanges = mergeIPRanges(destination.IPRanges, IPRange{Start: *startIP, End: *endIP})
+		if startPort != 0 && endPort != 0 {
+			destination.Ports = mergePorts(destination.Ports, Ports{Start: startPort, End: endPort})
+		}
 	}
 	return foundEgressDestinations, nil
 }
 
+// Each ip_ranges row holds one IP range and one port range, so a destination
+// with several of each is stored as their cross product. The merge helpers
+// collapse those rows back into the distinct ranges, preserving order.
+func mergeIPRanges(ipRanges []IPRange, newIPRanges ...IPRange) []IPRange {
+	for _, newIPRange := range newIPRanges {
+		found := false
+		for _, ipRange := range ipRanges {
+			if ipRange == newIPRange {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ipRanges = append(ipRanges, newIPRange)
+		}
+	}
+	return ipRanges
+}
+
+func mergePorts(ports []Ports, newPorts ...Ports) []Ports {
+	for _, newPortRange := range newPorts {
+		found := false
+		for _, portRange := range ports {
+			if portRange == newPortRange {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ports = append(ports, newPortRange)
+		}
+	}
+	return ports
+}
+
 func egressDestinationsQuery(whereClause string) string {
 	return strings.Join([]string{`SELECT
 			ip_ranges.protocol,
@@ -159,7 +197,11 @@ func egressDestinationsQuery(whereClause string) string {
 			ip_ranges.icmp_code,
 			ip_ranges.terminal_guid,
 			COALESCE(d_m.name, ''),
-			COALESCE(d_m.description, '')
+			COALESCE(d_m.description, ''),
+			COALESCE(d_m.fqdn, ''),
+			COALESCE(d_m.fqdn_resolution_error, ''),
+			COALESCE(d_m.org_guid, ''),
+			COALESCE(d_m.self_service, false)
 		FROM ip_ranges
 		LEFT OUTER JOIN destination_metadatas AS d_m
 		  ON d_m.terminal_guid = ip_ranges.terminal_guid`,
@@ -13,16 +13,17 @@ import (
 type egressDestinationRepo interface {
 	All(tx db.Transaction) ([]EgressDestination, error)
 	CreateIPRange(tx db.Transaction, destinationTerminalGUID, startIP, endIP, protocol string, startPort, endPort, icmpType, icmpCode int64) (int64, error)
-	UpdateIPRange(tx db.Transaction, destinationTerminalGUID, startIP, endIP, protocol string, startPort, endPort, icmpType, icmpCode int64) error
 	GetByGUID(tx db.Transaction, guid ...string) ([]EgressDestination, error)
 	Delete(tx db.Transaction, guid string) error
 	GetByName(tx db.Transaction, name ...string) ([]EgressDestination, error)
+	GetWithFQDN(tx db.Transaction) ([]EgressDestination, error)
 }
 
 //go:generate counterfeiter -o fakes/destination_metadata_repo.go --fake-name DestinationMetadataRepo . destinationMetadataRepo
 type destinationMetadataRepo interface {
 	Delete(tx db.Transaction, terminalGUID string) error
-	Upsert(tx db.Transaction, terminalGUID, name, description string) error
+	Upsert(tx db.Transaction, terminalGUID, name, description, fqdn, orgGUID string, selfService bool) error
+	UpdateFQDNResolutionError(tx db.Transaction, terminalGUID, resolutionError string) error
 }
 
 type EgressDestinationStore struct {
@@ -30,6 +31,7 @@ type EgressDestinationStore struct {
 	EgressDestinationRepo   egressDestinationRepo
 	TerminalsRepo           terminalsRepo
 	DestinationMetadataRepo destinationMetadataRepo
+	OverlapMode             string
 }
 
 func (e *EgressDestinationStore) GetByGUID(guid ...string) ([]EgressDestination, error) {
@@ -59,6 +61,88 @@ func (e *EgressDestinationStore) All() ([]EgressDestination, error) {
 	return e.EgressDestinationRepo.All(tx)
 }
 
+func (e *EgressDestinationStore) GetWithFQDN() ([]EgressDestination, error) {
+	tx, err := e.Conn.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("egress destination store get with fqdn transaction: %s", err)
+	}
+	defer tx.Rollback()
+	return e.EgressDestinationRepo.GetWithFQDN(tx)
+}
+
+// UpdateResolvedIPRanges replaces the IP ranges of an FQDN destination with
+// freshly resolved addresses and clears any previous resolution error. The
+// protocol and ports of the destination are left unchanged.
+func (e *EgressDestinationStore) UpdateResolvedIPRanges(guid string, ipRanges []IPRange) error {
+	tx, err := e.Conn.Beginx()
+	if err != nil {
+		return fmt.Errorf("egress destination store update resolved ip ranges transaction: %s", err)
+	}
+
+	destinations, err := e.EgressDestinationRepo.GetByGUID(tx, guid)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("egress destination store update resolved ip ranges get by guid: %s", err)
+	}
+
+	if len(destinations) == 0 {
+		tx.Rollback()
+		return fmt.Errorf("egress destination store update resolved ip ranges: destination GUID not found")
+	}
+
+	egressDestination := destinations[0]
+	egressDestination.IPRanges = ipRanges
+
+	err = e.EgressDestinationRepo.Delete(tx, guid)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("egress destination store update resolved ip ranges delete ip ranges: %s", err)
+	}
+
+	err = e.createIPRanges(tx, guid, egressDestination)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("egress destination store update resolved ip ranges create ip range: %s", err)
+	}
+
+	err = e.DestinationMetadataRepo.UpdateFQDNResolutionError(tx, guid, "")
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("egress destination store update resolved ip ranges clear resolution error: %s", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("egress destination store update resolved ip ranges commit: %s", err)
+	}
+
+	return nil
+}
+
+// RecordFQDNResolutionError stores the reason the last resolution of an FQDN
+// destination failed. The previously resolved IP ranges are kept.
+func (e *EgressDestinationStore) RecordFQDNResolutionError(guid, resolutionError string) error {
+	tx, err := e.Conn.Beginx()
+	if err != nil {
+		return fmt.Errorf("egress destination store record resolution error transaction: %s", err)
+	}
+
+	err = e.DestinationMetadataRepo.UpdateFQDNResolutionError(tx, guid, resolutionError)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("egress destination store record resolution error: %s", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("egress destination store record resolution error commit: %s", err)
+	}
+
+	return nil
+}
+
 func (e *EgressDestinationStore) Delete(guid string) (EgressDestination, error) {
 	tx, err := e.Conn.Beginx()
 	if err != nil {
@@ -105,10 +189,28 @@ func (e *EgressDestinationStore) Delete(guid string) (EgressDestination, error)
 	return EgressDestination{}, nil
 }
 
+// OverlappingGroups returns every group of destinations that overlap each
+// other.
+func (e *EgressDestinationStore) OverlappingGroups() ([][]EgressDestination, error) {
+	destinations, err := e.All()
+	if err != nil {
+		return nil, err
+	}
+	return GroupOverlappingDestinations(destinations), nil
+}
+
 func (e *EgressDestinationStore) Update(egressDestinations []EgressDestination) ([]EgressDestination, error) {
+	updatedDestinations, _, err := e.UpdateWithOverlaps(egressDestinations)
+	return updatedDestinations, err
+}
+
+// UpdateWithOverlaps updates the destinations and returns how they overlap
+// other destinations. When the store is in reject mode any overlap fails
+// the update instead.
+func (e *EgressDestinationStore) UpdateWithOverlaps(egressDestinations []EgressDestination) ([]EgressDestination, []DestinationOverlap, error) {
 	tx, err := e.Conn.Beginx()
 	if err != nil {
-		return nil, fmt.Errorf("egress destination store update transaction: %s", err)
+		return nil, nil, fmt.Errorf("egress destination store update transaction: %s", err)
 	}
 
 	var guids []string
@@ -119,44 +221,57 @@ func (e *EgressDestinationStore) Update(egressDestinations []EgressDestination)
 	foundDestinations, err := e.EgressDestinationRepo.GetByGUID(tx, guids...)
 	if err != nil {
 		tx.Rollback()
-		return nil, fmt.Errorf("egress destination store update GetByGUID: %s", err)
+		return nil, nil, fmt.Errorf("egress destination store update GetByGUID: %s", err)
 	}
 
 	if len(foundDestinations) != len(egressDestinations) {
 		tx.Rollback()
-		return nil, fmt.Errorf("egress destination store update iprange: destination GUID not found")
+		return nil, nil, fmt.Errorf("egress destination store update iprange: destination GUID not found")
+	}
+
+	allDestinations, err := e.EgressDestinationRepo.All(tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, fmt.Errorf("egress destination store update get all: %s", err)
+	}
+
+	var otherDestinations []EgressDestination
+	for _, destination := range allDestinations {
+		if !containsGUID(guids, destination.GUID) {
+			otherDestinations = append(otherDestinations, destination)
+		}
 	}
 
+	var overlaps []DestinationOverlap
 	for _, egressDestination := range egressDestinations {
-		var startPort, endPort int64
-		if len(egressDestination.Ports) > 0 {
-			startPort = int64(egressDestination.Ports[0].Start)
-			endPort = int64(egressDestination.Ports[0].End)
+		destinationOverlaps := FindOverlaps(egressDestination, otherDestinations)
+		if len(destinationOverlaps) > 0 && e.OverlapMode == OverlapModeReject {
+			tx.Rollback()
+			return nil, nil, NewOverlappingDestinationError("update", destinationOverlaps[0])
 		}
-		err = e.EgressDestinationRepo.UpdateIPRange(
-			tx,
-			egressDestination.GUID,
-			egressDestination.IPRanges[0].Start,
-			egressDestination.IPRanges[0].End,
-			egressDestination.Protocol,
-			startPort,
-			endPort,
-			int64(egressDestination.ICMPType),
-			int64(egressDestination.ICMPCode),
-		)
+		overlaps = append(overlaps, destinationOverlaps...)
+		otherDestinations = append(otherDestinations, egressDestination)
+
+		err = e.EgressDestinationRepo.Delete(tx, egressDestination.GUID)
 		if err != nil {
 			tx.Rollback()
-			return nil, fmt.Errorf("egress destination store update iprange: %s", err)
+			return nil, nil, fmt.Errorf("egress destination store update delete ip ranges: %s", err)
 		}
 
-		err := e.DestinationMetadataRepo.Upsert(tx, egressDestination.GUID, egressDestination.Name, egressDestination.Description)
+		err = e.createIPRanges(tx, egressDestination.GUID, egressDestination)
+		if err != nil {
+			tx.Rollback()
+			return nil, nil, fmt.Errorf("egress destination store update iprange: %s", err)
+		}
+
+		err := e.DestinationMetadataRepo.Upsert(tx, egressDestination.GUID, egressDestination.Name, egressDestination.Description, egressDestination.FQDN, egressDestination.OrgGUID, egressDestination.SelfService)
 
 		if err != nil {
 			tx.Rollback()
 			if isDuplicateError(err) {
-				return nil, fmt.Errorf("egress destination store update destination metadata: duplicate name error: entry with name '%s' already exists", egressDestination.Name)
+				return nil, nil, fmt.Errorf("egress destination store update destination metadata: duplicate name error: entry with name '%s' already exists", egressDestination.Name)
 			}
-			return nil, fmt.Errorf("egress destination store upsert metadata: %s", err)
+			return nil, nil, fmt.Errorf("egress destination store upsert metadata: %s", err)
 		}
 	}
 
@@ -164,24 +279,51 @@ func (e *EgressDestinationStore) Update(egressDestinations []EgressDestination)
 
 	if err != nil {
 		tx.Rollback()
-		return nil, fmt.Errorf("egress destination store update commit transaction: %s", err)
+		return nil, nil, fmt.Errorf("egress destination store update commit transaction: %s", err)
 	}
-	return egressDestinations, nil
+	return egressDestinations, overlaps, nil
 }
 
 func (e *EgressDestinationStore) Create(egressDestinations []EgressDestination) ([]EgressDestination, error) {
+	createdDestinations, _, err := e.CreateWithOverlaps(egressDestinations)
+	return createdDestinations, err
+}
+
+// CreateWithOverlaps creates the destinations and returns how they overlap
+// other destinations. When the store is in reject mode any overlap fails
+// the create instead.
+func (e *EgressDestinationStore) CreateWithOverlaps(egressDestinations []EgressDestination) ([]EgressDestination, []DestinationOverlap, error) {
+	return e.create(egressDestinations, e.OverlapMode == OverlapModeReject)
+}
+
+// CreateAllowingOverlaps creates the destinations and returns how they
+// overlap other destinations, whatever the overlap mode. It is meant for
+// bulk imports, such as converted security groups, whose ranges routinely
+// overlap.
+func (e *EgressDestinationStore) CreateAllowingOverlaps(egressDestinations []EgressDestination) ([]EgressDestination, []DestinationOverlap, error) {
+	return e.create(egressDestinations, false)
+}
+
+func (e *EgressDestinationStore) create(egressDestinations []EgressDestination, rejectOverlaps bool) ([]EgressDestination, []DestinationOverlap, error) {
 	tx, err := e.Conn.Beginx()
 	if err != nil {
-		return nil, fmt.Errorf("egress destination store create transaction: %s", err)
+		return nil, nil, fmt.Errorf("egress destination store create transaction: %s", err)
+	}
+
+	existingDestinations, err := e.EgressDestinationRepo.All(tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, fmt.Errorf("egress destination store create get all: %s", err)
 	}
 
 	var results []EgressDestination
+	var overlaps []DestinationOverlap
 	for _, egressDestination := range egressDestinations {
 
 		destinations, err := e.EgressDestinationRepo.GetByName(tx, egressDestination.Name)
 		if err != nil {
 			tx.Rollback()
-			return nil, fmt.Errorf("egress destination store create get by name: %s", err)
+			return nil, nil, fmt.Errorf("egress destination store create get by name: %s", err)
 		}
 
 		if len(destinations) > 0 {
@@ -191,66 +333,105 @@ func (e *EgressDestinationStore) Create(egressDestinations []EgressDestination)
 			}
 		}
 
+		destinationOverlaps := FindOverlaps(egressDestination, existingDestinations)
+		if len(destinationOverlaps) > 0 && rejectOverlaps {
+			tx.Rollback()
+			return nil, nil, NewOverlappingDestinationError("create", destinationOverlaps[0])
+		}
+		overlaps = append(overlaps, destinationOverlaps...)
+
 		destinationTerminalGUID, err := e.TerminalsRepo.Create(tx)
 		if err != nil {
 			tx.Rollback()
-			return nil, fmt.Errorf("egress destination store create terminal: %s", err)
+			return nil, nil, fmt.Errorf("egress destination store create terminal: %s", err)
 		}
 
-		err = e.DestinationMetadataRepo.Upsert(tx, destinationTerminalGUID, egressDestination.Name, egressDestination.Description)
+		err = e.DestinationMetadataRepo.Upsert(tx, destinationTerminalGUID, egressDestination.Name, egressDestination.Description, egressDestination.FQDN, egressDestination.OrgGUID, egressDestination.SelfService)
 		if err != nil {
 			tx.Rollback()
 			if isDuplicateError(err) {
-				return nil, fmt.Errorf("egress destination store create destination metadata: duplicate name error: entry with name '%s' already exists", egressDestination.Name)
+				return nil, nil, fmt.Errorf("egress destination store create destination metadata: duplicate name error: entry with name '%s' already exists", egressDestination.Name)
 			}
-			return nil, fmt.Errorf("egress destination store create destination metadata: %s", err)
-		}
-
-		var startPort, endPort int64
-		if len(egressDestination.Ports) > 0 {
-			startPort = int64(egressDestination.Ports[0].Start)
-			endPort = int64(egressDestination.Ports[0].End)
+			return nil, nil, fmt.Errorf("egress destination store create destination metadata: %s", err)
 		}
 
-		_, err = e.EgressDestinationRepo.CreateIPRange(
-			tx,
-			destinationTerminalGUID,
-			egressDestination.IPRanges[0].Start,
-			egressDestination.IPRanges[0].End,
-			egressDestination.Protocol,
-			startPort,
-			endPort,
-			int64(egressDestination.ICMPType),
-			int64(egressDestination.ICMPCode),
-		)
+		err = e.createIPRanges(tx, destinationTerminalGUID, egressDestination)
 		if err != nil {
 			tx.Rollback()
-			return nil, fmt.Errorf("egress destination store create ip range: %s", err)
+			return nil, nil, fmt.Errorf("egress destination store create ip range: %s", err)
 		}
 
 		egressDestination.GUID = destinationTerminalGUID
 		results = append(results, egressDestination)
+		existingDestinations = append(existingDestinations, egressDestination)
 	}
 
 	err = tx.Commit()
 	if err != nil {
 		tx.Rollback()
-		return nil, fmt.Errorf("egress destination store commit transaction: %s", err)
+		return nil, nil, fmt.Errorf("egress destination store commit transaction: %s", err)
 	}
 
-	return results, nil
+	return results, overlaps, nil
 }
 
+// createIPRanges writes one ip_ranges row for every combination of the
+// destination's IP ranges and port ranges.
+func (e *EgressDestinationStore) createIPRanges(tx db.Transaction, terminalGUID string, egressDestination EgressDestination) error {
+	ports := egressDestination.Ports
+	if len(ports) == 0 {
+		ports = []Ports{{}}
+	}
+
+	for _, ipRange := range egressDestination.IPRanges {
+		for _, portRange := range ports {
+			_, err := e.EgressDestinationRepo.CreateIPRange(
+				tx,
+				terminalGUID,
+				ipRange.Start,
+				ipRange.End,
+				egressDestination.Protocol,
+				int64(portRange.Start),
+				int64(portRange.End),
+				int64(egressDestination.ICMPType),
+				int64(egressDestination.ICMPCode),
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// FQDN destinations are compared by hostname since their resolved addresses
+// are expected to change over time.
 func isDuplicateDestination(a, b EgressDestination) bool {
+	sameAddresses := reflect.DeepEqual(a.IPRanges, b.IPRanges)
+	if a.FQDN != "" || b.FQDN != "" {
+		sameAddresses = a.FQDN == b.FQDN
+	}
+
 	return a.Name == b.Name &&
 		a.Description == b.Description &&
+		a.OrgGUID == b.OrgGUID &&
+		a.SelfService == b.SelfService &&
 		a.Protocol == b.Protocol &&
 		reflect.DeepEqual(a.Ports, b.Ports) &&
-		reflect.DeepEqual(a.IPRanges, b.IPRanges) &&
+		sameAddresses &&
 		a.ICMPType == b.ICMPType &&
 		a.ICMPCode == b.ICMPCode
 }
 
+func containsGUID(guids []string, guid string) bool {
+	for _, g := range guids {
+		if g == guid {
+			return true
+		}
+	}
+	return false
+}
+
 func isDuplicateError(err error) bool {
 	switch typedErr := err.(type) {
 	case *pq.Error:
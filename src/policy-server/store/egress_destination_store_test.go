@@ -216,8 +216,8 @@ var _ = Describe("EgressDestinationStore", func() {
 				destinationToUpdate1.Name = "dest-1-updated"
 				destinationToUpdate1.Description = "desc-1-updated"
 				destinationToUpdate1.Protocol = "tcp-updated"
-				destinationToUpdate1.IPRanges = []store.IPRange{{Start: "2.3.3.3", End: "2.3.3.4"}}
-				destinationToUpdate1.Ports = []store.Ports{{Start: 9090, End: 9091}}
+				destinationToUpdate1.IPRanges = []store.IPRange{{Start: "2.3.3.3", End: "2.3.3.4"}, {Start: "10.0.0.1", End: "10.0.0.5"}}
+				destinationToUpdate1.Ports = []store.Ports{{Start: 9090, End: 9091}, {Start: 443, End: 443}}
 
 				destinationToUpdate2 := createdDestinations[1]
 				destinationToUpdate2.Name = "dest-2-updated"
@@ -345,6 +345,124 @@ var _ = Describe("EgressDestinationStore", func() {
 				})
 			})
 
+			Context("when a destination has an fqdn", func() {
+				BeforeEach(func() {
+					toBeCreatedDestinations = []store.EgressDestination{
+						{
+							Name:     "fqdn-dest",
+							FQDN:     "example.com",
+							Protocol: "tcp",
+							IPRanges: []store.IPRange{{Start: "1.2.2.2", End: "1.2.2.2"}},
+							Ports:    []store.Ports{{Start: 443, End: 443}},
+						},
+						{
+							Name:     "ip-dest",
+							Protocol: "tcp",
+							IPRanges: []store.IPRange{{Start: "1.2.2.4", End: "1.2.2.5"}},
+						},
+					}
+
+					var err error
+					createdDestinations, err = egressDestinationsStore.Create(toBeCreatedDestinations)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("lists only the fqdn destinations", func() {
+					destinations, err := egressDestinationsStore.GetWithFQDN()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(destinations).To(HaveLen(1))
+					Expect(destinations[0].GUID).To(Equal(createdDestinations[0].GUID))
+					Expect(destinations[0].FQDN).To(Equal("example.com"))
+				})
+
+				It("replaces the ip ranges with the resolved addresses and clears the resolution error", func() {
+					err := egressDestinationsStore.RecordFQDNResolutionError(createdDestinations[0].GUID, "no such host")
+					Expect(err).NotTo(HaveOccurred())
+
+					destinations, err := egressDestinationsStore.GetByGUID(createdDestinations[0].GUID)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(destinations[0].FQDNResolutionError).To(Equal("no such host"))
+					Expect(destinations[0].IPRanges).To(Equal([]store.IPRange{{Start: "1.2.2.2", End: "1.2.2.2"}}))
+
+					err = egressDestinationsStore.UpdateResolvedIPRanges(createdDestinations[0].GUID, []store.IPRange{
+						{Start: "3.3.3.3", End: "3.3.3.3"},
+						{Start: "4.4.4.4", End: "4.4.4.4"},
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					destinations, err = egressDestinationsStore.GetByGUID(createdDestinations[0].GUID)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(destinations).To(HaveLen(1))
+					Expect(destinations[0].FQDNResolutionError).To(Equal(""))
+					Expect(destinations[0].Protocol).To(Equal("tcp"))
+					Expect(destinations[0].Ports).To(Equal([]store.Ports{{Start: 443, End: 443}}))
+					Expect(destinations[0].IPRanges).To(Equal([]store.IPRange{
+						{Start: "3.3.3.3", End: "3.3.3.3"},
+						{Start: "4.4.4.4", End: "4.4.4.4"},
+					}))
+				})
+
+				It("treats a destination with the same fqdn but different addresses as a duplicate on create", func() {
+					toBeCreatedDestinations[0].IPRanges = []store.IPRange{{Start: "5.5.5.5", End: "5.5.5.5"}}
+
+					destinations, err := egressDestinationsStore.Create(toBeCreatedDestinations[:1])
+					Expect(err).NotTo(HaveOccurred())
+					Expect(destinations).To(HaveLen(1))
+					Expect(destinations[0].GUID).To(Equal(createdDestinations[0].GUID))
+					Expect(destinations[0].IPRanges).To(Equal([]store.IPRange{{Start: "1.2.2.2", End: "1.2.2.2"}}))
+				})
+			})
+
+			Context("when a destination belongs to an org", func() {
+				BeforeEach(func() {
+					toBeCreatedDestinations[0].OrgGUID = "some-org-guid"
+
+					var err error
+					createdDestinations, err = egressDestinationsStore.Create(toBeCreatedDestinations)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("stores the org and clears it when the destination is made global", func() {
+					destinations, err := egressDestinationsStore.GetByGUID(createdDestinations[0].GUID, createdDestinations[1].GUID)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(destinations[0].OrgGUID).To(Equal("some-org-guid"))
+					Expect(destinations[1].OrgGUID).To(Equal(""))
+
+					createdDestinations[0].OrgGUID = ""
+					_, err = egressDestinationsStore.Update(createdDestinations[:1])
+					Expect(err).NotTo(HaveOccurred())
+
+					destinations, err = egressDestinationsStore.GetByGUID(createdDestinations[0].GUID)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(destinations[0].OrgGUID).To(Equal(""))
+				})
+			})
+
+			Context("when a destination is marked self service", func() {
+				BeforeEach(func() {
+					toBeCreatedDestinations[0].SelfService = true
+
+					var err error
+					createdDestinations, err = egressDestinationsStore.Create(toBeCreatedDestinations)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("stores the mark and clears it on update", func() {
+					destinations, err := egressDestinationsStore.GetByGUID(createdDestinations[0].GUID, createdDestinations[1].GUID)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(destinations[0].SelfService).To(BeTrue())
+					Expect(destinations[1].SelfService).To(BeFalse())
+
+					createdDestinations[0].SelfService = false
+					_, err = egressDestinationsStore.Update(createdDestinations[:1])
+					Expect(err).NotTo(HaveOccurred())
+
+					destinations, err = egressDestinationsStore.GetByGUID(createdDestinations[0].GUID)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(destinations[0].SelfService).To(BeFalse())
+				})
+			})
+
 			Context("when attempting to delete a destination that is referenced by a policy", func() {
 				BeforeEach(func() {
 					toBeCreatedDestinations := []store.EgressDestination{
@@ -485,9 +603,26 @@ var _ = Describe("EgressDestinationStore", func() {
 				})
 			})
 
+			Context("when deleting the existing ip ranges fails", func() {
+				BeforeEach(func() {
+					egressDestinationRepo.DeleteReturns(errors.New("can't delete ip ranges"))
+					egressDestinationRepo.GetByGUIDReturns([]store.EgressDestination{{}}, nil)
+				})
+
+				It("rolls back the transaction", func() {
+					egressDestinationsStore.Update(destinationsToUpdate)
+					Expect(tx.RollbackCallCount()).To(Equal(1))
+				})
+
+				It("returns the error", func() {
+					_, err := egressDestinationsStore.Update(destinationsToUpdate)
+					Expect(err).To(MatchError("egress destination store update delete ip ranges: can't delete ip ranges"))
+				})
+			})
+
 			Context("when updating the destination fails", func() {
 				BeforeEach(func() {
-					egressDestinationRepo.UpdateIPRangeReturns(errors.New("can't update iprange"))
+					egressDestinationRepo.CreateIPRangeReturns(-1, errors.New("can't update iprange"))
 					egressDestinationRepo.GetByGUIDReturns([]store.EgressDestination{{}}, nil)
 				})
 
@@ -521,6 +656,38 @@ var _ = Describe("EgressDestinationStore", func() {
 		})
 
 		Context("Create", func() {
+			It("creates an ip range row for each combination of ip range and port range", func() {
+				terminalsRepo.CreateReturns("some-terminal-guid", nil)
+				_, err := egressDestinationsStore.Create([]store.EgressDestination{
+					{
+						Name:     "multi",
+						Protocol: "tcp",
+						IPRanges: []store.IPRange{{Start: "1.1.1.1", End: "1.1.1.2"}, {Start: "2.2.2.2", End: "2.2.2.2"}},
+						Ports:    []store.Ports{{Start: 80, End: 80}, {Start: 443, End: 443}},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(egressDestinationRepo.CreateIPRangeCallCount()).To(Equal(4))
+				type row struct {
+					startIP, endIP     string
+					startPort, endPort int64
+				}
+				var rows []row
+				for i := 0; i < 4; i++ {
+					_, terminalGUID, startIP, endIP, protocol, startPort, endPort, _, _ := egressDestinationRepo.CreateIPRangeArgsForCall(i)
+					Expect(terminalGUID).To(Equal("some-terminal-guid"))
+					Expect(protocol).To(Equal("tcp"))
+					rows = append(rows, row{startIP, endIP, startPort, endPort})
+				}
+				Expect(rows).To(Equal([]row{
+					{"1.1.1.1", "1.1.1.2", 80, 80},
+					{"1.1.1.1", "1.1.1.2", 443, 443},
+					{"2.2.2.2", "2.2.2.2", 80, 80},
+					{"2.2.2.2", "2.2.2.2", 443, 443},
+				}))
+			})
+
 			Context("when the transaction cannot be created", func() {
 				BeforeEach(func() {
 					mockDB.BeginxReturns(nil, errors.New("can't create a transaction"))
@@ -639,6 +806,178 @@ var _ = Describe("EgressDestinationStore", func() {
 					Expect(tx.RollbackCallCount()).To(Equal(1))
 				})
 			})
+
+			Context("when getting all destinations returns an error", func() {
+				BeforeEach(func() {
+					egressDestinationRepo.AllReturns(nil, errors.New("can't get all"))
+				})
+
+				It("returns an error and rolls back the transaction", func() {
+					_, err := egressDestinationsStore.Create([]store.EgressDestination{{}})
+					Expect(err).To(MatchError("egress destination store create get all: can't get all"))
+					Expect(tx.RollbackCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("when a new destination overlaps an existing destination", func() {
+				var newDestinations []store.EgressDestination
+
+				BeforeEach(func() {
+					terminalsRepo.CreateReturns("some-terminal-guid", nil)
+					egressDestinationRepo.AllReturns([]store.EgressDestination{
+						{
+							GUID:     "existing-guid",
+							Name:     "existing",
+							Protocol: "tcp",
+							IPRanges: []store.IPRange{{Start: "10.0.0.0", End: "10.0.0.255"}},
+							Ports:    []store.Ports{{Start: 1, End: 65535}},
+						},
+					}, nil)
+					newDestinations = []store.EgressDestination{
+						{
+							Name:     "new",
+							Protocol: "tcp",
+							IPRanges: []store.IPRange{{Start: "10.0.0.10", End: "10.0.0.10"}},
+							Ports:    []store.Ports{{Start: 443, End: 443}},
+						},
+					}
+				})
+
+				It("creates the destination and returns the overlaps", func() {
+					created, overlaps, err := egressDestinationsStore.CreateWithOverlaps(newDestinations)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(created).To(HaveLen(1))
+					Expect(overlaps).To(HaveLen(1))
+					Expect(overlaps[0].Destination.Name).To(Equal("new"))
+					Expect(overlaps[0].OverlappingDestination.Name).To(Equal("existing"))
+					Expect(overlaps[0].Relation).To(Equal(store.OverlapRelationContainedBy))
+					Expect(tx.CommitCallCount()).To(Equal(1))
+				})
+
+				It("detects overlaps between destinations in the same request", func() {
+					egressDestinationRepo.AllReturns(nil, nil)
+					_, overlaps, err := egressDestinationsStore.CreateWithOverlaps(append(newDestinations, store.EgressDestination{
+						Name:     "other",
+						Protocol: "tcp",
+						IPRanges: []store.IPRange{{Start: "10.0.0.10", End: "10.0.0.10"}},
+						Ports:    []store.Ports{{Start: 443, End: 443}},
+					}))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(overlaps).To(HaveLen(1))
+					Expect(overlaps[0].Destination.Name).To(Equal("other"))
+					Expect(overlaps[0].OverlappingDestination.Name).To(Equal("new"))
+					Expect(overlaps[0].Relation).To(Equal(store.OverlapRelationEqual))
+				})
+
+				Context("when the overlap mode is reject", func() {
+					BeforeEach(func() {
+						egressDestinationsStore.OverlapMode = store.OverlapModeReject
+					})
+
+					It("returns an overlapping destination error and rolls back the transaction", func() {
+						_, _, err := egressDestinationsStore.CreateWithOverlaps(newDestinations)
+						Expect(err).To(MatchError("egress destination store create: overlapping destination error: destination 'new' is contained by destination 'existing'"))
+						Expect(terminalsRepo.CreateCallCount()).To(Equal(0))
+						Expect(tx.RollbackCallCount()).To(Equal(1))
+						Expect(tx.CommitCallCount()).To(Equal(0))
+					})
+
+					It("creates the destination with CreateAllowingOverlaps and returns the overlaps", func() {
+						created, overlaps, err := egressDestinationsStore.CreateAllowingOverlaps(newDestinations)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(created).To(HaveLen(1))
+						Expect(overlaps).To(HaveLen(1))
+						Expect(overlaps[0].OverlappingDestination.Name).To(Equal("existing"))
+						Expect(tx.CommitCallCount()).To(Equal(1))
+					})
+				})
+			})
+		})
+
+		Context("UpdateWithOverlaps", func() {
+			var destinationToUpdate store.EgressDestination
+
+			BeforeEach(func() {
+				destinationToUpdate = store.EgressDestination{
+					GUID:     "updated-guid",
+					Name:     "updated",
+					Protocol: "udp",
+					IPRanges: []store.IPRange{{Start: "10.0.0.0", End: "10.0.1.255"}},
+					Ports:    []store.Ports{{Start: 53, End: 53}},
+				}
+				egressDestinationRepo.GetByGUIDReturns([]store.EgressDestination{destinationToUpdate}, nil)
+				egressDestinationRepo.AllReturns([]store.EgressDestination{
+					destinationToUpdate,
+					{
+						GUID:     "other-guid",
+						Name:     "other",
+						Protocol: "udp",
+						IPRanges: []store.IPRange{{Start: "10.0.1.0", End: "10.0.2.255"}},
+						Ports:    []store.Ports{{Start: 1, End: 100}},
+					},
+				}, nil)
+			})
+
+			It("returns overlaps with other destinations but not with the destination itself", func() {
+				_, overlaps, err := egressDestinationsStore.UpdateWithOverlaps([]store.EgressDestination{destinationToUpdate})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(overlaps).To(HaveLen(1))
+				Expect(overlaps[0].OverlappingDestination.Name).To(Equal("other"))
+				Expect(overlaps[0].Relation).To(Equal(store.OverlapRelationOverlaps))
+			})
+
+			Context("when the overlap mode is reject", func() {
+				BeforeEach(func() {
+					egressDestinationsStore.OverlapMode = store.OverlapModeReject
+				})
+
+				It("returns an overlapping destination error and rolls back the transaction", func() {
+					_, _, err := egressDestinationsStore.UpdateWithOverlaps([]store.EgressDestination{destinationToUpdate})
+					Expect(err).To(MatchError("egress destination store update: overlapping destination error: destination 'updated' overlaps destination 'other'"))
+					Expect(egressDestinationRepo.DeleteCallCount()).To(Equal(0))
+					Expect(tx.RollbackCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("when getting all destinations returns an error", func() {
+				BeforeEach(func() {
+					egressDestinationRepo.AllReturns(nil, errors.New("can't get all"))
+				})
+
+				It("returns an error", func() {
+					_, _, err := egressDestinationsStore.UpdateWithOverlaps([]store.EgressDestination{destinationToUpdate})
+					Expect(err).To(MatchError("egress destination store update get all: can't get all"))
+					Expect(tx.RollbackCallCount()).To(Equal(1))
+				})
+			})
+		})
+
+		Context("OverlappingGroups", func() {
+			It("groups the overlapping destinations", func() {
+				egressDestinationRepo.AllReturns([]store.EgressDestination{
+					{Name: "a", Protocol: "tcp", IPRanges: []store.IPRange{{Start: "10.0.0.0", End: "10.0.0.10"}}, Ports: []store.Ports{{Start: 80, End: 80}}},
+					{Name: "b", Protocol: "tcp", IPRanges: []store.IPRange{{Start: "10.0.0.5", End: "10.0.0.5"}}, Ports: []store.Ports{{Start: 80, End: 80}}},
+					{Name: "c", Protocol: "udp", IPRanges: []store.IPRange{{Start: "10.0.0.5", End: "10.0.0.5"}}, Ports: []store.Ports{{Start: 80, End: 80}}},
+				}, nil)
+
+				groups, err := egressDestinationsStore.OverlappingGroups()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(groups).To(HaveLen(1))
+				Expect(groups[0]).To(HaveLen(2))
+				Expect(groups[0][0].Name).To(Equal("a"))
+				Expect(groups[0][1].Name).To(Equal("b"))
+			})
+
+			Context("when getting all destinations fails", func() {
+				BeforeEach(func() {
+					mockDB.BeginxReturns(nil, errors.New("can't create a transaction"))
+				})
+
+				It("returns an error", func() {
+					_, err := egressDestinationsStore.OverlappingGroups()
+					Expect(err).To(MatchError("egress destination store get all transaction: can't create a transaction"))
+				})
+			})
 		})
 
 		Context("All", func() {
@@ -691,6 +1030,151 @@ var _ = Describe("EgressDestinationStore", func() {
 			})
 		})
 
+		Context("UpdateResolvedIPRanges", func() {
+			var (
+				err      error
+				ipRanges []store.IPRange
+			)
+
+			BeforeEach(func() {
+				ipRanges = []store.IPRange{{Start: "3.3.3.3", End: "3.3.3.3"}}
+				egressDestinationRepo.GetByGUIDReturns([]store.EgressDestination{
+					{
+						GUID:     "a-guid",
+						FQDN:     "example.com",
+						Protocol: "tcp",
+						IPRanges: []store.IPRange{{Start: "1.1.1.1", End: "1.1.1.1"}},
+						Ports:    []store.Ports{{Start: 443, End: 443}},
+					},
+				}, nil)
+			})
+
+			It("recreates the ip ranges with the existing protocol and ports", func() {
+				err = egressDestinationsStore.UpdateResolvedIPRanges("a-guid", ipRanges)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(egressDestinationRepo.DeleteCallCount()).To(Equal(1))
+				_, guid := egressDestinationRepo.DeleteArgsForCall(0)
+				Expect(guid).To(Equal("a-guid"))
+
+				Expect(egressDestinationRepo.CreateIPRangeCallCount()).To(Equal(1))
+				_, guid, startIP, endIP, protocol, startPort, endPort, _, _ := egressDestinationRepo.CreateIPRangeArgsForCall(0)
+				Expect(guid).To(Equal("a-guid"))
+				Expect(startIP).To(Equal("3.3.3.3"))
+				Expect(endIP).To(Equal("3.3.3.3"))
+				Expect(protocol).To(Equal("tcp"))
+				Expect(startPort).To(Equal(int64(443)))
+				Expect(endPort).To(Equal(int64(443)))
+
+				Expect(destinationMetadataRepo.UpdateFQDNResolutionErrorCallCount()).To(Equal(1))
+				_, guid, resolutionError := destinationMetadataRepo.UpdateFQDNResolutionErrorArgsForCall(0)
+				Expect(guid).To(Equal("a-guid"))
+				Expect(resolutionError).To(BeEmpty())
+				Expect(tx.CommitCallCount()).To(Equal(1))
+			})
+
+			Context("when the transaction cannot be created", func() {
+				BeforeEach(func() {
+					mockDB.BeginxReturns(nil, errors.New("can't create a transaction"))
+				})
+
+				It("returns an error", func() {
+					err = egressDestinationsStore.UpdateResolvedIPRanges("a-guid", ipRanges)
+					Expect(err).To(MatchError("egress destination store update resolved ip ranges transaction: can't create a transaction"))
+				})
+			})
+
+			Context("when the destination does not exist", func() {
+				BeforeEach(func() {
+					egressDestinationRepo.GetByGUIDReturns([]store.EgressDestination{}, nil)
+					err = egressDestinationsStore.UpdateResolvedIPRanges("a-guid", ipRanges)
+				})
+
+				It("rolls back the transaction", func() {
+					Expect(tx.RollbackCallCount()).To(Equal(1))
+				})
+
+				It("returns an error", func() {
+					Expect(err).To(MatchError("egress destination store update resolved ip ranges: destination GUID not found"))
+				})
+			})
+
+			Context("when deleting the existing ip ranges fails", func() {
+				BeforeEach(func() {
+					egressDestinationRepo.DeleteReturns(errors.New("can't delete"))
+					err = egressDestinationsStore.UpdateResolvedIPRanges("a-guid", ipRanges)
+				})
+
+				It("rolls back the transaction", func() {
+					Expect(tx.RollbackCallCount()).To(Equal(1))
+				})
+
+				It("returns an error", func() {
+					Expect(err).To(MatchError("egress destination store update resolved ip ranges delete ip ranges: can't delete"))
+				})
+			})
+
+			Context("when creating the ip ranges fails", func() {
+				BeforeEach(func() {
+					egressDestinationRepo.CreateIPRangeReturns(-1, errors.New("can't create"))
+					err = egressDestinationsStore.UpdateResolvedIPRanges("a-guid", ipRanges)
+				})
+
+				It("rolls back the transaction", func() {
+					Expect(tx.RollbackCallCount()).To(Equal(1))
+				})
+
+				It("returns an error", func() {
+					Expect(err).To(MatchError("egress destination store update resolved ip ranges create ip range: can't create"))
+				})
+			})
+
+			Context("when clearing the resolution error fails", func() {
+				BeforeEach(func() {
+					destinationMetadataRepo.UpdateFQDNResolutionErrorReturns(errors.New("can't update"))
+					err = egressDestinationsStore.UpdateResolvedIPRanges("a-guid", ipRanges)
+				})
+
+				It("rolls back the transaction", func() {
+					Expect(tx.RollbackCallCount()).To(Equal(1))
+				})
+
+				It("returns an error", func() {
+					Expect(err).To(MatchError("egress destination store update resolved ip ranges clear resolution error: can't update"))
+				})
+			})
+		})
+
+		Context("RecordFQDNResolutionError", func() {
+			It("stores the resolution error", func() {
+				err := egressDestinationsStore.RecordFQDNResolutionError("a-guid", "no such host")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(destinationMetadataRepo.UpdateFQDNResolutionErrorCallCount()).To(Equal(1))
+				_, guid, resolutionError := destinationMetadataRepo.UpdateFQDNResolutionErrorArgsForCall(0)
+				Expect(guid).To(Equal("a-guid"))
+				Expect(resolutionError).To(Equal("no such host"))
+				Expect(egressDestinationRepo.DeleteCallCount()).To(Equal(0))
+			})
+
+			Context("when updating the destination metadata fails", func() {
+				var err error
+
+				BeforeEach(func() {
+					destinationMetadataRepo.UpdateFQDNResolutionErrorReturns(errors.New("can't update"))
+					err = egressDestinationsStore.RecordFQDNResolutionError("a-guid", "no such host")
+				})
+
+				It("rolls back the transaction", func() {
+					Expect(tx.RollbackCallCount()).To(Equal(1))
+				})
+
+				It("returns an error", func() {
+					Expect(err).To(MatchError("egress destination store record resolution error: can't update"))
+				})
+			})
+		})
+
 		Context("Delete", func() {
 			var err error
 			Context("when the transaction cannot be created", func() {
@@ -159,10 +159,43 @@ var _ = Describe("EgressDestination", func() {
 			})
 		})
 
+		Context("when a destination has multiple ip ranges and port ranges", func() {
+			BeforeEach(func() {
+				_, err = egressDestinationTable.CreateIPRange(tx, terminalIds[0], "1.1.1.1", "2.2.2.2", "tcp", 8443, 8443, -1, -1)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = egressDestinationTable.CreateIPRange(tx, terminalIds[0], "3.3.3.3", "3.3.3.4", "tcp", 8080, 8081, -1, -1)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = egressDestinationTable.CreateIPRange(tx, terminalIds[0], "3.3.3.3", "3.3.3.4", "tcp", 8443, 8443, -1, -1)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns one destination with the distinct ranges", func() {
+				destinations, err := egressDestinationTable.All(tx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(destinations).To(HaveLen(2))
+
+				Expect(destinations[0].GUID).To(Equal(terminalIds[0]))
+				Expect(destinations[0].IPRanges).To(Equal([]store.IPRange{
+					{Start: "1.1.1.1", End: "2.2.2.2"},
+					{Start: "3.3.3.3", End: "3.3.3.4"},
+				}))
+				Expect(destinations[0].Ports).To(Equal([]store.Ports{
+					{Start: 8080, End: 8081},
+					{Start: 8443, End: 8443},
+				}))
+
+				destinations, err = egressDestinationTable.GetByGUID(tx, terminalIds[0])
+				Expect(err).NotTo(HaveOccurred())
+				Expect(destinations).To(HaveLen(1))
+				Expect(destinations[0].IPRanges).To(HaveLen(2))
+				Expect(destinations[0].Ports).To(HaveLen(2))
+			})
+		})
+
 		Context("when a destination metadata exist for destination", func() {
 			BeforeEach(func() {
 				metadataTable := store.DestinationMetadataTable{}
-				err = metadataTable.Upsert(tx, terminalIds[0], "dest name", "dest desc")
+				err = metadataTable.Upsert(tx, terminalIds[0], "dest name", "dest desc", "", "", false)
 				Expect(err).NotTo(HaveOccurred())
 			})
 
@@ -206,6 +239,42 @@ var _ = Describe("EgressDestination", func() {
 				})
 			})
 		})
+		Context("when a destination has an fqdn", func() {
+			var metadataTable store.DestinationMetadataTable
+
+			BeforeEach(func() {
+				metadataTable = store.DestinationMetadataTable{}
+				err = metadataTable.Upsert(tx, terminalIds[0], "dest name", "dest desc", "", "", false)
+				Expect(err).NotTo(HaveOccurred())
+				err = metadataTable.Upsert(tx, terminalIds[1], "fqdn name", "fqdn desc", "example.com", "", false)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns the fqdn and resolution error", func() {
+				err = metadataTable.UpdateFQDNResolutionError(tx, terminalIds[1], "no such host")
+				Expect(err).NotTo(HaveOccurred())
+
+				destinations, err := egressDestinationTable.GetByGUID(tx, terminalIds...)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(destinations).To(HaveLen(2))
+				Expect(destinations[0].FQDN).To(Equal(""))
+				Expect(destinations[0].FQDNResolutionError).To(Equal(""))
+				Expect(destinations[1].FQDN).To(Equal("example.com"))
+				Expect(destinations[1].FQDNResolutionError).To(Equal("no such host"))
+			})
+
+			Context("GetWithFQDN", func() {
+				It("returns only the destinations with an fqdn", func() {
+					destinations, err := egressDestinationTable.GetWithFQDN(tx)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(destinations).To(HaveLen(1))
+					Expect(destinations[0].GUID).To(Equal(terminalIds[1]))
+					Expect(destinations[0].Name).To(Equal("fqdn name"))
+					Expect(destinations[0].FQDN).To(Equal("example.com"))
+					Expect(destinations[0].IPRanges).To(Equal([]store.IPRange{{Start: "1.1.1.2", End: "2.2.2.3"}}))
+				})
+			})
+		})
 	})
 
 	Context("edge cases with fake database", func() {
@@ -222,15 +291,20 @@ var _ = Describe("EgressDestination", func() {
 				tx.ExecReturns(nil, errors.New("bad things happened"))
 			})
 			It("returns the error", func() {
-				Expect(egressDestinationTable.UpdateIPRange(tx, "", "", "", "", int64(3), int64(4), int64(5), int64(6))).To(MatchError("bad things happened"))
+				Expect(egressDestinationTable.Delete(tx, "some-guid")).To(MatchError("bad things happened"))
 			})
 		})
 
-		Context("update", func() {
-			It("passes an error from Exec if Exec fails", func() {
-				tx.ExecReturns(nil, errors.New("bigger error"))
-				err := egressDestinationTable.UpdateIPRange(tx, "", "", "", "", int64(3), int64(4), int64(5), int64(6))
-				Expect(err).To(MatchError("bigger error"))
+		Context("GetWithFQDN", func() {
+			Context("when there is an error running the query", func() {
+				BeforeEach(func() {
+					tx.QueryxReturns(nil, errors.New("error with transaction"))
+				})
+
+				It("returns an error", func() {
+					_, err := egressDestinationTable.GetWithFQDN(tx)
+					Expect(err).To(MatchError("running query: error with transaction"))
+				})
 			})
 		})
 
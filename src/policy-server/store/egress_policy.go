@@ -100,16 +100,17 @@ func (e *EgressPolicyTable) CreateIPRange(tx db.Transaction, destinationTerminal
 	return -1, fmt.Errorf("unknown driver: %s", driverName)
 }
 
-func (e *EgressPolicyTable) CreateEgressPolicy(tx db.Transaction, sourceTerminalGUID, destinationTerminalGUID string) (string, error) {
+func (e *EgressPolicyTable) CreateEgressPolicy(tx db.Transaction, sourceTerminalGUID, destinationTerminalGUID string, log bool) (string, error) {
 	guid := e.Guids.New()
 
 	_, err := tx.Exec(tx.Rebind(`
-			INSERT INTO egress_policies (guid, source_guid, destination_guid)
-			VALUES (?,?,?)
+			INSERT INTO egress_policies (guid, source_guid, destination_guid, log)
+			VALUES (?,?,?,?)
 		`),
 		guid,
 		sourceTerminalGUID,
 		destinationTerminalGUID,
+		log,
 	)
 
 	if err != nil {
@@ -119,6 +120,19 @@ func (e *EgressPolicyTable) CreateEgressPolicy(tx db.Transaction, sourceTerminal
 	return guid, nil
 }
 
+func (e *EgressPolicyTable) UpdateEgressPolicy(tx db.Transaction, egressPolicyGUID, sourceTerminalGUID, destinationTerminalGUID string, log bool) error {
+	_, err := tx.Exec(tx.Rebind(`
+			UPDATE egress_policies SET source_guid = ?, destination_guid = ?, log = ?
+			WHERE guid = ?
+		`),
+		sourceTerminalGUID,
+		destinationTerminalGUID,
+		log,
+		egressPolicyGUID,
+	)
+	return err
+}
+
 func (e *EgressPolicyTable) CreateSpace(tx db.Transaction, sourceTerminalGUID, spaceGUID string) (int64, error) {
 	driverName := tx.DriverName()
 
@@ -156,6 +170,78 @@ func (e *EgressPolicyTable) CreateSpace(tx db.Transaction, sourceTerminalGUID, s
 	return -1, fmt.Errorf("unknown driver: %s", driverName)
 }
 
+func (e *EgressPolicyTable) CreateOrg(tx db.Transaction, sourceTerminalGUID, orgGUID string) (int64, error) {
+	driverName := tx.DriverName()
+
+	if driverName == "mysql" {
+		result, err := tx.Exec(tx.Rebind(`
+			INSERT INTO orgs (terminal_guid, org_guid)
+			VALUES (?,?)
+		`),
+			sourceTerminalGUID,
+			orgGUID,
+		)
+		if err != nil {
+			return -1, err
+		}
+
+		return result.LastInsertId()
+	} else if driverName == "postgres" {
+		var id int64
+
+		err := tx.QueryRow(tx.Rebind(`
+			INSERT INTO orgs (terminal_guid, org_guid)
+			VALUES (?,?)
+			RETURNING id
+		`),
+			sourceTerminalGUID,
+			orgGUID,
+		).Scan(&id)
+
+		if err != nil {
+			return -1, fmt.Errorf("error inserting org: %s", err)
+		}
+
+		return id, nil
+	}
+	return -1, fmt.Errorf("unknown driver: %s", driverName)
+}
+
+func (e *EgressPolicyTable) CreateDefault(tx db.Transaction, sourceTerminalGUID string) (int64, error) {
+	driverName := tx.DriverName()
+
+	if driverName == "mysql" {
+		result, err := tx.Exec(tx.Rebind(`
+			INSERT INTO defaults (terminal_guid)
+			VALUES (?)
+		`),
+			sourceTerminalGUID,
+		)
+		if err != nil {
+			return -1, err
+		}
+
+		return result.LastInsertId()
+	} else if driverName == "postgres" {
+		var id int64
+
+		err := tx.QueryRow(tx.Rebind(`
+			INSERT INTO defaults (terminal_guid)
+			VALUES (?)
+			RETURNING id
+		`),
+			sourceTerminalGUID,
+		).Scan(&id)
+
+		if err != nil {
+			return -1, fmt.Errorf("error inserting default: %s", err)
+		}
+
+		return id, nil
+	}
+	return -1, fmt.Errorf("unknown driver: %s", driverName)
+}
+
 func (e *EgressPolicyTable) DeleteEgressPolicy(tx db.Transaction, egressPolicyGUID string) error {
 	_, err := tx.Exec(tx.Rebind(`DELETE FROM egress_policies WHERE guid = ?`), egressPolicyGUID)
 	return err
@@ -176,6 +262,16 @@ func (e *EgressPolicyTable) DeleteSpace(tx db.Transaction, terminalGUID string)
 	return err
 }
 
+func (e *EgressPolicyTable) DeleteOrg(tx db.Transaction, terminalGUID string) error {
+	_, err := tx.Exec(tx.Rebind(`DELETE FROM orgs WHERE terminal_guid = ?`), terminalGUID)
+	return err
+}
+
+func (e *EgressPolicyTable) DeleteDefault(tx db.Transaction, terminalGUID string) error {
+	_, err := tx.Exec(tx.Rebind(`DELETE FROM defaults WHERE terminal_guid = ?`), terminalGUID)
+	return err
+}
+
 func (e *EgressPolicyTable) IsTerminalInUse(tx db.Transaction, terminalGUID string) (bool, error) {
 	var count int64
 	err := tx.QueryRow(tx.Rebind(`SELECT COUNT(guid) FROM egress_policies WHERE source_guid = ? OR destination_guid = ?`), terminalGUID, terminalGUID).Scan(&count)
@@ -235,6 +331,36 @@ func (e *EgressPolicyTable) GetTerminalBySpaceGUID(tx db.Transaction, spaceGUID
 	}
 }
 
+func (e *EgressPolicyTable) GetTerminalByOrgGUID(tx db.Transaction, orgGUID string) (string, error) {
+	var guid string
+
+	err := tx.QueryRow(tx.Rebind(`
+		SELECT terminal_guid FROM orgs WHERE org_guid = ?
+	`),
+		orgGUID,
+	).Scan(&guid)
+
+	if err != nil && err == sql.ErrNoRows {
+		return "", nil
+	} else {
+		return guid, err
+	}
+}
+
+func (e *EgressPolicyTable) GetDefaultTerminal(tx db.Transaction) (string, error) {
+	var guid string
+
+	err := tx.QueryRow(`
+		SELECT terminal_guid FROM defaults ORDER BY id LIMIT 1
+	`).Scan(&guid)
+
+	if err != nil && err == sql.ErrNoRows {
+		return "", nil
+	} else {
+		return guid, err
+	}
+}
+
 func (e *EgressPolicyTable) GetAllPolicies() ([]EgressPolicy, error) {
 	rows, err := e.Conn.Query(selectEgressPolicyQuery())
 	if err != nil {
@@ -247,10 +373,11 @@ func (e *EgressPolicyTable) GetAllPolicies() ([]EgressPolicy, error) {
 func (e *EgressPolicyTable) GetBySourceGuids(ids []string) ([]EgressPolicy, error) {
 
 	query := selectEgressPolicyQuery(fmt.Sprintf(`
-		WHERE apps.app_guid IN (%[1]s) OR spaces.space_guid IN (%[1]s)
+		WHERE apps.app_guid IN (%[1]s) OR spaces.space_guid IN (%[1]s) OR orgs.org_guid IN (%[1]s)
+		OR defaults.terminal_guid IS NOT NULL
 		ORDER BY ip_ranges.id;`, generateQuestionMarkString(len(ids))))
 
-	ids = append(ids, ids...)
+	ids = append(ids, append(ids, ids...)...)
 	rows, err := e.Conn.Query(e.Conn.Rebind(query), convertToInterfaceSlice(ids)...)
 	if err != nil {
 		return []EgressPolicy{}, err
@@ -259,6 +386,126 @@ func (e *EgressPolicyTable) GetBySourceGuids(ids []string) ([]EgressPolicy, erro
 	return e.convertRowsToEgressPolicies(rows)
 }
 
+func (e *EgressPolicyTable) GetByFilter(filter EgressPolicyFilter) ([]EgressPolicy, error) {
+	whereClause, args := egressPolicyFilterClause(filter)
+
+	pageClause := ""
+	if filter.Limit > 0 {
+		pageClause = "LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	rows, err := e.Conn.Query(e.Conn.Rebind(fmt.Sprintf(`
+		SELECT egress_policies.guid
+		%s
+		%s
+		ORDER BY egress_policies.id
+		%s;`, filteredEgressPoliciesFromClause, whereClause, pageClause)), args...)
+	if err != nil {
+		return []EgressPolicy{}, err
+	}
+
+	var guids []string
+	for rows.Next() {
+		var guid string
+		if err := rows.Scan(&guid); err != nil {
+			rows.Close()
+			return []EgressPolicy{}, err
+		}
+		guids = append(guids, guid)
+	}
+	rows.Close()
+
+	if len(guids) == 0 {
+		return []EgressPolicy{}, nil
+	}
+
+	rows, err = e.Conn.Query(e.Conn.Rebind(
+		selectEgressPolicyQuery(`
+			WHERE egress_policies.guid IN (`+generateQuestionMarkString(len(guids))+`)
+			ORDER BY egress_policies.id, ip_ranges.id`,
+		)),
+		convertToInterfaceSlice(guids)...)
+	if err != nil {
+		return []EgressPolicy{}, err
+	}
+
+	return e.convertRowsToEgressPolicies(rows)
+}
+
+func (e *EgressPolicyTable) CountByFilter(filter EgressPolicyFilter) (int, error) {
+	whereClause, args := egressPolicyFilterClause(filter)
+
+	var count int
+	err := e.Conn.QueryRow(e.Conn.Rebind(fmt.Sprintf(`
+		SELECT COUNT(*)
+		%s
+		%s;`, filteredEgressPoliciesFromClause, whereClause)), args...).Scan(&count)
+	return count, err
+}
+
+const filteredEgressPoliciesFromClause = `
+		FROM egress_policies
+		LEFT OUTER JOIN apps ON (egress_policies.source_guid = apps.terminal_guid)
+		LEFT OUTER JOIN spaces ON (egress_policies.source_guid = spaces.terminal_guid)
+		LEFT OUTER JOIN orgs ON (egress_policies.source_guid = orgs.terminal_guid)
+		LEFT OUTER JOIN defaults ON (egress_policies.source_guid = defaults.terminal_guid)
+		LEFT OUTER JOIN destination_metadatas ON (egress_policies.destination_guid = destination_metadatas.terminal_guid)`
+
+var sourceTypeColumns = map[string]string{
+	"app":     "apps.app_guid",
+	"space":   "spaces.space_guid",
+	"org":     "orgs.org_guid",
+	"default": "defaults.terminal_guid",
+}
+
+func egressPolicyFilterClause(filter EgressPolicyFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if len(filter.SourceIDs) > 0 {
+		questionMarks := generateQuestionMarkString(len(filter.SourceIDs))
+		conditions = append(conditions, fmt.Sprintf(
+			"(apps.app_guid IN (%[1]s) OR spaces.space_guid IN (%[1]s) OR orgs.org_guid IN (%[1]s))", questionMarks))
+		for i := 0; i < 3; i++ {
+			args = append(args, convertToInterfaceSlice(filter.SourceIDs)...)
+		}
+	}
+
+	if len(filter.SourceTypes) > 0 {
+		var typeConditions []string
+		for _, sourceType := range filter.SourceTypes {
+			column, ok := sourceTypeColumns[sourceType]
+			if !ok {
+				typeConditions = append(typeConditions, "1 = 0")
+				continue
+			}
+			typeConditions = append(typeConditions, column+" IS NOT NULL")
+		}
+		conditions = append(conditions, "("+strings.Join(typeConditions, " OR ")+")")
+	}
+
+	if len(filter.DestinationIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("egress_policies.destination_guid IN (%s)", generateQuestionMarkString(len(filter.DestinationIDs))))
+		args = append(args, convertToInterfaceSlice(filter.DestinationIDs)...)
+	}
+
+	if len(filter.DestinationNames) > 0 {
+		conditions = append(conditions, fmt.Sprintf("destination_metadatas.name IN (%s)", generateQuestionMarkString(len(filter.DestinationNames))))
+		args = append(args, convertToInterfaceSlice(filter.DestinationNames)...)
+	}
+
+	if len(filter.DestinationOrgGUIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("destination_metadatas.org_guid IN (%s)", generateQuestionMarkString(len(filter.DestinationOrgGUIDs))))
+		args = append(args, convertToInterfaceSlice(filter.DestinationOrgGUIDs)...)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
 func selectEgressPolicyQuery(extraClauses ...string) string {
 	return fmt.Sprintf(`
 		SELECT
@@ -268,6 +515,8 @@ func selectEgressPolicyQuery(extraClauses ...string) string {
 			COALESCE(destination_metadatas.description, ''),
 			apps.app_guid,
 			spaces.space_guid,
+			orgs.org_guid,
+			defaults.terminal_guid,
 			ip_ranges.terminal_guid,
 			ip_ranges.protocol,
 			ip_ranges.start_ip,
@@ -275,10 +524,13 @@ func selectEgressPolicyQuery(extraClauses ...string) string {
 			ip_ranges.start_port,
 			ip_ranges.end_port,
 			ip_ranges.icmp_type,
-			ip_ranges.icmp_code
+			ip_ranges.icmp_code,
+			egress_policies.log
 		FROM egress_policies
 		LEFT OUTER JOIN apps ON (egress_policies.source_guid = apps.terminal_guid)
 		LEFT OUTER JOIN spaces ON (egress_policies.source_guid = spaces.terminal_guid)
+		LEFT OUTER JOIN orgs ON (egress_policies.source_guid = orgs.terminal_guid)
+		LEFT OUTER JOIN defaults ON (egress_policies.source_guid = defaults.terminal_guid)
 		LEFT OUTER JOIN ip_ranges ON (egress_policies.destination_guid = ip_ranges.terminal_guid)
 		LEFT OUTER JOIN destination_metadatas ON (egress_policies.destination_guid = destination_metadatas.terminal_guid)
 		%s;`, strings.Join(extraClauses, " "))
@@ -292,10 +544,12 @@ type sqlRows interface {
 
 func (e *EgressPolicyTable) convertRowsToEgressPolicies(rows sqlRows) ([]EgressPolicy, error) {
 	var foundPolicies []EgressPolicy
+	policyIndexes := map[string]int{}
 	defer rows.Close()
 	for rows.Next() {
-		var egressPolicyGUID, sourceTerminalGUID, name, description, destinationGUID, sourceAppGUID, sourceSpaceGUID, protocol, startIP, endIP *string
+		var egressPolicyGUID, sourceTerminalGUID, name, description, destinationGUID, sourceAppGUID, sourceSpaceGUID, sourceOrgGUID, sourceDefaultGUID, protocol, startIP, endIP *string
 		var startPort, endPort, icmpType, icmpCode int
+		var log bool
 		err := rows.Scan(
 			&egressPolicyGUID,
 			&sourceTerminalGUID,
@@ -303,6 +557,8 @@ func (e *EgressPolicyTable) convertRowsToEgressPolicies(rows sqlRows) ([]EgressP
 			&description,
 			&sourceAppGUID,
 			&sourceSpaceGUID,
+			&sourceOrgGUID,
+			&sourceDefaultGUID,
 			&destinationGUID,
 			&protocol,
 			&startIP,
@@ -310,11 +566,12 @@ func (e *EgressPolicyTable) convertRowsToEgressPolicies(rows sqlRows) ([]EgressP
 			&startPort,
 			&endPort,
 			&icmpType,
-			&icmpCode)
+			&icmpCode,
+			&log)
 		if err != nil {
 			return foundPolicies, err
 		}
-		foundPolicies = append(foundPolicies, mapRowToEgressPolicy(
+		policy := mapRowToEgressPolicy(
 			egressPolicyGUID,
 			sourceTerminalGUID,
 			name,
@@ -322,20 +579,33 @@ func (e *EgressPolicyTable) convertRowsToEgressPolicies(rows sqlRows) ([]EgressP
 			destinationGUID,
 			sourceAppGUID,
 			sourceSpaceGUID,
+			sourceOrgGUID,
+			sourceDefaultGUID,
 			protocol,
 			startIP,
 			endIP,
 			startPort,
 			endPort,
 			icmpType,
-			icmpCode))
+			icmpCode,
+			log)
+
+		if index, ok := policyIndexes[policy.ID]; ok {
+			destination := &foundPolicies[index].Destination
+			destination.IPRanges = mergeIPRanges(destination.IPRanges, policy.Destination.IPRanges...)
+			destination.Ports = mergePorts(destination.Ports, policy.Destination.Ports...)
+			continue
+		}
+
+		policyIndexes[policy.ID] = len(foundPolicies)
+		foundPolicies = append(foundPolicies, policy)
 	}
 	return foundPolicies, nil
 }
 
 func mapRowToEgressPolicy(egressPolicyGUID, sourceTerminalGUID, name, description, destinationGUID,
-	sourceAppGUID, sourceSpaceGUID, protocol, startIP, endIP *string,
-	startPort, endPort, icmpType, icmpCode int) EgressPolicy {
+	sourceAppGUID, sourceSpaceGUID, sourceOrgGUID, sourceDefaultGUID, protocol, startIP, endIP *string,
+	startPort, endPort, icmpType, icmpCode int, log bool) EgressPolicy {
 
 	var ports []Ports
 	if startPort != 0 && endPort != 0 {
@@ -356,6 +626,17 @@ func mapRowToEgressPolicy(egressPolicyGUID, sourceTerminalGUID, name, descriptio
 			Type:         "space",
 			TerminalGUID: *sourceTerminalGUID,
 		}
+	case sourceOrgGUID != nil:
+		source = EgressSource{
+			ID:           *sourceOrgGUID,
+			Type:         "org",
+			TerminalGUID: *sourceTerminalGUID,
+		}
+	case sourceDefaultGUID != nil:
+		source = EgressSource{
+			Type:         "default",
+			TerminalGUID: *sourceTerminalGUID,
+		}
 	default:
 		source = EgressSource{
 			ID:           *sourceAppGUID,
@@ -382,5 +663,6 @@ func mapRowToEgressPolicy(egressPolicyGUID, sourceTerminalGUID, name, descriptio
 			ICMPType: icmpType,
 			ICMPCode: icmpCode,
 		},
+		Log: log,
 	}
 }
@@ -10,17 +10,26 @@ import (
 type egressPolicyRepo interface {
 	CreateApp(tx db.Transaction, sourceTerminalGUID string, appGUID string) (int64, error)
 	CreateIPRange(tx db.Transaction, destinationTerminalGUID string, startIP, endIP, protocol string, startPort, endPort, icmpType, icmpCode int64) (int64, error)
-	CreateEgressPolicy(tx db.Transaction, sourceTerminalGUID, destinationTerminalGUID string) (string, error)
+	CreateEgressPolicy(tx db.Transaction, sourceTerminalGUID, destinationTerminalGUID string, log bool) (string, error)
+	UpdateEgressPolicy(tx db.Transaction, egressPolicyGUID, sourceTerminalGUID, destinationTerminalGUID string, log bool) error
 	CreateSpace(tx db.Transaction, sourceTerminalGUID string, spaceGUID string) (int64, error)
+	CreateOrg(tx db.Transaction, sourceTerminalGUID string, orgGUID string) (int64, error)
+	CreateDefault(tx db.Transaction, sourceTerminalGUID string) (int64, error)
 	GetTerminalByAppGUID(tx db.Transaction, appGUID string) (string, error)
 	GetTerminalBySpaceGUID(tx db.Transaction, appGUID string) (string, error)
+	GetTerminalByOrgGUID(tx db.Transaction, orgGUID string) (string, error)
+	GetDefaultTerminal(tx db.Transaction) (string, error)
 	GetAllPolicies() ([]EgressPolicy, error)
 	GetBySourceGuids(ids []string) ([]EgressPolicy, error)
+	GetByFilter(filter EgressPolicyFilter) ([]EgressPolicy, error)
+	CountByFilter(filter EgressPolicyFilter) (int, error)
 	GetByGUID(tx db.Transaction, ids ...string) ([]EgressPolicy, error)
 	DeleteEgressPolicy(tx db.Transaction, egressPolicyGUID string) error
 	DeleteIPRange(tx db.Transaction, ipRangeID int64) error
 	DeleteApp(tx db.Transaction, terminalID string) error
 	DeleteSpace(tx db.Transaction, spaceID string) error
+	DeleteOrg(tx db.Transaction, terminalGUID string) error
+	DeleteDefault(tx db.Transaction, terminalGUID string) error
 	IsTerminalInUse(tx db.Transaction, terminalGUID string) (bool, error)
 }
 
@@ -53,57 +62,151 @@ func (e *EgressPolicyStore) Create(policies []EgressPolicy) ([]EgressPolicy, err
 func (e *EgressPolicyStore) createWithTx(tx db.Transaction, policies []EgressPolicy) ([]EgressPolicy, error) {
 	var createdPolicies []EgressPolicy
 	for _, policy := range policies {
-		var sourceTerminalGUID string
-		var err error
+		sourceTerminalGUID, err := e.findOrCreateSourceTerminal(tx, policy.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		createdPolicyGUID, err := e.EgressPolicyRepo.CreateEgressPolicy(tx, sourceTerminalGUID, policy.Destination.GUID, policy.Log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create egress policy: %s", err)
+		}
+
+		policy.ID = createdPolicyGUID
+		policy.Source.TerminalGUID = sourceTerminalGUID
+
+		createdPolicies = append(createdPolicies, policy)
+	}
+	return createdPolicies, nil
+}
+
+func (e *EgressPolicyStore) findOrCreateSourceTerminal(tx db.Transaction, source EgressSource) (string, error) {
+	var sourceTerminalGUID string
+	var err error
 
-		switch policy.Source.Type {
-		case "space":
-			sourceTerminalGUID, err = e.EgressPolicyRepo.GetTerminalBySpaceGUID(tx, policy.Source.ID)
+	switch source.Type {
+	case "space":
+		sourceTerminalGUID, err = e.EgressPolicyRepo.GetTerminalBySpaceGUID(tx, source.ID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get terminal by space guid: %s", err)
+		}
+
+		if sourceTerminalGUID == "" {
+			sourceTerminalGUID, err = e.TerminalsRepo.Create(tx)
 			if err != nil {
-				return nil, fmt.Errorf("failed to get terminal by space guid: %s", err)
+				return "", fmt.Errorf("failed to create source terminal: %s", err)
 			}
 
-			if sourceTerminalGUID == "" {
-				sourceTerminalGUID, err = e.TerminalsRepo.Create(tx)
-				if err != nil {
-					return nil, fmt.Errorf("failed to create source terminal: %s", err)
-				}
+			_, err = e.EgressPolicyRepo.CreateSpace(tx, sourceTerminalGUID, source.ID)
+			if err != nil {
+				return "", fmt.Errorf("failed to create space: %s", err)
+			}
+		}
+	case "org":
+		sourceTerminalGUID, err = e.EgressPolicyRepo.GetTerminalByOrgGUID(tx, source.ID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get terminal by org guid: %s", err)
+		}
 
-				_, err = e.EgressPolicyRepo.CreateSpace(tx, sourceTerminalGUID, policy.Source.ID)
-				if err != nil {
-					return nil, fmt.Errorf("failed to create space: %s", err)
-				}
+		if sourceTerminalGUID == "" {
+			sourceTerminalGUID, err = e.TerminalsRepo.Create(tx)
+			if err != nil {
+				return "", fmt.Errorf("failed to create source terminal: %s", err)
 			}
-		default:
-			sourceTerminalGUID, err = e.EgressPolicyRepo.GetTerminalByAppGUID(tx, policy.Source.ID)
+
+			_, err = e.EgressPolicyRepo.CreateOrg(tx, sourceTerminalGUID, source.ID)
 			if err != nil {
-				return nil, fmt.Errorf("failed to get terminal by app guid: %s", err)
+				return "", fmt.Errorf("failed to create org: %s", err)
 			}
+		}
+	case "default":
+		sourceTerminalGUID, err = e.EgressPolicyRepo.GetDefaultTerminal(tx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get default terminal: %s", err)
+		}
 
-			if sourceTerminalGUID == "" {
-				sourceTerminalGUID, err = e.TerminalsRepo.Create(tx)
-				if err != nil {
-					return nil, fmt.Errorf("failed to create source terminal: %s", err)
-				}
+		if sourceTerminalGUID == "" {
+			sourceTerminalGUID, err = e.TerminalsRepo.Create(tx)
+			if err != nil {
+				return "", fmt.Errorf("failed to create source terminal: %s", err)
+			}
 
-				_, err = e.EgressPolicyRepo.CreateApp(tx, sourceTerminalGUID, policy.Source.ID)
-				if err != nil {
-					return nil, fmt.Errorf("failed to create source app: %s", err)
-				}
+			_, err = e.EgressPolicyRepo.CreateDefault(tx, sourceTerminalGUID)
+			if err != nil {
+				return "", fmt.Errorf("failed to create default: %s", err)
 			}
 		}
-
-		createdPolicyGUID, err := e.EgressPolicyRepo.CreateEgressPolicy(tx, sourceTerminalGUID, policy.Destination.GUID)
+	default:
+		sourceTerminalGUID, err = e.EgressPolicyRepo.GetTerminalByAppGUID(tx, source.ID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create egress policy: %s", err)
+			return "", fmt.Errorf("failed to get terminal by app guid: %s", err)
 		}
 
-		policy.ID = createdPolicyGUID
-		policy.Source.TerminalGUID = sourceTerminalGUID
+		if sourceTerminalGUID == "" {
+			sourceTerminalGUID, err = e.TerminalsRepo.Create(tx)
+			if err != nil {
+				return "", fmt.Errorf("failed to create source terminal: %s", err)
+			}
 
-		createdPolicies = append(createdPolicies, policy)
+			_, err = e.EgressPolicyRepo.CreateApp(tx, sourceTerminalGUID, source.ID)
+			if err != nil {
+				return "", fmt.Errorf("failed to create source app: %s", err)
+			}
+		}
 	}
-	return createdPolicies, nil
+	return sourceTerminalGUID, nil
+}
+
+// Update points an existing egress policy at a new source and/or destination
+// without changing its GUID. The previous source is removed if no other
+// policy uses it.
+func (e *EgressPolicyStore) Update(policy EgressPolicy) (EgressPolicy, error) {
+	tx, err := e.Conn.Beginx()
+	if err != nil {
+		return EgressPolicy{}, fmt.Errorf("create transaction: %s", err)
+	}
+
+	policy, err = e.updateWithTx(tx, policy)
+	if err != nil {
+		return EgressPolicy{}, rollback(tx, err)
+	}
+
+	return policy, commit(tx)
+}
+
+func (e *EgressPolicyStore) updateWithTx(tx db.Transaction, policy EgressPolicy) (EgressPolicy, error) {
+	existingPolicies, err := e.EgressPolicyRepo.GetByGUID(tx, policy.ID)
+	if err != nil {
+		return EgressPolicy{}, fmt.Errorf("failed to find egress policy: %s", err)
+	}
+
+	if len(existingPolicies) == 0 {
+		return EgressPolicy{}, fmt.Errorf("egress policy GUID not found: %s", policy.ID)
+	}
+	existingPolicy := existingPolicies[0]
+
+	sourceTerminalGUID, err := e.findOrCreateSourceTerminal(tx, policy.Source)
+	if err != nil {
+		return EgressPolicy{}, err
+	}
+
+	err = e.EgressPolicyRepo.UpdateEgressPolicy(tx, policy.ID, sourceTerminalGUID, policy.Destination.GUID, policy.Log)
+	if err != nil {
+		if isDuplicateError(err) {
+			return EgressPolicy{}, fmt.Errorf("failed to update egress policy: policy already exists for source '%s' and destination '%s'", policy.Source.ID, policy.Destination.GUID)
+		}
+		return EgressPolicy{}, fmt.Errorf("failed to update egress policy: %s", err)
+	}
+
+	if existingPolicy.Source.TerminalGUID != sourceTerminalGUID {
+		err = e.deleteSourceIfUnused(tx, existingPolicy.Source)
+		if err != nil {
+			return EgressPolicy{}, err
+		}
+	}
+
+	policy.Source.TerminalGUID = sourceTerminalGUID
+	return policy, nil
 }
 
 func (e *EgressPolicyStore) Delete(egressPolicyGUIDs ...string) ([]EgressPolicy, error) {
@@ -136,40 +239,93 @@ func (e *EgressPolicyStore) deleteWithTx(tx db.Transaction, egressPolicyGUIDs ..
 			return []EgressPolicy{}, fmt.Errorf("failed to delete egress policy: %s", err)
 		}
 
-		terminalInUse, err := e.EgressPolicyRepo.IsTerminalInUse(tx, egressPolicy.Source.TerminalGUID)
+		err = e.deleteSourceIfUnused(tx, egressPolicy.Source)
 		if err != nil {
-			return []EgressPolicy{}, fmt.Errorf("failed to check if source terminal is in use: %s", err)
+			return []EgressPolicy{}, err
 		}
+	}
 
-		if !terminalInUse {
-			if egressPolicy.Source.Type == "app" {
-				err = e.EgressPolicyRepo.DeleteApp(tx, egressPolicy.Source.TerminalGUID)
-				if err != nil {
-					return []EgressPolicy{}, fmt.Errorf("failed to delete source app: %s", err)
-				}
-			}
+	return egressPolicies, nil
+}
 
-			if egressPolicy.Source.Type == "space" {
-				err = e.EgressPolicyRepo.DeleteSpace(tx, egressPolicy.Source.TerminalGUID)
-				if err != nil {
-					return []EgressPolicy{}, fmt.Errorf("failed to delete source space: %s", err)
-				}
-			}
+func (e *EgressPolicyStore) deleteSourceIfUnused(tx db.Transaction, source EgressSource) error {
+	terminalInUse, err := e.EgressPolicyRepo.IsTerminalInUse(tx, source.TerminalGUID)
+	if err != nil {
+		return fmt.Errorf("failed to check if source terminal is in use: %s", err)
+	}
 
-			err = e.TerminalsRepo.Delete(tx, egressPolicy.Source.TerminalGUID)
-			if err != nil {
-				return []EgressPolicy{}, fmt.Errorf("failed to delete source terminal: %s", err)
-			}
+	if terminalInUse {
+		return nil
+	}
+
+	if source.Type == "app" {
+		err = e.EgressPolicyRepo.DeleteApp(tx, source.TerminalGUID)
+		if err != nil {
+			return fmt.Errorf("failed to delete source app: %s", err)
 		}
 	}
 
-	return egressPolicies, nil
+	if source.Type == "space" {
+		err = e.EgressPolicyRepo.DeleteSpace(tx, source.TerminalGUID)
+		if err != nil {
+			return fmt.Errorf("failed to delete source space: %s", err)
+		}
+	}
+
+	if source.Type == "org" {
+		err = e.EgressPolicyRepo.DeleteOrg(tx, source.TerminalGUID)
+		if err != nil {
+			return fmt.Errorf("failed to delete source org: %s", err)
+		}
+	}
+
+	if source.Type == "default" {
+		err = e.EgressPolicyRepo.DeleteDefault(tx, source.TerminalGUID)
+		if err != nil {
+			return fmt.Errorf("failed to delete source default: %s", err)
+		}
+	}
+
+	err = e.TerminalsRepo.Delete(tx, source.TerminalGUID)
+	if err != nil {
+		return fmt.Errorf("failed to delete source terminal: %s", err)
+	}
+	return nil
+}
+
+func (e *EgressPolicyStore) GetByGUID(egressPolicyGUIDs ...string) ([]EgressPolicy, error) {
+	tx, err := e.Conn.Beginx()
+	if err != nil {
+		return []EgressPolicy{}, fmt.Errorf("create transaction: %s", err)
+	}
+	defer tx.Rollback()
+
+	policies, err := e.EgressPolicyRepo.GetByGUID(tx, egressPolicyGUIDs...)
+	if err != nil {
+		return []EgressPolicy{}, fmt.Errorf("failed to get policies by guid: %s", err)
+	}
+	return policies, nil
 }
 
 func (e *EgressPolicyStore) All() ([]EgressPolicy, error) {
 	return e.EgressPolicyRepo.GetAllPolicies()
 }
 
+// GetByFilter returns the page of policies matching the filter, along with
+// the total number of matching policies.
+func (e *EgressPolicyStore) GetByFilter(filter EgressPolicyFilter) ([]EgressPolicy, int, error) {
+	total, err := e.EgressPolicyRepo.CountByFilter(filter)
+	if err != nil {
+		return []EgressPolicy{}, 0, fmt.Errorf("failed to count policies by filter: %s", err)
+	}
+
+	policies, err := e.EgressPolicyRepo.GetByFilter(filter)
+	if err != nil {
+		return []EgressPolicy{}, 0, fmt.Errorf("failed to get policies by filter: %s", err)
+	}
+	return policies, total, nil
+}
+
 func (e *EgressPolicyStore) GetBySourceGuids(ids []string) ([]EgressPolicy, error) {
 	policies, err := e.EgressPolicyRepo.GetBySourceGuids(ids)
 	if err != nil {
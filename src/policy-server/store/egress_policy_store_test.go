@@ -53,6 +53,7 @@ var _ = Describe("EgressPolicyStore", func() {
 				Destination: store.EgressDestination{
 					GUID: "some-destination-guid-2",
 				},
+				Log: true,
 			},
 		}
 
@@ -100,18 +101,21 @@ var _ = Describe("EgressPolicyStore", func() {
 					Destination: store.EgressDestination{
 						GUID: "some-destination-guid-2",
 					},
+					Log: true,
 				},
 			}))
 
-			argTx, sourceID, destinationID := egressPolicyRepo.CreateEgressPolicyArgsForCall(0)
+			argTx, sourceID, destinationID, log := egressPolicyRepo.CreateEgressPolicyArgsForCall(0)
 			Expect(argTx).To(Equal(tx))
 			Expect(sourceID).To(Equal("some-terminal-app-guid"))
 			Expect(destinationID).To(Equal("some-destination-guid"))
+			Expect(log).To(BeFalse())
 
-			argTx, sourceID, destinationID = egressPolicyRepo.CreateEgressPolicyArgsForCall(1)
+			argTx, sourceID, destinationID, log = egressPolicyRepo.CreateEgressPolicyArgsForCall(1)
 			Expect(argTx).To(Equal(tx))
 			Expect(sourceID).To(Equal("some-terminal-space-guid"))
 			Expect(destinationID).To(Equal("some-destination-guid-2"))
+			Expect(log).To(BeTrue())
 		})
 
 		It("returns an error when the database connection can't begin a transaction", func() {
@@ -203,15 +207,17 @@ var _ = Describe("EgressPolicyStore", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(egressPolicyRepo.CreateEgressPolicyCallCount()).To(Equal(2))
 
-			argTx, sourceID, destinationID := egressPolicyRepo.CreateEgressPolicyArgsForCall(0)
+			argTx, sourceID, destinationID, log := egressPolicyRepo.CreateEgressPolicyArgsForCall(0)
 			Expect(argTx).To(Equal(tx))
 			Expect(sourceID).To(Equal("some-app-guid"))
 			Expect(destinationID).To(Equal("some-destination-guid"))
+			Expect(log).To(BeFalse())
 
-			argTx, sourceID, destinationID = egressPolicyRepo.CreateEgressPolicyArgsForCall(1)
+			argTx, sourceID, destinationID, log = egressPolicyRepo.CreateEgressPolicyArgsForCall(1)
 			Expect(argTx).To(Equal(tx))
 			Expect(sourceID).To(Equal("some-space-guid"))
 			Expect(destinationID).To(Equal("some-destination-guid-2"))
+			Expect(log).To(BeTrue())
 		})
 
 		It("returns an error when the CreateEgressPolicy fails", func() {
@@ -227,7 +233,7 @@ var _ = Describe("EgressPolicyStore", func() {
 			_, err := egressPolicyStore.Create(egressPolicies)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(egressPolicyRepo.CreateAppCallCount()).To(Equal(0))
-			_, sourceID, _ := egressPolicyRepo.CreateEgressPolicyArgsForCall(0)
+			_, sourceID, _, _ := egressPolicyRepo.CreateEgressPolicyArgsForCall(0)
 			Expect(sourceID).To(Equal("66"))
 		})
 
@@ -237,7 +243,7 @@ var _ = Describe("EgressPolicyStore", func() {
 			_, err := egressPolicyStore.Create([]store.EgressPolicy{spacePolicy})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(egressPolicyRepo.CreateSpaceCallCount()).To(Equal(0))
-			_, sourceID, _ := egressPolicyRepo.CreateEgressPolicyArgsForCall(0)
+			_, sourceID, _, _ := egressPolicyRepo.CreateEgressPolicyArgsForCall(0)
 			Expect(sourceID).To(Equal("55"))
 		})
 
@@ -270,6 +276,109 @@ var _ = Describe("EgressPolicyStore", func() {
 			_, err := egressPolicyStore.Create(egressPolicies)
 			Expect(err).To(MatchError("failed to get terminal by app guid: OMG WHY DID THIS FAIL"))
 		})
+
+		Context("when the source is an org", func() {
+			var orgPolicy store.EgressPolicy
+
+			BeforeEach(func() {
+				orgPolicy = store.EgressPolicy{
+					Source: store.EgressSource{
+						Type: "org",
+						ID:   "org-guid",
+					},
+					Destination: store.EgressDestination{
+						GUID: "some-destination-guid",
+					},
+				}
+			})
+
+			It("creates an org with a sourceTerminalGUID", func() {
+				terminalsRepo.CreateReturns("some-term-guid", nil)
+				_, err := egressPolicyStore.Create([]store.EgressPolicy{orgPolicy})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(egressPolicyRepo.CreateOrgCallCount()).To(Equal(1))
+				Expect(egressPolicyRepo.CreateAppCallCount()).To(Equal(0))
+				argTx, argSourceTerminalGUID, argOrgGUID := egressPolicyRepo.CreateOrgArgsForCall(0)
+				Expect(argTx).To(Equal(tx))
+				Expect(argSourceTerminalGUID).To(Equal("some-term-guid"))
+				Expect(argOrgGUID).To(Equal("org-guid"))
+			})
+
+			It("uses the existing org terminal id when it exists", func() {
+				egressPolicyRepo.GetTerminalByOrgGUIDReturns("77", nil)
+
+				_, err := egressPolicyStore.Create([]store.EgressPolicy{orgPolicy})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(egressPolicyRepo.CreateOrgCallCount()).To(Equal(0))
+				_, sourceID, _, _ := egressPolicyRepo.CreateEgressPolicyArgsForCall(0)
+				Expect(sourceID).To(Equal("77"))
+			})
+
+			It("returns an error when the GetTerminalByOrgGUID fails", func() {
+				egressPolicyRepo.GetTerminalByOrgGUIDReturns("", errors.New("OMG WHY DID THIS FAIL"))
+
+				_, err := egressPolicyStore.Create([]store.EgressPolicy{orgPolicy})
+				Expect(err).To(MatchError("failed to get terminal by org guid: OMG WHY DID THIS FAIL"))
+			})
+
+			It("returns an error when the CreateOrg fails", func() {
+				egressPolicyRepo.CreateOrgReturns(-1, errors.New("OMG WHY DID THIS FAIL"))
+
+				_, err := egressPolicyStore.Create([]store.EgressPolicy{orgPolicy})
+				Expect(err).To(MatchError("failed to create org: OMG WHY DID THIS FAIL"))
+			})
+		})
+
+		Context("when the source is the platform default", func() {
+			var defaultPolicy store.EgressPolicy
+
+			BeforeEach(func() {
+				defaultPolicy = store.EgressPolicy{
+					Source: store.EgressSource{
+						Type: "default",
+					},
+					Destination: store.EgressDestination{
+						GUID: "some-destination-guid",
+					},
+				}
+			})
+
+			It("creates the default source with a sourceTerminalGUID", func() {
+				terminalsRepo.CreateReturns("some-term-guid", nil)
+				_, err := egressPolicyStore.Create([]store.EgressPolicy{defaultPolicy})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(egressPolicyRepo.CreateDefaultCallCount()).To(Equal(1))
+				Expect(egressPolicyRepo.CreateAppCallCount()).To(Equal(0))
+				argTx, argSourceTerminalGUID := egressPolicyRepo.CreateDefaultArgsForCall(0)
+				Expect(argTx).To(Equal(tx))
+				Expect(argSourceTerminalGUID).To(Equal("some-term-guid"))
+			})
+
+			It("uses the existing default terminal id when it exists", func() {
+				egressPolicyRepo.GetDefaultTerminalReturns("88", nil)
+
+				_, err := egressPolicyStore.Create([]store.EgressPolicy{defaultPolicy})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(egressPolicyRepo.CreateDefaultCallCount()).To(Equal(0))
+				Expect(terminalsRepo.CreateCallCount()).To(Equal(0))
+				_, sourceID, _, _ := egressPolicyRepo.CreateEgressPolicyArgsForCall(0)
+				Expect(sourceID).To(Equal("88"))
+			})
+
+			It("returns an error when the GetDefaultTerminal fails", func() {
+				egressPolicyRepo.GetDefaultTerminalReturns("", errors.New("OMG WHY DID THIS FAIL"))
+
+				_, err := egressPolicyStore.Create([]store.EgressPolicy{defaultPolicy})
+				Expect(err).To(MatchError("failed to get default terminal: OMG WHY DID THIS FAIL"))
+			})
+
+			It("returns an error when the CreateDefault fails", func() {
+				egressPolicyRepo.CreateDefaultReturns(-1, errors.New("OMG WHY DID THIS FAIL"))
+
+				_, err := egressPolicyStore.Create([]store.EgressPolicy{defaultPolicy})
+				Expect(err).To(MatchError("failed to create default: OMG WHY DID THIS FAIL"))
+			})
+		})
 	})
 
 	Describe("Delete", func() {
@@ -401,6 +510,52 @@ var _ = Describe("EgressPolicyStore", func() {
 			})
 		})
 
+		Context("when the sources are an org and the platform default", func() {
+			BeforeEach(func() {
+				expectedEgressPolicies[0].Source = store.EgressSource{
+					TerminalGUID: srcTerminalGUID,
+					ID:           "some-org-guid",
+					Type:         "org",
+				}
+				expectedEgressPolicies[1].Source = store.EgressSource{
+					TerminalGUID: srcTerminalGUID2,
+					Type:         "default",
+				}
+				egressPolicyRepo.GetByGUIDReturns(expectedEgressPolicies, nil)
+			})
+
+			It("deletes the org and default sources", func() {
+				_, err := egressPolicyStore.Delete(egressPolicyGUID, egressPolicyGUID2)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(egressPolicyRepo.DeleteOrgCallCount()).To(Equal(1))
+				passedTx, passedSrcTerminalGUID := egressPolicyRepo.DeleteOrgArgsForCall(0)
+				Expect(passedTx).To(Equal(tx))
+				Expect(passedSrcTerminalGUID).To(Equal(srcTerminalGUID))
+
+				Expect(egressPolicyRepo.DeleteDefaultCallCount()).To(Equal(1))
+				passedTx, passedSrcTerminalGUID = egressPolicyRepo.DeleteDefaultArgsForCall(0)
+				Expect(passedTx).To(Equal(tx))
+				Expect(passedSrcTerminalGUID).To(Equal(srcTerminalGUID2))
+
+				Expect(egressPolicyRepo.DeleteAppCallCount()).To(Equal(0))
+				Expect(egressPolicyRepo.DeleteSpaceCallCount()).To(Equal(0))
+				Expect(terminalsRepo.DeleteCallCount()).To(Equal(2))
+			})
+
+			It("returns an error when the EgressPolicyRepo.DeleteOrg fails", func() {
+				egressPolicyRepo.DeleteOrgReturns(errors.New("ther's a bug"))
+				_, err := egressPolicyStore.Delete(egressPolicyGUID, egressPolicyGUID2)
+				Expect(err).To(MatchError("failed to delete source org: ther's a bug"))
+			})
+
+			It("returns an error when the EgressPolicyRepo.DeleteDefault fails", func() {
+				egressPolicyRepo.DeleteDefaultReturns(errors.New("ther's a bug"))
+				_, err := egressPolicyStore.Delete(egressPolicyGUID, egressPolicyGUID2)
+				Expect(err).To(MatchError("failed to delete source default: ther's a bug"))
+			})
+		})
+
 		Context("when the egress policy doesn't exist", func() {
 			BeforeEach(func() {
 				egressPolicyRepo.GetByGUIDReturns([]store.EgressPolicy{}, nil)
@@ -530,4 +685,230 @@ var _ = Describe("EgressPolicyStore", func() {
 			})
 		})
 	})
+
+	Describe("GetByFilter", func() {
+		var filter store.EgressPolicyFilter
+
+		BeforeEach(func() {
+			filter = store.EgressPolicyFilter{SourceTypes: []string{"space"}, Limit: 2, Offset: 4}
+			egressPolicyRepo.CountByFilterReturns(7, nil)
+			egressPolicyRepo.GetByFilterReturns(egressPolicies, nil)
+		})
+
+		It("returns the matching policies and the total count", func() {
+			policies, total, err := egressPolicyStore.GetByFilter(filter)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policies).To(Equal(egressPolicies))
+			Expect(total).To(Equal(7))
+
+			Expect(egressPolicyRepo.CountByFilterArgsForCall(0)).To(Equal(filter))
+			Expect(egressPolicyRepo.GetByFilterArgsForCall(0)).To(Equal(filter))
+		})
+
+		Context("when counting the policies fails", func() {
+			BeforeEach(func() {
+				egressPolicyRepo.CountByFilterReturns(0, errors.New("bark bark"))
+			})
+
+			It("returns an error", func() {
+				_, _, err := egressPolicyStore.GetByFilter(filter)
+				Expect(err).To(MatchError("failed to count policies by filter: bark bark"))
+			})
+		})
+
+		Context("when getting the policies fails", func() {
+			BeforeEach(func() {
+				egressPolicyRepo.GetByFilterReturns(nil, errors.New("bark bark"))
+			})
+
+			It("returns an error", func() {
+				_, _, err := egressPolicyStore.GetByFilter(filter)
+				Expect(err).To(MatchError("failed to get policies by filter: bark bark"))
+			})
+		})
+	})
+
+	Describe("GetByGUID", func() {
+		BeforeEach(func() {
+			egressPolicyRepo.GetByGUIDReturns(egressPolicies, nil)
+		})
+
+		It("returns the policies from the repo", func() {
+			policies, err := egressPolicyStore.GetByGUID("some-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policies).To(Equal(egressPolicies))
+
+			passedTx, ids := egressPolicyRepo.GetByGUIDArgsForCall(0)
+			Expect(passedTx).To(Equal(tx))
+			Expect(ids).To(Equal([]string{"some-guid"}))
+			Expect(tx.RollbackCallCount()).To(Equal(1))
+		})
+
+		It("returns an error when beginning a transaction fails", func() {
+			mockDb.BeginxReturns(nil, errors.New("no tx"))
+			_, err := egressPolicyStore.GetByGUID("some-guid")
+			Expect(err).To(MatchError("create transaction: no tx"))
+		})
+
+		It("returns an error when the repo fails", func() {
+			egressPolicyRepo.GetByGUIDReturns(nil, errors.New("bark bark"))
+			_, err := egressPolicyStore.GetByGUID("some-guid")
+			Expect(err).To(MatchError("failed to get policies by guid: bark bark"))
+		})
+	})
+
+	Describe("Update", func() {
+		var (
+			existingPolicy store.EgressPolicy
+			updatedPolicy  store.EgressPolicy
+		)
+
+		BeforeEach(func() {
+			existingPolicy = store.EgressPolicy{
+				ID: "some-policy-guid",
+				Source: store.EgressSource{
+					ID:           "old-app-guid",
+					Type:         "app",
+					TerminalGUID: "old-app-terminal-guid",
+				},
+				Destination: store.EgressDestination{
+					GUID: "old-destination-guid",
+				},
+			}
+			egressPolicyRepo.GetByGUIDReturns([]store.EgressPolicy{existingPolicy}, nil)
+			egressPolicyRepo.GetTerminalBySpaceGUIDReturns("new-space-terminal-guid", nil)
+
+			updatedPolicy = store.EgressPolicy{
+				ID: "some-policy-guid",
+				Source: store.EgressSource{
+					ID:   "new-space-guid",
+					Type: "space",
+				},
+				Destination: store.EgressDestination{
+					GUID: "new-destination-guid",
+				},
+				Log: true,
+			}
+		})
+
+		It("updates the source and destination in place and commits", func() {
+			policy, err := egressPolicyStore.Update(updatedPolicy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy.ID).To(Equal("some-policy-guid"))
+			Expect(policy.Source.TerminalGUID).To(Equal("new-space-terminal-guid"))
+
+			_, guids := egressPolicyRepo.GetByGUIDArgsForCall(0)
+			Expect(guids).To(Equal([]string{"some-policy-guid"}))
+
+			Expect(egressPolicyRepo.UpdateEgressPolicyCallCount()).To(Equal(1))
+			passedTx, policyGUID, sourceTerminalGUID, destinationTerminalGUID, log := egressPolicyRepo.UpdateEgressPolicyArgsForCall(0)
+			Expect(passedTx).To(Equal(tx))
+			Expect(policyGUID).To(Equal("some-policy-guid"))
+			Expect(sourceTerminalGUID).To(Equal("new-space-terminal-guid"))
+			Expect(destinationTerminalGUID).To(Equal("new-destination-guid"))
+			Expect(log).To(BeTrue())
+
+			Expect(egressPolicyRepo.CreateEgressPolicyCallCount()).To(Equal(0))
+			Expect(egressPolicyRepo.DeleteEgressPolicyCallCount()).To(Equal(0))
+			Expect(tx.CommitCallCount()).To(Equal(1))
+		})
+
+		It("removes the previous source when it is no longer used", func() {
+			_, err := egressPolicyStore.Update(updatedPolicy)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(egressPolicyRepo.IsTerminalInUseCallCount()).To(Equal(1))
+			_, terminalGUID := egressPolicyRepo.IsTerminalInUseArgsForCall(0)
+			Expect(terminalGUID).To(Equal("old-app-terminal-guid"))
+
+			Expect(egressPolicyRepo.DeleteAppCallCount()).To(Equal(1))
+			_, terminalGUID = egressPolicyRepo.DeleteAppArgsForCall(0)
+			Expect(terminalGUID).To(Equal("old-app-terminal-guid"))
+
+			Expect(terminalsRepo.DeleteCallCount()).To(Equal(1))
+			_, terminalGUID = terminalsRepo.DeleteArgsForCall(0)
+			Expect(terminalGUID).To(Equal("old-app-terminal-guid"))
+		})
+
+		Context("when the previous source is still in use", func() {
+			BeforeEach(func() {
+				egressPolicyRepo.IsTerminalInUseReturns(true, nil)
+			})
+
+			It("keeps the previous source", func() {
+				_, err := egressPolicyStore.Update(updatedPolicy)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(egressPolicyRepo.DeleteAppCallCount()).To(Equal(0))
+				Expect(terminalsRepo.DeleteCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when only the destination changes", func() {
+			BeforeEach(func() {
+				egressPolicyRepo.GetTerminalByAppGUIDReturns("old-app-terminal-guid", nil)
+				updatedPolicy.Source = store.EgressSource{ID: "old-app-guid", Type: "app"}
+			})
+
+			It("does not touch the source", func() {
+				_, err := egressPolicyStore.Update(updatedPolicy)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(egressPolicyRepo.IsTerminalInUseCallCount()).To(Equal(0))
+				Expect(terminalsRepo.CreateCallCount()).To(Equal(0))
+				Expect(terminalsRepo.DeleteCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the egress policy doesn't exist", func() {
+			BeforeEach(func() {
+				egressPolicyRepo.GetByGUIDReturns([]store.EgressPolicy{}, nil)
+			})
+
+			It("returns a not found error and rolls back", func() {
+				_, err := egressPolicyStore.Update(updatedPolicy)
+				Expect(err).To(MatchError("egress policy GUID not found: some-policy-guid"))
+				Expect(egressPolicyRepo.UpdateEgressPolicyCallCount()).To(Equal(0))
+				Expect(tx.RollbackCallCount()).To(Equal(1))
+			})
+		})
+
+		It("returns an error when beginning a transaction fails", func() {
+			mockDb.BeginxReturns(nil, errors.New("no tx"))
+			_, err := egressPolicyStore.Update(updatedPolicy)
+			Expect(err).To(MatchError("create transaction: no tx"))
+		})
+
+		It("returns an error when the EgressPolicyRepo.GetByGUID fails", func() {
+			egressPolicyRepo.GetByGUIDReturns(nil, errors.New("bark"))
+			_, err := egressPolicyStore.Update(updatedPolicy)
+			Expect(err).To(MatchError("failed to find egress policy: bark"))
+			Expect(tx.RollbackCallCount()).To(Equal(1))
+		})
+
+		It("returns an error when finding the source terminal fails", func() {
+			egressPolicyRepo.GetTerminalBySpaceGUIDReturns("", errors.New("bark"))
+			_, err := egressPolicyStore.Update(updatedPolicy)
+			Expect(err).To(MatchError("failed to get terminal by space guid: bark"))
+			Expect(tx.RollbackCallCount()).To(Equal(1))
+		})
+
+		It("returns an error when the EgressPolicyRepo.UpdateEgressPolicy fails", func() {
+			egressPolicyRepo.UpdateEgressPolicyReturns(errors.New("bark"))
+			_, err := egressPolicyStore.Update(updatedPolicy)
+			Expect(err).To(MatchError("failed to update egress policy: bark"))
+			Expect(tx.RollbackCallCount()).To(Equal(1))
+		})
+
+		It("returns an error when removing the previous source fails", func() {
+			egressPolicyRepo.DeleteAppReturns(errors.New("bark"))
+			_, err := egressPolicyStore.Update(updatedPolicy)
+			Expect(err).To(MatchError("failed to delete source app: bark"))
+			Expect(tx.RollbackCallCount()).To(Equal(1))
+		})
+
+		It("returns an error when commit transaction fails", func() {
+			tx.CommitReturns(errors.New("failed to commit"))
+			_, err := egressPolicyStore.Update(updatedPolicy)
+			Expect(err).To(MatchError("commit transaction: failed to commit"))
+		})
+	})
 })
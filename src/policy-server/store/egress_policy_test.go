@@ -146,6 +146,66 @@ var _ = Describe("Egress Policy Table", func() {
 		})
 	})
 
+	Context("CreateOrg", func() {
+		It("should create an org and return the ID", func() {
+			db, tx := getMigratedRealDb(dbConf)
+			setupEgressPolicyStore(db)
+
+			orgTerminalGUID, err := terminalsTable.Create(tx)
+			Expect(err).ToNot(HaveOccurred())
+
+			id, err := egressPolicyTable.CreateOrg(tx, orgTerminalGUID, "some-org-guid")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(id).To(Equal(int64(1)))
+
+			var foundOrgGuid string
+			row := tx.QueryRow(`SELECT org_guid FROM orgs WHERE id = 1`)
+			err = row.Scan(&foundOrgGuid)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(foundOrgGuid).To(Equal("some-org-guid"))
+		})
+
+		It("should return an error if the driver is not supported", func() {
+			setupEgressPolicyStore(mockDb)
+			fakeTx := &dbfakes.Transaction{}
+
+			fakeTx.DriverNameReturns("db2")
+			_, err := egressPolicyTable.CreateOrg(fakeTx, "some-term-guid", "some-org-guid")
+			Expect(err).To(MatchError("unknown driver: db2"))
+		})
+	})
+
+	Context("CreateDefault", func() {
+		It("should create the default source and return the ID", func() {
+			db, tx := getMigratedRealDb(dbConf)
+			setupEgressPolicyStore(db)
+
+			defaultTerminalGUID, err := terminalsTable.Create(tx)
+			Expect(err).ToNot(HaveOccurred())
+
+			id, err := egressPolicyTable.CreateDefault(tx, defaultTerminalGUID)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(id).To(Equal(int64(1)))
+
+			var foundTerminalGUID string
+			row := tx.QueryRow(`SELECT terminal_guid FROM defaults WHERE id = 1`)
+			err = row.Scan(&foundTerminalGUID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(foundTerminalGUID).To(Equal(defaultTerminalGUID))
+		})
+
+		It("should return an error if the driver is not supported", func() {
+			setupEgressPolicyStore(mockDb)
+			fakeTx := &dbfakes.Transaction{}
+
+			fakeTx.DriverNameReturns("db2")
+			_, err := egressPolicyTable.CreateDefault(fakeTx, "some-term-guid")
+			Expect(err).To(MatchError("unknown driver: db2"))
+		})
+	})
+
 	Context("CreateIPRange", func() {
 
 		It("should create an iprange and return the ID", func() {
@@ -209,19 +269,21 @@ var _ = Describe("Egress Policy Table", func() {
 			destinationTerminalId, err := terminalsTable.Create(tx)
 			Expect(err).ToNot(HaveOccurred())
 
-			guid, err := egressPolicyTable.CreateEgressPolicy(tx, sourceTerminalId, destinationTerminalId)
+			guid, err := egressPolicyTable.CreateEgressPolicy(tx, sourceTerminalId, destinationTerminalId, true)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(guid).To(Equal("guid-1"))
 
 			var foundSourceID, foundDestinationID string
-			row := tx.QueryRow(tx.Rebind(`SELECT source_guid, destination_guid FROM egress_policies WHERE guid = ?`), guid)
-			err = row.Scan(&foundSourceID, &foundDestinationID)
+			var foundLog bool
+			row := tx.QueryRow(tx.Rebind(`SELECT source_guid, destination_guid, log FROM egress_policies WHERE guid = ?`), guid)
+			err = row.Scan(&foundSourceID, &foundDestinationID, &foundLog)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(foundSourceID).To(Equal(sourceTerminalId))
 			Expect(foundDestinationID).To(Equal(destinationTerminalId))
+			Expect(foundLog).To(BeTrue())
 
 			By("checking that if bad args are sent, it returns an error") // merged because db's are slow
-			_, err = egressPolicyTable.CreateEgressPolicy(tx, "some-term-guid", "some-term-guid")
+			_, err = egressPolicyTable.CreateEgressPolicy(tx, "some-term-guid", "some-term-guid", false)
 			Expect(err).To(HaveOccurred())
 		})
 	})
@@ -236,7 +298,7 @@ var _ = Describe("Egress Policy Table", func() {
 			destinationTerminalId, err := terminalsTable.Create(tx)
 			Expect(err).ToNot(HaveOccurred())
 
-			egressPolicyGUID, err := egressPolicyTable.CreateEgressPolicy(tx, sourceTerminalId, destinationTerminalId)
+			egressPolicyGUID, err := egressPolicyTable.CreateEgressPolicy(tx, sourceTerminalId, destinationTerminalId, false)
 			Expect(err).ToNot(HaveOccurred())
 
 			err = egressPolicyTable.DeleteEgressPolicy(tx, egressPolicyGUID)
@@ -260,6 +322,45 @@ var _ = Describe("Egress Policy Table", func() {
 		})
 	})
 
+	Context("UpdateEgressPolicy", func() {
+		It("points the policy at the new terminals and keeps its guid", func() {
+			db, tx := getMigratedRealDb(dbConf)
+			setupEgressPolicyStore(db)
+
+			sourceTerminalId, err := terminalsTable.Create(tx)
+			Expect(err).ToNot(HaveOccurred())
+			destinationTerminalId, err := terminalsTable.Create(tx)
+			Expect(err).ToNot(HaveOccurred())
+			newDestinationTerminalId, err := terminalsTable.Create(tx)
+			Expect(err).ToNot(HaveOccurred())
+
+			egressPolicyGUID, err := egressPolicyTable.CreateEgressPolicy(tx, sourceTerminalId, destinationTerminalId, false)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = egressPolicyTable.UpdateEgressPolicy(tx, egressPolicyGUID, sourceTerminalId, newDestinationTerminalId, true)
+			Expect(err).ToNot(HaveOccurred())
+
+			var foundSourceID, foundDestinationID string
+			var foundLog bool
+			row := tx.QueryRow(tx.Rebind(`SELECT source_guid, destination_guid, log FROM egress_policies WHERE guid = ?`), egressPolicyGUID)
+			err = row.Scan(&foundSourceID, &foundDestinationID, &foundLog)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(foundSourceID).To(Equal(sourceTerminalId))
+			Expect(foundDestinationID).To(Equal(newDestinationTerminalId))
+			Expect(foundLog).To(BeTrue())
+		})
+
+		It("should return the sql error", func() {
+			fakeTx := &dbfakes.Transaction{}
+			fakeTx.ExecReturns(nil, errors.New("broke"))
+
+			setupEgressPolicyStore(mockDb)
+
+			err := egressPolicyTable.UpdateEgressPolicy(fakeTx, "some-guid", "some-source", "some-destination", false)
+			Expect(err).To(MatchError("broke"))
+		})
+	})
+
 	Context("DeleteIPRange", func() {
 		It("deletes the ip range", func() {
 			db, tx := getMigratedRealDb(dbConf)
@@ -389,6 +490,48 @@ var _ = Describe("Egress Policy Table", func() {
 		})
 	})
 
+	Context("DeleteOrg", func() {
+		It("deletes the org provided a terminal guid", func() {
+			db, tx := getMigratedRealDb(dbConf)
+			setupEgressPolicyStore(db)
+
+			terminalGUID, err := terminalsTable.Create(tx)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = egressPolicyTable.CreateOrg(tx, terminalGUID, "some-org-guid")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = egressPolicyTable.DeleteOrg(tx, terminalGUID)
+			Expect(err).ToNot(HaveOccurred())
+
+			var orgCount int
+			row := tx.QueryRow(tx.Rebind(`SELECT COUNT(id) FROM orgs WHERE terminal_guid = ?`), terminalGUID)
+			err = row.Scan(&orgCount)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(orgCount).To(Equal(0))
+		})
+	})
+
+	Context("DeleteDefault", func() {
+		It("deletes the default source provided a terminal guid", func() {
+			db, tx := getMigratedRealDb(dbConf)
+			setupEgressPolicyStore(db)
+
+			terminalGUID, err := terminalsTable.Create(tx)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = egressPolicyTable.CreateDefault(tx, terminalGUID)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = egressPolicyTable.DeleteDefault(tx, terminalGUID)
+			Expect(err).ToNot(HaveOccurred())
+
+			var defaultCount int
+			row := tx.QueryRow(tx.Rebind(`SELECT COUNT(id) FROM defaults WHERE terminal_guid = ?`), terminalGUID)
+			err = row.Scan(&defaultCount)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(defaultCount).To(Equal(0))
+		})
+	})
+
 	Context("IsTerminalInUse", func() {
 		It("returns true if the terminal is in use by an egress policy", func() {
 			db, tx := getMigratedRealDb(dbConf)
@@ -399,7 +542,7 @@ var _ = Describe("Egress Policy Table", func() {
 			sourceTerminalGUID, err := terminalsTable.Create(tx)
 			Expect(err).ToNot(HaveOccurred())
 
-			_, err = egressPolicyTable.CreateEgressPolicy(tx, sourceTerminalGUID, destinationTerminalGUID)
+			_, err = egressPolicyTable.CreateEgressPolicy(tx, sourceTerminalGUID, destinationTerminalGUID, false)
 			Expect(err).ToNot(HaveOccurred())
 			inUse, err := egressPolicyTable.IsTerminalInUse(tx, sourceTerminalGUID)
 			Expect(err).ToNot(HaveOccurred())
@@ -672,6 +815,52 @@ var _ = Describe("Egress Policy Table", func() {
 				})
 			})
 		})
+		Context("when a destination has multiple ip ranges and port ranges", func() {
+			BeforeEach(func() {
+				db, _ := getMigratedRealDb(dbConf)
+				egressStore := setupEgressPolicyStore(db)
+
+				var err error
+				createdEgressDestinations, err = egressDestinationStore(db).Create([]store.EgressDestination{
+					{
+						Name:     "multi",
+						Protocol: "tcp",
+						Ports:    []store.Ports{{Start: 80, End: 80}, {Start: 443, End: 443}},
+						IPRanges: []store.IPRange{{Start: "1.2.3.4", End: "1.2.3.5"}, {Start: "10.0.0.1", End: "10.0.0.9"}},
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				createdEgressPolicies, err = egressStore.Create([]store.EgressPolicy{
+					{
+						Source:      store.EgressSource{ID: "some-app-guid", Type: "app"},
+						Destination: store.EgressDestination{GUID: createdEgressDestinations[0].GUID},
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns a single policy with all of the destination ranges", func() {
+				egressPolicies, err := egressPolicyTable.GetByGUID(tx, createdEgressPolicies[0].ID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(egressPolicies).To(HaveLen(1))
+				Expect(egressPolicies[0].Destination.IPRanges).To(Equal([]store.IPRange{
+					{Start: "1.2.3.4", End: "1.2.3.5"},
+					{Start: "10.0.0.1", End: "10.0.0.9"},
+				}))
+				Expect(egressPolicies[0].Destination.Ports).To(Equal([]store.Ports{
+					{Start: 80, End: 80},
+					{Start: 443, End: 443},
+				}))
+
+				listedPolicies, err := egressPolicyTable.GetAllPolicies()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(listedPolicies).To(HaveLen(1))
+				Expect(listedPolicies[0].Destination.IPRanges).To(HaveLen(2))
+				Expect(listedPolicies[0].Destination.Ports).To(HaveLen(2))
+			})
+		})
+
 		Context("when the query fails", func() {
 			It("returns an error", func() {
 				setupEgressPolicyStore(mockDb)
@@ -730,9 +919,52 @@ var _ = Describe("Egress Policy Table", func() {
 		})
 	})
 
+	Context("GetTerminalByOrgGUID", func() {
+		It("should return the terminal guid for an org if it exists", func() {
+			db, tx := getMigratedRealDb(dbConf)
+			setupEgressPolicyStore(db)
+
+			terminalId, err := terminalsTable.Create(tx)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = egressPolicyTable.CreateOrg(tx, terminalId, "some-org-guid")
+			Expect(err).ToNot(HaveOccurred())
+
+			foundID, err := egressPolicyTable.GetTerminalByOrgGUID(tx, "some-org-guid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(foundID).To(Equal(terminalId))
+
+			By("should return empty string and no error if the org is not found")
+			foundID, err = egressPolicyTable.GetTerminalByOrgGUID(tx, "garbage-org-guid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(foundID).To(Equal(""))
+		})
+	})
+
+	Context("GetDefaultTerminal", func() {
+		It("should return the default terminal guid if it exists", func() {
+			db, tx := getMigratedRealDb(dbConf)
+			setupEgressPolicyStore(db)
+
+			By("should return empty string and no error if there is no default source")
+			foundID, err := egressPolicyTable.GetDefaultTerminal(tx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(foundID).To(Equal(""))
+
+			terminalId, err := terminalsTable.Create(tx)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = egressPolicyTable.CreateDefault(tx, terminalId)
+			Expect(err).ToNot(HaveOccurred())
+
+			foundID, err = egressPolicyTable.GetDefaultTerminal(tx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(foundID).To(Equal(terminalId))
+		})
+	})
+
 	Context("GetBySourceGuids", func() {
 		Context("When using a real db", func() {
 			var (
+				egressStore           store.EgressPolicyStore
 				egressPolicies        []store.EgressPolicy
 				createdDestinations   []store.EgressDestination
 				createdEgressPolicies []store.EgressPolicy
@@ -740,7 +972,7 @@ var _ = Describe("Egress Policy Table", func() {
 
 			BeforeEach(func() {
 				db, _ := getMigratedRealDb(dbConf)
-				egressStore := setupEgressPolicyStore(db)
+				egressStore = setupEgressPolicyStore(db)
 
 				egressDestinations := []store.EgressDestination{
 					{
@@ -883,6 +1115,61 @@ var _ = Describe("Egress Policy Table", func() {
 					Expect(policies).To(HaveLen(0))
 				})
 			})
+
+			Context("when there are org and default policies", func() {
+				var orgAndDefaultPolicies []store.EgressPolicy
+
+				BeforeEach(func() {
+					var err error
+					orgAndDefaultPolicies, err = egressStore.Create([]store.EgressPolicy{
+						{
+							Source:      store.EgressSource{ID: "some-org-guid", Type: "org"},
+							Destination: store.EgressDestination{GUID: createdDestinations[0].GUID},
+						},
+						{
+							Source:      store.EgressSource{Type: "default"},
+							Destination: store.EgressDestination{GUID: createdDestinations[1].GUID},
+						},
+					})
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				It("returns org policies matching the ids and every default policy", func() {
+					policies, err := egressPolicyTable.GetBySourceGuids([]string{"some-org-guid"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(policies).To(ConsistOf(
+						store.EgressPolicy{
+							ID: orgAndDefaultPolicies[0].ID,
+							Source: store.EgressSource{
+								ID:           "some-org-guid",
+								Type:         "org",
+								TerminalGUID: orgAndDefaultPolicies[0].Source.TerminalGUID,
+							},
+							Destination: createdDestinations[0],
+						},
+						store.EgressPolicy{
+							ID: orgAndDefaultPolicies[1].ID,
+							Source: store.EgressSource{
+								Type:         "default",
+								TerminalGUID: orgAndDefaultPolicies[1].Source.TerminalGUID,
+							},
+							Destination: createdDestinations[1],
+						},
+					))
+
+					By("not expanding org policies to the apps of the org")
+					policies, err = egressPolicyTable.GetBySourceGuids([]string{"some-app-guid-in-some-org"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(policies).To(HaveLen(1))
+					Expect(policies[0].Source.Type).To(Equal("default"))
+
+					By("returning only the default policies for non-matching ids")
+					policies, err = egressPolicyTable.GetBySourceGuids([]string{"meow-this-is-a-bogus-app-guid"})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(policies).To(HaveLen(1))
+					Expect(policies[0].Source.Type).To(Equal("default"))
+				})
+			})
 		})
 
 		Context("when the query fails", func() {
@@ -900,6 +1187,149 @@ var _ = Describe("Egress Policy Table", func() {
 			})
 		})
 	})
+	Context("GetByFilter and CountByFilter", func() {
+		Context("When using a real db", func() {
+			var (
+				createdDestinations   []store.EgressDestination
+				createdEgressPolicies []store.EgressPolicy
+			)
+
+			BeforeEach(func() {
+				db, _ := getMigratedRealDb(dbConf)
+				egressStore := setupEgressPolicyStore(db)
+
+				var err error
+				createdDestinations, err = egressDestinationStore(db).Create([]store.EgressDestination{
+					{
+						Name:     "dest-a",
+						Protocol: "tcp",
+						Ports:    []store.Ports{{Start: 8080, End: 8081}},
+						IPRanges: []store.IPRange{{Start: "1.2.3.4", End: "1.2.3.5"}, {Start: "1.2.3.7", End: "1.2.3.8"}},
+					},
+					{
+						Name:     "dest-b",
+						OrgGUID:  "some-org-guid",
+						Protocol: "udp",
+						Ports:    []store.Ports{{Start: 53, End: 53}},
+						IPRanges: []store.IPRange{{Start: "2.2.3.4", End: "2.2.3.5"}},
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				createdEgressPolicies, err = egressStore.Create([]store.EgressPolicy{
+					{
+						Source:      store.EgressSource{ID: "some-app-guid", Type: "app"},
+						Destination: store.EgressDestination{GUID: createdDestinations[0].GUID},
+					},
+					{
+						Source:      store.EgressSource{ID: "some-space-guid", Type: "space"},
+						Destination: store.EgressDestination{GUID: createdDestinations[0].GUID},
+					},
+					{
+						Source:      store.EgressSource{ID: "some-space-guid", Type: "space"},
+						Destination: store.EgressDestination{GUID: createdDestinations[1].GUID},
+					},
+					{
+						Source:      store.EgressSource{Type: "default"},
+						Destination: store.EgressDestination{GUID: createdDestinations[1].GUID},
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			policyIDs := func(policies []store.EgressPolicy) []string {
+				ids := []string{}
+				for _, policy := range policies {
+					ids = append(ids, policy.ID)
+				}
+				return ids
+			}
+
+			It("returns every policy when the filter is empty", func() {
+				policies, err := egressPolicyTable.GetByFilter(store.EgressPolicyFilter{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(policyIDs(policies)).To(Equal(policyIDs(createdEgressPolicies)))
+				Expect(policies[0].Destination.IPRanges).To(HaveLen(2))
+
+				count, err := egressPolicyTable.CountByFilter(store.EgressPolicyFilter{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(count).To(Equal(4))
+			})
+
+			It("filters by source id", func() {
+				filter := store.EgressPolicyFilter{SourceIDs: []string{"some-space-guid"}}
+				policies, err := egressPolicyTable.GetByFilter(filter)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(policyIDs(policies)).To(Equal(policyIDs(createdEgressPolicies[1:3])))
+
+				count, err := egressPolicyTable.CountByFilter(filter)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(count).To(Equal(2))
+			})
+
+			It("filters by source type", func() {
+				policies, err := egressPolicyTable.GetByFilter(store.EgressPolicyFilter{SourceTypes: []string{"app", "default"}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(policyIDs(policies)).To(Equal([]string{createdEgressPolicies[0].ID, createdEgressPolicies[3].ID}))
+			})
+
+			It("filters by destination id and name", func() {
+				policies, err := egressPolicyTable.GetByFilter(store.EgressPolicyFilter{DestinationIDs: []string{createdDestinations[1].GUID}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(policyIDs(policies)).To(Equal(policyIDs(createdEgressPolicies[2:4])))
+
+				policies, err = egressPolicyTable.GetByFilter(store.EgressPolicyFilter{DestinationNames: []string{"dest-a"}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(policyIDs(policies)).To(Equal(policyIDs(createdEgressPolicies[0:2])))
+			})
+
+			It("filters by the org of the destination", func() {
+				filter := store.EgressPolicyFilter{DestinationOrgGUIDs: []string{"some-org-guid"}}
+				policies, err := egressPolicyTable.GetByFilter(filter)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(policyIDs(policies)).To(Equal(policyIDs(createdEgressPolicies[2:4])))
+
+				count, err := egressPolicyTable.CountByFilter(filter)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(count).To(Equal(2))
+			})
+
+			It("combines filters", func() {
+				filter := store.EgressPolicyFilter{SourceTypes: []string{"space"}, DestinationNames: []string{"dest-b"}}
+				policies, err := egressPolicyTable.GetByFilter(filter)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(policyIDs(policies)).To(Equal([]string{createdEgressPolicies[2].ID}))
+			})
+
+			It("paginates", func() {
+				filter := store.EgressPolicyFilter{Limit: 2, Offset: 1}
+				policies, err := egressPolicyTable.GetByFilter(filter)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(policyIDs(policies)).To(Equal(policyIDs(createdEgressPolicies[1:3])))
+
+				count, err := egressPolicyTable.CountByFilter(filter)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(count).To(Equal(4))
+
+				policies, err = egressPolicyTable.GetByFilter(store.EgressPolicyFilter{Limit: 2, Offset: 4})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(policies).To(BeEmpty())
+			})
+		})
+
+		Context("when the query fails", func() {
+			It("returns an error", func() {
+				mockDb.QueryReturns(nil, errors.New("some error that sql would return"))
+
+				egressPolicyTable = &store.EgressPolicyTable{
+					Conn: mockDb,
+				}
+
+				_, err := egressPolicyTable.GetByFilter(store.EgressPolicyFilter{})
+				Expect(err).To(MatchError("some error that sql would return"))
+			})
+		})
+	})
 })
 
 func egressDestinationStore(db store.Database) *store.EgressDestinationStore {
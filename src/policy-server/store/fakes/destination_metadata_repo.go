@@ -20,13 +20,16 @@ type DestinationMetadataRepo struct {
 	deleteReturnsOnCall map[int]struct {
 		result1 error
 	}
-	UpsertStub        func(tx db.Transaction, terminalGUID, name, description string) error
+	UpsertStub        func(tx db.Transaction, terminalGUID, name, description, fqdn, orgGUID string, selfService bool) error
 	upsertMutex       sync.RWMutex
 	upsertArgsForCall []struct {
 		tx           db.Transaction
 		terminalGUID string
 		name         string
 		description  string
+		fqdn         string
+		orgGUID      string
+		selfService  bool
 	}
 	upsertReturns struct {
 		result1 error
@@ -34,6 +37,19 @@ type DestinationMetadataRepo struct {
 	upsertReturnsOnCall map[int]struct {
 		result1 error
 	}
+	UpdateFQDNResolutionErrorStub        func(tx db.Transaction, terminalGUID, resolutionError string) error
+	updateFQDNResolutionErrorMutex       sync.RWMutex
+	updateFQDNResolutionErrorArgsForCall []struct {
+		tx              db.Transaction
+		terminalGUID    string
+		resolutionError string
+	}
+	updateFQDNResolutionErrorReturns struct {
+		result1 error
+	}
+	updateFQDNResolutionErrorReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -87,7 +103,7 @@ func (fake *DestinationMetadataRepo) DeleteReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *DestinationMetadataRepo) Upsert(tx db.Transaction, terminalGUID string, name string, description string) error {
+func (fake *DestinationMetadataRepo) Upsert(tx db.Transaction, terminalGUID string, name string, description string, fqdn string, orgGUID string, selfService bool) error {
 	fake.upsertMutex.Lock()
 	ret, specificReturn := fake.upsertReturnsOnCall[len(fake.upsertArgsForCall)]
 	fake.upsertArgsForCall = append(fake.upsertArgsForCall, struct {
@@ -95,11 +111,14 @@ func (fake *DestinationMetadataRepo) Upsert(tx db.Transaction, terminalGUID stri
 		terminalGUID string
 		name         string
 		description  string
-	}{tx, terminalGUID, name, description})
-	fake.recordInvocation("Upsert", []interface{}{tx, terminalGUID, name, description})
+		fqdn         string
+		orgGUID      string
+		selfService  bool
+	}{tx, terminalGUID, name, description, fqdn, orgGUID, selfService})
+	fake.recordInvocation("Upsert", []interface{}{tx, terminalGUID, name, description, fqdn, orgGUID, selfService})
 	fake.upsertMutex.Unlock()
 	if fake.UpsertStub != nil {
-		return fake.UpsertStub(tx, terminalGUID, name, description)
+		return fake.UpsertStub(tx, terminalGUID, name, description, fqdn, orgGUID, selfService)
 	}
 	if specificReturn {
 		return ret.result1
@@ -113,10 +132,10 @@ func (fake *DestinationMetadataRepo) UpsertCallCount() int {
 	return len(fake.upsertArgsForCall)
 }
 
-func (fake *DestinationMetadataRepo) UpsertArgsForCall(i int) (db.Transaction, string, string, string) {
+func (fake *DestinationMetadataRepo) UpsertArgsForCall(i int) (db.Transaction, string, string, string, string, string, bool) {
 	fake.upsertMutex.RLock()
 	defer fake.upsertMutex.RUnlock()
-	return fake.upsertArgsForCall[i].tx, fake.upsertArgsForCall[i].terminalGUID, fake.upsertArgsForCall[i].name, fake.upsertArgsForCall[i].description
+	return fake.upsertArgsForCall[i].tx, fake.upsertArgsForCall[i].terminalGUID, fake.upsertArgsForCall[i].name, fake.upsertArgsForCall[i].description, fake.upsertArgsForCall[i].fqdn, fake.upsertArgsForCall[i].orgGUID, fake.upsertArgsForCall[i].selfService
 }
 
 func (fake *DestinationMetadataRepo) UpsertReturns(result1 error) {
@@ -138,6 +157,56 @@ func (fake *DestinationMetadataRepo) UpsertReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *DestinationMetadataRepo) UpdateFQDNResolutionError(tx db.Transaction, terminalGUID string, resolutionError string) error {
+	fake.updateFQDNResolutionErrorMutex.Lock()
+	ret, specificReturn := fake.updateFQDNResolutionErrorReturnsOnCall[len(fake.updateFQDNResolutionErrorArgsForCall)]
+	fake.updateFQDNResolutionErrorArgsForCall = append(fake.updateFQDNResolutionErrorArgsForCall, struct {
+		tx              db.Transaction
+		terminalGUID    string
+		resolutionError string
+	}{tx, terminalGUID, resolutionError})
+	fake.recordInvocation("UpdateFQDNResolutionError", []interface{}{tx, terminalGUID, resolutionError})
+	fake.updateFQDNResolutionErrorMutex.Unlock()
+	if fake.UpdateFQDNResolutionErrorStub != nil {
+		return fake.UpdateFQDNResolutionErrorStub(tx, terminalGUID, resolutionError)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.updateFQDNResolutionErrorReturns.result1
+}
+
+func (fake *DestinationMetadataRepo) UpdateFQDNResolutionErrorCallCount() int {
+	fake.updateFQDNResolutionErrorMutex.RLock()
+	defer fake.updateFQDNResolutionErrorMutex.RUnlock()
+	return len(fake.updateFQDNResolutionErrorArgsForCall)
+}
+
+func (fake *DestinationMetadataRepo) UpdateFQDNResolutionErrorArgsForCall(i int) (db.Transaction, string, string) {
+	fake.updateFQDNResolutionErrorMutex.RLock()
+	defer fake.updateFQDNResolutionErrorMutex.RUnlock()
+	return fake.updateFQDNResolutionErrorArgsForCall[i].tx, fake.updateFQDNResolutionErrorArgsForCall[i].terminalGUID, fake.updateFQDNResolutionErrorArgsForCall[i].resolutionError
+}
+
+func (fake *DestinationMetadataRepo) UpdateFQDNResolutionErrorReturns(result1 error) {
+	fake.UpdateFQDNResolutionErrorStub = nil
+	fake.updateFQDNResolutionErrorReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *DestinationMetadataRepo) UpdateFQDNResolutionErrorReturnsOnCall(i int, result1 error) {
+	fake.UpdateFQDNResolutionErrorStub = nil
+	if fake.updateFQDNResolutionErrorReturnsOnCall == nil {
+		fake.updateFQDNResolutionErrorReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateFQDNResolutionErrorReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *DestinationMetadataRepo) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -145,6 +214,8 @@ func (fake *DestinationMetadataRepo) Invocations() map[string][][]interface{} {
 	defer fake.deleteMutex.RUnlock()
 	fake.upsertMutex.RLock()
 	defer fake.upsertMutex.RUnlock()
+	fake.updateFQDNResolutionErrorMutex.RLock()
+	defer fake.updateFQDNResolutionErrorMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
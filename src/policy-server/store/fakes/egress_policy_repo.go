@@ -45,12 +45,13 @@ type EgressPolicyRepo struct {
 		result1 int64
 		result2 error
 	}
-	CreateEgressPolicyStub        func(tx db.Transaction, sourceTerminalGUID, destinationTerminalGUID string) (string, error)
+	CreateEgressPolicyStub        func(tx db.Transaction, sourceTerminalGUID, destinationTerminalGUID string, log bool) (string, error)
 	createEgressPolicyMutex       sync.RWMutex
 	createEgressPolicyArgsForCall []struct {
 		tx                      db.Transaction
 		sourceTerminalGUID      string
 		destinationTerminalGUID string
+		log                     bool
 	}
 	createEgressPolicyReturns struct {
 		result1 string
@@ -203,6 +204,127 @@ type EgressPolicyRepo struct {
 		result1 bool
 		result2 error
 	}
+	UpdateEgressPolicyStub        func(tx db.Transaction, egressPolicyGUID string, sourceTerminalGUID string, destinationTerminalGUID string, log bool) error
+	updateEgressPolicyMutex       sync.RWMutex
+	updateEgressPolicyArgsForCall []struct {
+		tx                      db.Transaction
+		egressPolicyGUID        string
+		sourceTerminalGUID      string
+		destinationTerminalGUID string
+		log                     bool
+	}
+	updateEgressPolicyReturns struct {
+		result1 error
+	}
+	updateEgressPolicyReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CreateOrgStub        func(tx db.Transaction, sourceTerminalGUID string, orgGUID string) (int64, error)
+	createOrgMutex       sync.RWMutex
+	createOrgArgsForCall []struct {
+		tx                 db.Transaction
+		sourceTerminalGUID string
+		orgGUID            string
+	}
+	createOrgReturns struct {
+		result1 int64
+		result2 error
+	}
+	createOrgReturnsOnCall map[int]struct {
+		result1 int64
+		result2 error
+	}
+	CreateDefaultStub        func(tx db.Transaction, sourceTerminalGUID string) (int64, error)
+	createDefaultMutex       sync.RWMutex
+	createDefaultArgsForCall []struct {
+		tx                 db.Transaction
+		sourceTerminalGUID string
+	}
+	createDefaultReturns struct {
+		result1 int64
+		result2 error
+	}
+	createDefaultReturnsOnCall map[int]struct {
+		result1 int64
+		result2 error
+	}
+	GetTerminalByOrgGUIDStub        func(tx db.Transaction, orgGUID string) (string, error)
+	getTerminalByOrgGUIDMutex       sync.RWMutex
+	getTerminalByOrgGUIDArgsForCall []struct {
+		tx      db.Transaction
+		orgGUID string
+	}
+	getTerminalByOrgGUIDReturns struct {
+		result1 string
+		result2 error
+	}
+	getTerminalByOrgGUIDReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	GetDefaultTerminalStub        func(tx db.Transaction) (string, error)
+	getDefaultTerminalMutex       sync.RWMutex
+	getDefaultTerminalArgsForCall []struct {
+		tx db.Transaction
+	}
+	getDefaultTerminalReturns struct {
+		result1 string
+		result2 error
+	}
+	getDefaultTerminalReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	DeleteOrgStub        func(tx db.Transaction, terminalGUID string) error
+	deleteOrgMutex       sync.RWMutex
+	deleteOrgArgsForCall []struct {
+		tx           db.Transaction
+		terminalGUID string
+	}
+	deleteOrgReturns struct {
+		result1 error
+	}
+	deleteOrgReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteDefaultStub        func(tx db.Transaction, terminalGUID string) error
+	deleteDefaultMutex       sync.RWMutex
+	deleteDefaultArgsForCall []struct {
+		tx           db.Transaction
+		terminalGUID string
+	}
+	deleteDefaultReturns struct {
+		result1 error
+	}
+	deleteDefaultReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetByFilterStub        func(filter store.EgressPolicyFilter) ([]store.EgressPolicy, error)
+	getByFilterMutex       sync.RWMutex
+	getByFilterArgsForCall []struct {
+		filter store.EgressPolicyFilter
+	}
+	getByFilterReturns struct {
+		result1 []store.EgressPolicy
+		result2 error
+	}
+	getByFilterReturnsOnCall map[int]struct {
+		result1 []store.EgressPolicy
+		result2 error
+	}
+	CountByFilterStub        func(filter store.EgressPolicyFilter) (int, error)
+	countByFilterMutex       sync.RWMutex
+	countByFilterArgsForCall []struct {
+		filter store.EgressPolicyFilter
+	}
+	countByFilterReturns struct {
+		result1 int
+		result2 error
+	}
+	countByFilterReturnsOnCall map[int]struct {
+		result1 int
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -319,18 +441,19 @@ func (fake *EgressPolicyRepo) CreateIPRangeReturnsOnCall(i int, result1 int64, r
 	}{result1, result2}
 }
 
-func (fake *EgressPolicyRepo) CreateEgressPolicy(tx db.Transaction, sourceTerminalGUID string, destinationTerminalGUID string) (string, error) {
+func (fake *EgressPolicyRepo) CreateEgressPolicy(tx db.Transaction, sourceTerminalGUID string, destinationTerminalGUID string, log bool) (string, error) {
 	fake.createEgressPolicyMutex.Lock()
 	ret, specificReturn := fake.createEgressPolicyReturnsOnCall[len(fake.createEgressPolicyArgsForCall)]
 	fake.createEgressPolicyArgsForCall = append(fake.createEgressPolicyArgsForCall, struct {
 		tx                      db.Transaction
 		sourceTerminalGUID      string
 		destinationTerminalGUID string
-	}{tx, sourceTerminalGUID, destinationTerminalGUID})
-	fake.recordInvocation("CreateEgressPolicy", []interface{}{tx, sourceTerminalGUID, destinationTerminalGUID})
+		log                     bool
+	}{tx, sourceTerminalGUID, destinationTerminalGUID, log})
+	fake.recordInvocation("CreateEgressPolicy", []interface{}{tx, sourceTerminalGUID, destinationTerminalGUID, log})
 	fake.createEgressPolicyMutex.Unlock()
 	if fake.CreateEgressPolicyStub != nil {
-		return fake.CreateEgressPolicyStub(tx, sourceTerminalGUID, destinationTerminalGUID)
+		return fake.CreateEgressPolicyStub(tx, sourceTerminalGUID, destinationTerminalGUID, log)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -344,10 +467,10 @@ func (fake *EgressPolicyRepo) CreateEgressPolicyCallCount() int {
 	return len(fake.createEgressPolicyArgsForCall)
 }
 
-func (fake *EgressPolicyRepo) CreateEgressPolicyArgsForCall(i int) (db.Transaction, string, string) {
+func (fake *EgressPolicyRepo) CreateEgressPolicyArgsForCall(i int) (db.Transaction, string, string, bool) {
 	fake.createEgressPolicyMutex.RLock()
 	defer fake.createEgressPolicyMutex.RUnlock()
-	return fake.createEgressPolicyArgsForCall[i].tx, fake.createEgressPolicyArgsForCall[i].sourceTerminalGUID, fake.createEgressPolicyArgsForCall[i].destinationTerminalGUID
+	return fake.createEgressPolicyArgsForCall[i].tx, fake.createEgressPolicyArgsForCall[i].sourceTerminalGUID, fake.createEgressPolicyArgsForCall[i].destinationTerminalGUID, fake.createEgressPolicyArgsForCall[i].log
 }
 
 func (fake *EgressPolicyRepo) CreateEgressPolicyReturns(result1 string, result2 error) {
@@ -928,6 +1051,466 @@ func (fake *EgressPolicyRepo) IsTerminalInUseReturnsOnCall(i int, result1 bool,
 	}{result1, result2}
 }
 
+func (fake *EgressPolicyRepo) UpdateEgressPolicy(tx db.Transaction, egressPolicyGUID string, sourceTerminalGUID string, destinationTerminalGUID string, log bool) error {
+	fake.updateEgressPolicyMutex.Lock()
+	ret, specificReturn := fake.updateEgressPolicyReturnsOnCall[len(fake.updateEgressPolicyArgsForCall)]
+	fake.updateEgressPolicyArgsForCall = append(fake.updateEgressPolicyArgsForCall, struct {
+		tx                      db.Transaction
+		egressPolicyGUID        string
+		sourceTerminalGUID      string
+		destinationTerminalGUID string
+		log                     bool
+	}{tx, egressPolicyGUID, sourceTerminalGUID, destinationTerminalGUID, log})
+	fake.recordInvocation("UpdateEgressPolicy", []interface{}{tx, egressPolicyGUID, sourceTerminalGUID, destinationTerminalGUID, log})
+	fake.updateEgressPolicyMutex.Unlock()
+	if fake.UpdateEgressPolicyStub != nil {
+		return fake.UpdateEgressPolicyStub(tx, egressPolicyGUID, sourceTerminalGUID, destinationTerminalGUID, log)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.updateEgressPolicyReturns.result1
+}
+
+func (fake *EgressPolicyRepo) UpdateEgressPolicyCallCount() int {
+	fake.updateEgressPolicyMutex.RLock()
+	defer fake.updateEgressPolicyMutex.RUnlock()
+	return len(fake.updateEgressPolicyArgsForCall)
+}
+
+func (fake *EgressPolicyRepo) UpdateEgressPolicyArgsForCall(i int) (db.Transaction, string, string, string, bool) {
+	fake.updateEgressPolicyMutex.RLock()
+	defer fake.updateEgressPolicyMutex.RUnlock()
+	return fake.updateEgressPolicyArgsForCall[i].tx, fake.updateEgressPolicyArgsForCall[i].egressPolicyGUID, fake.updateEgressPolicyArgsForCall[i].sourceTerminalGUID, fake.updateEgressPolicyArgsForCall[i].destinationTerminalGUID, fake.updateEgressPolicyArgsForCall[i].log
+}
+
+func (fake *EgressPolicyRepo) UpdateEgressPolicyReturns(result1 error) {
+	fake.UpdateEgressPolicyStub = nil
+	fake.updateEgressPolicyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *EgressPolicyRepo) UpdateEgressPolicyReturnsOnCall(i int, result1 error) {
+	fake.UpdateEgressPolicyStub = nil
+	if fake.updateEgressPolicyReturnsOnCall == nil {
+		fake.updateEgressPolicyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateEgressPolicyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *EgressPolicyRepo) CreateOrg(tx db.Transaction, sourceTerminalGUID string, orgGUID string) (int64, error) {
+	fake.createOrgMutex.Lock()
+	ret, specificReturn := fake.createOrgReturnsOnCall[len(fake.createOrgArgsForCall)]
+	fake.createOrgArgsForCall = append(fake.createOrgArgsForCall, struct {
+		tx                 db.Transaction
+		sourceTerminalGUID string
+		orgGUID            string
+	}{tx, sourceTerminalGUID, orgGUID})
+	fake.recordInvocation("CreateOrg", []interface{}{tx, sourceTerminalGUID, orgGUID})
+	fake.createOrgMutex.Unlock()
+	if fake.CreateOrgStub != nil {
+		return fake.CreateOrgStub(tx, sourceTerminalGUID, orgGUID)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.createOrgReturns.result1, fake.createOrgReturns.result2
+}
+
+func (fake *EgressPolicyRepo) CreateOrgCallCount() int {
+	fake.createOrgMutex.RLock()
+	defer fake.createOrgMutex.RUnlock()
+	return len(fake.createOrgArgsForCall)
+}
+
+func (fake *EgressPolicyRepo) CreateOrgArgsForCall(i int) (db.Transaction, string, string) {
+	fake.createOrgMutex.RLock()
+	defer fake.createOrgMutex.RUnlock()
+	return fake.createOrgArgsForCall[i].tx, fake.createOrgArgsForCall[i].sourceTerminalGUID, fake.createOrgArgsForCall[i].orgGUID
+}
+
+func (fake *EgressPolicyRepo) CreateOrgReturns(result1 int64, result2 error) {
+	fake.CreateOrgStub = nil
+	fake.createOrgReturns = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *EgressPolicyRepo) CreateOrgReturnsOnCall(i int, result1 int64, result2 error) {
+	fake.CreateOrgStub = nil
+	if fake.createOrgReturnsOnCall == nil {
+		fake.createOrgReturnsOnCall = make(map[int]struct {
+			result1 int64
+			result2 error
+		})
+	}
+	fake.createOrgReturnsOnCall[i] = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *EgressPolicyRepo) CreateDefault(tx db.Transaction, sourceTerminalGUID string) (int64, error) {
+	fake.createDefaultMutex.Lock()
+	ret, specificReturn := fake.createDefaultReturnsOnCall[len(fake.createDefaultArgsForCall)]
+	fake.createDefaultArgsForCall = append(fake.createDefaultArgsForCall, struct {
+		tx                 db.Transaction
+		sourceTerminalGUID string
+	}{tx, sourceTerminalGUID})
+	fake.recordInvocation("CreateDefault", []interface{}{tx, sourceTerminalGUID})
+	fake.createDefaultMutex.Unlock()
+	if fake.CreateDefaultStub != nil {
+		return fake.CreateDefaultStub(tx, sourceTerminalGUID)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.createDefaultReturns.result1, fake.createDefaultReturns.result2
+}
+
+func (fake *EgressPolicyRepo) CreateDefaultCallCount() int {
+	fake.createDefaultMutex.RLock()
+	defer fake.createDefaultMutex.RUnlock()
+	return len(fake.createDefaultArgsForCall)
+}
+
+func (fake *EgressPolicyRepo) CreateDefaultArgsForCall(i int) (db.Transaction, string) {
+	fake.createDefaultMutex.RLock()
+	defer fake.createDefaultMutex.RUnlock()
+	return fake.createDefaultArgsForCall[i].tx, fake.createDefaultArgsForCall[i].sourceTerminalGUID
+}
+
+func (fake *EgressPolicyRepo) CreateDefaultReturns(result1 int64, result2 error) {
+	fake.CreateDefaultStub = nil
+	fake.createDefaultReturns = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *EgressPolicyRepo) CreateDefaultReturnsOnCall(i int, result1 int64, result2 error) {
+	fake.CreateDefaultStub = nil
+	if fake.createDefaultReturnsOnCall == nil {
+		fake.createDefaultReturnsOnCall = make(map[int]struct {
+			result1 int64
+			result2 error
+		})
+	}
+	fake.createDefaultReturnsOnCall[i] = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *EgressPolicyRepo) GetTerminalByOrgGUID(tx db.Transaction, orgGUID string) (string, error) {
+	fake.getTerminalByOrgGUIDMutex.Lock()
+	ret, specificReturn := fake.getTerminalByOrgGUIDReturnsOnCall[len(fake.getTerminalByOrgGUIDArgsForCall)]
+	fake.getTerminalByOrgGUIDArgsForCall = append(fake.getTerminalByOrgGUIDArgsForCall, struct {
+		tx      db.Transaction
+		orgGUID string
+	}{tx, orgGUID})
+	fake.recordInvocation("GetTerminalByOrgGUID", []interface{}{tx, orgGUID})
+	fake.getTerminalByOrgGUIDMutex.Unlock()
+	if fake.GetTerminalByOrgGUIDStub != nil {
+		return fake.GetTerminalByOrgGUIDStub(tx, orgGUID)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getTerminalByOrgGUIDReturns.result1, fake.getTerminalByOrgGUIDReturns.result2
+}
+
+func (fake *EgressPolicyRepo) GetTerminalByOrgGUIDCallCount() int {
+	fake.getTerminalByOrgGUIDMutex.RLock()
+	defer fake.getTerminalByOrgGUIDMutex.RUnlock()
+	return len(fake.getTerminalByOrgGUIDArgsForCall)
+}
+
+func (fake *EgressPolicyRepo) GetTerminalByOrgGUIDArgsForCall(i int) (db.Transaction, string) {
+	fake.getTerminalByOrgGUIDMutex.RLock()
+	defer fake.getTerminalByOrgGUIDMutex.RUnlock()
+	return fake.getTerminalByOrgGUIDArgsForCall[i].tx, fake.getTerminalByOrgGUIDArgsForCall[i].orgGUID
+}
+
+func (fake *EgressPolicyRepo) GetTerminalByOrgGUIDReturns(result1 string, result2 error) {
+	fake.GetTerminalByOrgGUIDStub = nil
+	fake.getTerminalByOrgGUIDReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *EgressPolicyRepo) GetTerminalByOrgGUIDReturnsOnCall(i int, result1 string, result2 error) {
+	fake.GetTerminalByOrgGUIDStub = nil
+	if fake.getTerminalByOrgGUIDReturnsOnCall == nil {
+		fake.getTerminalByOrgGUIDReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.getTerminalByOrgGUIDReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *EgressPolicyRepo) GetDefaultTerminal(tx db.Transaction) (string, error) {
+	fake.getDefaultTerminalMutex.Lock()
+	ret, specificReturn := fake.getDefaultTerminalReturnsOnCall[len(fake.getDefaultTerminalArgsForCall)]
+	fake.getDefaultTerminalArgsForCall = append(fake.getDefaultTerminalArgsForCall, struct {
+		tx db.Transaction
+	}{tx})
+	fake.recordInvocation("GetDefaultTerminal", []interface{}{tx})
+	fake.getDefaultTerminalMutex.Unlock()
+	if fake.GetDefaultTerminalStub != nil {
+		return fake.GetDefaultTerminalStub(tx)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getDefaultTerminalReturns.result1, fake.getDefaultTerminalReturns.result2
+}
+
+func (fake *EgressPolicyRepo) GetDefaultTerminalCallCount() int {
+	fake.getDefaultTerminalMutex.RLock()
+	defer fake.getDefaultTerminalMutex.RUnlock()
+	return len(fake.getDefaultTerminalArgsForCall)
+}
+
+func (fake *EgressPolicyRepo) GetDefaultTerminalArgsForCall(i int) db.Transaction {
+	fake.getDefaultTerminalMutex.RLock()
+	defer fake.getDefaultTerminalMutex.RUnlock()
+	return fake.getDefaultTerminalArgsForCall[i].tx
+}
+
+func (fake *EgressPolicyRepo) GetDefaultTerminalReturns(result1 string, result2 error) {
+	fake.GetDefaultTerminalStub = nil
+	fake.getDefaultTerminalReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *EgressPolicyRepo) GetDefaultTerminalReturnsOnCall(i int, result1 string, result2 error) {
+	fake.GetDefaultTerminalStub = nil
+	if fake.getDefaultTerminalReturnsOnCall == nil {
+		fake.getDefaultTerminalReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.getDefaultTerminalReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *EgressPolicyRepo) DeleteOrg(tx db.Transaction, terminalGUID string) error {
+	fake.deleteOrgMutex.Lock()
+	ret, specificReturn := fake.deleteOrgReturnsOnCall[len(fake.deleteOrgArgsForCall)]
+	fake.deleteOrgArgsForCall = append(fake.deleteOrgArgsForCall, struct {
+		tx           db.Transaction
+		terminalGUID string
+	}{tx, terminalGUID})
+	fake.recordInvocation("DeleteOrg", []interface{}{tx, terminalGUID})
+	fake.deleteOrgMutex.Unlock()
+	if fake.DeleteOrgStub != nil {
+		return fake.DeleteOrgStub(tx, terminalGUID)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.deleteOrgReturns.result1
+}
+
+func (fake *EgressPolicyRepo) DeleteOrgCallCount() int {
+	fake.deleteOrgMutex.RLock()
+	defer fake.deleteOrgMutex.RUnlock()
+	return len(fake.deleteOrgArgsForCall)
+}
+
+func (fake *EgressPolicyRepo) DeleteOrgArgsForCall(i int) (db.Transaction, string) {
+	fake.deleteOrgMutex.RLock()
+	defer fake.deleteOrgMutex.RUnlock()
+	return fake.deleteOrgArgsForCall[i].tx, fake.deleteOrgArgsForCall[i].terminalGUID
+}
+
+func (fake *EgressPolicyRepo) DeleteOrgReturns(result1 error) {
+	fake.DeleteOrgStub = nil
+	fake.deleteOrgReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *EgressPolicyRepo) DeleteOrgReturnsOnCall(i int, result1 error) {
+	fake.DeleteOrgStub = nil
+	if fake.deleteOrgReturnsOnCall == nil {
+		fake.deleteOrgReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteOrgReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *EgressPolicyRepo) DeleteDefault(tx db.Transaction, terminalGUID string) error {
+	fake.deleteDefaultMutex.Lock()
+	ret, specificReturn := fake.deleteDefaultReturnsOnCall[len(fake.deleteDefaultArgsForCall)]
+	fake.deleteDefaultArgsForCall = append(fake.deleteDefaultArgsForCall, struct {
+		tx           db.Transaction
+		terminalGUID string
+	}{tx, terminalGUID})
+	fake.recordInvocation("DeleteDefault", []interface{}{tx, terminalGUID})
+	fake.deleteDefaultMutex.Unlock()
+	if fake.DeleteDefaultStub != nil {
+		return fake.DeleteDefaultStub(tx, terminalGUID)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.deleteDefaultReturns.result1
+}
+
+func (fake *EgressPolicyRepo) DeleteDefaultCallCount() int {
+	fake.deleteDefaultMutex.RLock()
+	defer fake.deleteDefaultMutex.RUnlock()
+	return len(fake.deleteDefaultArgsForCall)
+}
+
+func (fake *EgressPolicyRepo) DeleteDefaultArgsForCall(i int) (db.Transaction, string) {
+	fake.deleteDefaultMutex.RLock()
+	defer fake.deleteDefaultMutex.RUnlock()
+	return fake.deleteDefaultArgsForCall[i].tx, fake.deleteDefaultArgsForCall[i].terminalGUID
+}
+
+func (fake *EgressPolicyRepo) DeleteDefaultReturns(result1 error) {
+	fake.DeleteDefaultStub = nil
+	fake.deleteDefaultReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *EgressPolicyRepo) DeleteDefaultReturnsOnCall(i int, result1 error) {
+	fake.DeleteDefaultStub = nil
+	if fake.deleteDefaultReturnsOnCall == nil {
+		fake.deleteDefaultReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteDefaultReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *EgressPolicyRepo) GetByFilter(filter store.EgressPolicyFilter) ([]store.EgressPolicy, error) {
+	fake.getByFilterMutex.Lock()
+	ret, specificReturn := fake.getByFilterReturnsOnCall[len(fake.getByFilterArgsForCall)]
+	fake.getByFilterArgsForCall = append(fake.getByFilterArgsForCall, struct {
+		filter store.EgressPolicyFilter
+	}{filter})
+	fake.recordInvocation("GetByFilter", []interface{}{filter})
+	fake.getByFilterMutex.Unlock()
+	if fake.GetByFilterStub != nil {
+		return fake.GetByFilterStub(filter)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getByFilterReturns.result1, fake.getByFilterReturns.result2
+}
+
+func (fake *EgressPolicyRepo) GetByFilterCallCount() int {
+	fake.getByFilterMutex.RLock()
+	defer fake.getByFilterMutex.RUnlock()
+	return len(fake.getByFilterArgsForCall)
+}
+
+func (fake *EgressPolicyRepo) GetByFilterArgsForCall(i int) store.EgressPolicyFilter {
+	fake.getByFilterMutex.RLock()
+	defer fake.getByFilterMutex.RUnlock()
+	return fake.getByFilterArgsForCall[i].filter
+}
+
+func (fake *EgressPolicyRepo) GetByFilterReturns(result1 []store.EgressPolicy, result2 error) {
+	fake.GetByFilterStub = nil
+	fake.getByFilterReturns = struct {
+		result1 []store.EgressPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *EgressPolicyRepo) GetByFilterReturnsOnCall(i int, result1 []store.EgressPolicy, result2 error) {
+	fake.GetByFilterStub = nil
+	if fake.getByFilterReturnsOnCall == nil {
+		fake.getByFilterReturnsOnCall = make(map[int]struct {
+			result1 []store.EgressPolicy
+			result2 error
+		})
+	}
+	fake.getByFilterReturnsOnCall[i] = struct {
+		result1 []store.EgressPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *EgressPolicyRepo) CountByFilter(filter store.EgressPolicyFilter) (int, error) {
+	fake.countByFilterMutex.Lock()
+	ret, specificReturn := fake.countByFilterReturnsOnCall[len(fake.countByFilterArgsForCall)]
+	fake.countByFilterArgsForCall = append(fake.countByFilterArgsForCall, struct {
+		filter store.EgressPolicyFilter
+	}{filter})
+	fake.recordInvocation("CountByFilter", []interface{}{filter})
+	fake.countByFilterMutex.Unlock()
+	if fake.CountByFilterStub != nil {
+		return fake.CountByFilterStub(filter)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.countByFilterReturns.result1, fake.countByFilterReturns.result2
+}
+
+func (fake *EgressPolicyRepo) CountByFilterCallCount() int {
+	fake.countByFilterMutex.RLock()
+	defer fake.countByFilterMutex.RUnlock()
+	return len(fake.countByFilterArgsForCall)
+}
+
+func (fake *EgressPolicyRepo) CountByFilterArgsForCall(i int) store.EgressPolicyFilter {
+	fake.countByFilterMutex.RLock()
+	defer fake.countByFilterMutex.RUnlock()
+	return fake.countByFilterArgsForCall[i].filter
+}
+
+func (fake *EgressPolicyRepo) CountByFilterReturns(result1 int, result2 error) {
+	fake.CountByFilterStub = nil
+	fake.countByFilterReturns = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *EgressPolicyRepo) CountByFilterReturnsOnCall(i int, result1 int, result2 error) {
+	fake.CountByFilterStub = nil
+	if fake.countByFilterReturnsOnCall == nil {
+		fake.countByFilterReturnsOnCall = make(map[int]struct {
+			result1 int
+			result2 error
+		})
+	}
+	fake.countByFilterReturnsOnCall[i] = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *EgressPolicyRepo) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -959,6 +1542,24 @@ func (fake *EgressPolicyRepo) Invocations() map[string][][]interface{} {
 	defer fake.deleteSpaceMutex.RUnlock()
 	fake.isTerminalInUseMutex.RLock()
 	defer fake.isTerminalInUseMutex.RUnlock()
+	fake.updateEgressPolicyMutex.RLock()
+	defer fake.updateEgressPolicyMutex.RUnlock()
+	fake.createOrgMutex.RLock()
+	defer fake.createOrgMutex.RUnlock()
+	fake.createDefaultMutex.RLock()
+	defer fake.createDefaultMutex.RUnlock()
+	fake.getTerminalByOrgGUIDMutex.RLock()
+	defer fake.getTerminalByOrgGUIDMutex.RUnlock()
+	fake.getDefaultTerminalMutex.RLock()
+	defer fake.getDefaultTerminalMutex.RUnlock()
+	fake.deleteOrgMutex.RLock()
+	defer fake.deleteOrgMutex.RUnlock()
+	fake.deleteDefaultMutex.RLock()
+	defer fake.deleteDefaultMutex.RUnlock()
+	fake.getByFilterMutex.RLock()
+	defer fake.getByFilterMutex.RUnlock()
+	fake.countByFilterMutex.RLock()
+	defer fake.countByFilterMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -9,12 +9,13 @@ import (
 )
 
 type PolicyRepo struct {
-	CreateStub        func(db.Transaction, int, int) error
+	CreateStub        func(db.Transaction, int, int, *bool) error
 	createMutex       sync.RWMutex
 	createArgsForCall []struct {
 		arg1 db.Transaction
 		arg2 int
 		arg3 int
+		arg4 *bool
 	}
 	createReturns struct {
 		result1 error
@@ -67,18 +68,19 @@ type PolicyRepo struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *PolicyRepo) Create(arg1 db.Transaction, arg2 int, arg3 int) error {
+func (fake *PolicyRepo) Create(arg1 db.Transaction, arg2 int, arg3 int, arg4 *bool) error {
 	fake.createMutex.Lock()
 	ret, specificReturn := fake.createReturnsOnCall[len(fake.createArgsForCall)]
 	fake.createArgsForCall = append(fake.createArgsForCall, struct {
 		arg1 db.Transaction
 		arg2 int
 		arg3 int
-	}{arg1, arg2, arg3})
-	fake.recordInvocation("Create", []interface{}{arg1, arg2, arg3})
+		arg4 *bool
+	}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("Create", []interface{}{arg1, arg2, arg3, arg4})
 	fake.createMutex.Unlock()
 	if fake.CreateStub != nil {
-		return fake.CreateStub(arg1, arg2, arg3)
+		return fake.CreateStub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1
@@ -92,10 +94,10 @@ func (fake *PolicyRepo) CreateCallCount() int {
 	return len(fake.createArgsForCall)
 }
 
-func (fake *PolicyRepo) CreateArgsForCall(i int) (db.Transaction, int, int) {
+func (fake *PolicyRepo) CreateArgsForCall(i int) (db.Transaction, int, int, *bool) {
 	fake.createMutex.RLock()
 	defer fake.createMutex.RUnlock()
-	return fake.createArgsForCall[i].arg1, fake.createArgsForCall[i].arg2, fake.createArgsForCall[i].arg3
+	return fake.createArgsForCall[i].arg1, fake.createArgsForCall[i].arg2, fake.createArgsForCall[i].arg3, fake.createArgsForCall[i].arg4
 }
 
 func (fake *PolicyRepo) CreateReturns(result1 error) {
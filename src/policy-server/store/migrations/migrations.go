@@ -332,4 +332,32 @@ var MigrationsToPerform = PolicyServerMigrations{
 		Id: "56",
 		Up: migration_v0056,
 	},
+	PolicyServerMigration{
+		Id: "57",
+		Up: migration_v0057,
+	},
+	PolicyServerMigration{
+		Id: "58",
+		Up: migration_v0058,
+	},
+	PolicyServerMigration{
+		Id: "59",
+		Up: migration_v0059,
+	},
+	PolicyServerMigration{
+		Id: "60",
+		Up: migration_v0060,
+	},
+	PolicyServerMigration{
+		Id: "61",
+		Up: migration_v0061,
+	},
+	PolicyServerMigration{
+		Id: "62",
+		Up: migration_v0062,
+	},
+	PolicyServerMigration{
+		Id: "63",
+		Up: migration_v0063,
+	},
 }
@@ -1537,6 +1537,71 @@ var _ = Describe("migrations", func() {
 			})
 		})
 
+		Describe("V57 - Destination metadata FQDN columns", func() {
+			It("should migrate", func() {
+				By("performing migration")
+				migrateTo("57")
+
+				Expect(queryTableColumnNames("destination_metadatas", realDb)).To(ContainElement("fqdn"))
+				Expect(queryTableColumnNames("destination_metadatas", realDb)).To(ContainElement("fqdn_resolution_error"))
+			})
+		})
+
+		Describe("V58 - Create orgs table", func() {
+			It("should migrate", func() {
+				By("performing migration")
+				migrateTo("58")
+
+				Expect(queryTableColumnNames("orgs", realDb)).To(ContainElement("terminal_guid"))
+				Expect(queryTableColumnNames("orgs", realDb)).To(ContainElement("org_guid"))
+			})
+		})
+
+		Describe("V59 - Create defaults table", func() {
+			It("should migrate", func() {
+				By("performing migration")
+				migrateTo("59")
+
+				Expect(queryTableColumnNames("defaults", realDb)).To(ContainElement("terminal_guid"))
+			})
+		})
+
+		Describe("V60 - Add org guid to destination metadatas", func() {
+			It("should migrate", func() {
+				By("performing migration")
+				migrateTo("60")
+
+				Expect(queryTableColumnNames("destination_metadatas", realDb)).To(ContainElement("org_guid"))
+			})
+		})
+
+		Describe("V61 - Add self service to destination metadatas", func() {
+			It("should migrate", func() {
+				By("performing migration")
+				migrateTo("61")
+
+				Expect(queryTableColumnNames("destination_metadatas", realDb)).To(ContainElement("self_service"))
+			})
+		})
+
+		Describe("V62 - Add log to policies", func() {
+			It("should migrate", func() {
+				By("performing migration")
+				migrateTo("62")
+
+				Expect(queryTableColumnNames("policies", realDb)).To(ContainElement("log"))
+			})
+		})
+
+		Describe("V63 - Add log to egress policies", func() {
+			It("should migrate", func() {
+				By("performing migration")
+				migrateTo("63")
+
+				Expect(queryTableColumnNames("egress_policies", realDb)).To(ContainElement("log"))
+			})
+		})
+
 		Context("when migrating in parallel", func() {
 			Context("mysql", func() {
 				BeforeEach(func() {
@@ -0,0 +1,14 @@
+package migrations
+
+var migration_v0057 = map[string][]string{
+	"mysql": {
+		`ALTER TABLE destination_metadatas
+		 ADD COLUMN fqdn VARCHAR(255),
+		 ADD COLUMN fqdn_resolution_error VARCHAR(1024);`,
+	},
+	"postgres": {
+		`ALTER TABLE destination_metadatas
+		 ADD COLUMN fqdn VARCHAR(255),
+		 ADD COLUMN fqdn_resolution_error VARCHAR(1024);`,
+	},
+}
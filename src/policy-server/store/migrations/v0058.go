@@ -0,0 +1,25 @@
+package migrations
+
+var migration_v0058 = map[string][]string{
+	"mysql": {
+		`CREATE TABLE IF NOT EXISTS orgs (
+		id int NOT NULL AUTO_INCREMENT,
+		PRIMARY KEY (id),
+		terminal_guid VARCHAR(36) NOT NULL UNIQUE,
+		CONSTRAINT orgs_terminal_guid_fk
+			FOREIGN KEY (terminal_guid)
+			REFERENCES terminals(guid),
+		org_guid varchar(255),
+		UNIQUE(org_guid),
+		INDEX orgs_org_guid_idx (org_guid)
+	);`,
+	},
+	"postgres": {
+		`CREATE TABLE IF NOT EXISTS orgs (
+		id SERIAL PRIMARY KEY,
+		terminal_guid VARCHAR(36) NOT NULL CONSTRAINT orgs_terminal_guid_unique UNIQUE,
+		FOREIGN KEY (terminal_guid) references terminals(guid),
+		org_guid text CONSTRAINT orgs_org_guid_unique UNIQUE
+	);`,
+	},
+}
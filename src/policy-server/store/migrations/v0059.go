@@ -0,0 +1,21 @@
+package migrations
+
+var migration_v0059 = map[string][]string{
+	"mysql": {
+		`CREATE TABLE IF NOT EXISTS defaults (
+		id int NOT NULL AUTO_INCREMENT,
+		PRIMARY KEY (id),
+		terminal_guid VARCHAR(36) NOT NULL UNIQUE,
+		CONSTRAINT defaults_terminal_guid_fk
+			FOREIGN KEY (terminal_guid)
+			REFERENCES terminals(guid)
+	);`,
+	},
+	"postgres": {
+		`CREATE TABLE IF NOT EXISTS defaults (
+		id SERIAL PRIMARY KEY,
+		terminal_guid VARCHAR(36) NOT NULL CONSTRAINT defaults_terminal_guid_unique UNIQUE,
+		FOREIGN KEY (terminal_guid) references terminals(guid)
+	);`,
+	},
+}
@@ -0,0 +1,12 @@
+package migrations
+
+var migration_v0060 = map[string][]string{
+	"mysql": {
+		`ALTER TABLE destination_metadatas
+		 ADD COLUMN org_guid VARCHAR(36);`,
+	},
+	"postgres": {
+		`ALTER TABLE destination_metadatas
+		 ADD COLUMN org_guid VARCHAR(36);`,
+	},
+}
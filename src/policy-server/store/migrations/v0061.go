@@ -0,0 +1,12 @@
+package migrations
+
+var migration_v0061 = map[string][]string{
+	"mysql": {
+		`ALTER TABLE destination_metadatas
+		 ADD COLUMN self_service BOOLEAN NOT NULL DEFAULT false;`,
+	},
+	"postgres": {
+		`ALTER TABLE destination_metadatas
+		 ADD COLUMN self_service BOOLEAN NOT NULL DEFAULT false;`,
+	},
+}
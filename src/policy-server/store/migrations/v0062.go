@@ -0,0 +1,12 @@
+package migrations
+
+var migration_v0062 = map[string][]string{
+	"mysql": {
+		`ALTER TABLE policies
+		 ADD COLUMN log BOOLEAN NOT NULL DEFAULT false;`,
+	},
+	"postgres": {
+		`ALTER TABLE policies
+		 ADD COLUMN log BOOLEAN NOT NULL DEFAULT false;`,
+	},
+}
@@ -0,0 +1,12 @@
+package migrations
+
+var migration_v0063 = map[string][]string{
+	"mysql": {
+		`ALTER TABLE egress_policies
+		 ADD COLUMN log BOOLEAN NOT NULL DEFAULT false;`,
+	},
+	"postgres": {
+		`ALTER TABLE egress_policies
+		 ADD COLUMN log BOOLEAN NOT NULL DEFAULT false;`,
+	},
+}
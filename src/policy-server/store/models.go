@@ -8,6 +8,10 @@ type PolicyCollection struct {
 type Policy struct {
 	Source      Source
 	Destination Destination
+	Log         bool
+	// KeepLog makes Create leave the log flag of a policy that already
+	// exists as it is, rather than setting it to Log.
+	KeepLog bool
 }
 
 type Source struct {
@@ -38,6 +42,19 @@ type EgressPolicy struct {
 	ID          string
 	Source      EgressSource
 	Destination EgressDestination
+	Log         bool
+}
+
+// EgressPolicyFilter narrows down a listing of egress policies. Empty fields
+// match everything and a zero Limit returns every matching policy.
+type EgressPolicyFilter struct {
+	SourceIDs           []string
+	SourceTypes         []string
+	DestinationIDs      []string
+	DestinationNames    []string
+	DestinationOrgGUIDs []string
+	Limit               int
+	Offset              int
 }
 
 type EgressSource struct {
@@ -47,14 +64,18 @@ type EgressSource struct {
 }
 
 type EgressDestination struct {
-	GUID        string
-	Name        string
-	Description string
-	Protocol    string
-	Ports       []Ports
-	IPRanges    []IPRange
-	ICMPType    int
-	ICMPCode    int
+	GUID                string
+	Name                string
+	Description         string
+	FQDN                string
+	FQDNResolutionError string
+	OrgGUID             string
+	SelfService         bool
+	Protocol            string
+	Ports               []Ports
+	IPRanges            []IPRange
+	ICMPType            int
+	ICMPCode            int
 }
 
 type IPRange struct {
@@ -0,0 +1,25 @@
+package store
+
+import "fmt"
+
+type OverlappingDestinationError struct {
+	operation string
+	Overlap   DestinationOverlap
+}
+
+func NewOverlappingDestinationError(operation string, overlap DestinationOverlap) OverlappingDestinationError {
+	return OverlappingDestinationError{
+		operation: operation,
+		Overlap:   overlap,
+	}
+}
+
+func (o OverlappingDestinationError) Error() string {
+	return fmt.Sprintf("egress destination store %s: overlapping destination error: %s", o.operation, o.Overlap.Description())
+}
+
+// AnonymousError is the error without the name of the overlapping
+// destination, for users who may not see it.
+func (o OverlappingDestinationError) AnonymousError() string {
+	return fmt.Sprintf("egress destination store %s: overlapping destination error: %s", o.operation, o.Overlap.AnonymousDescription())
+}
@@ -4,7 +4,7 @@ import "code.cloudfoundry.org/cf-networking-helpers/db"
 
 //go:generate counterfeiter -o fakes/policy_repo.go --fake-name PolicyRepo . PolicyRepo
 type PolicyRepo interface {
-	Create(db.Transaction, int, int) error
+	Create(db.Transaction, int, int, *bool) error
 	Delete(db.Transaction, int, int) error
 	CountWhereGroupID(db.Transaction, int) (int, error)
 	CountWhereDestinationID(db.Transaction, int) (int, error)
@@ -13,15 +13,18 @@ type PolicyRepo interface {
 type PolicyTable struct {
 }
 
-func (p *PolicyTable) Create(tx db.Transaction, sourceGroupId int, destinationId int) error {
+// Create inserts the policy unless it already exists. Either way the policy
+// ends up with the given log flag. A nil log flag inserts the policy without
+// logging and leaves the flag of an existing policy as it is.
+func (p *PolicyTable) Create(tx db.Transaction, sourceGroupId int, destinationId int, log *bool) error {
 	dualStatement := ""
 	if tx.DriverName() == "mysql" {
 		dualStatement = " FROM DUAL "
 	}
 
 	_, err := tx.Exec(tx.Rebind(`
-		INSERT INTO policies (group_id, destination_id)
-		SELECT ?, ? `+dualStatement+`
+		INSERT INTO policies (group_id, destination_id, log)
+		SELECT ?, ?, ? `+dualStatement+`
 		WHERE
 		NOT EXISTS (
 			SELECT *
@@ -30,6 +33,16 @@ func (p *PolicyTable) Create(tx db.Transaction, sourceGroupId int, destinationId
 		)`),
 		sourceGroupId,
 		destinationId,
+		log != nil && *log,
+		sourceGroupId,
+		destinationId,
+	)
+	if err != nil || log == nil {
+		return err
+	}
+
+	_, err = tx.Exec(tx.Rebind(`UPDATE policies SET log = ? WHERE group_id = ? AND destination_id = ?`),
+		*log,
 		sourceGroupId,
 		destinationId,
 	)
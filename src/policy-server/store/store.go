@@ -115,7 +115,11 @@ func (s *store) createWithTx(tx db.Transaction, policies []Policy) error {
 			return fmt.Errorf("creating destination: %s", err)
 		}
 
-		err = s.policy.Create(tx, sourceGroupId, destinationId)
+		var log *bool
+		if !policy.KeepLog {
+			log = &policy.Log
+		}
+		err = s.policy.Create(tx, sourceGroupId, destinationId, log)
 		if err != nil {
 			return fmt.Errorf("creating policy: %s", err)
 		}
@@ -226,6 +230,7 @@ func (s *store) policiesQuery(query string, args ...interface{}) ([]Policy, erro
 	for rows.Next() {
 		var sourceId, destinationId, protocol string
 		var port, startPort, endPort, sourceTag, destinationTag int
+		var log bool
 		err = rows.Scan(
 			&sourceId,
 			&sourceTag,
@@ -235,6 +240,7 @@ func (s *store) policiesQuery(query string, args ...interface{}) ([]Policy, erro
 			&startPort,
 			&endPort,
 			&protocol,
+			&log,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("listing all: %s", err)
@@ -255,6 +261,7 @@ func (s *store) policiesQuery(query string, args ...interface{}) ([]Policy, erro
 					End:   endPort,
 				},
 			},
+			Log: log,
 		})
 	}
 	err = rows.Err()
@@ -289,7 +296,8 @@ func (s *store) ByGuids(srcGuids, destGuids []string, inSourceAndDest bool) ([]P
 			destinations.port,
 			destinations.start_port,
 			destinations.end_port,
-			destinations.protocol
+			destinations.protocol,
+			policies.log
 		from policies
 		left outer join groups as src_grp on (policies.group_id = src_grp.id)
 		left outer join destinations on (destinations.id = policies.destination_id)
@@ -326,7 +334,8 @@ func (s *store) All() ([]Policy, error) {
 			destinations.port,
 			destinations.start_port,
 			destinations.end_port,
-			destinations.protocol
+			destinations.protocol,
+			policies.log
 		from policies
 		left outer join groups as src_grp on (policies.group_id = src_grp.id)
 		left outer join destinations on (destinations.id = policies.destination_id)
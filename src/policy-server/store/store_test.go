@@ -253,6 +253,60 @@ var _ = Describe("Store", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(p)).To(Equal(1))
 			})
+
+			It("updates the log flag", func() {
+				policies := []store.Policy{{
+					Source: store.Source{ID: "some-app-guid"},
+					Destination: store.Destination{
+						ID:       "some-other-app-guid",
+						Protocol: "tcp",
+						Ports: store.Ports{
+							Start: 7000,
+							End:   8000,
+						},
+					},
+				}}
+
+				err := dataStore.Create(policies)
+				Expect(err).NotTo(HaveOccurred())
+
+				policies[0].Log = true
+				err = dataStore.Create(policies)
+				Expect(err).NotTo(HaveOccurred())
+
+				p, err := dataStore.All()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(p).To(HaveLen(1))
+				Expect(p[0].Log).To(BeTrue())
+			})
+
+			It("keeps the log flag when it is not given", func() {
+				policies := []store.Policy{{
+					Source: store.Source{ID: "some-app-guid"},
+					Destination: store.Destination{
+						ID:       "some-other-app-guid",
+						Protocol: "tcp",
+						Ports: store.Ports{
+							Start: 7000,
+							End:   8000,
+						},
+					},
+					Log: true,
+				}}
+
+				err := dataStore.Create(policies)
+				Expect(err).NotTo(HaveOccurred())
+
+				policies[0].Log = false
+				policies[0].KeepLog = true
+				err = dataStore.Create(policies)
+				Expect(err).NotTo(HaveOccurred())
+
+				p, err := dataStore.All()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(p).To(HaveLen(1))
+				Expect(p[0].Log).To(BeTrue())
+			})
 		})
 
 		Context("when there are no tags left to allocate", func() {